@@ -0,0 +1,77 @@
+package resolv
+
+import "math"
+
+// AoEHit is a Shape caught in an area of effect, like by Space.Explosion().
+type AoEHit struct {
+	Shape Shape
+
+	// Distance is how far the Shape's closest point (X, Y, rounded to the nearest pixel) is from the center of the area,
+	// for scaling damage with; it's 0 for a Shape that contains the center.
+	Distance float64
+	X, Y     int32
+
+	// Blocked is whether the line of sight from the center to the Shape's closest point is blocked.
+	Blocked bool
+}
+
+// Explosion returns the Shapes in the Space that are within the radius provided of the point x, y (like for a grenade),
+// in the order they're in the Space, along with how far each one's closest point is from x, y, and whether the line of
+// sight from x, y to that point is blocked by a Shape that has all of the blocker tags provided (like a wall). If no
+// blocker tags are provided, any Shape can block sight, like with LineOfSight().
+// A Shape whose closest point is exactly radius away counts as within it, like a Circle just touching another does. A
+// Shape that contains x, y itself (like the grenade) is hit at a distance of 0 and is never blocked; nor does it block
+// the line of sight to the other Shapes, as the explosion starts inside it. A hit Shape never blocks the line of sight to
+// itself.
+func (sp *Space) Explosion(x, y, radius int32, blockerTags ...string) []AoEHit {
+
+	area := NewCircle(x, y, radius)
+	center := NewRectangle(x, y, 1, 1)
+
+	// The Shapes containing the center are found first, so that they can be left out of the lines of sight.
+	var epicenter []Shape
+	for _, shape := range sp.shapes {
+		if center.IsColliding(shape) {
+			epicenter = append(epicenter, shape)
+		}
+	}
+
+	var hits []AoEHit
+	sight := NewLine(x, y, x, y)
+	ignore := make([]Shape, len(epicenter)+1)
+	copy(ignore[1:], epicenter)
+
+	for _, shape := range sp.shapes {
+
+		if !area.IsColliding(shape) {
+			continue
+		}
+
+		hit := AoEHit{Shape: shape, X: x, Y: y}
+
+		if !containsShape(epicenter, shape) {
+			cx, cy := closestPoint(shape, x, y)
+			hit.Distance = math.Hypot(cx-float64(x), cy-float64(y))
+			hit.X, hit.Y = int32(math.Round(cx)), int32(math.Round(cy))
+			sight.SetEndpoints(x, y, hit.X, hit.Y)
+			ignore[0] = shape
+			hit.Blocked = !sp.lineOfSight(sight, blockerTags, ignore...)
+		}
+
+		hits = append(hits, hit)
+
+	}
+
+	return hits
+
+}
+
+// containsShape returns whether the Shape provided is in the slice.
+func containsShape(shapes []Shape, shape Shape) bool {
+	for _, s := range shapes {
+		if s == shape {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package resolv
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// arenaSlabSize is how many Shapes of each type a ShapeArena allocates at once.
+const arenaSlabSize = 256
+
+// ShapeArena allocates Shapes from contiguous slabs, rather than one at a time, for loading levels with many Shapes:
+// there are far fewer allocations for the garbage collector to keep track of, and the Shapes are laid out next to each
+// other in memory. Shapes from a ShapeArena behave just like Shapes created with NewRectangle() and the like.
+// Once the level's done with (like when it's unloaded), Reset() frees all of the ShapeArena's Shapes at once, and the
+// ShapeArena's slabs are reused for the Shapes allocated after that. Every Shape from the ShapeArena has to be removed
+// from the Spaces it's in (or the Spaces cleared) before then; Reset() panics if any of them are still in a Space. The
+// Spaces returned by queries (like Filter() and GetCollidingShapes()) don't count, so they don't have to be cleared.
+// The zero ShapeArena is ready to use. Like Spaces, a ShapeArena isn't safe to allocate from in multiple goroutines at
+// once.
+type ShapeArena struct {
+	rectangleSlabs [][]Rectangle
+	circleSlabs    [][]Circle
+	lineSlabs      [][]Line
+
+	// How many Shapes of each type have been allocated from the slabs.
+	rectangles, circles, lines int
+
+	// live is how many times Shapes from the ShapeArena are in Spaces.
+	live int64
+}
+
+// arenaSlot returns the slab and the index within it of the Shape allocated after the number of Shapes provided.
+func arenaSlot(used int) (slab, index int) {
+	return used / arenaSlabSize, used % arenaSlabSize
+}
+
+// NewShapeArena returns a new, empty ShapeArena.
+func NewShapeArena() *ShapeArena {
+	return &ShapeArena{}
+}
+
+// NewRectangle returns a new Rectangle allocated from the ShapeArena, like resolv.NewRectangle() does.
+func (a *ShapeArena) NewRectangle(x, y, w, h int32, opts ...ShapeOption) *Rectangle {
+	slab, index := arenaSlot(a.rectangles)
+	if slab == len(a.rectangleSlabs) {
+		a.rectangleSlabs = append(a.rectangleSlabs, make([]Rectangle, arenaSlabSize))
+	}
+	a.rectangles++
+	r := &a.rectangleSlabs[slab][index]
+	r.arena = a
+	r.init(x, y, w, h, opts)
+	return r
+}
+
+// NewCircle returns a new Circle allocated from the ShapeArena, like resolv.NewCircle() does.
+func (a *ShapeArena) NewCircle(x, y, radius int32, opts ...ShapeOption) *Circle {
+	slab, index := arenaSlot(a.circles)
+	if slab == len(a.circleSlabs) {
+		a.circleSlabs = append(a.circleSlabs, make([]Circle, arenaSlabSize))
+	}
+	a.circles++
+	c := &a.circleSlabs[slab][index]
+	c.arena = a
+	c.init(x, y, radius, opts)
+	return c
+}
+
+// NewLine returns a new Line allocated from the ShapeArena, like resolv.NewLine() does.
+func (a *ShapeArena) NewLine(x, y, x2, y2 int32, opts ...ShapeOption) *Line {
+	slab, index := arenaSlot(a.lines)
+	if slab == len(a.lineSlabs) {
+		a.lineSlabs = append(a.lineSlabs, make([]Line, arenaSlabSize))
+	}
+	a.lines++
+	l := &a.lineSlabs[slab][index]
+	l.arena = a
+	l.init(x, y, x2, y2, opts)
+	return l
+}
+
+// Len returns how many Shapes have been allocated from the ShapeArena since it was created or last reset.
+func (a *ShapeArena) Len() int {
+	return a.rectangles + a.circles + a.lines
+}
+
+// Reset frees all of the Shapes allocated from the ShapeArena at once, so that its slabs are reused for the Shapes
+// allocated after it. The Shapes mustn't be used once the ShapeArena is reset, as they'll be handed out again as new
+// Shapes. Reset panics if any of the Shapes are still in a Space, as the Space would otherwise end up with Shapes that
+// change out from under it.
+func (a *ShapeArena) Reset() {
+
+	if live := atomic.LoadInt64(&a.live); live > 0 {
+		panic(fmt.Sprintf("ERROR! ShapeArena can't be reset, as its Shapes are still in Spaces %d times!", live))
+	}
+
+	// The Shapes are zeroed so that the slabs don't keep their tags, Data, and OnMove() functions alive.
+	for i := 0; i < a.rectangles; i++ {
+		slab, index := arenaSlot(i)
+		a.rectangleSlabs[slab][index] = Rectangle{}
+	}
+	for i := 0; i < a.circles; i++ {
+		slab, index := arenaSlot(i)
+		a.circleSlabs[slab][index] = Circle{}
+	}
+	for i := 0; i < a.lines; i++ {
+		slab, index := arenaSlot(i)
+		a.lineSlabs[slab][index] = Line{}
+	}
+
+	a.rectangles, a.circles, a.lines = 0, 0, 0
+
+}
+
+func (b *BasicShape) shapeArena() *ShapeArena {
+	return b.arena
+}
+
+// arenaOf returns the ShapeArena the Shape was allocated from, or nil if it wasn't allocated from one.
+func arenaOf(shape Shape) *ShapeArena {
+	if s, ok := shape.(interface{ shapeArena() *ShapeArena }); ok {
+		return s.shapeArena()
+	}
+	return nil
+}
+
+// holdArenaShape counts the Shape as being in the Space, if it's from a ShapeArena and the Space isn't the results of a
+// query.
+func (sp *Space) holdArenaShape(shape Shape) {
+	if sp.result {
+		return
+	}
+	if a := arenaOf(shape); a != nil {
+		atomic.AddInt64(&a.live, 1)
+		sp.arenaShapes++
+	}
+}
+
+// releaseArenaShape counts the Shape as no longer being in the Space, if it's from a ShapeArena.
+func (sp *Space) releaseArenaShape(shape Shape) {
+	if sp.result {
+		return
+	}
+	if a := arenaOf(shape); a != nil {
+		atomic.AddInt64(&a.live, -1)
+		sp.arenaShapes--
+	}
+}
+
+// releaseArenaShapes counts all of the Shapes in the Space as no longer being in it, for when the Space is cleared.
+func (sp *Space) releaseArenaShapes() {
+	if sp.arenaShapes == 0 {
+		return
+	}
+	for _, shape := range sp.shapes {
+		sp.releaseArenaShape(shape)
+	}
+}
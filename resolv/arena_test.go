@@ -0,0 +1,161 @@
+package resolv
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// arenaCopy returns a copy of the built-in Shape provided, allocated from the ShapeArena.
+func arenaCopy(a *ShapeArena, shape Shape) Shape {
+	tags := WithTags(shape.GetTags()...)
+	switch s := shape.(type) {
+	case *Rectangle:
+		return a.NewRectangle(s.X, s.Y, s.W, s.H, tags)
+	case *Circle:
+		return a.NewCircle(s.X, s.Y, s.Radius, tags)
+	case *Line:
+		return a.NewLine(s.X, s.Y, s.X2, s.Y2, tags)
+	}
+	panic(fmt.Sprintf("can't copy %T", shape))
+}
+
+func TestShapeArenaMatchesHeap(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(428))
+	arena := NewShapeArena()
+
+	// More Shapes than fit in a slab, so that some come from the second.
+	heap, arenaShapes := NewSpace(), NewSpace()
+	for i := 0; i < arenaSlabSize*3/2; i++ {
+		shape := randomShape(rng, false)
+		if rng.Intn(3) == 0 {
+			shape.AddTags("solid")
+		}
+		heap.Add(shape)
+		arenaShapes.Add(arenaCopy(arena, shape))
+	}
+
+	for i := 0; i < 500; i++ {
+		probe := randomShape(rng, false)
+		dx, dy := rng.Int31n(64)-32, rng.Int31n(64)-32
+		for j := 0; j < heap.Length(); j++ {
+			h, a := heap.Get(j), arenaShapes.Get(j)
+			if h.IsColliding(probe) != a.IsColliding(probe) || probe.IsColliding(h) != probe.IsColliding(a) {
+				t.Fatalf("IsColliding(%v) = %v for %v, but %v for the arena's copy", probe, h.IsColliding(probe), h,
+					a.IsColliding(probe))
+			}
+			hc, ac := Resolve(probe, h, dx, dy), Resolve(probe, a, dx, dy)
+			if hc.ResolveX != ac.ResolveX || hc.ResolveY != ac.ResolveY || hc.Teleporting != ac.Teleporting {
+				t.Fatalf("Resolve(%v, %v, %d, %d) = %+v, but %+v for the arena's copy", probe, h, dx, dy, hc, ac)
+			}
+		}
+		if h, a := heap.GetCollidingShapes(probe).Length(), arenaShapes.GetCollidingShapes(probe).Length(); h != a {
+			t.Fatalf("GetCollidingShapes(%v) found %d Shapes, but %d in the arena's Space", probe, h, a)
+		}
+	}
+
+	if h, a := len(heap.GetCollidingPairs()), len(arenaShapes.GetCollidingPairs()); h != a {
+		t.Errorf("GetCollidingPairs() found %d pairs, but %d in the arena's Space", h, a)
+	}
+	if h, a := heap.CountByTags("solid"), arenaShapes.CountByTags("solid"); h != a {
+		t.Errorf("CountByTags() = %d, but %d in the arena's Space", h, a)
+	}
+	if arena.Len() != heap.Length() {
+		t.Errorf("Len() = %d, want %d", arena.Len(), heap.Length())
+	}
+
+}
+
+// mustPanic returns whether the function provided panics.
+func mustPanic(f func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	f()
+	return false
+}
+
+func TestShapeArenaReset(t *testing.T) {
+
+	arena := NewShapeArena()
+	rect, circle := arena.NewRectangle(0, 0, 8, 8, WithTags("solid")), arena.NewCircle(4, 4, 4)
+	nested, space := NewSpace(), NewSpace()
+	nested.Add(circle)
+	space.Add(rect, nested)
+
+	tests := []struct {
+		name      string
+		change    func()
+		wantPanic bool
+	}{
+		{"in a Space", func() {}, true},
+		{"query results", func() {
+			space.Filter(func(Shape) bool { return true })
+			space.GetCollidingShapes(NewRectangle(0, 0, 16, 16))
+		}, true},
+		{"removed from one Space", func() { space.Remove(rect) }, true},
+		{"in the other Space twice", func() { nested.Add(circle) }, true},
+		{"removed from it once", func() { nested.Remove(circle) }, true},
+		{"cleared", func() { nested.Clear() }, false},
+	}
+
+	for _, tt := range tests {
+		tt.change()
+		if panicked := mustPanic(arena.Reset); panicked != tt.wantPanic {
+			t.Fatalf("%s: Reset() panicked = %v, want %v", tt.name, panicked, tt.wantPanic)
+		}
+	}
+
+	// Once reset, the ShapeArena's slabs are reused, and the Shapes handed out again are like new ones.
+	if arena.Len() != 0 {
+		t.Errorf("Len() after Reset() = %d, want 0", arena.Len())
+	}
+	again := arena.NewRectangle(2, 2, 4, 4)
+	if again != rect {
+		t.Error("the Rectangle allocated after Reset() doesn't reuse the first one's place in the slab")
+	}
+	if again.HasTags("solid") || again.X != 2 || again.W != 4 || again.Version() != 0 {
+		t.Errorf("the Rectangle allocated after Reset() is %v with tags %v and version %d", again, again.GetTags(),
+			again.Version())
+	}
+
+}
+
+// loadShapes adds the number of Rectangles provided to the Space, allocated from the ShapeArena if it's not nil.
+func loadShapes(space *Space, arena *ShapeArena, count int) {
+	for i := 0; i < count; i++ {
+		x, y := int32(i%100)*16, int32(i/100)*16
+		if arena != nil {
+			space.Add(arena.NewRectangle(x, y, 16, 16))
+		} else {
+			space.Add(NewRectangle(x, y, 16, 16))
+		}
+	}
+}
+
+func BenchmarkShapeArenaLoad(b *testing.B) {
+
+	const shapes = 10000
+
+	b.Run("heap", func(b *testing.B) {
+		space := NewSpace()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			loadShapes(space, nil, shapes)
+			space.ClearWithoutCallbacks()
+		}
+	})
+
+	// Reusing the ShapeArena between loads is what makes it quicker, as the Shapes are allocated once.
+	b.Run("arena", func(b *testing.B) {
+		space, arena := NewSpace(), NewShapeArena()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			loadShapes(space, arena, shapes)
+			space.ClearWithoutCallbacks()
+			arena.Reset()
+		}
+	})
+
+}
@@ -0,0 +1,79 @@
+package resolv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type asepriteBounds struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+type asepriteSlice struct {
+	Name string `json:"name"`
+	Keys []struct {
+		Frame  int             `json:"frame"`
+		Bounds *asepriteBounds `json:"bounds"`
+	} `json:"keys"`
+}
+
+type asepriteFile struct {
+	Meta *struct {
+		Slices *[]asepriteSlice `json:"slices"`
+	} `json:"meta"`
+}
+
+// LoadAsepriteSlices reads the JSON metadata exported by Aseprite for a sprite sheet, and returns a Space for each of the
+// sprite's slices (like hitboxes authored in Aseprite), by the slices' names. Each Space holds a Rectangle for each of its
+// slice's keys, tagged with the index of the frame the key starts at, like "frame=3"; a key applies from its frame until
+// the frame of the next key, so the Rectangle for a frame is the one with the highest frame index that isn't after it.
+// Only the bounds of slices are read, so slices with 9-patch centers or pivots are loaded like any others.
+// LoadAsepriteSlices returns an error if the JSON can't be read, if it has no slices section (like when slices weren't
+// included in the export), or if a slice key has no bounds or has a negative size.
+func LoadAsepriteSlices(r io.Reader) (map[string]*Space, error) {
+
+	file := asepriteFile{}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("resolv: can't read Aseprite JSON: %w", err)
+	}
+
+	if file.Meta == nil {
+		return nil, fmt.Errorf("resolv: Aseprite JSON has no \"meta\" section")
+	}
+	if file.Meta.Slices == nil {
+		return nil, fmt.Errorf("resolv: Aseprite JSON has no \"slices\" section in \"meta\"; make sure slices are included when exporting the sprite sheet")
+	}
+
+	spaces := map[string]*Space{}
+
+	for _, slice := range *file.Meta.Slices {
+
+		space := spaces[slice.Name]
+		if space == nil {
+			space = NewSpace(WithCapacity(len(slice.Keys)))
+			spaces[slice.Name] = space
+		}
+
+		for _, key := range slice.Keys {
+			if key.Bounds == nil {
+				return nil, fmt.Errorf("resolv: Aseprite slice %q has a key at frame %d without bounds", slice.Name, key.Frame)
+			}
+			b := key.Bounds
+			rect := &Rectangle{BasicShape: BasicShape{X: b.X, Y: b.Y}, W: b.W, H: b.H}
+			applyShapeOptions(rect, []ShapeOption{WithTags("frame=" + strconv.Itoa(key.Frame))})
+			if err := rect.Validate(); err != nil {
+				return nil, fmt.Errorf("resolv: Aseprite slice %q at frame %d: %w", slice.Name, key.Frame, err)
+			}
+			space.Add(rect)
+		}
+
+	}
+
+	return spaces, nil
+
+}
@@ -0,0 +1,129 @@
+package resolv
+
+// WouldBeCollidingBatch returns, for each of the deltas provided, whether the Shape would be colliding with any of the
+// Shapes in the Space if it moved by that delta, like calling shape.WouldBeColliding(sp, dx, dy) for each of them. This
+// is meant for things like AI picking between many directions to move in each frame: rather than going through the Space
+// once for each delta, it goes through it once, testing each Shape in the Space against every delta that isn't yet known
+// to collide (and skipping Shapes that aren't near any of the deltas). The Shape itself isn't changed.
+// Only the built-in Shapes (other than Spaces) can be tested in a single pass; other Shapes are tested for each delta in
+// turn.
+func (sp *Space) WouldBeCollidingBatch(shape Shape, deltas [][2]int32) []bool {
+
+	results := make([]bool, len(deltas))
+
+	moved := translatedCopies(shape, deltas)
+	if moved == nil {
+		for i, delta := range deltas {
+			results[i] = shape.WouldBeColliding(sp, delta[0], delta[1])
+		}
+		return results
+	}
+
+	defer sp.stats.timeSince(sp.stats.now())
+
+	// The bounds of all of the moved copies together, so that Shapes that none of them are near are skipped at once.
+	var all aabb
+	for i, m := range moved {
+		bounds, _ := shapeBounds(m)
+		if i == 0 {
+			all = bounds
+			continue
+		}
+		all.minX, all.minY = minInt64(all.minX, bounds.minX), minInt64(all.minY, bounds.minY)
+		all.maxX, all.maxY = maxInt64(all.maxX, bounds.maxX), maxInt64(all.maxY, bounds.maxY)
+	}
+
+	remaining := len(deltas)
+
+	for _, other := range sp.shapes {
+
+		if remaining == 0 {
+			break
+		}
+
+		sp.stats.candidate()
+
+		if other == shape || !sp.canCollide(shape, other) {
+			continue
+		}
+
+		if otherBounds, ok := shapeBounds(other); ok && !all.overlaps(otherBounds) {
+			continue
+		}
+
+		for i, m := range moved {
+			if results[i] {
+				continue
+			}
+			sp.stats.test()
+			if collidingAs(shape, m, other) {
+				results[i] = true
+				remaining--
+			}
+		}
+
+	}
+
+	return results
+
+}
+
+// translatedCopies returns copies of the Shape moved by each of the deltas provided, or nil if the Shape isn't one of the
+// built-in Shapes that can be copied that way. The copies are allocated together, rather than one at a time.
+func translatedCopies(shape Shape, deltas [][2]int32) []Shape {
+
+	moved := make([]Shape, len(deltas))
+
+	switch s := shape.(type) {
+
+	case *Rectangle:
+		copies := make([]Rectangle, len(deltas))
+		for i, delta := range deltas {
+			copies[i] = *s
+			copies[i].X += delta[0]
+			copies[i].Y += delta[1]
+			moved[i] = &copies[i]
+		}
+
+	case *Circle:
+		copies := make([]Circle, len(deltas))
+		for i, delta := range deltas {
+			copies[i] = *s
+			copies[i].X += delta[0]
+			copies[i].Y += delta[1]
+			moved[i] = &copies[i]
+		}
+
+	case *Line:
+		copies := make([]Line, len(deltas))
+		for i, delta := range deltas {
+			copies[i] = *s
+			copies[i].X += delta[0]
+			copies[i].Y += delta[1]
+			copies[i].X2 += delta[0]
+			copies[i].Y2 += delta[1]
+			moved[i] = &copies[i]
+		}
+
+	default:
+		return nil
+
+	}
+
+	return moved
+
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,84 @@
+package resolv
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// directions are the 16 deltas an AI might try moving in: the 8 directions, at two speeds.
+var directions = [][2]int32{
+	{4, 0}, {4, 4}, {0, 4}, {-4, 4}, {-4, 0}, {-4, -4}, {0, -4}, {4, -4},
+	{12, 0}, {12, 12}, {0, 12}, {-12, 12}, {-12, 0}, {-12, -12}, {0, -12}, {12, -12},
+}
+
+func TestWouldBeCollidingBatch(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(22))
+
+	for level := 0; level < 50; level++ {
+
+		space := NewSpace()
+		for i := 0; i < 40; i++ {
+			space.Add(randomShape(rng, true))
+		}
+
+		// The Shapes tested include ones in the Space, which skip themselves, and nested Spaces, which are tested for
+		// each delta in turn.
+		shapes := []Shape{space.Get(rng.Intn(space.Length())), randomShape(rng, true)}
+		for i := 0; i < 8; i++ {
+			shapes = append(shapes, randomShape(rng, false))
+		}
+
+		for _, shape := range shapes {
+
+			before := fmt.Sprint(shape)
+			got := space.WouldBeCollidingBatch(shape, directions)
+
+			if len(got) != len(directions) {
+				t.Fatalf("WouldBeCollidingBatch() returned %d results for %d deltas", len(got), len(directions))
+			}
+			for i, delta := range directions {
+				if want := shape.WouldBeColliding(space, delta[0], delta[1]); got[i] != want {
+					t.Fatalf("level %d: WouldBeCollidingBatch(%v)[%v] = %v, want %v", level, shape, delta, got[i], want)
+				}
+			}
+			if after := fmt.Sprint(shape); after != before {
+				t.Fatalf("WouldBeCollidingBatch() changed %v to %v", before, after)
+			}
+
+		}
+
+	}
+
+	if got := NewSpace().WouldBeCollidingBatch(NewRectangle(0, 0, 8, 8), nil); len(got) != 0 {
+		t.Errorf("WouldBeCollidingBatch() without deltas = %v, want no results", got)
+	}
+
+}
+
+func BenchmarkWouldBeCollidingBatch(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 256; i++ {
+		space.Add(NewRectangle(i%16*32, i/16*32, 16, 16))
+	}
+	shape := NewCircle(40, 40, 6)
+
+	b.Run("separate calls", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, delta := range directions {
+				shape.WouldBeColliding(space, delta[0], delta[1])
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.WouldBeCollidingBatch(shape, directions)
+		}
+	})
+
+}
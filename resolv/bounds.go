@@ -0,0 +1,58 @@
+package resolv
+
+// aabb is an axis-aligned box, inclusive on all sides, used to quickly rule out pairs of Shapes that can't be colliding
+// before running the more expensive collision tests. The bounds of a Shape are conservative: they contain every point the
+// Shape's collision tests can find it touching, so a pair whose bounds don't overlap is never colliding. They're worked
+// out from the Shapes' fields each time, as that's cheaper than the tests they rule out, and can't go stale.
+// The coordinates are 64-bit, so that the bounds of Shapes near the edges of the int32 range don't overflow.
+type aabb struct {
+	minX, minY, maxX, maxY int64
+}
+
+func (a aabb) overlaps(b aabb) bool {
+	return a.minX <= b.maxX && b.minX <= a.maxX && a.minY <= b.maxY && b.minY <= a.maxY
+}
+
+// span returns the inclusive range covering both of the values provided.
+func span(a, b int64) (int64, int64) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// rectangleBounds returns the bounds of the Rectangle. They include its far edges (X+W and Y+H), as the Line tests
+// check for intersections with them.
+func rectangleBounds(r *Rectangle) aabb {
+	minX, maxX := span(int64(r.X), int64(r.X)+int64(r.W))
+	minY, maxY := span(int64(r.Y), int64(r.Y)+int64(r.H))
+	return aabb{minX, minY, maxX, maxY}
+}
+
+func circleBounds(c *Circle) aabb {
+	radius := int64(c.Radius)
+	if radius < 0 {
+		radius = -radius
+	}
+	return aabb{int64(c.X) - radius, int64(c.Y) - radius, int64(c.X) + radius, int64(c.Y) + radius}
+}
+
+func lineBounds(l *Line) aabb {
+	minX, maxX := span(int64(l.X), int64(l.X2))
+	minY, maxY := span(int64(l.Y), int64(l.Y2))
+	return aabb{minX, minY, maxX, maxY}
+}
+
+// shapeBounds returns the bounds of the Shape, and false if it isn't one of the built-in Shapes they can be worked out
+// for (Spaces aren't included, as working out their bounds means going through all of their Shapes).
+func shapeBounds(shape Shape) (aabb, bool) {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return rectangleBounds(s), true
+	case *Circle:
+		return circleBounds(s), true
+	case *Line:
+		return lineBounds(s), true
+	}
+	return aabb{}, false
+}
@@ -0,0 +1,85 @@
+package resolv
+
+// The functions in this file are chainable variants of the Shape functions, returning the Shape they're called on, so a
+// Shape can be created and set up in one expression, like when laying out a level:
+//
+//	level := resolv.NewSpace()
+//	level.Add(
+//		resolv.NewRectangle(0, 0, 320, 16).Tagged("solid", "ground").At(0, 224),
+//		resolv.NewRectangle(0, 0, 16, 16).Tagged("solid").At(96, 208).WithData(crate),
+//		resolv.NewCircle(0, 0, 8).Tagged("coin").At(160, 200),
+//		resolv.NewLine(0, 0, 64, 0).Tagged("platform").At(200, 160).Offset(0, -16),
+//	)
+
+// Tagged adds the tags provided to the Rectangle (see AddTags()), and returns the Rectangle.
+func (r *Rectangle) Tagged(tags ...string) *Rectangle {
+	r.AddTags(tags...)
+	return r
+}
+
+// At sets the position of the Rectangle (see SetXY()), and returns the Rectangle.
+func (r *Rectangle) At(x, y int32) *Rectangle {
+	r.SetXY(x, y)
+	return r
+}
+
+// WithData sets the Data of the Rectangle (see SetData()), and returns the Rectangle.
+func (r *Rectangle) WithData(data interface{}) *Rectangle {
+	r.SetData(data)
+	return r
+}
+
+// Offset moves the Rectangle by the delta X and Y values provided (see Move()), and returns the Rectangle.
+func (r *Rectangle) Offset(dx, dy int32) *Rectangle {
+	r.Move(dx, dy)
+	return r
+}
+
+// Tagged adds the tags provided to the Circle (see AddTags()), and returns the Circle.
+func (c *Circle) Tagged(tags ...string) *Circle {
+	c.AddTags(tags...)
+	return c
+}
+
+// At sets the position of the Circle (see SetXY()), and returns the Circle.
+func (c *Circle) At(x, y int32) *Circle {
+	c.SetXY(x, y)
+	return c
+}
+
+// WithData sets the Data of the Circle (see SetData()), and returns the Circle.
+func (c *Circle) WithData(data interface{}) *Circle {
+	c.SetData(data)
+	return c
+}
+
+// Offset moves the Circle by the delta X and Y values provided (see Move()), and returns the Circle.
+func (c *Circle) Offset(dx, dy int32) *Circle {
+	c.Move(dx, dy)
+	return c
+}
+
+// Tagged adds the tags provided to the Line (see AddTags()), and returns the Line.
+func (l *Line) Tagged(tags ...string) *Line {
+	l.AddTags(tags...)
+	return l
+}
+
+// At sets the position of the Line's start point, moving its end point along with it (see SetXY()), and returns the
+// Line.
+func (l *Line) At(x, y int32) *Line {
+	l.SetXY(x, y)
+	return l
+}
+
+// WithData sets the Data of the Line (see SetData()), and returns the Line.
+func (l *Line) WithData(data interface{}) *Line {
+	l.SetData(data)
+	return l
+}
+
+// Offset moves the Line by the delta X and Y values provided (see Move()), and returns the Line.
+func (l *Line) Offset(dx, dy int32) *Line {
+	l.Move(dx, dy)
+	return l
+}
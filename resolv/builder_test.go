@@ -0,0 +1,52 @@
+package resolv
+
+import "testing"
+
+func TestBuilderLevel(t *testing.T) {
+
+	crate := &struct{ hp int }{3}
+
+	// Each builder returns the concrete type, so they keep chaining, and the results can be kept as their own types.
+	var (
+		ground   *Rectangle = NewRectangle(0, 0, 320, 16).Tagged("solid", "ground").At(0, 224)
+		box      *Rectangle = NewRectangle(0, 0, 16, 16).Tagged("solid").At(96, 208).WithData(crate)
+		coin     *Circle    = NewCircle(0, 0, 8).Tagged("coin").At(160, 200).Offset(4, 0)
+		platform *Line      = NewLine(0, 0, 64, 0).Tagged("platform").At(200, 160).Offset(0, -16).WithData("oneway")
+	)
+
+	level := NewSpace()
+	level.Add(ground, box, coin, platform)
+
+	tests := []struct {
+		name  string
+		shape Shape
+		x, y  int32
+		tags  []string
+		data  interface{}
+	}{
+		{"ground", ground, 0, 224, []string{"solid", "ground"}, nil},
+		{"box", box, 96, 208, []string{"solid"}, crate},
+		{"coin", coin, 164, 200, []string{"coin"}, nil},
+		{"platform", platform, 200, 144, []string{"platform"}, "oneway"},
+	}
+
+	for _, tt := range tests {
+		if x, y := tt.shape.GetXY(); x != tt.x || y != tt.y {
+			t.Errorf("%s is at %d, %d, want %d, %d", tt.name, x, y, tt.x, tt.y)
+		}
+		if !tt.shape.HasTags(tt.tags...) {
+			t.Errorf("%s has tags %v, want %v", tt.name, tt.shape.GetTags(), tt.tags)
+		}
+		if tt.shape.GetData() != tt.data {
+			t.Errorf("%s has Data %v, want %v", tt.name, tt.shape.GetData(), tt.data)
+		}
+	}
+
+	if platform.X2 != 264 || platform.Y2 != 144 {
+		t.Errorf("the platform ends at %d, %d, want it moved along with its start", platform.X2, platform.Y2)
+	}
+	if got := level.FilterByTags("solid").Length(); got != 2 {
+		t.Errorf("the level has %d solid Shapes, want 2", got)
+	}
+
+}
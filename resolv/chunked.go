@@ -0,0 +1,348 @@
+package resolv
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+type chunkKey struct {
+	x, y int32
+}
+
+// ChunkedSpace partitions Shapes into square chunks of a fixed size, so that only the chunks around the action (the
+// active region) take part in collision testing. This is useful for worlds far too large to test all at once.
+// A Shape belongs to every chunk its bounding rectangle overlaps, so a Shape straddling the edge of the active region
+// still collides with Shapes on the active side of it. Shapes that move into other chunks are moved to them
+// automatically the next time the ChunkedSpace is queried after they move (see BasicShape.Version()).
+// When a chunk enters the active region, OnLoadChunk is called with it (if set), so the game can add the chunk's Shapes
+// lazily. When a chunk leaves the active region, OnUnloadChunk is called with it (if set), and its Shapes are then
+// dropped from it (Shapes that also belong to chunks that are still loaded stay in those).
+// ChunkSize mustn't be changed once Shapes have been added.
+type ChunkedSpace struct {
+	ChunkSize     int32
+	OnLoadChunk   func(chunkX, chunkY int32, chunk *Space)
+	OnUnloadChunk func(chunkX, chunkY int32, chunk *Space)
+
+	chunks       map[chunkKey]*Space
+	chunksOf     map[Shape]chunkMembership
+	active       map[chunkKey]bool
+	cache        *Space
+	cacheVersion uint64
+}
+
+// NewChunkedSpace returns a pointer to a new ChunkedSpace with chunks of the size provided, which has to be positive. No
+// chunks are active until SetActiveRegion() is called.
+func NewChunkedSpace(chunkSize int32) *ChunkedSpace {
+	if chunkSize <= 0 {
+		panic(fmt.Sprintf("ERROR! ChunkedSpace chunk size %d must be positive!", chunkSize))
+	}
+	return &ChunkedSpace{
+		ChunkSize: chunkSize,
+		chunks:    map[chunkKey]*Space{},
+		chunksOf:  map[Shape]chunkMembership{},
+		active:    map[chunkKey]bool{},
+	}
+}
+
+// chunkMembership is the chunks a Shape is in, and the version of the Shape (see BasicShape.Version()) as of when it was
+// placed in them, if it has one.
+type chunkMembership struct {
+	keys      []chunkKey
+	version   uint64
+	versioned bool
+}
+
+// shapeVersion returns the version of the Shape, and false if it doesn't have one.
+func shapeVersion(shape Shape) (uint64, bool) {
+	if v, ok := shape.(interface{ Version() uint64 }); ok {
+		return v.Version(), true
+	}
+	return 0, false
+}
+
+func floorDiv(a, b int32) int32 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// keysOf returns the keys of the chunks the Shape's bounding rectangle overlaps, top to bottom, then left to right.
+// Shapes without an area belong to the chunk they're in.
+func (cs *ChunkedSpace) keysOf(shape Shape) []chunkKey {
+	var r Rectangle
+	boundingRectInto(shape, &r)
+	x1, y1, x2, y2 := cs.chunkRange(r.X, r.Y, r.W, r.H)
+	keys := make([]chunkKey, 0, int(x2-x1+1)*int(y2-y1+1))
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			keys = append(keys, chunkKey{x, y})
+		}
+	}
+	return keys
+}
+
+// chunkRange returns the range of chunk coordinates the area provided overlaps, inclusive.
+func (cs *ChunkedSpace) chunkRange(x, y, w, h int32) (x1, y1, x2, y2 int32) {
+	last := func(pos, size int32) int32 {
+		if size < 1 {
+			size = 1
+		}
+		end := int64(pos) + int64(size) - 1
+		if end > math.MaxInt32 {
+			end = math.MaxInt32
+		}
+		return int32(end)
+	}
+	return floorDiv(x, cs.ChunkSize), floorDiv(y, cs.ChunkSize), floorDiv(last(x, w), cs.ChunkSize), floorDiv(last(y, h), cs.ChunkSize)
+}
+
+func (cs *ChunkedSpace) chunk(key chunkKey) *Space {
+	chunk, exists := cs.chunks[key]
+	if !exists {
+		chunk = NewSpace()
+		cs.chunks[key] = chunk
+	}
+	return chunk
+}
+
+// Add adds the designated Shapes to the ChunkedSpace, each into every chunk it belongs to.
+func (cs *ChunkedSpace) Add(shapes ...Shape) {
+	for _, shape := range shapes {
+		cs.place(shape, cs.keysOf(shape))
+	}
+	cs.dropCache()
+}
+
+// place moves the Shape into the chunks with the keys provided, and out of any others it was in.
+func (cs *ChunkedSpace) place(shape Shape, keys []chunkKey) {
+	for _, key := range cs.chunksOf[shape].keys {
+		if !hasChunkKey(keys, key) {
+			if chunk, exists := cs.chunks[key]; exists {
+				chunk.Remove(shape)
+			}
+		}
+	}
+	for _, key := range keys {
+		cs.chunk(key).AddUnique(shape)
+	}
+	m := chunkMembership{keys: keys}
+	m.version, m.versioned = shapeVersion(shape)
+	cs.chunksOf[shape] = m
+}
+
+func hasChunkKey(keys []chunkKey, key chunkKey) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func sameChunkKeys(a, b []chunkKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove removes the designated Shapes from the ChunkedSpace.
+func (cs *ChunkedSpace) Remove(shapes ...Shape) {
+	for _, shape := range shapes {
+		for _, key := range cs.chunksOf[shape].keys {
+			if chunk, exists := cs.chunks[key]; exists {
+				chunk.Remove(shape)
+			}
+		}
+		delete(cs.chunksOf, shape)
+	}
+	cs.dropCache()
+}
+
+// Chunk returns the Space holding the Shapes of the chunk at the chunk coordinates provided, or nil if there's no such
+// chunk. Shapes shouldn't be added to or removed from the returned Space directly.
+func (cs *ChunkedSpace) Chunk(chunkX, chunkY int32) *Space {
+	return cs.chunks[chunkKey{chunkX, chunkY}]
+}
+
+// SetActiveRegion sets the region of the world that takes part in collision testing; every chunk overlapping the region
+// is active. Chunks entering the region are loaded, and chunks leaving it unloaded (see ChunkedSpace).
+func (cs *ChunkedSpace) SetActiveRegion(region *Rectangle) {
+
+	active := map[chunkKey]bool{}
+	if region.W > 0 && region.H > 0 {
+		x1, y1, x2, y2 := cs.chunkRange(region.X, region.Y, region.W, region.H)
+		for y := y1; y <= y2; y++ {
+			for x := x1; x <= x2; x++ {
+				active[chunkKey{x, y}] = true
+			}
+		}
+	}
+
+	for _, key := range sortedChunkKeys(cs.active) {
+		if !active[key] {
+			chunk := cs.chunk(key)
+			if cs.OnUnloadChunk != nil {
+				cs.OnUnloadChunk(key.x, key.y, chunk)
+			}
+			for _, shape := range chunk.shapes {
+				cs.forgetChunk(shape, key)
+			}
+			delete(cs.chunks, key)
+		}
+	}
+
+	for _, key := range sortedChunkKeys(active) {
+		if !cs.active[key] && cs.OnLoadChunk != nil {
+			chunk := cs.chunk(key)
+			cs.OnLoadChunk(key.x, key.y, chunk)
+			// The game may have added Shapes to the chunk directly.
+			for _, shape := range chunk.shapes {
+				if m, known := cs.chunksOf[shape]; !known {
+					m.version, m.versioned = shapeVersion(shape)
+					m.keys = []chunkKey{key}
+					cs.chunksOf[shape] = m
+				} else if !hasChunkKey(m.keys, key) {
+					m.keys = append(m.keys, key)
+					cs.chunksOf[shape] = m
+				}
+			}
+		}
+	}
+
+	cs.active = active
+	cs.dropCache()
+
+}
+
+// forgetChunk records that the Shape is no longer in the chunk with the key provided, forgetting the Shape once it's in
+// no chunks at all.
+func (cs *ChunkedSpace) forgetChunk(shape Shape, key chunkKey) {
+	m := cs.chunksOf[shape]
+	kept := make([]chunkKey, 0, len(m.keys))
+	for _, k := range m.keys {
+		if k != key {
+			kept = append(kept, k)
+		}
+	}
+	if len(kept) == 0 {
+		delete(cs.chunksOf, shape)
+	} else {
+		m.keys = kept
+		cs.chunksOf[shape] = m
+	}
+}
+
+func sortedChunkKeys(keys map[chunkKey]bool) []chunkKey {
+	sorted := make([]chunkKey, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].y != sorted[j].y {
+			return sorted[i].y < sorted[j].y
+		}
+		return sorted[i].x < sorted[j].x
+	})
+	return sorted
+}
+
+// dropCache drops the Space returned by Active(), so that it's rebuilt when it's next needed.
+func (cs *ChunkedSpace) dropCache() {
+	if cs.cache != nil {
+		cs.cache.Unwatch()
+		cs.cache = nil
+	}
+}
+
+// activeShapes returns the Shapes in the active chunks, ordered by chunk (top to bottom, then left to right), each only
+// once, even if it's in several of them.
+func (cs *ChunkedSpace) activeShapes() []Shape {
+	var shapes []Shape
+	seen := map[Shape]bool{}
+	for _, key := range sortedChunkKeys(cs.active) {
+		if chunk, exists := cs.chunks[key]; exists {
+			for _, shape := range chunk.shapes {
+				if !seen[shape] {
+					seen[shape] = true
+					shapes = append(shapes, shape)
+				}
+			}
+		}
+	}
+	return shapes
+}
+
+// migrate moves Shapes in active chunks that have moved into other chunks into those chunks. Shapes that haven't changed
+// since they were placed are skipped, so Shapes left in some of their chunks when others were unloaded stay that way.
+func (cs *ChunkedSpace) migrate() {
+	var shapes []Shape
+	if cs.cache != nil {
+		// The cached Space already holds just the active Shapes, each once.
+		shapes = append(shapes, cs.cache.shapes...)
+	} else {
+		shapes = cs.activeShapes()
+	}
+	for _, shape := range shapes {
+		m := cs.chunksOf[shape]
+		if version, versioned := shapeVersion(shape); versioned && m.versioned && version == m.version {
+			continue
+		}
+		if keys := cs.keysOf(shape); !sameChunkKeys(keys, m.keys) {
+			cs.place(shape, keys)
+			cs.dropCache()
+		} else {
+			m.version, m.versioned = shapeVersion(shape)
+			cs.chunksOf[shape] = m
+		}
+	}
+}
+
+// Active returns a Space holding all of the Shapes in the active chunks (each once), ordered by chunk (top to bottom,
+// then left to right). The Space is rebuilt only when the ChunkedSpace changes, so it shouldn't be changed directly.
+// Shapes are only checked for having moved into other chunks when any of them has changed since the last query.
+func (cs *ChunkedSpace) Active() *Space {
+
+	if cs.cache != nil && cs.cache.Version() == cs.cacheVersion {
+		return cs.cache
+	}
+
+	cs.migrate()
+
+	if cs.cache == nil {
+		cs.cache = NewSpace()
+		cs.cache.Add(cs.activeShapes()...)
+	}
+
+	cs.cacheVersion = cs.cache.Version()
+	return cs.cache
+
+}
+
+// IsColliding returns whether the provided Shape is colliding with a Shape in an active chunk.
+func (cs *ChunkedSpace) IsColliding(shape Shape) bool {
+	return cs.Active().IsColliding(shape)
+}
+
+// GetCollidingShapes returns a Space comprised of the Shapes in active chunks that collide with the checking Shape.
+func (cs *ChunkedSpace) GetCollidingShapes(shape Shape) *Space {
+	return cs.Active().GetCollidingShapes(shape)
+}
+
+// Resolve runs Resolve() using the checking Shape against the Shapes in active chunks. See Space.Resolve().
+func (cs *ChunkedSpace) Resolve(checkingShape Shape, deltaX, deltaY int32) Collision {
+	return cs.Active().Resolve(checkingShape, deltaX, deltaY)
+}
+
+// FilterByTags returns a new Space that has just the Shapes in active chunks that have all of the specified tags.
+func (cs *ChunkedSpace) FilterByTags(tags ...string) *Space {
+	return cs.Active().FilterByTags(tags...)
+}
@@ -0,0 +1,138 @@
+package resolv
+
+import "testing"
+
+func TestChunkedSpaceMembership(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		shape      *Rectangle
+		wantChunks []chunkKey
+	}{
+		{"inside one chunk", NewRectangle(8, 8, 16, 16), []chunkKey{{0, 0}}},
+		{"straddling two", NewRectangle(56, 8, 16, 16), []chunkKey{{0, 0}, {1, 0}}},
+		{"straddling four", NewRectangle(56, 56, 16, 16), []chunkKey{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+		{"negative", NewRectangle(-8, -120, 16, 8), []chunkKey{{-1, -2}, {0, -2}}},
+		{"ending on an edge", NewRectangle(0, 0, 64, 64), []chunkKey{{0, 0}}},
+		{"no area", NewRectangle(64, 64, 0, 0), []chunkKey{{1, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := NewChunkedSpace(64)
+			cs.Add(tt.shape)
+			if got := cs.chunksOf[tt.shape].keys; !sameChunkKeys(got, tt.wantChunks) {
+				t.Errorf("in chunks %v, want %v", got, tt.wantChunks)
+			}
+			for _, key := range tt.wantChunks {
+				if chunk := cs.Chunk(key.x, key.y); chunk == nil || !chunk.Contains(tt.shape) {
+					t.Errorf("chunk %v doesn't contain the Shape", key)
+				}
+			}
+		})
+	}
+
+}
+
+func TestChunkedSpaceActive(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		region     *Rectangle
+		probe      *Rectangle
+		wantActive int
+		wantHit    bool
+	}{
+		{"straddler seen from the right", NewRectangle(64, 0, 64, 64), NewRectangle(66, 8, 4, 4), 1, true},
+		{"straddler seen from the left", NewRectangle(0, 0, 64, 64), NewRectangle(58, 8, 4, 4), 1, true},
+		{"straddler counted once", NewRectangle(0, 0, 128, 64), NewRectangle(66, 8, 4, 4), 1, true},
+		{"out of the region", NewRectangle(256, 0, 64, 64), NewRectangle(66, 8, 4, 4), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := NewChunkedSpace(64)
+			cs.Add(NewRectangle(56, 0, 16, 16))
+			cs.SetActiveRegion(tt.region)
+			if got := cs.Active().Length(); got != tt.wantActive {
+				t.Errorf("Active() has %d shapes, want %d", got, tt.wantActive)
+			}
+			if got := cs.IsColliding(tt.probe); got != tt.wantHit {
+				t.Errorf("IsColliding() = %v, want %v", got, tt.wantHit)
+			}
+		})
+	}
+
+}
+
+func TestChunkedSpaceMigration(t *testing.T) {
+
+	cs := NewChunkedSpace(64)
+	cs.SetActiveRegion(NewRectangle(0, 0, 256, 64))
+	mover := NewRectangle(8, 8, 8, 8)
+	straddler := NewRectangle(56, 8, 16, 8)
+	cs.Add(mover, straddler)
+
+	// Unloading the chunk on the left leaves the straddler in the chunk on the right only.
+	cs.SetActiveRegion(NewRectangle(64, 0, 192, 64))
+	mover.Move(192, 0)
+	cs.Add(mover)
+	mover.Move(-64, 0)
+	cs.Active()
+
+	if chunk := cs.Chunk(2, 0); chunk == nil || !chunk.Contains(mover) {
+		t.Error("the moved Shape wasn't migrated into its new chunk")
+	}
+	if chunk := cs.Chunk(3, 0); chunk != nil && chunk.Contains(mover) {
+		t.Error("the moved Shape was left in its old chunk")
+	}
+	if cs.Chunk(0, 0) != nil {
+		t.Error("the unloaded chunk was brought back by migrating an unchanged Shape")
+	}
+
+}
+
+func TestNewChunkedSpaceRejectsBadSizes(t *testing.T) {
+
+	for _, size := range []int32{0, -16} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewChunkedSpace(%d) didn't panic", size)
+				}
+			}()
+			NewChunkedSpace(size)
+		}()
+	}
+
+}
+
+func BenchmarkChunkedSpaceQuery(b *testing.B) {
+
+	for _, bb := range []struct {
+		name   string
+		moving bool
+	}{{"static", false}, {"moving", true}} {
+		b.Run(bb.name, func(b *testing.B) {
+
+			cs := NewChunkedSpace(256)
+			cs.SetActiveRegion(NewRectangle(0, 0, 1024, 1024))
+			for i := int32(0); i < 2000; i++ {
+				cs.Add(NewRectangle(i%64*16, i/64*16, 8, 8))
+			}
+			player := NewRectangle(500, 500, 8, 8)
+			cs.Add(player)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if bb.moving {
+					player.Move(1-int32(i%2)*2, 0)
+				}
+				cs.IsColliding(player)
+			}
+
+		})
+	}
+
+}
@@ -1,9 +1,6 @@
 package resolv
 
-import (
-	"fmt"
-	"math"
-)
+import "math"
 
 // A Circle represents an ordinary circle, and has a radius, in addition to normal shape properties.
 type Circle struct {
@@ -11,106 +8,134 @@ type Circle struct {
 	Radius int32
 }
 
-// NewCircle returns a pointer to a new Circle object.
-func NewCircle(x, y, radius int32) *Circle {
-	c := &Circle{Radius: radius}
+// NewCircle returns a pointer to a new Circle object. A negative radius is normalized to the matching positive one. A
+// radius that can't be normalized is left as it is; see Validate(). The Circle is configured with the options provided
+// (like WithTags()).
+func NewCircle(x, y, radius int32, opts ...ShapeOption) *Circle {
+	c := &Circle{}
+	c.init(x, y, radius, opts)
+	return c
+}
+
+// init sets up the zero Circle provided like NewCircle() does.
+func (c *Circle) init(x, y, radius int32, opts []ShapeOption) {
+	if radius < 0 && radius != math.MinInt32 {
+		radius = -radius
+	}
+	c.Radius = radius
 	c.X = x
 	c.Y = y
-	return c
+	applyShapeOptions(c, opts)
 }
 
 // IsColliding returns true if the Circle is colliding with the specified other Shape, including the other Shape
 // being wholly within the Circle.
 func (c *Circle) IsColliding(other Shape) bool {
+	return ShapesColliding(c, other)
+}
+
+// circlesColliding returns whether the two Circles overlap.
+func circlesColliding(c, b *Circle) bool {
+	return withinRadius(c.X, c.Y, b.X, b.Y, int64(c.Radius)+int64(b.Radius))
+}
 
-	switch b := other.(type) {
-
-	case *Circle:
-		return Distance(c.X, c.Y, b.X, b.Y) <= c.Radius+b.Radius
-	case *Rectangle:
-		closestX := c.X
-		closestY := c.Y
-
-		if c.X < b.X {
-			closestX = b.X
-		} else if c.X > b.X+b.W {
-			closestX = b.X + b.W
-		}
-
-		if c.Y < b.Y {
-			closestY = b.Y
-		} else if c.Y > b.Y+b.H {
-			closestY = b.Y + b.H
-		}
-
-		return Distance(c.X, c.Y, closestX, closestY) <= c.Radius
-	case *Line:
-		//return b.IsColliding(c)
-		// 通过该线段与圆心作三角形，判断线段与圆是否相交或在圆内
-		return c.isCollidingWithLine(b)
-	case *Space:
-		return b.IsColliding(c)
+// circleRectangleColliding returns whether the Circle overlaps the Rectangle.
+func circleRectangleColliding(c *Circle, b *Rectangle) bool {
 
+	// The Rectangle is half-open (see Rectangle), so the closest point is clamped to its last column and row.
+	right, bottom := b.X+b.W-1, b.Y+b.H-1
+	if right < b.X {
+		right = b.X
+	}
+	if bottom < b.Y {
+		bottom = b.Y
 	}
 
-	fmt.Println("WARNING! Object ", other, " isn't a valid shape for collision testing against Circle ", c, "!")
+	closestX := c.X
+	closestY := c.Y
 
-	return false
+	if c.X < b.X {
+		closestX = b.X
+	} else if c.X > right {
+		closestX = right
+	}
+
+	if c.Y < b.Y {
+		closestY = b.Y
+	} else if c.Y > bottom {
+		closestY = bottom
+	}
+
+	return withinRadius(c.X, c.Y, closestX, closestY, int64(c.Radius))
 
 }
 
 // WouldBeColliding returns whether the Circle would be colliding with the specified other Shape if it were to move
-// in the specified direction.
+// in the specified direction. The Circle itself isn't changed in the process.
 func (c *Circle) WouldBeColliding(other Shape, dx, dy int32) bool {
-	c.X += dx
-	c.Y += dy
-	isColliding := c.IsColliding(other)
-	c.X -= dx
-	c.Y -= dy
-	return isColliding
+	moved := *c
+	moved.X += dx
+	moved.Y += dy
+	return collidingAs(c, &moved, other)
+}
+
+// Center returns the center point of the Circle, which is simply its position.
+func (c *Circle) Center() (int32, int32) {
+	return c.X, c.Y
 }
 
 // GetBoundingRect returns a Rectangle which has a width and height of 2*Radius.
 func (c *Circle) GetBoundingRect() *Rectangle {
 	r := &Rectangle{}
-	r.W = c.Radius * 2
-	r.H = c.Radius * 2
-	r.X = c.X - c.Radius
-	r.Y = c.Y - c.Radius
+	c.GetBoundingRectInto(r)
 	return r
 }
 
-func (c *Circle) isCollidingWithLine(l *Line) bool {
-	AC := float64(Distance(c.X, c.Y, l.X, l.Y))
-	CB := float64(Distance(c.X, c.Y, l.X2, l.Y2))
-	BA := float64(l.GetLength())
+// GetBoundingRectInto sets the position and size of the Rectangle provided to the Circle's bounding rectangle, without
+// allocating a new one. The rest of dst (its tags, Data, etc.) is left as it is.
+func (c *Circle) GetBoundingRectInto(dst *Rectangle) {
+	dst.W = c.Radius * 2
+	dst.H = c.Radius * 2
+	dst.X = c.X - c.Radius
+	dst.Y = c.Y - c.Radius
+}
 
-	// 线段两点到圆心的距离小于圆半径则一定碰撞
-	if AC <= float64(c.Radius) || CB <= float64(c.Radius) {
-		return true
+func (c *Circle) isCollidingWithLine(l *Line) bool {
+	cx, cy := float64(c.X), float64(c.Y)
+	ax, ay := float64(l.X), float64(l.Y)
+	dx, dy := float64(l.X2)-ax, float64(l.Y2)-ay
+
+	// 将圆心投影到线段上，并将投影参数限制在[0,1]内，求得线段上离圆心最近的点
+	t := 0.0
+	if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+		t = ((cx-ax)*dx + (cy-ay)*dy) / lengthSquared
+		t = math.Max(0, math.Min(1, t))
 	}
 
-	// 线段与圆心作三角形，线段为底边，求其高
-	p := (AC + CB + BA) / 2
-	h := 2 * math.Sqrt(p*(p-AC)*(p-CB)*(p-BA)) / BA
-
-	// 高若大于圆半径则必然不碰撞
-	if h <= float64(c.Radius) {
-		cosC := (AC*AC + CB*CB - BA*BA) / (2 * AC * CB)
-		primaryLine := func() float64 {
-			if AC < CB {
-				return CB
-			}
-			return AC
-		}()
-
-		newBA := math.Sqrt(primaryLine*primaryLine + float64(c.Radius*c.Radius) - 2*primaryLine*float64(c.Radius)*cosC)
-		// 如果原底边小于以半径做临边的底边，则不碰撞
-		if BA >= newBA {
-			return true
-		}
+	// 最近点到圆心的距离不大于圆半径则碰撞
+	px, py := ax+t*dx-cx, ay+t*dy-cy
+	radius := float64(c.Radius)
+	return px*px+py*py <= radius*radius
+}
 
-	}
+// SetXY sets the position of the Circle, calling the functions registered with OnMove().
+func (c *Circle) SetXY(x, y int32) {
+	dx, dy := x-c.X, y-c.Y
+	c.X, c.Y = x, y
+	c.NotifyMove(c, dx, dy)
+}
+
+// Move moves the Circle by the delta X and Y values provided, calling the functions registered with OnMove().
+func (c *Circle) Move(dx, dy int32) {
+	c.X += dx
+	c.Y += dy
+	c.NotifyMove(c, dx, dy)
+}
 
-	return false
+// Clone returns a copy of the Circle. The tags of the copy are separate from the original's, while Data is shared between
+// them.
+func (c *Circle) Clone() *Circle {
+	clone := *c
+	clone.BasicShape = c.BasicShape.clone()
+	return &clone
 }
@@ -0,0 +1,104 @@
+package resolv
+
+import "testing"
+
+func TestCircleLineColliding(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		cx, cy, r int32
+		line      [4]int32
+		colliding bool
+	}{
+		{"perpendicular hit on a long segment", 500, 5, 10, [4]int32{0, 0, 1000, 0}, true},
+		{"perpendicular hit from below", 50, -9, 10, [4]int32{0, 0, 100, 0}, true},
+		{"perpendicular tangent", 5, 10, 10, [4]int32{0, 0, 10, 0}, true},
+		{"perpendicular near miss", 5, 11, 10, [4]int32{0, 0, 10, 0}, false},
+		{"tangent past an endpoint", 108, 6, 10, [4]int32{0, 0, 100, 0}, true},
+		{"near miss past an endpoint", 109, 6, 10, [4]int32{0, 0, 100, 0}, false},
+		{"endpoint at the center", 0, 0, 1, [4]int32{0, 0, 10, 10}, true},
+		{"collinear, within the segment", 5, 0, 1, [4]int32{0, 0, 10, 0}, true},
+		{"collinear, tangent to an endpoint", 15, 0, 5, [4]int32{0, 0, 10, 0}, true},
+		{"collinear, past an endpoint", 16, 0, 5, [4]int32{0, 0, 10, 0}, false},
+		{"diagonal, just out of reach", 10, 0, 7, [4]int32{0, 0, 10, 10}, false},
+		{"diagonal, just in reach", 10, 0, 8, [4]int32{0, 0, 10, 10}, true},
+		{"zero length, on the edge", 3, 4, 5, [4]int32{0, 0, 0, 0}, true},
+		{"zero length, outside", 3, 4, 4, [4]int32{0, 0, 0, 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCircle(tt.cx, tt.cy, tt.r)
+			l := NewLine(tt.line[0], tt.line[1], tt.line[2], tt.line[3])
+			if got := c.IsColliding(l); got != tt.colliding {
+				t.Errorf("Circle.IsColliding(Line) = %v, want %v", got, tt.colliding)
+			}
+			if got := l.IsColliding(c); got != tt.colliding {
+				t.Errorf("Line.IsColliding(Circle) = %v, want %v", got, tt.colliding)
+			}
+		})
+	}
+
+}
+
+func TestCircleBoundaries(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		a     *Circle
+		other Shape
+		want  bool
+	}{
+		// The centers are 50 apart.
+		{"Circles, distance under the sum", NewCircle(0, 0, 20), NewCircle(30, 40, 31), true},
+		{"Circles, distance at the sum", NewCircle(0, 0, 20), NewCircle(30, 40, 30), true},
+		{"Circles, distance over the sum", NewCircle(0, 0, 20), NewCircle(30, 40, 29), false},
+		// The centers are about 11.18 apart, which a truncated distance would round down to 11.
+		{"Circles, fractional distance", NewCircle(0, 0, 5), NewCircle(10, 5, 6), false},
+		{"Circle and Rectangle, distance under the radius", NewCircle(0, 0, 51), NewRectangle(30, 40, 10, 10), true},
+		{"Circle and Rectangle, distance at the radius", NewCircle(0, 0, 50), NewRectangle(30, 40, 10, 10), true},
+		{"Circle and Rectangle, distance over the radius", NewCircle(0, 0, 49), NewRectangle(30, 40, 10, 10), false},
+		{"Circle and Rectangle, touching an edge", NewCircle(0, 5, 10), NewRectangle(10, 0, 10, 10), true},
+		{"Circle and Rectangle, short of an edge", NewCircle(0, 5, 9), NewRectangle(10, 0, 10, 10), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.IsColliding(tt.other); got != tt.want {
+				t.Errorf("IsColliding() = %v, want %v", got, tt.want)
+			}
+			if got := tt.other.IsColliding(tt.a); got != tt.want {
+				t.Errorf("IsColliding() the other way around = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+}
+
+func BenchmarkCircleIsColliding(b *testing.B) {
+
+	circle := NewCircle(50, 50, 10)
+	benchmarks := []struct {
+		name  string
+		other Shape
+	}{
+		{"line crossing", NewLine(0, 0, 100, 100)},
+		{"line touched near an endpoint", NewLine(0, 42, 45, 42)},
+		{"line missing", NewLine(0, 0, 100, 20)},
+		{"line far away", NewLine(200, 200, 300, 220)},
+		{"rectangle overlapping", NewRectangle(55, 40, 20, 20)},
+		{"rectangle touched at a corner", NewRectangle(56, 56, 20, 20)},
+		{"rectangle missing", NewRectangle(62, 62, 20, 20)},
+		{"rectangle far away", NewRectangle(200, 200, 20, 20)},
+	}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				circle.IsColliding(bb.other)
+			}
+		})
+	}
+
+}
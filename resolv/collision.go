@@ -5,9 +5,10 @@ package resolv
 // ResolveX and ResolveY represent the displacement of the Shape to the point of collision. How far along the Shape
 // got when attempting to move along the direction given by deltaX and deltaY in the Resolve() function before
 // touching another Shape.
-// Teleporting is if moving according to ResolveX and ResolveY might be considered teleporting, which is moving
-// greater than the deltaX or deltaY provided to the Resolve function * 1.5 (this is arbitrary, but can be useful
-// when attempting to see if a movement would be ).
+// Teleporting is true if moving by the full deltaX and deltaY provided to the Resolve function would have taken the Shape
+// through the other Shape and out of its far side (like a fast Shape skipping over a thin wall), rather than into it;
+// Resolve() still stops the Shape at the other Shape in that case. It's also true if the Shape was already colliding and
+// had to be pushed back by more than 1.5 times the delta provided to be freed.
 // ShapeA is a pointer to the Shape that initiated the resolution check.
 // ShapeB is a pointer to the Shape that the colliding object collided with, if the Collision was successful.
 type Collision struct {
@@ -21,3 +22,16 @@ type Collision struct {
 func (c *Collision) Colliding() bool {
 	return c.ShapeB != nil
 }
+
+// Reset clears the Collision back to its zero value, so that a Collision kept around between frames (like in a struct
+// being reused) doesn't hold on to the Shapes from an earlier collision.
+func (c *Collision) Reset() {
+	*c = Collision{}
+}
+
+// CollisionPair describes two Shapes that are colliding with each other within a Space, as reported by
+// Space.GetCollidingPairs(). The order of ShapeA and ShapeB follows the order of the Shapes within the Space.
+type CollisionPair struct {
+	ShapeA Shape
+	ShapeB Shape
+}
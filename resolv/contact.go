@@ -0,0 +1,97 @@
+package resolv
+
+// ContactInfo describes which sides of a Shape are touching other Shapes; see Space.ContactSides().
+type ContactInfo struct {
+	// Left, Right, Top, and Bottom are whether the Shape is touching something on each side.
+	Left, Right, Top, Bottom bool
+
+	// LeftShapes, RightShapes, TopShapes, and BottomShapes are the Shapes touched on each side, in the order they're in
+	// the Space. A Shape touched on multiple sides (like a wall the Shape is in a corner of) is in each of them.
+	LeftShapes, RightShapes, TopShapes, BottomShapes []Shape
+
+	// NormalX and NormalY point away from the sides that are touched, like 1, 0 for a wall on the left, or 0, -1 for the
+	// floor; sides touched on opposite sides cancel out.
+	NormalX, NormalY int32
+
+	// Overlapping are the Shapes the Shape is already colliding with, which aren't counted as touching any side.
+	Overlapping []Shape
+}
+
+// ContactSides returns which sides of the Shape are touching Shapes in the Space with all of the tags provided (any Shape,
+// if none are provided): a side is touching a Shape if moving the Shape up to probeDist pixels (at least 1) that way would
+// collide with it, like a wall the Shape is pressed against for wall jumping. The Shape isn't moved in the process. Shapes
+// the Shape is already colliding with are reported in Overlapping instead, rather than as touching every side. Which
+// Shapes are touched follows their collision layers and masks, and elevation, like Space.Resolve(). If the Shape is in the
+// Space, it's skipped, rather than tested against itself.
+func (sp *Space) ContactSides(shape Shape, probeDist int32, tags ...string) ContactInfo {
+
+	if probeDist < 1 {
+		probeDist = 1
+	}
+
+	info := ContactInfo{}
+
+	for _, other := range sp.shapes {
+
+		if other == shape || !other.HasTags(tags...) || !sp.canCollide(shape, other) {
+			continue
+		}
+
+		if shape.IsColliding(other) {
+			info.Overlapping = append(info.Overlapping, other)
+			continue
+		}
+
+		if touching(shape, other, -probeDist, 0) {
+			info.Left = true
+			info.LeftShapes = append(info.LeftShapes, other)
+		}
+		if touching(shape, other, probeDist, 0) {
+			info.Right = true
+			info.RightShapes = append(info.RightShapes, other)
+		}
+		if touching(shape, other, 0, -probeDist) {
+			info.Top = true
+			info.TopShapes = append(info.TopShapes, other)
+		}
+		if touching(shape, other, 0, probeDist) {
+			info.Bottom = true
+			info.BottomShapes = append(info.BottomShapes, other)
+		}
+
+	}
+
+	if info.Left {
+		info.NormalX++
+	}
+	if info.Right {
+		info.NormalX--
+	}
+	if info.Top {
+		info.NormalY++
+	}
+	if info.Bottom {
+		info.NormalY--
+	}
+
+	return info
+
+}
+
+// touching returns whether the Shape would collide with the other Shape when moved by any distance along the delta
+// provided, up to the whole delta, so that Shapes thinner than the delta aren't skipped over.
+func touching(shape, other Shape, dx, dy int32) bool {
+
+	if !sweptBoundsOverlap(shape, other, dx, dy) {
+		return false
+	}
+
+	n := absInt64(int64(dx) + int64(dy))
+	for k := int64(1); k <= n; k++ {
+		if shape.WouldBeColliding(other, stepAlong(dx, k, n), stepAlong(dy, k, n)) {
+			return true
+		}
+	}
+	return false
+
+}
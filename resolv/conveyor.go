@@ -0,0 +1,139 @@
+package resolv
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Conveyor is the Data to set on a Shape to make it a conveyor belt (see ApplyConveyors()), moving the Shapes standing on
+// it by DX and DY pixels each frame. A *Conveyor works too.
+type Conveyor struct {
+	DX, DY int32
+}
+
+// conveyorTagPrefix is the prefix of the tags that make a Shape a conveyor belt, like "conveyor:+x" or "conveyor:-2y".
+const conveyorTagPrefix = "conveyor:"
+
+// ApplyConveyors moves each Shape in movers that's standing on a conveyor belt in the Space (that is, resting within a
+// pixel on top of it, like PlatformerBody.OnGround()) by the belt's speed, with the movement resolved against the Space
+// horizontally and then vertically, so that a Shape carried off the end of a belt still stops at the wall beyond it. Call
+// it once a frame.
+// A Shape is a conveyor belt if its Data is a Conveyor (or a *Conveyor), or otherwise, if it has tags like "conveyor:+x"
+// (moving Shapes 1 pixel to the right each frame), "conveyor:-y", or "conveyor:+3x" (3 pixels to the right); multiple
+// such tags are added together. A Shape standing on multiple belts is moved by the sum of their speeds, so standing
+// across two opposing belts cancels out; belts with the same speed (like the tiles of a single belt) are only counted
+// once, so that standing across two tiles of a belt doesn't move a Shape twice as fast. Which belts a Shape stands on
+// follows their collision layers and masks, and elevation, like Space.Resolve(). The movers should be in the Space, so
+// that they don't collide with themselves.
+func ApplyConveyors(space *Space, movers *Space) {
+
+	var speeds [][2]int32
+
+	for _, mover := range movers.shapes {
+
+		speeds = speeds[:0]
+		var dx, dy int32
+
+		for _, surface := range space.shapes {
+
+			if surface == mover || !space.canCollide(mover, surface) {
+				continue
+			}
+
+			sx, sy, ok := conveyorSpeed(surface)
+			if !ok || mover.IsColliding(surface) || !mover.WouldBeColliding(surface, 0, 1) {
+				continue
+			}
+
+			counted := false
+			for _, s := range speeds {
+				if s[0] == sx && s[1] == sy {
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				speeds = append(speeds, [2]int32{sx, sy})
+				dx += sx
+				dy += sy
+			}
+
+		}
+
+		if dx != 0 {
+			col := resolveDelta(space, mover, dx, 0)
+			mover.Move(col.ResolveX, 0)
+		}
+		if dy != 0 {
+			col := resolveDelta(space, mover, 0, dy)
+			mover.Move(0, col.ResolveY)
+		}
+
+	}
+
+}
+
+// conveyorSpeed returns the speed of the conveyor belt the Shape is, and whether it's one at all (see ApplyConveyors()).
+func conveyorSpeed(shape Shape) (int32, int32, bool) {
+
+	switch c := shape.GetData().(type) {
+	case Conveyor:
+		return c.DX, c.DY, true
+	case *Conveyor:
+		if c != nil {
+			return c.DX, c.DY, true
+		}
+	}
+
+	if !hasTagMatching(shape, compileTagPattern(conveyorTagPrefix+"*")) {
+		return 0, 0, false
+	}
+
+	var dx, dy int32
+	found := false
+	for _, tag := range shape.GetTags() {
+		if x, y, ok := parseConveyorTag(tag); ok {
+			dx += x
+			dy += y
+			found = true
+		}
+	}
+	return dx, dy, found
+
+}
+
+// parseConveyorTag parses a conveyor belt's tag, like "conveyor:+x" or "conveyor:-2y", into its speed.
+func parseConveyorTag(tag string) (int32, int32, bool) {
+
+	if !strings.HasPrefix(tag, conveyorTagPrefix) {
+		return 0, 0, false
+	}
+	spec := tag[len(conveyorTagPrefix):]
+	if len(spec) < 2 || (spec[0] != '+' && spec[0] != '-') {
+		return 0, 0, false
+	}
+
+	axis := spec[len(spec)-1]
+	speed := int64(1)
+	if digits := spec[1 : len(spec)-1]; digits != "" {
+		if digits[0] < '0' || digits[0] > '9' {
+			return 0, 0, false
+		}
+		var err error
+		if speed, err = strconv.ParseInt(digits, 10, 32); err != nil {
+			return 0, 0, false
+		}
+	}
+	if spec[0] == '-' {
+		speed = -speed
+	}
+
+	switch axis {
+	case 'x':
+		return int32(speed), 0, true
+	case 'y':
+		return 0, int32(speed), true
+	}
+	return 0, 0, false
+
+}
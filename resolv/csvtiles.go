@@ -0,0 +1,73 @@
+package resolv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadCSVTiles reads a tile grid in CSV format, with one row of tiles per record and each tile given as a number (like the
+// collision layers many tile editors export), and returns a Space containing Rectangles for the solid tiles, each tile
+// being tileW by tileH in size. The tiles whose numbers are among the solidValues provided are solid; if none are
+// provided, every non-zero tile is. Neighboring solid tiles are greedily merged into larger Rectangles, the same way
+// LoadTMX() does, so that a wall of tiles becomes a single Rectangle.
+// LoadCSVTiles returns an error giving the row and column of the problem for rows with a different number of tiles than
+// the first one, and for tiles that aren't numbers.
+func LoadCSVTiles(r io.Reader, tileW, tileH int32, solidValues ...string) (*Space, error) {
+
+	solidSet := map[int64]bool{}
+	for _, value := range solidValues {
+		v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("resolv: solid CSV tile value %q isn't a number", value)
+		}
+		solidSet[v] = true
+	}
+
+	reader := csv.NewReader(r)
+	// Rows of different lengths are checked for below, to report them with their positions.
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	solid := [][]bool{}
+
+	for {
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resolv: can't read CSV tiles: %w", err)
+		}
+
+		row := len(solid) + 1
+		if len(solid) > 0 && len(record) != len(solid[0]) {
+			return nil, fmt.Errorf("resolv: CSV tiles row %d has %d columns, but row 1 has %d", row, len(record), len(solid[0]))
+		}
+
+		cells := make([]bool, len(record))
+		for x, cell := range record {
+			v, err := strconv.ParseInt(strings.TrimSpace(cell), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("resolv: CSV tiles row %d column %d: %q isn't a number", row, x+1, cell)
+			}
+			if len(solidSet) > 0 {
+				cells[x] = solidSet[v]
+			} else {
+				cells[x] = v != 0
+			}
+		}
+		solid = append(solid, cells)
+
+	}
+
+	space := NewSpace()
+	for _, rect := range gridRectangles(solid, tileW, tileH, true) {
+		space.Add(rect)
+	}
+	return space, nil
+
+}
@@ -0,0 +1,104 @@
+package resolv
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestLoadCSVTilesMatchesNaive(t *testing.T) {
+
+	const tile, w, h = 16, 30, 20
+	rng := rand.New(rand.NewSource(7))
+
+	var csvText strings.Builder
+	naive := NewSpace()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := 0
+			if rng.Intn(3) == 0 {
+				v = 1
+				naive.Add(NewRectangle(int32(x)*tile, int32(y)*tile, tile, tile))
+			}
+			if x > 0 {
+				csvText.WriteString(",")
+			}
+			fmt.Fprint(&csvText, v)
+		}
+		csvText.WriteString("\n")
+	}
+
+	merged, err := LoadCSVTiles(strings.NewReader(csvText.String()), tile, tile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Length() >= naive.Length() {
+		t.Errorf("merging left %d Rectangles, no fewer than the %d tiles", merged.Length(), naive.Length())
+	}
+
+	for i := 0; i < 2000; i++ {
+		x, y := rng.Int31n(w*tile)-tile, rng.Int31n(h*tile)-tile
+		var probe Shape = NewRectangle(x, y, 1+rng.Int31n(24), 1+rng.Int31n(24))
+		if i%2 == 1 {
+			probe = NewCircle(x, y, rng.Int31n(12))
+		}
+		got, want := merged.IsColliding(probe), naive.IsColliding(probe)
+		if got != want {
+			t.Fatalf("IsColliding(%v) = %v, want %v", probe, got, want)
+		}
+		if got {
+			// Shapes that start out overlapping the tiles are pushed out of whichever tile they're in, which depends on
+			// the tiles' shapes, so only movement from free positions can be compared.
+			continue
+		}
+		dx, dy := rng.Int31n(64)-32, rng.Int31n(64)-32
+		gotCol, wantCol := merged.Resolve(probe, dx, dy), naive.Resolve(probe, dx, dy)
+		if gotCol.Colliding() != wantCol.Colliding() || gotCol.ResolveX != wantCol.ResolveX ||
+			gotCol.ResolveY != wantCol.ResolveY {
+			t.Fatalf("Resolve(%v, %d, %d) = %d, %d, want %d, %d", probe, dx, dy, gotCol.ResolveX, gotCol.ResolveY,
+				wantCol.ResolveX, wantCol.ResolveY)
+		}
+	}
+
+}
+
+func TestLoadCSVTiles(t *testing.T) {
+
+	wall := strings.Repeat("1,", 99) + "1\n"
+
+	tests := []struct {
+		name    string
+		csv     string
+		solid   []string
+		shapes  int
+		wantErr string
+	}{
+		{"wall", wall, nil, 1, ""},
+		{"block", "1,1\n1,1\n", nil, 1, ""},
+		{"solid values", "1,2,3\n2,2,1\n", []string{"2"}, 2, ""},
+		{"empty", "0,0\n0,0\n", nil, 0, ""},
+		{"ragged", "1,1\n1\n", nil, 0, "row 2 has 1 columns"},
+		{"not a number", "1,1\n1,x\n", nil, 0, "row 2 column 2"},
+		{"bad solid value", "1\n", []string{"wall"}, 0, "isn't a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp, err := LoadCSVTiles(strings.NewReader(tt.csv), 16, 16, tt.solid...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadCSVTiles() = %v, want an error mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sp.Length() != tt.shapes {
+				t.Errorf("LoadCSVTiles() made %d Rectangles, want %d", sp.Length(), tt.shapes)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,31 @@
+package resolv
+
+// boundsOverlap returns whether the two bounding rectangles provided overlap or touch. It's deliberately inclusive, so
+// that zero-width or zero-height bounds (like those of horizontal and vertical Lines) aren't missed.
+func boundsOverlap(a, b *Rectangle) bool {
+	return a.X <= b.X+b.W && b.X <= a.X+a.W && a.Y <= b.Y+b.H && b.Y <= a.Y+a.H
+}
+
+// CullFunc calls the function provided for each Shape in the Space whose bounding rectangle overlaps the viewport,
+// expanded by the margin on every side (so Shapes just off-screen can be included too). Unlike Cull(), it doesn't create
+// a new Space.
+func (sp *Space) CullFunc(viewport *Rectangle, margin int32, fn func(Shape)) {
+	view := NewRectangle(viewport.X-margin, viewport.Y-margin, viewport.W+margin*2, viewport.H+margin*2)
+	var bounds Rectangle
+	for _, shape := range sp.shapes {
+		boundingRectInto(shape, &bounds)
+		if boundsOverlap(view, &bounds) {
+			fn(shape)
+		}
+	}
+}
+
+// Cull returns a new Space containing the Shapes in the Space whose bounding rectangles overlap the viewport, expanded by
+// the margin on every side; that is, the Shapes that are visible on screen and should be drawn.
+func (sp *Space) Cull(viewport *Rectangle, margin int32) *Space {
+	visible := newResultSpace()
+	sp.CullFunc(viewport, margin, func(s Shape) {
+		visible.Add(s)
+	})
+	return visible
+}
@@ -0,0 +1,90 @@
+package resolv
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCullMargin(t *testing.T) {
+
+	// A 100x100 viewport at the origin, with Shapes at different distances off its right edge, and one far off.
+	viewport := NewRectangle(0, 0, 100, 100)
+	inside := NewRectangle(40, 40, 8, 8)
+	touching := NewRectangle(100, 40, 8, 8)
+	near := NewCircle(110, 50, 4)
+	far := NewLine(130, 0, 130, 100)
+	above := NewRectangle(40, -30, 8, 8)
+	space := NewSpace()
+	space.Add(inside, touching, near, far, above)
+
+	tests := []struct {
+		margin int32
+		want   []Shape
+	}{
+		{0, []Shape{inside, touching}},
+		{6, []Shape{inside, touching, near}},
+		{22, []Shape{inside, touching, near, above}},
+		{30, []Shape{inside, touching, near, far, above}},
+		// A negative margin shrinks the viewport.
+		{-45, []Shape{inside}},
+		{-50, nil},
+	}
+
+	for _, tt := range tests {
+
+		got := space.Cull(viewport, tt.margin)
+		var gotFunc []Shape
+		space.CullFunc(viewport, tt.margin, func(shape Shape) { gotFunc = append(gotFunc, shape) })
+
+		if got.Length() != len(tt.want) || len(gotFunc) != len(tt.want) {
+			t.Errorf("with a margin of %d, Cull() = %v and CullFunc() found %v, want %v", tt.margin, got.Shapes(), gotFunc,
+				tt.want)
+			continue
+		}
+		for i, shape := range tt.want {
+			if got.Get(i) != shape || gotFunc[i] != shape {
+				t.Errorf("with a margin of %d, Cull() = %v and CullFunc() found %v, want %v", tt.margin, got.Shapes(),
+					gotFunc, tt.want)
+				break
+			}
+		}
+
+	}
+
+}
+
+func BenchmarkCull(b *testing.B) {
+
+	rng := rand.New(rand.NewSource(378))
+	space := NewSpace()
+	for i := 0; i < 20000; i++ {
+		space.Add(randomShape(rng, false))
+		space.Get(i).SetXY(rng.Int31n(8000), rng.Int31n(8000))
+	}
+	viewport := NewRectangle(2000, 2000, 640, 360)
+
+	// The naive scan tests each Shape against the viewport (expanded by the margin) for a collision.
+	b.Run("naive", func(b *testing.B) {
+		view := NewRectangle(viewport.X-32, viewport.Y-32, viewport.W+64, viewport.H+64)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.Filter(func(shape Shape) bool { return view.IsColliding(shape) })
+		}
+	})
+
+	b.Run("Cull", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.Cull(viewport, 32)
+		}
+	})
+
+	b.Run("CullFunc", func(b *testing.B) {
+		visible := 0
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.CullFunc(viewport, 32, func(Shape) { visible++ })
+		}
+	})
+
+}
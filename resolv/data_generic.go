@@ -0,0 +1,58 @@
+//go:build go1.18
+
+package resolv
+
+// DataAs returns the Data of the Shape provided as a value of type T, and whether the Data actually is of that type. If
+// it isn't (or there's no Data), the zero value of T and false are returned. For a Space, the Data of its root Shape is
+// used (see Space.GetData()).
+//
+//	if player, ok := resolv.DataAs[*Player](shape); ok {
+//		player.Hurt()
+//	}
+func DataAs[T any](s Shape) (T, bool) {
+	data, ok := s.GetData().(T)
+	return data, ok
+}
+
+// SetData sets the Data of the Shape provided to the value provided. It's the same as calling s.SetData(v), but makes the
+// type of the Data visible at the call site, and pairs with DataAs().
+func SetData[T any](s Shape, v T) {
+	s.SetData(v)
+}
+
+// TypedShape wraps a Shape whose Data is of type T, so that its Data can be read and set without type assertions. As it
+// embeds the Shape, a TypedShape is itself a Shape, and can be used for queries anywhere the Shape can: collision tests
+// (including a built-in Shape's IsColliding(), ShapesColliding(), and a Space's queries and Resolve()) test the wrapped
+// Shape in its place, so a TypedShape of a Shape in a Space doesn't collide with the Shape itself. Add the wrapped Shape
+// to Spaces rather than the TypedShape, though, as Spaces tell Shapes apart by identity.
+type TypedShape[T any] struct {
+	Shape
+}
+
+// Typed returns a TypedShape wrapping the Shape provided, for Data of type T.
+func Typed[T any](s Shape) TypedShape[T] {
+	return TypedShape[T]{s}
+}
+
+func (t TypedShape[T]) unwrapShape() Shape {
+	return t.Shape
+}
+
+// Data returns the Data of the Shape as a value of type T, or the zero value of T if the Data isn't of that type.
+func (t TypedShape[T]) Data() T {
+	data, _ := DataAs[T](t.Shape)
+	return data
+}
+
+// SetTypedData sets the Data of the Shape to the value provided.
+func (t TypedShape[T]) SetTypedData(v T) {
+	t.Shape.SetData(v)
+}
+
+// FilterByDataType returns a new Space that has just the Shapes in the Space provided whose Data is of type T.
+func FilterByDataType[T any](sp *Space) *Space {
+	return sp.Filter(func(s Shape) bool {
+		_, ok := s.GetData().(T)
+		return ok
+	})
+}
@@ -0,0 +1,136 @@
+//go:build go1.18
+
+package resolv
+
+import "testing"
+
+func TestTypedShapeColliding(t *testing.T) {
+
+	shapes := func() []Shape {
+		return []Shape{NewRectangle(0, 0, 16, 16), NewCircle(8, 8, 8), NewLine(0, 0, 16, 16)}
+	}
+	far := func() []Shape {
+		return []Shape{NewRectangle(100, 100, 16, 16), NewCircle(108, 108, 8), NewLine(100, 100, 116, 116)}
+	}
+
+	tests := []struct {
+		name  string
+		other func(i int) Shape
+		want  bool
+	}{
+		{"overlapping", func(i int) Shape { return Typed[int](shapes()[i]) }, true},
+		{"apart", func(i int) Shape { return Typed[int](far()[i]) }, false},
+		{"wrapped twice", func(i int) Shape { return Typed[string](Typed[int](shapes()[i])) }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, a := range shapes() {
+				for j := range shapes() {
+					other := tt.other(j)
+					if got := a.IsColliding(other); got != tt.want {
+						t.Errorf("%T.IsColliding(%T) = %v, want %v", a, shapes()[j], got, tt.want)
+					}
+					if got := ShapesColliding(other, a); got != tt.want {
+						t.Errorf("ShapesColliding(typed %T, %T) = %v, want %v", shapes()[j], a, got, tt.want)
+					}
+					if got := Typed[int](a).IsColliding(other); got != tt.want {
+						t.Errorf("typed %T colliding with typed %T = %v, want %v", a, shapes()[j], got, tt.want)
+					}
+				}
+			}
+		})
+	}
+
+}
+
+func TestTypedShapeInSpace(t *testing.T) {
+
+	player := NewRectangle(0, 0, 8, 8)
+	wall := NewRectangle(20, 0, 8, 8)
+	sp := NewSpace()
+	sp.Add(player, wall)
+	typed := Typed[int](player)
+
+	tests := []struct {
+		name string
+		got  func() interface{}
+		want interface{}
+	}{
+		{"doesn't collide with itself", func() interface{} { return sp.IsColliding(typed) }, false},
+		{"colliding shapes", func() interface{} { return sp.GetCollidingShapes(typed).Length() }, 0},
+		{"space in place doesn't collide with it", func() interface{} { return sp.WouldBeColliding(typed, 0, 0) }, false},
+		{"space moved onto it", func() interface{} { return sp.WouldBeColliding(typed, -16, 0) }, true},
+		{"resolves against the wall", func() interface{} { return sp.Resolve(typed, 30, 0).ResolveX }, int32(12)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+}
+
+func TestTypedShapeQueriesAgree(t *testing.T) {
+
+	player := NewRectangle(0, 0, 8, 8)
+	sp := NewSpace()
+	sp.Add(player, NewRectangle(4, 4, 8, 8), NewCircle(40, 40, 4), NewRectangle(-4, 0, 6, 6))
+	view := sp.View(func(Shape) bool { return true })
+
+	for _, shape := range []Shape{player, Typed[int](player), Typed[string](Typed[int](player))} {
+
+		want := sp.GetCollidingShapes(shape).Shapes()
+		if len(want) != 2 {
+			t.Fatalf("GetCollidingShapes(%T) = %d Shapes, want 2", shape, len(want))
+		}
+
+		for name, got := range map[string]*Space{
+			"GetCollidingShapesParallel":   sp.GetCollidingShapesParallel(shape, 3),
+			"SpaceView.GetCollidingShapes": view.GetCollidingShapes(shape),
+		} {
+			if got.Length() != len(want) {
+				t.Errorf("%s(%T) = %d Shapes, want %d", name, shape, got.Length(), len(want))
+				continue
+			}
+			for i := range want {
+				if got.Get(i) != want[i] {
+					t.Errorf("%s(%T)[%d] = %v, want %v", name, shape, i, got.Get(i), want[i])
+				}
+			}
+		}
+
+		if got, want := view.IsColliding(shape), sp.IsColliding(shape); got != want {
+			t.Errorf("SpaceView.IsColliding(%T) = %v, want %v", shape, got, want)
+		}
+		if got, want := view.Resolve(shape, 20, 0), sp.Resolve(shape, 20, 0); got.ShapeB != want.ShapeB ||
+			got.ResolveX != want.ResolveX {
+			t.Errorf("SpaceView.Resolve(%T) = %v, %d, want %v, %d", shape, got.ShapeB, got.ResolveX, want.ShapeB,
+				want.ResolveX)
+		}
+
+	}
+
+}
+
+func BenchmarkTypedShapeIsColliding(b *testing.B) {
+
+	r := NewRectangle(0, 0, 16, 16)
+	other := NewRectangle(8, 8, 16, 16)
+
+	for _, bb := range []struct {
+		name  string
+		other Shape
+	}{{"plain", other}, {"typed", Typed[int](other)}} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.IsColliding(bb.other)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,469 @@
+package resolv
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Drawer is an interface for anything that can draw the outlines of the basic Shapes, like a game framework's screen.
+// It's used by Space.DebugDraw() to draw the Shapes in a Space for debugging.
+type Drawer interface {
+	DrawRect(x, y, w, h int32, c color.Color)
+	DrawCircle(x, y, radius int32, c color.Color)
+	DrawLine(x, y, x2, y2 int32, c color.Color)
+}
+
+type debugDrawOptions struct {
+	color          color.Color
+	tagColors      []tagColor
+	collidingColor color.Color
+	boundsColor    color.Color
+	tracker        *CollisionTracker
+	trackedColor   color.Color
+}
+
+type tagColor struct {
+	tag   string
+	color color.Color
+}
+
+// DebugDrawOption is an option that changes how Space.DebugDraw() draws Shapes.
+type DebugDrawOption func(*debugDrawOptions)
+
+// DrawColor sets the color Shapes are drawn with, if no other option gives them a color. The default is white.
+func DrawColor(c color.Color) DebugDrawOption {
+	return func(o *debugDrawOptions) {
+		o.color = c
+	}
+}
+
+// DrawTagColor sets the color Shapes with the tag provided are drawn with. If a Shape has multiple tags with colors set,
+// the color set first is used.
+func DrawTagColor(tag string, c color.Color) DebugDrawOption {
+	return func(o *debugDrawOptions) {
+		o.tagColors = append(o.tagColors, tagColor{tag, c})
+	}
+}
+
+// DrawColliding sets the color Shapes that are currently colliding with another Shape in the Space are drawn with,
+// overriding any other color they'd have.
+func DrawColliding(c color.Color) DebugDrawOption {
+	return func(o *debugDrawOptions) {
+		o.collidingColor = c
+	}
+}
+
+// DrawTracked sets the color Shapes that the CollisionTracker provided found colliding on its last Update() are drawn
+// with, overriding any other color they'd have. Unlike DrawColliding(), this doesn't test the Shapes for collision again.
+func DrawTracked(tracker *CollisionTracker, c color.Color) DebugDrawOption {
+	return func(o *debugDrawOptions) {
+		o.tracker = tracker
+		o.trackedColor = c
+	}
+}
+
+// DrawBoundingRects draws the bounding rectangle of each Shape (see Rectangle.GetBoundingRect()) in the color provided, in
+// addition to the Shape itself.
+func DrawBoundingRects(c color.Color) DebugDrawOption {
+	return func(o *debugDrawOptions) {
+		o.boundsColor = c
+	}
+}
+
+// DebugDraw draws all of the Shapes in the Space (including the Shapes within nested Spaces) using the Drawer provided.
+// Shapes other than the built-in ones are drawn as their bounding rectangles.
+func (sp *Space) DebugDraw(d Drawer, opts ...DebugDrawOption) {
+
+	options := &debugDrawOptions{color: color.White}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	colliding := map[Shape]bool{}
+	if options.collidingColor != nil {
+		for _, pair := range sp.GetCollidingPairs() {
+			colliding[pair.ShapeA] = true
+			colliding[pair.ShapeB] = true
+		}
+	}
+
+	tracked := map[Shape]bool{}
+	if options.tracker != nil {
+		for _, pair := range options.tracker.previous {
+			tracked[pair.ShapeA] = true
+			tracked[pair.ShapeB] = true
+		}
+	}
+
+	var draw func(shape Shape)
+	draw = func(shape Shape) {
+
+		if inner, ok := shape.(*Space); ok {
+			for _, s := range inner.shapes {
+				draw(s)
+			}
+			return
+		}
+
+		c := options.color
+		for _, tc := range options.tagColors {
+			if shape.HasTags(tc.tag) {
+				c = tc.color
+				break
+			}
+		}
+		if tracked[shape] {
+			c = options.trackedColor
+		}
+		if colliding[shape] {
+			c = options.collidingColor
+		}
+
+		switch s := shape.(type) {
+		case *Rectangle:
+			d.DrawRect(s.X, s.Y, s.W, s.H, c)
+		case *Circle:
+			d.DrawCircle(s.X, s.Y, s.Radius, c)
+		case *Line:
+			d.DrawLine(s.X, s.Y, s.X2, s.Y2, c)
+		default:
+			r := shapeBoundingRect(shape)
+			d.DrawRect(r.X, r.Y, r.W, r.H, c)
+		}
+
+		if options.boundsColor != nil {
+			r := shapeBoundingRect(shape)
+			d.DrawRect(r.X, r.Y, r.W, r.H, options.boundsColor)
+		}
+
+	}
+
+	for _, shape := range sp.shapes {
+		draw(shape)
+	}
+
+}
+
+// ImageDrawer is a Drawer that draws into an *image.RGBA, which can then be saved as a PNG, for example (see also
+// RenderPNG()). Shapes are drawn offset by OffsetX and OffsetY, and then scaled by Scale (where zero is the same as 1).
+// If Fill is true, rectangles and circles are drawn filled rather than as outlines. Translucent colors are blended over
+// what's already in the image, and anything outside of the image's bounds is clipped.
+type ImageDrawer struct {
+	Image            *image.RGBA
+	OffsetX, OffsetY int32
+	Scale            float64
+	Fill             bool
+}
+
+// NewImageDrawer returns a pointer to a new ImageDrawer drawing into the image provided.
+func NewImageDrawer(img *image.RGBA) *ImageDrawer {
+	return &ImageDrawer{Image: img}
+}
+
+// pixel returns the position in the image of the point provided.
+func (d *ImageDrawer) pixel(x, y int32) (int64, int64) {
+	px, py := int64(x)+int64(d.OffsetX), int64(y)+int64(d.OffsetY)
+	if d.Scale == 0 || d.Scale == 1 {
+		return px, py
+	}
+	return int64(math.Floor(float64(px) * d.Scale)), int64(math.Floor(float64(py) * d.Scale))
+}
+
+// length returns the length provided in pixels.
+func (d *ImageDrawer) length(l int32) int64 {
+	if d.Scale == 0 || d.Scale == 1 {
+		return int64(l)
+	}
+	return int64(math.Round(float64(l) * d.Scale))
+}
+
+// set sets the pixel provided, blending translucent colors over what's already there. Pixels outside of the image's
+// bounds are ignored, which takes care of clipping.
+func (d *ImageDrawer) set(x, y int64, c color.Color) {
+
+	bounds := d.Image.Bounds()
+	if x < int64(bounds.Min.X) || y < int64(bounds.Min.Y) || x >= int64(bounds.Max.X) || y >= int64(bounds.Max.Y) {
+		return
+	}
+
+	r, g, b, a := c.RGBA()
+	if a == 0xffff {
+		d.Image.Set(int(x), int(y), c)
+		return
+	}
+
+	dst := d.Image.RGBAAt(int(x), int(y))
+	blend := func(src uint32, dst uint8) uint8 {
+		return uint8((src + uint32(dst)*0x101*(0xffff-a)/0xffff) >> 8)
+	}
+	d.Image.SetRGBA(int(x), int(y), color.RGBA{blend(r, dst.R), blend(g, dst.G), blend(b, dst.B), blend(a, dst.A)})
+
+}
+
+// span sets the pixels from x to x2 (inclusive) on row y, clipped to the image's bounds first so that huge Shapes don't
+// take long to draw.
+func (d *ImageDrawer) span(x, x2, y int64, c color.Color) {
+	bounds := d.Image.Bounds()
+	if y < int64(bounds.Min.Y) || y >= int64(bounds.Max.Y) {
+		return
+	}
+	if x < int64(bounds.Min.X) {
+		x = int64(bounds.Min.X)
+	}
+	if x2 >= int64(bounds.Max.X) {
+		x2 = int64(bounds.Max.X) - 1
+	}
+	for ; x <= x2; x++ {
+		d.set(x, y, c)
+	}
+}
+
+// DrawRect draws a rectangle, either as an outline or filled, depending on Fill.
+func (d *ImageDrawer) DrawRect(x, y, w, h int32, c color.Color) {
+
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	x0, y0 := d.pixel(x, y)
+	x1, y1 := x0+d.length(w)-1, y0+d.length(h)-1
+	if x1 < x0 {
+		x1 = x0
+	}
+	if y1 < y0 {
+		y1 = y0
+	}
+
+	bounds := d.Image.Bounds()
+	top, bottom := y0, y1
+	if top < int64(bounds.Min.Y) {
+		top = int64(bounds.Min.Y)
+	}
+	if bottom >= int64(bounds.Max.Y) {
+		bottom = int64(bounds.Max.Y) - 1
+	}
+
+	for py := top; py <= bottom; py++ {
+		if d.Fill || py == y0 || py == y1 {
+			d.span(x0, x1, py, c)
+		} else {
+			d.set(x0, py, c)
+			if x1 != x0 {
+				d.set(x1, py, c)
+			}
+		}
+	}
+
+}
+
+// DrawCircle draws a circle, either as an outline or filled, depending on Fill, using the midpoint circle algorithm.
+func (d *ImageDrawer) DrawCircle(cx, cy, radius int32, c color.Color) {
+
+	px, py := d.pixel(cx, cy)
+	r := d.length(radius)
+
+	bounds := d.Image.Bounds()
+	if r < 0 || px+r < int64(bounds.Min.X) || py+r < int64(bounds.Min.Y) || px-r >= int64(bounds.Max.X) || py-r >= int64(bounds.Max.Y) {
+		return
+	}
+
+	if r > maxMidpointRadius {
+		d.drawHugeCircle(px, py, r, c)
+		return
+	}
+
+	// Each octant is mirrored, but pixels that land on the same row are only drawn once, so that translucent colors
+	// aren't blended over themselves.
+	row := func(y, x int64) {
+		if d.Fill {
+			d.span(px-x, px+x, y, c)
+			return
+		}
+		d.set(px-x, y, c)
+		if x != 0 {
+			d.set(px+x, y, c)
+		}
+	}
+
+	x, y := r, int64(0)
+	err := 1 - r
+
+	for x >= y {
+		row(py+y, x)
+		if y != 0 {
+			row(py-y, x)
+		}
+		if x != y {
+			// The rows at the top and bottom of the circle are only drawn once they stop changing, so that filled
+			// circles don't draw over the same row more than once.
+			if d.Fill {
+				if err >= 0 {
+					row(py+x, y)
+					row(py-x, y)
+				}
+			} else {
+				row(py+x, y)
+				row(py-x, y)
+			}
+		}
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+
+}
+
+// maxMidpointRadius is the largest radius (in pixels) of circles drawn with the midpoint circle algorithm; it goes
+// around the whole circle, even the parts outside of the image, so larger circles are drawn row by row instead.
+const maxMidpointRadius = 1 << 14
+
+// drawHugeCircle draws a circle too large for the midpoint circle algorithm, by working out the extent of each of the
+// circle's rows within the image.
+func (d *ImageDrawer) drawHugeCircle(px, py, r int64, c color.Color) {
+
+	bounds := d.Image.Bounds()
+
+	// halfWidth returns the distance from the center of the circle to its edge on the row dy away from its center.
+	halfWidth := func(dy int64) int64 {
+		if dy < 0 {
+			dy = -dy
+		}
+		if dy > r {
+			return -1
+		}
+		return int64(math.Sqrt(float64(r*r - dy*dy)))
+	}
+
+	top, bottom := py-r, py+r
+	if top < int64(bounds.Min.Y) {
+		top = int64(bounds.Min.Y)
+	}
+	if bottom >= int64(bounds.Max.Y) {
+		bottom = int64(bounds.Max.Y) - 1
+	}
+
+	for y := top; y <= bottom; y++ {
+		x := halfWidth(y - py)
+		if d.Fill {
+			d.span(px-x, px+x, y, c)
+			continue
+		}
+		// The outline covers the edge of this row out to where the next row towards the circle's middle reaches, so
+		// that it stays connected where the edge is nearly horizontal.
+		inner := x
+		if y < py {
+			inner = halfWidth(y + 1 - py)
+		} else if y > py {
+			inner = halfWidth(y - 1 - py)
+		}
+		if inner > x+1 {
+			inner--
+		} else {
+			inner = x
+		}
+		d.span(px-inner, px-x, y, c)
+		d.span(px+x, px+inner, y, c)
+	}
+
+}
+
+// DrawLine draws a line, using Bresenham's line algorithm. The line is clipped to the image's bounds first, so that huge
+// lines don't take long to draw.
+func (d *ImageDrawer) DrawLine(x1, y1, x2, y2 int32, c color.Color) {
+
+	x, y := d.pixel(x1, y1)
+	ex, ey := d.pixel(x2, y2)
+
+	var ok bool
+	if x, y, ex, ey, ok = d.clipLine(x, y, ex, ey); !ok {
+		return
+	}
+
+	dx, dy := ex-x, ey-y
+	sx, sy := int64(1), int64(1)
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+
+	err := dx - dy
+
+	for {
+		d.set(x, y, c)
+		if x == ex && y == ey {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+	}
+
+}
+
+// clipLine clips the line between the pixels provided to the image's bounds (using the Liang-Barsky algorithm),
+// returning the clipped line, and whether any of it is within the image. Lines within the image are left as they are.
+func (d *ImageDrawer) clipLine(x, y, x2, y2 int64) (int64, int64, int64, int64, bool) {
+
+	bounds := d.Image.Bounds()
+	minX, minY := int64(bounds.Min.X), int64(bounds.Min.Y)
+	maxX, maxY := int64(bounds.Max.X)-1, int64(bounds.Max.Y)-1
+
+	inside := func(x, y int64) bool {
+		return x >= minX && y >= minY && x <= maxX && y <= maxY
+	}
+	if inside(x, y) && inside(x2, y2) {
+		return x, y, x2, y2, true
+	}
+
+	dx, dy := float64(x2-x), float64(y2-y)
+	t0, t1 := 0.0, 1.0
+
+	for _, edge := range [4][2]float64{
+		{-dx, float64(x - minX)},
+		{dx, float64(maxX - x)},
+		{-dy, float64(y - minY)},
+		{dy, float64(maxY - y)},
+	} {
+		p, q := edge[0], edge[1]
+		if p == 0 {
+			if q < 0 {
+				return 0, 0, 0, 0, false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			if t > t1 {
+				return 0, 0, 0, 0, false
+			}
+			if t > t0 {
+				t0 = t
+			}
+		} else {
+			if t < t0 {
+				return 0, 0, 0, 0, false
+			}
+			if t < t1 {
+				t1 = t
+			}
+		}
+	}
+
+	fx, fy := float64(x), float64(y)
+	return int64(math.Round(fx + t0*dx)), int64(math.Round(fy + t0*dy)),
+		int64(math.Round(fx + t1*dx)), int64(math.Round(fy + t1*dy)), true
+
+}
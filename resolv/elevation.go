@@ -0,0 +1,79 @@
+package resolv
+
+// TransitionTag is the tag for Shapes that connect elevations, like stairs or ramps. With the default elevation filter
+// (see ElevationsCollide), a Shape with this tag collides with Shapes on its own elevation and on the elevations
+// directly above and below it.
+const TransitionTag = "elevation-transition"
+
+// ElevationsCollide is the default elevation filter used by Spaces (see Space.SetElevationFilter()). It returns true if
+// the two Shapes are on the same elevation, or if either of them has the TransitionTag and their elevations are one
+// apart.
+func ElevationsCollide(a, b Shape) bool {
+	diff := shapeElevation(a) - shapeElevation(b)
+	if diff == 0 {
+		return true
+	}
+	if diff == 1 || diff == -1 {
+		return a.HasTags(TransitionTag) || b.HasTags(TransitionTag)
+	}
+	return false
+}
+
+// AnyElevation is an elevation filter for Space.SetElevationFilter() that lets Shapes collide regardless of their
+// elevations.
+func AnyElevation(a, b Shape) bool {
+	return true
+}
+
+// SetElevationFilter sets the function the Space uses to decide whether two Shapes can collide given their elevations.
+// Pairs of Shapes the function returns false for are skipped by the Space's collision functions, just like pairs whose
+// collision layers don't match. By default (or if the filter is set to nil), ElevationsCollide is used; use AnyElevation
+// to ignore elevations entirely.
+func (sp *Space) SetElevationFilter(filter func(a, b Shape) bool) {
+	sp.elevationFilter = filter
+}
+
+// canCollide returns whether the two Shapes should be tested against each other by the Space, according to their
+// collision layers and elevations.
+func (sp *Space) canCollide(a, b Shape) bool {
+	if !LayersCollide(a, b) {
+		return false
+	}
+	if sp.elevationFilter != nil {
+		return sp.elevationFilter(a, b)
+	}
+	return ElevationsCollide(a, b)
+}
+
+// FilterByElevation filters a Space out, creating a new Space that has just the Shapes on the elevation provided.
+func (sp *Space) FilterByElevation(elevation int32) *Space {
+	return sp.Filter(func(s Shape) bool {
+		return shapeElevation(s) == elevation
+	})
+}
+
+// GetElevation returns the elevation of the root Shape in the Space (see Root()), or 0 if there aren't any Shapes within
+// the Space.
+func (sp *Space) GetElevation() int32 {
+	if root := sp.Root(); root != nil {
+		return shapeElevation(root)
+	}
+	return 0
+}
+
+// SetElevation sets the elevation of all Shapes within the Space.
+func (sp *Space) SetElevation(elevation int32) {
+	for _, shape := range sp.shapes {
+		if s, ok := shape.(interface{ SetElevation(int32) }); ok {
+			s.SetElevation(elevation)
+		}
+	}
+}
+
+// shapeElevation returns the elevation of the Shape, or 0 if it doesn't have a GetElevation() function.
+func shapeElevation(shape Shape) int32 {
+	if s, ok := shape.(interface{ GetElevation() int32 }); ok {
+		return s.GetElevation()
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package resolv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tagsSuffix returns the tags of the BasicShape formatted for String(), or an empty string if it has no tags.
+func (b *BasicShape) tagsSuffix() string {
+	if b.tags.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" tags=%v", b.tags.sorted)
+}
+
+// optionsGoString returns the options needed to recreate the BasicShape's tags, layer, mask, and elevation, for
+// GoString(). Data can't be written out as Go syntax in general, so it's left out.
+func (b *BasicShape) optionsGoString() string {
+	var opts strings.Builder
+	if b.tags.Len() > 0 {
+		quoted := make([]string, b.tags.Len())
+		for i, tag := range b.tags.sorted {
+			quoted[i] = fmt.Sprintf("%q", tag)
+		}
+		fmt.Fprintf(&opts, ", resolv.WithTags(%s)", strings.Join(quoted, ", "))
+	}
+	if layer := b.GetLayer(); layer != DefaultLayer {
+		fmt.Fprintf(&opts, ", resolv.WithLayer(%#x)", layer)
+	}
+	if mask := b.GetMask(); mask != AllLayers {
+		fmt.Fprintf(&opts, ", resolv.WithMask(%#x)", mask)
+	}
+	if b.Elevation != 0 {
+		fmt.Fprintf(&opts, ", resolv.WithElevation(%d)", b.Elevation)
+	}
+	return opts.String()
+}
+
+// String returns a description of the Rectangle, like "Rect(0,0 16x16) tags=[solid]".
+func (r *Rectangle) String() string {
+	return fmt.Sprintf("Rect(%d,%d %dx%d)%s", r.X, r.Y, r.W, r.H, r.tagsSuffix())
+}
+
+// GoString returns Go syntax that creates a copy of the Rectangle (other than its Data), for printing it with %#v.
+func (r *Rectangle) GoString() string {
+	return fmt.Sprintf("resolv.NewRectangle(%d, %d, %d, %d%s)", r.X, r.Y, r.W, r.H, r.optionsGoString())
+}
+
+// String returns a description of the Circle, like "Circle(8,8 r=4) tags=[coin]".
+func (c *Circle) String() string {
+	return fmt.Sprintf("Circle(%d,%d r=%d)%s", c.X, c.Y, c.Radius, c.tagsSuffix())
+}
+
+// GoString returns Go syntax that creates a copy of the Circle (other than its Data), for printing it with %#v.
+func (c *Circle) GoString() string {
+	return fmt.Sprintf("resolv.NewCircle(%d, %d, %d%s)", c.X, c.Y, c.Radius, c.optionsGoString())
+}
+
+// String returns a description of the Line, like "Line(0,0 -> 16,8) tags=[ramp]".
+func (l *Line) String() string {
+	return fmt.Sprintf("Line(%d,%d -> %d,%d)%s", l.X, l.Y, l.X2, l.Y2, l.tagsSuffix())
+}
+
+// GoString returns Go syntax that creates a copy of the Line (other than its Data), for printing it with %#v.
+func (l *Line) GoString() string {
+	return fmt.Sprintf("resolv.NewLine(%d, %d, %d, %d%s)", l.X, l.Y, l.X2, l.Y2, l.optionsGoString())
+}
+
+// String returns a description of the Space and the Shapes in it, one per line and indented under the Space, with nested
+// Spaces indented further:
+//
+//	Space(2 shapes)
+//	  Rect(0,0 16x16) tags=[solid]
+//	  Space(1 shape)
+//	    Circle(8,8 r=4)
+func (sp *Space) String() string {
+	var str strings.Builder
+	sp.writeString(&str, "")
+	return str.String()
+}
+
+func (sp *Space) writeString(str *strings.Builder, indent string) {
+
+	if len(sp.shapes) == 1 {
+		str.WriteString("Space(1 shape)")
+	} else {
+		fmt.Fprintf(str, "Space(%d shapes)", len(sp.shapes))
+	}
+
+	for _, shape := range sp.shapes {
+		str.WriteString("\n" + indent + "  ")
+		if inner, ok := shape.(*Space); ok {
+			inner.writeString(str, indent+"  ")
+		} else {
+			fmt.Fprint(str, shape)
+		}
+	}
+
+}
+
+// GoString returns Go syntax that creates a copy of the Space (other than the Data of its Shapes), for printing it with
+// %#v. Shapes other than the built-in ones are written with their own GoString() functions, if they have them.
+func (sp *Space) GoString() string {
+	var str strings.Builder
+	str.WriteString("func() *resolv.Space {\n\tsp := resolv.NewSpace()\n")
+	for _, shape := range sp.shapes {
+		fmt.Fprintf(&str, "\tsp.Add(%#v)\n", shape)
+	}
+	str.WriteString("\treturn sp\n}()")
+	return str.String()
+}
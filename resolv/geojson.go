@@ -0,0 +1,329 @@
+package resolv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+type geoJSONObject struct {
+	Type        string                     `json:"type"`
+	Features    []geoJSONObject            `json:"features"`
+	Geometry    *geoJSONObject             `json:"geometry"`
+	Geometries  []geoJSONObject            `json:"geometries"`
+	Properties  map[string]json.RawMessage `json:"properties"`
+	Coordinates json.RawMessage            `json:"coordinates"`
+}
+
+type geoJSONOptions struct {
+	pointRadius int32
+	allRings    bool
+}
+
+// GeoJSONOption is an option that changes how LoadGeoJSON() reads GeoJSON.
+type GeoJSONOption func(*geoJSONOptions)
+
+// GeoJSONPointRadius sets the radius of the Circles LoadGeoJSON() creates for Point features. The default is 4.
+func GeoJSONPointRadius(radius int32) GeoJSONOption {
+	return func(o *geoJSONOptions) {
+		o.pointRadius = radius
+	}
+}
+
+// GeoJSONAllRings sets whether LoadGeoJSON() creates Shapes for the interior rings (holes) of Polygons, each as its own
+// Space of Lines alongside the exterior ring's. By default, only exterior rings are loaded.
+func GeoJSONAllRings(all bool) GeoJSONOption {
+	return func(o *geoJSONOptions) {
+		o.allRings = all
+	}
+}
+
+// geoJSONLoader converts GeoJSON coordinates into positions within a Space.
+type geoJSONLoader struct {
+	options          *geoJSONOptions
+	scale            float64
+	offsetX, offsetY int32
+}
+
+// point converts a GeoJSON position into a point within the Space, returning an error if it's malformed or out of the
+// range of int32 coordinates.
+func (l *geoJSONLoader) point(position []float64) ([2]int32, error) {
+
+	if len(position) < 2 {
+		return [2]int32{}, fmt.Errorf("position %v has fewer than two coordinates", position)
+	}
+
+	// GeoJSON's Y axis points north, while a Space's points down, so the Y axis is flipped.
+	x := math.Round(position[0]*l.scale) + float64(l.offsetX)
+	y := math.Round(-position[1]*l.scale) + float64(l.offsetY)
+
+	if !(x >= math.MinInt32 && x <= math.MaxInt32 && y >= math.MinInt32 && y <= math.MaxInt32) {
+		return [2]int32{}, fmt.Errorf("position %v is out of the range of int32 coordinates once scaled", position)
+	}
+
+	return [2]int32{int32(x), int32(y)}, nil
+
+}
+
+// lines converts a GeoJSON line string into a Line, or a Space of Lines if it has more than two positions.
+func (l *geoJSONLoader) lines(positions [][]float64) (Shape, error) {
+
+	if len(positions) < 2 {
+		return nil, fmt.Errorf("line has fewer than two positions")
+	}
+
+	points := make([][2]int32, len(positions))
+	for i, position := range positions {
+		p, err := l.point(position)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = p
+	}
+
+	if len(points) == 2 {
+		return NewLine(points[0][0], points[0][1], points[1][0], points[1][1]), nil
+	}
+
+	lines := NewSpace(WithCapacity(len(points) - 1))
+	for i := 1; i < len(points); i++ {
+		lines.Add(NewLine(points[i-1][0], points[i-1][1], points[i][0], points[i][1]))
+	}
+	return lines, nil
+
+}
+
+// polygon converts the rings of a GeoJSON polygon into Spaces of Lines.
+func (l *geoJSONLoader) polygon(rings [][][]float64) ([]Shape, error) {
+
+	if len(rings) == 0 {
+		return nil, fmt.Errorf("polygon has no rings")
+	}
+	if !l.options.allRings {
+		rings = rings[:1]
+	}
+
+	shapes := []Shape{}
+	for _, ring := range rings {
+		if len(ring) < 4 {
+			return nil, fmt.Errorf("polygon ring has fewer than four positions")
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if len(first) < 2 || len(last) < 2 || first[0] != last[0] || first[1] != last[1] {
+			return nil, fmt.Errorf("polygon ring isn't closed")
+		}
+		lines, err := l.lines(ring)
+		if err != nil {
+			return nil, err
+		}
+		shapes = append(shapes, lines)
+	}
+	return shapes, nil
+
+}
+
+// geometry converts a GeoJSON geometry into Shapes.
+func (l *geoJSONLoader) geometry(g *geoJSONObject) ([]Shape, error) {
+
+	if g == nil {
+		return nil, fmt.Errorf("feature has no geometry")
+	}
+
+	decode := func(v interface{}) error {
+		if err := json.Unmarshal(g.Coordinates, v); err != nil {
+			return fmt.Errorf("malformed %s coordinates: %v", g.Type, err)
+		}
+		return nil
+	}
+
+	switch g.Type {
+
+	case "Point":
+		var position []float64
+		if err := decode(&position); err != nil {
+			return nil, err
+		}
+		p, err := l.point(position)
+		if err != nil {
+			return nil, err
+		}
+		return []Shape{NewCircle(p[0], p[1], l.options.pointRadius)}, nil
+
+	case "MultiPoint":
+		var positions [][]float64
+		if err := decode(&positions); err != nil {
+			return nil, err
+		}
+		shapes := []Shape{}
+		for _, position := range positions {
+			p, err := l.point(position)
+			if err != nil {
+				return nil, err
+			}
+			shapes = append(shapes, NewCircle(p[0], p[1], l.options.pointRadius))
+		}
+		return shapes, nil
+
+	case "LineString":
+		var positions [][]float64
+		if err := decode(&positions); err != nil {
+			return nil, err
+		}
+		shape, err := l.lines(positions)
+		if err != nil {
+			return nil, err
+		}
+		return []Shape{shape}, nil
+
+	case "MultiLineString":
+		var lineStrings [][][]float64
+		if err := decode(&lineStrings); err != nil {
+			return nil, err
+		}
+		shapes := []Shape{}
+		for _, positions := range lineStrings {
+			shape, err := l.lines(positions)
+			if err != nil {
+				return nil, err
+			}
+			shapes = append(shapes, shape)
+		}
+		return shapes, nil
+
+	case "Polygon":
+		var rings [][][]float64
+		if err := decode(&rings); err != nil {
+			return nil, err
+		}
+		return l.polygon(rings)
+
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := decode(&polygons); err != nil {
+			return nil, err
+		}
+		shapes := []Shape{}
+		for _, rings := range polygons {
+			polygon, err := l.polygon(rings)
+			if err != nil {
+				return nil, err
+			}
+			shapes = append(shapes, polygon...)
+		}
+		return shapes, nil
+
+	case "GeometryCollection":
+		shapes := []Shape{}
+		for i := range g.Geometries {
+			inner, err := l.geometry(&g.Geometries[i])
+			if err != nil {
+				return nil, err
+			}
+			shapes = append(shapes, inner...)
+		}
+		return shapes, nil
+
+	}
+
+	return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+
+}
+
+// geoJSONTags returns the tags for the properties of a feature: "key=value" for strings and numbers, and just "key" for
+// properties set to true, in order of their keys.
+func geoJSONTags(properties map[string]json.RawMessage) []string {
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := []string{}
+	for _, key := range keys {
+		var value interface{}
+		if json.Unmarshal(properties[key], &value) != nil {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			tags = append(tags, key+"="+v)
+		case float64:
+			tags = append(tags, key+"="+strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			if v {
+				tags = append(tags, key)
+			}
+		}
+	}
+	return tags
+
+}
+
+// LoadGeoJSON reads map data in the GeoJSON format (a FeatureCollection, a single Feature, or a bare geometry), and
+// returns a Space containing Shapes for its features. Coordinates are multiplied by the scale provided, rounded, and then
+// offset by offsetX and offsetY; as GeoJSON's Y axis points north and a Space's points down, the Y axis is flipped.
+// Points become Circles (see GeoJSONPointRadius()), LineStrings with two positions become Lines, longer LineStrings
+// become Spaces of Lines, and Polygons become a Space of Lines for their exterior rings (see GeoJSONAllRings()); the Multi
+// geometries and GeometryCollections become a Shape for each of their parts. Each feature's properties are added to its
+// Shapes as tags (see below).
+// Features with invalid or unsupported geometry, or with coordinates that end up out of the range of int32 coordinates,
+// are skipped, with an error describing each of them returned as a warning. LoadGeoJSON only returns an error if the
+// GeoJSON can't be read at all.
+// Properties become tags in the form "key=value" for strings and numbers, and "key" for properties that are true; other
+// properties are ignored.
+func LoadGeoJSON(r io.Reader, scale float64, offsetX, offsetY int32, opts ...GeoJSONOption) (*Space, []error, error) {
+
+	options := &geoJSONOptions{pointRadius: 4}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	root := geoJSONObject{}
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, nil, fmt.Errorf("resolv: can't read GeoJSON: %w", err)
+	}
+
+	var features []geoJSONObject
+	switch root.Type {
+	case "FeatureCollection":
+		features = root.Features
+	case "Feature":
+		features = []geoJSONObject{root}
+	default:
+		features = []geoJSONObject{{Type: "Feature", Geometry: &root}}
+	}
+
+	loader := &geoJSONLoader{options, scale, offsetX, offsetY}
+	space := NewSpace()
+	var warnings []error
+
+	for i, feature := range features {
+
+		if feature.Type != "Feature" {
+			warnings = append(warnings, fmt.Errorf("resolv: GeoJSON feature %d has type %q rather than \"Feature\"", i, feature.Type))
+			continue
+		}
+
+		shapes, err := loader.geometry(feature.Geometry)
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("resolv: GeoJSON feature %d skipped: %v", i, err))
+			continue
+		}
+
+		tags := geoJSONTags(feature.Properties)
+		for _, shape := range shapes {
+			if len(tags) > 0 {
+				shape.AddTags(tags...)
+			}
+			space.Add(shape)
+		}
+
+	}
+
+	return space, warnings, nil
+
+}
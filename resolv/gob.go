@@ -0,0 +1,258 @@
+package resolv
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// The built-in Shapes are registered with gob, so that they can be encoded as Shape interface values, like the Shapes in
+// a Space.
+func init() {
+	gob.Register(&Rectangle{})
+	gob.Register(&Circle{})
+	gob.Register(&Line{})
+	gob.Register(&Space{})
+}
+
+// basicShapeState is the state of a BasicShape saved by gob. Data isn't included, as it can be of any type, including
+// types gob can't encode; it's up to the game to save it separately (like by the Shape's ID) and restore it after
+// decoding.
+type basicShapeState struct {
+	X, Y        int32
+	Tags        []string
+	Layer, Mask uint32
+	Elevation   int32
+	ID          uint64
+}
+
+func (b *BasicShape) gobState() basicShapeState {
+	return basicShapeState{
+		X:         b.X,
+		Y:         b.Y,
+		Tags:      b.tags.Strings(),
+		Layer:     b.GetLayer(),
+		Mask:      b.GetMask(),
+		Elevation: b.Elevation,
+		ID:        b.ID(),
+	}
+}
+
+func (b *BasicShape) setGobState(state basicShapeState) {
+	b.X, b.Y = state.X, state.Y
+	b.tags = NewTags(state.Tags...)
+	b.SetLayer(state.Layer)
+	b.SetMask(state.Mask)
+	b.Elevation = state.Elevation
+	b.SetID(state.ID)
+	b.tagsChanged()
+	b.changed()
+}
+
+func gobEncode(state interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte, state interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(state)
+}
+
+type rectangleState struct {
+	Basic basicShapeState
+	W, H  int32
+}
+
+// GobEncode encodes the Rectangle for encoding/gob. Its position, size, tags, layer, mask, elevation, and ID are saved,
+// but its Data isn't (see basicShapeState).
+func (r *Rectangle) GobEncode() ([]byte, error) {
+	return gobEncode(rectangleState{r.gobState(), r.W, r.H})
+}
+
+// GobDecode decodes a Rectangle encoded with GobEncode().
+func (r *Rectangle) GobDecode(data []byte) error {
+	state := rectangleState{}
+	if err := gobDecode(data, &state); err != nil {
+		return err
+	}
+	r.setGobState(state.Basic)
+	r.W, r.H = state.W, state.H
+	return nil
+}
+
+type circleState struct {
+	Basic  basicShapeState
+	Radius int32
+}
+
+// GobEncode encodes the Circle for encoding/gob. Its position, radius, tags, layer, mask, elevation, and ID are saved,
+// but its Data isn't.
+func (c *Circle) GobEncode() ([]byte, error) {
+	return gobEncode(circleState{c.gobState(), c.Radius})
+}
+
+// GobDecode decodes a Circle encoded with GobEncode().
+func (c *Circle) GobDecode(data []byte) error {
+	state := circleState{}
+	if err := gobDecode(data, &state); err != nil {
+		return err
+	}
+	c.setGobState(state.Basic)
+	c.Radius = state.Radius
+	return nil
+}
+
+type lineState struct {
+	Basic  basicShapeState
+	X2, Y2 int32
+}
+
+// GobEncode encodes the Line for encoding/gob. Its points, tags, layer, mask, elevation, and ID are saved, but its Data
+// isn't.
+func (l *Line) GobEncode() ([]byte, error) {
+	return gobEncode(lineState{l.gobState(), l.X2, l.Y2})
+}
+
+// GobDecode decodes a Line encoded with GobEncode().
+func (l *Line) GobDecode(data []byte) error {
+	state := lineState{}
+	if err := gobDecode(data, &state); err != nil {
+		return err
+	}
+	l.setGobState(state.Basic)
+	l.X2, l.Y2 = state.X2, state.Y2
+	l.cacheLength()
+	return nil
+}
+
+type spaceState struct {
+	Shapes []Shape
+	// Root is the index of the root Shape set with SetRoot(), or -1 if it wasn't set.
+	Root   int
+	Names  map[string]int
+	Strict bool
+}
+
+// GobEncode encodes the Space for encoding/gob, along with its Shapes (including nested Spaces), root Shape, Shape names,
+// and whether it's strict. Shapes other than the built-in ones have to be registered with gob.Register() to be encoded.
+// The Data of the Shapes, and functions registered with the Space (like with OnAdd()), aren't saved.
+func (sp *Space) GobEncode() ([]byte, error) {
+
+	state := spaceState{Shapes: sp.shapes, Root: -1, Strict: sp.strict}
+	if sp.root != nil {
+		state.Root = sp.IndexOf(sp.root)
+	}
+	if len(sp.names) > 0 {
+		state.Names = make(map[string]int, len(sp.names))
+		for name, shape := range sp.names {
+			state.Names[name] = sp.IndexOf(shape)
+		}
+	}
+
+	data, err := gobEncode(state)
+	if err != nil {
+		return nil, fmt.Errorf("resolv: can't encode space: %w", err)
+	}
+	return data, nil
+
+}
+
+// GobDecode decodes a Space encoded with GobEncode(), adding the decoded Shapes to the Space. gob decodes each Shape on
+// its own, so a Shape that was in the encoded Space more than once (like in the Space and in a Space nested within it)
+// would be decoded as separate copies; Shapes decoded with the same ID are made the same Shape again, like they were when
+// encoded (nested Spaces themselves have no IDs, so a Space nested in more than one place is still decoded as copies).
+// Decoded Shapes keep their saved IDs (see BasicShape.SetID()), so decoding the same data twice gives two sets of Shapes
+// with the same IDs.
+func (sp *Space) GobDecode(data []byte) error {
+
+	state := spaceState{}
+	if err := gobDecode(data, &state); err != nil {
+		return fmt.Errorf("resolv: can't decode space: %w", err)
+	}
+
+	if err := shareDecoded(state.Shapes, map[uint64]Shape{}); err != nil {
+		return fmt.Errorf("resolv: can't decode space: %w", err)
+	}
+
+	offset := len(sp.shapes)
+	sp.SetStrict(state.Strict)
+	if err := sp.Add(state.Shapes...); err != nil {
+		return err
+	}
+
+	if state.Root >= 0 && state.Root < len(state.Shapes) {
+		sp.root = sp.shapes[offset+state.Root]
+	}
+	for name, index := range state.Names {
+		if index >= 0 && index < len(state.Shapes) {
+			sp.setName(name, sp.shapes[offset+index])
+		}
+	}
+
+	return nil
+
+}
+
+// shareDecoded replaces decoded Shapes with the first Shape decoded with the same ID (see BasicShape.ID()), if any,
+// including the Shapes within nested Spaces, recording the first Shape decoded with each ID in the map provided.
+func shareDecoded(shapes []Shape, decoded map[uint64]Shape) error {
+	for i, shape := range shapes {
+		switch s := shape.(type) {
+		case *Space:
+			if err := s.shareDecoded(decoded); err != nil {
+				return err
+			}
+		case interface{ ID() uint64 }:
+			if first, seen := decoded[s.ID()]; seen {
+				shapes[i] = first
+			} else {
+				decoded[s.ID()] = shape
+			}
+		}
+	}
+	return nil
+}
+
+// shareDecoded replaces the Shapes in a decoded Space like shareDecoded() does, rebuilding the Space (keeping its root and
+// names) if any of them were replaced.
+func (sp *Space) shareDecoded(decoded map[uint64]Shape) error {
+
+	shapes := append([]Shape(nil), sp.shapes...)
+	if err := shareDecoded(shapes, decoded); err != nil {
+		return err
+	}
+
+	replaced := map[Shape]Shape{}
+	for i, shape := range shapes {
+		if shape != sp.shapes[i] {
+			replaced[sp.shapes[i]] = shape
+		}
+	}
+	if len(replaced) == 0 {
+		return nil
+	}
+
+	replace := func(shape Shape) Shape {
+		if other, ok := replaced[shape]; ok {
+			return other
+		}
+		return shape
+	}
+	root, names := sp.root, sp.names
+
+	sp.ClearWithoutCallbacks()
+	if err := sp.Add(shapes...); err != nil {
+		return err
+	}
+	if root != nil {
+		sp.root = replace(root)
+	}
+	for name, shape := range names {
+		sp.setName(name, replace(shape))
+	}
+	return nil
+
+}
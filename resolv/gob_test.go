@@ -0,0 +1,213 @@
+package resolv
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+func gobRoundTrip(t testing.TB, sp *Space) *Space {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(sp); err != nil {
+		t.Fatalf("encoding failed: %v", err)
+	}
+	decoded := NewSpace()
+	if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+		t.Fatalf("decoding failed: %v", err)
+	}
+	return decoded
+}
+
+func TestSpaceGobRoundTrip(t *testing.T) {
+
+	wall := NewRectangle(1, 2, 30, 40, WithTags("solid", "wall"), WithLayer(4), WithMask(6), WithElevation(2))
+	wall.SetData("skipped")
+	coin := NewCircle(-5, 6, 7, WithTags("coin"))
+	ramp := NewLine(10, 20, -30, 40, WithTags("ramp"))
+	deepest := NewSpace()
+	deepest.Add(NewRectangle(100, 100, 1, 1))
+	nested := NewSpace()
+	nested.Add(coin, deepest)
+	sp := NewSpace()
+	sp.Add(wall, nested, ramp)
+
+	decoded := gobRoundTrip(t, sp)
+	if decoded.Length() != 3 {
+		t.Fatalf("decoded %d Shapes, want 3", decoded.Length())
+	}
+
+	r, ok := decoded.Get(0).(*Rectangle)
+	if !ok || r.X != 1 || r.Y != 2 || r.W != 30 || r.H != 40 || !r.HasTags("solid", "wall") || len(r.GetTags()) != 2 {
+		t.Errorf("decoded Rectangle = %#v", decoded.Get(0))
+	} else if r.GetLayer() != 4 || r.GetMask() != 6 || r.GetElevation() != 2 || r.ID() != wall.ID() {
+		t.Errorf("decoded Rectangle has layer %d, mask %d, elevation %d, and ID %d", r.GetLayer(), r.GetMask(),
+			r.GetElevation(), r.ID())
+	} else if r.GetData() != nil {
+		t.Errorf("decoded Rectangle has Data %v, want it skipped", r.GetData())
+	}
+
+	n, ok := decoded.Get(1).(*Space)
+	if !ok || n.Length() != 2 {
+		t.Fatalf("decoded nested Space = %#v", decoded.Get(1))
+	}
+	if c, ok := n.Get(0).(*Circle); !ok || c.X != -5 || c.Y != 6 || c.Radius != 7 || !c.HasTags("coin") {
+		t.Errorf("decoded Circle = %#v", n.Get(0))
+	}
+	if d, ok := n.Get(1).(*Space); !ok || d.Length() != 1 {
+		t.Errorf("decoded doubly nested Space = %#v", n.Get(1))
+	} else if x, y := d.GetXY(); x != 100 || y != 100 {
+		t.Errorf("doubly nested Shape decoded at %d, %d, want 100, 100", x, y)
+	}
+
+	if l, ok := decoded.Get(2).(*Line); !ok || l.X != 10 || l.Y != 20 || l.X2 != -30 || l.Y2 != 40 || !l.HasTags("ramp") {
+		t.Errorf("decoded Line = %#v", decoded.Get(2))
+	}
+
+	// Shapes on their own round trip through the Shape interface too, as the concrete types are registered.
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode([]Shape{coin, ramp}); err != nil {
+		t.Fatal(err)
+	}
+	var shapes []Shape
+	if err := gob.NewDecoder(&buf).Decode(&shapes); err != nil {
+		t.Fatal(err)
+	}
+	if len(shapes) != 2 || shapes[0].(*Circle).Radius != 7 || shapes[1].(*Line).X2 != -30 {
+		t.Errorf("decoded Shapes = %#v", shapes)
+	}
+
+}
+
+func TestSpaceGobSharedShapes(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		build func(shared Shape) *Space
+		check func(t *testing.T, decoded *Space)
+	}{
+		{"in the space and a nested space", func(shared Shape) *Space {
+			nested := NewSpace()
+			nested.Add(shared, NewCircle(0, 0, 4))
+			sp := NewSpace()
+			sp.Add(shared, nested)
+			return sp
+		}, func(t *testing.T, decoded *Space) {
+			if decoded.Get(0) != decoded.Get(1).(*Space).Get(0) {
+				t.Error("the shared Shape was decoded as separate copies")
+			}
+		}},
+		{"in two nested spaces", func(shared Shape) *Space {
+			a, b := NewSpace(), NewSpace()
+			a.Add(shared)
+			b.Add(NewCircle(0, 0, 4), shared)
+			b.SetRoot(shared)
+			b.SetName(shared, "player")
+			sp := NewSpace()
+			sp.Add(a, b)
+			return sp
+		}, func(t *testing.T, decoded *Space) {
+			a, b := decoded.Get(0).(*Space), decoded.Get(1).(*Space)
+			if a.Get(0) != b.Get(1) {
+				t.Error("the shared Shape was decoded as separate copies")
+			}
+			if b.Root() != a.Get(0) || b.GetByName("player") != a.Get(0) {
+				t.Error("the root or name of the nested Space points at a dropped copy")
+			}
+		}},
+		{"twice in a space", func(shared Shape) *Space {
+			sp := NewSpace()
+			sp.Add(shared, shared)
+			return sp
+		}, func(t *testing.T, decoded *Space) {
+			if decoded.Length() != 2 || decoded.Get(0) != decoded.Get(1) {
+				t.Error("the Shape added twice was decoded as separate copies")
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, gobRoundTrip(t, tt.build(NewRectangle(8, 8, 16, 16))))
+		})
+	}
+
+}
+
+func TestSpaceGobDecodeBadIndices(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		root     int
+		names    map[string]int
+		wantRoot bool
+		wantName bool
+	}{
+		{"valid", 1, map[string]int{"a": 1}, true, true},
+		{"negative", -2, map[string]int{"a": -1}, false, false},
+		{"past the end", 2, map[string]int{"a": 2}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			data, err := gobEncode(spaceState{
+				Shapes: []Shape{NewRectangle(0, 0, 8, 8), NewRectangle(8, 0, 8, 8)},
+				Root:   tt.root,
+				Names:  tt.names,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Decoding into a Space that already has Shapes offsets the indices.
+			sp := NewSpace()
+			sp.Add(NewCircle(0, 0, 4))
+			if err := sp.GobDecode(data); err != nil {
+				t.Fatalf("GobDecode() = %v", err)
+			}
+
+			if got := sp.root != nil; got != tt.wantRoot {
+				t.Errorf("root set = %v, want %v", got, tt.wantRoot)
+			} else if got && sp.root != sp.Get(2) {
+				t.Error("the root isn't the Shape it was saved as")
+			}
+			if got := sp.GetByName("a") != nil; got != tt.wantName {
+				t.Errorf("name set = %v, want %v", got, tt.wantName)
+			} else if got && sp.GetByName("a") != sp.Get(2) {
+				t.Error("the name isn't on the Shape it was saved on")
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkSpaceGobDecode(b *testing.B) {
+
+	nested := NewSpace()
+	sp := NewSpace()
+	for i := int32(0); i < 1000; i++ {
+		shape := NewRectangle(i*8, 0, 8, 8)
+		sp.Add(shape)
+		if i%10 == 0 {
+			nested.Add(shape)
+		}
+	}
+	sp.Add(nested)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(sp); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(NewSpace()); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+}
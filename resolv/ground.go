@@ -0,0 +1,100 @@
+package resolv
+
+import "math"
+
+// IsOnGround returns whether the Shape is standing on ground: a Shape in the Space with all of the ground tags provided
+// (any Shape, if none are provided) that's within snapDist pixels (at least 1) below it, and whose surface there is no
+// steeper than maxSlopeAngle (in radians, where 0 is flat). It also returns the Shape it's standing on, which is the
+// nearest one below it (or the first one in the Space, if several are as near). Probing further than a pixel means that
+// seams between tiles and bumps on slopes don't leave the Shape briefly off the ground; see SnapToGround() for moving the
+// Shape down onto the ground as well.
+// The surfaces of Lines slope as the Lines do, and those of Circles as their edges do where the Shape is on them; other
+// Shapes have flat tops. Shapes that the Shape is already colliding with aren't ground. Which Shapes are ground follows
+// their collision layers and masks, and elevation, like Space.Resolve(). The Shape isn't moved; if it's in the Space,
+// it's skipped, rather than tested against itself.
+func (sp *Space) IsOnGround(shape Shape, maxSlopeAngle float64, snapDist int32, groundTags ...string) (bool, Shape) {
+	ground, _ := sp.findGround(shape, maxSlopeAngle, snapDist, groundTags)
+	return ground != nil, ground
+}
+
+// SnapToGround is like IsOnGround(), but also moves the Shape down onto the ground it finds, so that it's resting right
+// on top of it. Call it each frame after moving a Shape that's on the ground (and not jumping), so that it follows the
+// ground down slopes and steps, rather than walking off into the air and falling in a series of hops.
+// The move down is resolved against the whole Space (see Space.Resolve()), so the Shape never snaps through a Shape
+// between it and the ground that isn't ground itself (like an enemy); it stops on top of that Shape instead, and as it
+// isn't on the ground then, false is returned.
+func (sp *Space) SnapToGround(shape Shape, maxSlopeAngle float64, snapDist int32, groundTags ...string) (bool, Shape) {
+	ground, depth := sp.findGround(shape, maxSlopeAngle, snapDist, groundTags)
+	if ground == nil {
+		return false, nil
+	}
+	if depth > 1 {
+		col := resolveDelta(sp, shape, 0, depth-1)
+		shape.Move(0, col.ResolveY)
+		if col.Colliding() {
+			return false, nil
+		}
+	}
+	return true, ground
+}
+
+// findGround returns the ground the Shape is standing on (see IsOnGround()), along with how far the Shape would have to
+// move down to collide with it, or nil if it isn't on any.
+func (sp *Space) findGround(shape Shape, maxSlopeAngle float64, snapDist int32, groundTags []string) (Shape, int32) {
+
+	if snapDist < 1 {
+		snapDist = 1
+	}
+
+	var ground Shape
+	nearest := snapDist + 1
+
+	for _, other := range sp.shapes {
+
+		if other == shape || !other.HasTags(groundTags...) || !sp.canCollide(shape, other) {
+			continue
+		}
+
+		// Only Shapes nearer than the nearest ground found so far can be nearer ground.
+		if !sweptBoundsOverlap(shape, other, 0, nearest-1) || shape.IsColliding(other) {
+			continue
+		}
+
+		for depth := int32(1); depth < nearest; depth++ {
+			if shape.WouldBeColliding(other, 0, depth) {
+				if surfaceSlope(shape, other, depth) <= maxSlopeAngle {
+					ground, nearest = other, depth
+				}
+				break
+			}
+		}
+
+	}
+
+	return ground, nearest
+
+}
+
+// surfaceSlope returns the angle (in radians, from 0 for flat to math.Pi/2 for vertical, or more for the undersides of
+// Circles) of the ground Shape's surface where the Shape lands on it when moving down by the distance provided.
+func surfaceSlope(shape, ground Shape, dy int32) float64 {
+
+	switch g := ground.(type) {
+
+	case *Line:
+		return math.Atan2(math.Abs(float64(g.Y2)-float64(g.Y)), math.Abs(float64(g.X2)-float64(g.X)))
+
+	case *Circle:
+		var bounds Rectangle
+		boundingRectInto(shape, &bounds)
+		bounds.Y += dy
+		// The Shape lands on the Circle at its point closest to the Circle's center, and the surface there is
+		// perpendicular to the line from the center to that point.
+		px, py := closestPoint(&bounds, g.X, g.Y)
+		return math.Atan2(math.Abs(px-float64(g.X)), float64(g.Y)-py)
+
+	}
+
+	return 0
+
+}
@@ -0,0 +1,107 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSnapToGroundWalkingDownhill(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		slope              *Line
+		snap               bool
+		wantAlwaysGrounded bool
+	}{
+		{"gentle slope snapped", NewLine(0, 40, 400, 140, WithTags("ground")), true, true},
+		{"steep slope snapped", NewLine(0, 40, 200, 140, WithTags("ground")), true, true},
+		{"steep slope unsnapped", NewLine(0, 40, 200, 140, WithTags("ground")), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			space.Add(tt.slope)
+			player := NewRectangle(20, 0, 8, 16)
+			space.Add(player)
+			if ok, _ := space.SnapToGround(player, math.Pi/4, 64, "ground"); !ok {
+				t.Fatal("the player didn't start on the ground")
+			}
+
+			grounded := true
+			for frame := 0; frame < 60; frame++ {
+				col := resolveDelta(space, player, 3, 0)
+				player.Move(col.ResolveX, 0)
+				if tt.snap {
+					space.SnapToGround(player, math.Pi/4, 8, "ground")
+				} else {
+					// Without snapping, the player falls a fixed amount each frame, which doesn't keep up.
+					col := resolveDelta(space, player, 0, 1)
+					player.Move(0, col.ResolveY)
+				}
+				if ok, _ := space.IsOnGround(player, math.Pi/4, 1, "ground"); !ok {
+					grounded = false
+				}
+				if player.IsColliding(tt.slope) {
+					t.Fatalf("frame %d: the player sank into the slope", frame)
+				}
+			}
+
+			if grounded != tt.wantAlwaysGrounded {
+				t.Errorf("grounded every frame = %v, want %v", grounded, tt.wantAlwaysGrounded)
+			}
+
+		})
+	}
+
+}
+
+func TestSnapToGroundStopsAtBlockers(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		blocker *Rectangle
+		wantOK  bool
+		wantY   int32
+	}{
+		{"nothing in between", nil, true, 24},
+		{"enemy in between", NewRectangle(0, 20, 8, 2, WithTags("enemy")), false, 4},
+		{"enemy beside", NewRectangle(40, 20, 8, 2, WithTags("enemy")), true, 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			space := NewSpace()
+			space.Add(NewRectangle(0, 40, 64, 8, WithTags("ground")))
+			if tt.blocker != nil {
+				space.Add(tt.blocker)
+			}
+			player := NewRectangle(0, 0, 8, 16)
+			space.Add(player)
+			ok, _ := space.SnapToGround(player, 0, 32, "ground")
+			if ok != tt.wantOK || player.Y != tt.wantY {
+				t.Errorf("SnapToGround() = %v, at Y = %d, want %v, %d", ok, player.Y, tt.wantOK, tt.wantY)
+			}
+		})
+	}
+
+}
+
+func BenchmarkSnapToGround(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 200; i++ {
+		space.Add(NewRectangle(i*16, 100+i%4, 16, 16, WithTags("ground")))
+	}
+	player := NewRectangle(64, 60, 8, 16)
+	space.Add(player)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		player.Y = 60
+		space.SnapToGround(player, math.Pi/4, 32, "ground")
+	}
+
+}
@@ -0,0 +1,336 @@
+package resolv
+
+import "sort"
+
+// Handle is an opaque reference to a Shape added to a Space through Space.AddH(). It allows the Shape to be removed with
+// Space.RemoveH() in constant time, rather than having to search the Space for it like Space.Remove() does. A Handle
+// stops being valid once its Shape is removed from the Space (through any means) or the Space is cleared. The zero
+// Handle is never valid.
+type Handle struct {
+	space *Space
+	slot  int
+	gen   uint32
+}
+
+type handleSlot struct {
+	index int
+	gen   uint32
+	live  bool
+}
+
+// handleTable keeps track of the Handles given out by a Space. slotOf runs parallel to the Space's Shapes, holding the
+// slot (plus one) of each Shape's Handle, or zero for Shapes added without one.
+type handleTable struct {
+	slots  []handleSlot
+	free   []int
+	slotOf []int
+}
+
+func (ht *handleTable) active() bool {
+	return ht.slotOf != nil
+}
+
+func (ht *handleTable) clear() {
+	for i := range ht.slots {
+		if ht.slots[i].live {
+			ht.release(i)
+		}
+	}
+	ht.slotOf = ht.slotOf[:0]
+}
+
+func (ht *handleTable) release(slot int) {
+	ht.slots[slot].live = false
+	ht.slots[slot].gen++
+	ht.free = append(ht.free, slot)
+}
+
+func (ht *handleTable) acquire(index int) int {
+	var slot int
+	if len(ht.free) > 0 {
+		slot = ht.free[len(ht.free)-1]
+		ht.free = ht.free[:len(ht.free)-1]
+	} else {
+		slot = len(ht.slots)
+		ht.slots = append(ht.slots, handleSlot{})
+	}
+	ht.slots[slot].index = index
+	ht.slots[slot].live = true
+	ht.slotOf[index] = slot + 1
+	return slot
+}
+
+// appendShape adds the Shape to the end of the Space (or in order, if the Space is kept sorted), keeping the Space's
+// indexes up to date and calling the OnAdd() functions, and returns the index the Shape was added at. All additions to
+// the Space go through here.
+func (sp *Space) appendShape(shape Shape) int {
+
+	if sp.sortedBy != nil {
+		index := sort.Search(len(sp.shapes), func(i int) bool { return sp.sortedBy(shape, sp.shapes[i]) })
+		if index < len(sp.shapes) {
+			return sp.insertAt(index, shape)
+		}
+	}
+
+	sp.shapes = append(sp.shapes, shape)
+	sp.tags.add(shape, len(sp.shapes)-1)
+	if sp.members != nil {
+		sp.members[shape]++
+	}
+	sp.holdArenaShape(shape)
+	if sp.handles.active() {
+		sp.handles.slotOf = append(sp.handles.slotOf, 0)
+	}
+	sp.added(shape)
+
+	return len(sp.shapes) - 1
+
+}
+
+// insertAt inserts the Shape into the Space at the index provided, moving the Shapes after it up, and returns the index.
+func (sp *Space) insertAt(index int, shape Shape) int {
+
+	sp.shapes = append(sp.shapes, nil)
+	copy(sp.shapes[index+1:], sp.shapes[index:])
+	sp.shapes[index] = shape
+	sp.tags.invalidate()
+	if sp.members != nil {
+		sp.members[shape]++
+	}
+	sp.holdArenaShape(shape)
+
+	if ht := &sp.handles; ht.active() {
+		ht.slotOf = append(ht.slotOf, 0)
+		copy(ht.slotOf[index+1:], ht.slotOf[index:])
+		ht.slotOf[index] = 0
+		for i := index + 1; i < len(ht.slotOf); i++ {
+			if slot := ht.slotOf[i]; slot > 0 {
+				ht.slots[slot-1].index = i
+			}
+		}
+	}
+
+	sp.added(shape)
+
+	return index
+
+}
+
+// permute reorders the Shapes in the Space so that the Shape at order[i] ends up at index i, keeping the Space's
+// indexes up to date.
+func (sp *Space) permute(order []int) {
+
+	shapes := make([]Shape, len(sp.shapes))
+	for i, from := range order {
+		shapes[i] = sp.shapes[from]
+	}
+	sp.shapes = shapes
+	sp.tags.invalidate()
+	sp.sortedX.invalidate()
+	sp.changed()
+
+	if ht := &sp.handles; ht.active() {
+		slotOf := make([]int, len(ht.slotOf))
+		for i, from := range order {
+			slotOf[i] = ht.slotOf[from]
+			if slot := slotOf[i]; slot > 0 {
+				ht.slots[slot-1].index = i
+			}
+		}
+		ht.slotOf = slotOf
+	}
+
+}
+
+// forget removes the Shape from the Space's membership counts, if the Space keeps them, and stops using it as the root.
+// Its name is forgotten by removed(), once the Space no longer contains it.
+func (sp *Space) forget(shape Shape) {
+	if shape == sp.root {
+		sp.root = nil
+	}
+	sp.releaseArenaShape(shape)
+	if sp.members != nil {
+		if sp.members[shape] <= 1 {
+			delete(sp.members, shape)
+		} else {
+			sp.members[shape]--
+		}
+	}
+}
+
+// removeAt removes the Shape at the index provided from the Space, keeping the order of the remaining Shapes and the
+// Space's indexes up to date and calling the OnRemove() functions.
+func (sp *Space) removeAt(index int) {
+
+	shape := sp.shapes[index]
+	sp.forget(shape)
+
+	last := len(sp.shapes) - 1
+	copy(sp.shapes[index:], sp.shapes[index+1:])
+	sp.shapes[last] = nil
+	sp.shapes = sp.shapes[:last]
+	sp.tags.invalidate()
+
+	if ht := &sp.handles; ht.active() {
+		if slot := ht.slotOf[index]; slot > 0 {
+			ht.release(slot - 1)
+		}
+		copy(ht.slotOf[index:], ht.slotOf[index+1:])
+		ht.slotOf = ht.slotOf[:last]
+		for i := index; i < last; i++ {
+			if slot := ht.slotOf[i]; slot > 0 {
+				ht.slots[slot-1].index = i
+			}
+		}
+	}
+
+	sp.removed(shape)
+
+}
+
+// swapRemoveAt removes the Shape at the index provided from the Space in constant time by moving the last Shape in the
+// Space into its place.
+func (sp *Space) swapRemoveAt(index int) {
+
+	shape := sp.shapes[index]
+	sp.forget(shape)
+
+	last := len(sp.shapes) - 1
+	sp.shapes[index] = sp.shapes[last]
+	sp.shapes[last] = nil
+	sp.shapes = sp.shapes[:last]
+	sp.tags.swapRemove(index, last)
+
+	if ht := &sp.handles; ht.active() {
+		if slot := ht.slotOf[index]; slot > 0 {
+			ht.release(slot - 1)
+		}
+		ht.slotOf[index] = ht.slotOf[last]
+		ht.slotOf = ht.slotOf[:last]
+		if index < last {
+			if slot := ht.slotOf[index]; slot > 0 {
+				ht.slots[slot-1].index = index
+			}
+		}
+	}
+
+	sp.removed(shape)
+
+}
+
+// compact removes every Shape for which the function provided returns true from the Space in a single pass, keeping the
+// order of the remaining Shapes and the Space's indexes up to date, and calling the OnRemove() functions once it's done.
+// It returns how many Shapes were removed.
+func (sp *Space) compact(remove func(Shape) bool) int {
+
+	var removed []Shape
+	ht := &sp.handles
+	kept := 0
+
+	for i, shape := range sp.shapes {
+
+		if remove(shape) {
+			sp.forget(shape)
+			if ht.active() && ht.slotOf[i] > 0 {
+				ht.release(ht.slotOf[i] - 1)
+			}
+			removed = append(removed, shape)
+			continue
+		}
+
+		sp.shapes[kept] = shape
+		if ht.active() {
+			ht.slotOf[kept] = ht.slotOf[i]
+			if slot := ht.slotOf[kept]; slot > 0 {
+				ht.slots[slot-1].index = kept
+			}
+		}
+		kept++
+
+	}
+
+	if len(removed) == 0 {
+		return 0
+	}
+
+	for i := kept; i < len(sp.shapes); i++ {
+		sp.shapes[i] = nil
+	}
+	sp.shapes = sp.shapes[:kept]
+	if ht.active() {
+		ht.slotOf = ht.slotOf[:kept]
+	}
+	sp.tags.invalidate()
+
+	for _, shape := range removed {
+		sp.removed(shape)
+	}
+
+	return len(removed)
+
+}
+
+// AddH adds the designated Shape to the Space like Add() does, returning a Handle that can be used to remove it from the
+// Space in constant time with RemoveH(). You cannot add the Space to itself. If the Space is strict and already
+// contains the Shape, it isn't added again, and the zero (invalid) Handle is returned.
+func (sp *Space) AddH(shape Shape) Handle {
+
+	if err := sp.checkAdd([]Shape{shape}); err != nil {
+		return Handle{}
+	}
+
+	ht := &sp.handles
+	if !ht.active() {
+		ht.slotOf = make([]int, len(sp.shapes))
+	}
+
+	// A Space kept sorted inserts the Shape in order, so it isn't necessarily the last one.
+	slot := ht.acquire(sp.appendShape(shape))
+	return Handle{space: sp, slot: slot, gen: ht.slots[slot].gen}
+
+}
+
+// lookup returns the index of the Shape the Handle refers to, and whether the Handle is valid for the Space.
+func (sp *Space) lookup(h Handle) (int, bool) {
+	if h.space != sp || h.slot >= len(sp.handles.slots) {
+		return 0, false
+	}
+	slot := sp.handles.slots[h.slot]
+	if !slot.live || slot.gen != h.gen {
+		return 0, false
+	}
+	return slot.index, true
+}
+
+// GetH returns the Shape the Handle refers to, and true if the Handle is still valid for the Space.
+func (sp *Space) GetH(h Handle) (Shape, bool) {
+	index, ok := sp.lookup(h)
+	if !ok {
+		return nil, false
+	}
+	return sp.shapes[index], true
+}
+
+// RemoveH removes the Shape the Handle refers to from the Space in constant time, returning true if it was removed. To do
+// this, the last Shape in the Space is moved into the removed Shape's place, so unlike Remove(), RemoveH() doesn't keep
+// the order of the Shapes in the Space (unless the Space is kept sorted with KeepSorted(), in which case removal is no
+// longer constant time). Removing a Shape through a Handle that's no longer valid (because the Shape was
+// already removed, or the Handle is from another Space) does nothing and returns false. Like with Remove(), a Shape
+// removed while iterating with ForEach() is removed once it's done, and its Handle stays valid until then.
+func (sp *Space) RemoveH(h Handle) bool {
+	index, ok := sp.lookup(h)
+	if !ok {
+		return false
+	}
+	if sp.iterating > 0 {
+		sp.deferredH = append(sp.deferredH, h)
+		return true
+	}
+	if sp.sortedBy != nil {
+		// Swapping would break the order of a Space kept sorted.
+		sp.removeAt(index)
+	} else {
+		sp.swapRemoveAt(index)
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package resolv
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAddHSorted(t *testing.T) {
+
+	space := NewSpace()
+	space.KeepSorted(ByPosition)
+	a := NewRectangle(10, 0, 4, 4)
+	space.Add(a)
+
+	b := NewRectangle(0, 0, 4, 4)
+	hb := space.AddH(b)
+	c := NewRectangle(20, 0, 4, 4)
+	hc := space.AddH(c)
+
+	if got, ok := space.GetH(hb); !ok || got != b {
+		t.Fatalf("GetH() = %v, %v, want the Shape added through the Handle", got, ok)
+	}
+
+	// Moving b to the end and sorting again must move its Handle along with it.
+	b.SetXY(30, 0)
+	space.Sort(ByPosition)
+	if got, ok := space.GetH(hb); !ok || got != b {
+		t.Fatalf("GetH() after Sort() = %v, %v, want the Shape added through the Handle", got, ok)
+	}
+	if got, ok := space.GetH(hc); !ok || got != c {
+		t.Fatalf("GetH() after Sort() = %v, %v, want the Shape added through the Handle", got, ok)
+	}
+
+	if !space.RemoveH(hb) {
+		t.Fatal("RemoveH() = false, want true")
+	}
+	if space.Contains(b) || !space.Contains(a) || !space.Contains(c) {
+		t.Errorf("RemoveH() removed the wrong Shape: %v left", space.Shapes())
+	}
+	if got, ok := space.GetH(hc); !ok || got != c {
+		t.Errorf("GetH() after RemoveH() = %v, %v, want the other Handle's Shape", got, ok)
+	}
+
+}
+
+func TestRemoveHInForEach(t *testing.T) {
+
+	space := NewSpace()
+	var handles []Handle
+	for i := int32(0); i < 5; i++ {
+		handles = append(handles, space.AddH(NewRectangle(i*8, 0, 4, 4)))
+	}
+	want := space.Shapes()
+
+	var visited []Shape
+	space.ForEach(func(shape Shape) bool {
+		visited = append(visited, shape)
+		if shape == want[1] {
+			space.RemoveH(handles[1])
+		}
+		return true
+	})
+
+	if len(visited) != len(want) {
+		t.Fatalf("ForEach() visited %d Shapes, want %d", len(visited), len(want))
+	}
+	for i := range want {
+		if visited[i] != want[i] {
+			t.Errorf("ForEach() visited %v at %d, want %v", visited[i], i, want[i])
+		}
+	}
+	if space.Length() != 4 || space.Contains(want[1]) {
+		t.Errorf("RemoveH() within ForEach() left %v, want %v removed", space.Shapes(), want[1])
+	}
+	for i, h := range handles {
+		if got, ok := space.GetH(h); (i == 1) == ok || (ok && got != want[i]) {
+			t.Errorf("GetH(handle %d) = %v, %v", i, got, ok)
+		}
+	}
+
+}
+
+func TestRemoveHInForEachDuplicate(t *testing.T) {
+
+	// The same Shape is in the Space twice, with a Handle to each; removing the second through its Handle while iterating
+	// removes that one, not the first.
+	space := NewSpace()
+	shape, other := NewRectangle(0, 0, 4, 4), NewRectangle(8, 0, 4, 4)
+	first := space.AddH(shape)
+	space.AddH(other)
+	second := space.AddH(shape)
+
+	space.ForEach(func(s Shape) bool {
+		if s == other {
+			space.RemoveH(second)
+		}
+		return true
+	})
+
+	if _, ok := space.GetH(second); ok {
+		t.Error("the removed Handle is still valid")
+	}
+	if got, ok := space.GetH(first); !ok || got != shape {
+		t.Errorf("GetH() of the other Handle to the Shape = %v, %v, want it still valid", got, ok)
+	}
+	if space.Length() != 2 || !space.Contains(shape) || !space.Contains(other) {
+		t.Errorf("RemoveH() within ForEach() left %v, want one copy of the Shape removed", space.Shapes())
+	}
+
+}
+
+func TestRemoveHKeepsTagIndex(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(351))
+	tags := []string{"a", "b", "c"}
+
+	space := NewSpace()
+	var handles []Handle
+	for i := 0; i < 200; i++ {
+		var shape Shape
+		if i%10 == 0 {
+			// Some nested Spaces, which aren't indexed.
+			nested := NewSpace()
+			nested.Add(NewRectangle(int32(i), 0, 4, 4, WithTags(tags[rng.Intn(len(tags))])))
+			shape = nested
+		} else {
+			shape = NewRectangle(int32(i), 0, 4, 4, WithTags(tags[rng.Intn(len(tags))], tags[rng.Intn(len(tags))]))
+		}
+		handles = append(handles, space.AddH(shape))
+	}
+
+	for len(handles) > 0 {
+
+		i := rng.Intn(len(handles))
+		space.tags.update(space.shapes)
+		if !space.RemoveH(handles[i]) {
+			t.Fatalf("RemoveH() of a valid Handle returned false")
+		}
+		handles = append(handles[:i], handles[i+1:]...)
+
+		if !space.tags.current() {
+			t.Fatal("RemoveH() threw the tag index away")
+		}
+		for _, tag := range tags {
+			want := space.Filter(func(shape Shape) bool { return shape.HasTags(tag) })
+			got := space.FilterByTags(tag)
+			if got.Length() != want.Length() {
+				t.Fatalf("after RemoveH(), FilterByTags(%q) found %d Shapes, want %d", tag, got.Length(), want.Length())
+			}
+			for j, shape := range want.Shapes() {
+				if got.Get(j) != shape {
+					t.Fatalf("after RemoveH(), FilterByTags(%q) found %v at %d, want %v", tag, got.Get(j), j, shape)
+				}
+			}
+		}
+		if census := space.TagCensus(); census["a"] != space.CountByTags("a") {
+			t.Fatalf("after RemoveH(), TagCensus() = %v, with CountByTags(\"a\") = %d", census, space.CountByTags("a"))
+		}
+
+	}
+
+}
+
+func BenchmarkRemoveHTagged(b *testing.B) {
+
+	space := NewSpace()
+	var handles []Handle
+	for i := 0; i < 5000; i++ {
+		handles = append(handles, space.AddH(NewRectangle(int32(i), 0, 4, 4, WithTags("solid"))))
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		// Removing and re-adding a Shape, then counting, keeps the tag index up to date rather than rebuilding it.
+		index := i % len(handles)
+		shape, _ := space.GetH(handles[index])
+		space.RemoveH(handles[index])
+		handles[index] = space.AddH(shape)
+		space.CountByTags("solid")
+	}
+
+}
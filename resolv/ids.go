@@ -0,0 +1,50 @@
+package resolv
+
+import "sync/atomic"
+
+// lastID is the last ID handed out to a Shape.
+var lastID uint64
+
+// ID returns the ID of the Shape, a number unique to it within the process. Shapes made with the constructors (like
+// NewRectangle()) get their IDs when they're created, in order of creation; any other Shape embedding a BasicShape gets
+// its ID the first time it's asked for. Unlike the Shape's pointer, the ID can be saved and restored (see SetID()), so
+// it can identify the Shape across save files or the network. Copies made with Clone() get new IDs.
+func (b *BasicShape) ID() uint64 {
+	if id := atomic.LoadUint64(&b.id); id != 0 {
+		return id
+	}
+	atomic.CompareAndSwapUint64(&b.id, 0, atomic.AddUint64(&lastID, 1))
+	return atomic.LoadUint64(&b.id)
+}
+
+// SetID sets the ID of the Shape, like when restoring a saved Shape. IDs handed out to new Shapes afterwards are always
+// higher than the ID set, so they don't clash with it; keeping restored IDs unique among each other is up to the caller
+// (decoding the same saved Shapes twice, for example, gives two Shapes with each ID).
+// Setting the ID to 0 gives the Shape a new ID the next time it's asked for.
+func (b *BasicShape) SetID(id uint64) {
+	atomic.StoreUint64(&b.id, id)
+	for {
+		last := atomic.LoadUint64(&lastID)
+		if id <= last || atomic.CompareAndSwapUint64(&lastID, last, id) {
+			return
+		}
+	}
+}
+
+// GetByID returns the Shape in the Space (including the Shapes within nested Spaces) with the ID provided (see
+// BasicShape.ID()), or nil if there's no such Shape.
+func (sp *Space) GetByID(id uint64) Shape {
+	for _, shape := range sp.shapes {
+		switch s := shape.(type) {
+		case *Space:
+			if found := s.GetByID(id); found != nil {
+				return found
+			}
+		case interface{ ID() uint64 }:
+			if s.ID() == id {
+				return shape
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package resolv
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestIDsUniqueAcrossGoroutines(t *testing.T) {
+
+	const goroutines, each = 8, 500
+	ids := make([][]uint64, goroutines)
+	wg := sync.WaitGroup{}
+	for g := range ids {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < each; i++ {
+				// Shapes that don't come from a constructor get their IDs lazily, which has to be just as safe.
+				if i%2 == 0 {
+					ids[g] = append(ids[g], NewRectangle(0, 0, 1, 1).ID())
+				} else {
+					ids[g] = append(ids[g], (&Circle{}).ID())
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	seen := map[uint64]bool{}
+	for _, list := range ids {
+		for i, id := range list {
+			if id == 0 || seen[id] {
+				t.Fatalf("ID %d was handed out twice (or is 0)", id)
+			}
+			if i > 0 && id <= list[i-1] {
+				t.Errorf("IDs went down within a goroutine: %d after %d", id, list[i-1])
+			}
+			seen[id] = true
+		}
+	}
+
+}
+
+func TestIDs(t *testing.T) {
+
+	a, b := NewRectangle(0, 0, 8, 8), NewCircle(0, 0, 4)
+	if a.ID() == b.ID() || a.ID() != a.ID() {
+		t.Fatalf("IDs %d and %d aren't unique and stable", a.ID(), b.ID())
+	}
+
+	// Restoring a high ID makes later Shapes get IDs above it.
+	restored := NewLine(0, 0, 8, 8)
+	restored.SetID(b.ID() + 1000)
+	if next := NewRectangle(0, 0, 1, 1).ID(); next <= restored.ID() {
+		t.Errorf("a Shape created after SetID(%d) got ID %d", restored.ID(), next)
+	}
+
+	inner := NewRectangle(0, 0, 8, 8)
+	nested := NewSpace()
+	nested.Add(inner)
+	space := NewSpace()
+	space.Add(a, nested, restored)
+	for _, shape := range []Shape{a, inner, restored} {
+		id := shape.(interface{ ID() uint64 }).ID()
+		if got := space.GetByID(id); got != shape {
+			t.Errorf("GetByID(%d) = %v, want %v", id, got, shape)
+		}
+	}
+	if got := space.GetByID(b.ID()); got != nil {
+		t.Errorf("GetByID() of a Shape outside the Space = %v, want nil", got)
+	}
+
+	// Clones are new Shapes, so they get new IDs.
+	clone := space.Clone()
+	if clone.GetByID(a.ID()) != nil || clone.GetByID(inner.ID()) != nil {
+		t.Error("Clone() kept the original Shapes' IDs")
+	}
+
+}
+
+func TestIDJSONRoundTrip(t *testing.T) {
+
+	for _, shape := range []Shape{NewRectangle(1, 2, 3, 4), NewCircle(5, 6, 7), NewLine(1, 2, 3, 4)} {
+
+		encoded, err := json.Marshal(recordShape(shape))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs := &recordedShape{}
+		if err := json.Unmarshal(encoded, rs); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := rs.shape()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := shape.(interface{ ID() uint64 }).ID()
+		if got := decoded.(interface{ ID() uint64 }).ID(); got != want {
+			t.Errorf("%v decoded from JSON has ID %d, want %d", shape, got, want)
+		}
+
+	}
+
+}
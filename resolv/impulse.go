@@ -0,0 +1,114 @@
+package resolv
+
+// ImpulseOption is an option for ApplyImpulse().
+type ImpulseOption func(*impulseOptions)
+
+type impulseOptions struct {
+	slide bool
+}
+
+// ImpulseSlide makes ApplyImpulse() slide the Shape along whatever blocks it, keeping the rest of the impulse along the
+// axis that isn't blocked (for a "launch" feel), rather than stopping the Shape dead at the first obstruction (a "shove").
+func ImpulseSlide() ImpulseOption {
+	return func(o *impulseOptions) {
+		o.slide = true
+	}
+}
+
+// ApplyImpulse moves the Shape by the impulse dx, dy (like knockback) in the number of steps provided, resolving each step
+// against the Space, and returns the Collisions with the Shapes that blocked it along the way, in order. By default, the
+// Shape stops at the first Shape in its way; see ImpulseSlide() for sliding along it instead. Each step is resolved against
+// the nearest Shape in the way, so the Shape never passes through a Shape. If the Shape is still colliding with something
+// at the end (like if it started out inside a wall), it's moved out to the nearest free position along an axis, trying
+// back against the impulse first, so it always ends up in a free position.
+// More steps follow the line of the impulse more closely when sliding, at the cost of more collision tests. If steps is
+// less than 1, the impulse is applied in a single step.
+func ApplyImpulse(space *Space, shape Shape, dx, dy int32, steps int, opts ...ImpulseOption) []Collision {
+
+	o := impulseOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if steps < 1 {
+		steps = 1
+	}
+
+	var contacts []Collision
+	blockedX, blockedY := dx == 0, dy == 0
+	n := int64(steps)
+
+	for k := int64(1); k <= n && !(blockedX && blockedY); k++ {
+
+		// The steps are worked out from the whole impulse, so that they add up to it exactly.
+		stepX := stepAlong(dx, k, n) - stepAlong(dx, k-1, n)
+		stepY := stepAlong(dy, k, n) - stepAlong(dy, k-1, n)
+
+		if !o.slide {
+			col := resolveDelta(space, shape, stepX, stepY)
+			shape.Move(col.ResolveX, col.ResolveY)
+			if col.Colliding() {
+				contacts = append(contacts, col)
+				break
+			}
+			continue
+		}
+
+		if !blockedX && stepX != 0 {
+			col := resolveDelta(space, shape, stepX, 0)
+			shape.Move(col.ResolveX, 0)
+			if col.Colliding() {
+				contacts = append(contacts, col)
+				blockedX = true
+			}
+		}
+
+		if !blockedY && stepY != 0 {
+			col := resolveDelta(space, shape, 0, stepY)
+			shape.Move(0, col.ResolveY)
+			if col.Colliding() {
+				contacts = append(contacts, col)
+				blockedY = true
+			}
+		}
+
+	}
+
+	if shape.WouldBeColliding(space, 0, 0) {
+		escape(space, shape, dx, dy)
+	}
+
+	return contacts
+
+}
+
+// escape moves the Shape, which is colliding with something in the Space, out to the nearest position along an axis
+// where it isn't, trying back against the delta provided first. Past the Space's bounding rectangle there's nothing to
+// collide with, so it always finds one.
+func escape(space *Space, shape Shape, dx, dy int32) {
+
+	dirs := [4][2]int32{{-sign32(dx), 0}, {0, -sign32(dy)}, {sign32(dx), 0}, {0, sign32(dy)}}
+	// Directions the delta doesn't give are filled in, so that all four are tried, backing out against the delta first.
+	if dx == 0 {
+		dirs[0], dirs[2] = [2]int32{-1, 0}, [2]int32{1, 0}
+	}
+	if dy == 0 {
+		dirs[1], dirs[3] = [2]int32{0, -1}, [2]int32{0, 1}
+	}
+
+	var bounds, own Rectangle
+	space.GetBoundingRectInto(&bounds)
+	boundingRectInto(shape, &own)
+	limit := int64(bounds.W) + int64(bounds.H) + int64(own.W) + int64(own.H)
+
+	for d := int64(1); d <= limit; d++ {
+		for _, dir := range dirs {
+			ex, ey := int32(int64(dir[0])*d), int32(int64(dir[1])*d)
+			if !shape.WouldBeColliding(space, ex, ey) {
+				shape.Move(ex, ey)
+				return
+			}
+		}
+	}
+
+}
@@ -0,0 +1,75 @@
+package resolv
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	// DefaultLayer is the collision layer Shapes are on unless set otherwise.
+	DefaultLayer uint32 = 1
+	// AllLayers is a mask covering every collision layer, and is the mask Shapes have unless set otherwise.
+	AllLayers uint32 = 0xFFFFFFFF
+)
+
+// layerNames holds the layers registered with RegisterLayer(), by name. It's guarded by layerNamesMutex, as layers can be
+// registered while Shapes are being tested from other goroutines (like through a SyncSpace).
+var (
+	layerNames      = map[string]uint32{}
+	layerNamesMutex sync.RWMutex
+)
+
+// RegisterLayer returns the collision layer bit associated with the name provided, assigning the next free layer bit
+// to it if it hasn't been registered yet. This way, layers and masks can be built from readable names, like:
+//
+//	player.SetMask(RegisterLayer("solid") | RegisterLayer("enemy"))
+//
+// DefaultLayer is never handed out by RegisterLayer, leaving 31 layers that can be registered; registering more than that
+// panics. RegisterLayer is safe to call from multiple goroutines.
+func RegisterLayer(name string) uint32 {
+
+	layerNamesMutex.RLock()
+	layer, exists := layerNames[name]
+	layerNamesMutex.RUnlock()
+	if exists {
+		return layer
+	}
+
+	layerNamesMutex.Lock()
+	defer layerNamesMutex.Unlock()
+
+	if layer, exists := layerNames[name]; exists {
+		return layer
+	}
+
+	if len(layerNames) >= 31 {
+		panic(fmt.Sprintf("ERROR! Can't register layer %s, as all collision layers are in use!", name))
+	}
+
+	layer = DefaultLayer << uint(len(layerNames)+1)
+	layerNames[name] = layer
+	return layer
+
+}
+
+// LayersCollide returns whether the two Shapes provided are allowed to collide according to their collision layers and
+// masks; that is, if each Shape is on a layer that the other Shape's mask includes.
+func LayersCollide(a, b Shape) bool {
+	return shapeLayer(a)&shapeMask(b) != 0 && shapeLayer(b)&shapeMask(a) != 0
+}
+
+// shapeLayer returns the collision layer bits of the Shape, or DefaultLayer if it doesn't have a GetLayer() function.
+func shapeLayer(shape Shape) uint32 {
+	if s, ok := shape.(interface{ GetLayer() uint32 }); ok {
+		return s.GetLayer()
+	}
+	return DefaultLayer
+}
+
+// shapeMask returns the collision mask of the Shape, or AllLayers if it doesn't have a GetMask() function.
+func shapeMask(shape Shape) uint32 {
+	if s, ok := shape.(interface{ GetMask() uint32 }); ok {
+		return s.GetMask()
+	}
+	return AllLayers
+}
@@ -0,0 +1,162 @@
+package resolv
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRegisterLayerConcurrent(t *testing.T) {
+
+	names := make([]string, 8)
+	for i := range names {
+		names[i] = fmt.Sprintf("concurrent-%d", i)
+	}
+
+	layers := make([][]uint32, 4)
+	wg := sync.WaitGroup{}
+	for g := range layers {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for _, name := range names {
+				layers[g] = append(layers[g], RegisterLayer(name))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	seen := map[uint32]bool{}
+	for i, layer := range layers[0] {
+		if seen[layer] || layer == DefaultLayer {
+			t.Errorf("RegisterLayer(%q) = %#x, which is already in use", names[i], layer)
+		}
+		seen[layer] = true
+		for g := range layers[1:] {
+			if layers[g+1][i] != layer {
+				t.Errorf("RegisterLayer(%q) = %#x and %#x from different goroutines", names[i], layer, layers[g+1][i])
+			}
+		}
+	}
+
+}
+
+// minimalShape is a custom Shape with only the functions the Shape interface requires.
+type minimalShape struct {
+	x, y int32
+	tags []string
+}
+
+func (m *minimalShape) IsColliding(other Shape) bool                    { return false }
+func (m *minimalShape) WouldBeColliding(other Shape, dx, dy int32) bool { return false }
+func (m *minimalShape) GetTags() []string                               { return m.tags }
+func (m *minimalShape) ClearTags()                                      { m.tags = nil }
+func (m *minimalShape) AddTags(tags ...string)                          { m.tags = append(m.tags, tags...) }
+func (m *minimalShape) RemoveTags(tags ...string)                       {}
+func (m *minimalShape) HasTags(tags ...string) bool                     { return NewTags(m.tags...).HasAll(tags...) }
+func (m *minimalShape) GetData() interface{}                            { return nil }
+func (m *minimalShape) SetData(interface{})                             {}
+func (m *minimalShape) GetXY() (int32, int32)                           { return m.x, m.y }
+func (m *minimalShape) SetXY(x, y int32)                                { m.x, m.y = x, y }
+func (m *minimalShape) Move(dx, dy int32)                               { m.x += dx; m.y += dy }
+
+func boundingRectOf(shape Shape) [4]int32 {
+	r := shapeBoundingRect(shape)
+	return [4]int32{r.X, r.Y, r.W, r.H}
+}
+
+func TestMinimalShapeDefaults(t *testing.T) {
+
+	m := &minimalShape{x: 4, y: 8, tags: []string{"enemy/bat"}}
+	rect := NewRectangle(0, 0, 16, 16)
+	space := NewSpace()
+	space.Add(m)
+	space.SetLayer(RegisterLayer("minimal"))
+	space.SetElevation(2)
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"layers collide", LayersCollide(m, rect), true},
+		{"masked out", LayersCollide(m, NewRectangle(0, 0, 16, 16, WithLayer(RegisterLayer("other")), WithMask(0))), false},
+		{"elevations collide", ElevationsCollide(m, rect), true},
+		{"layer", space.GetLayer(), DefaultLayer},
+		{"mask", space.GetMask(), AllLayers},
+		{"elevation", space.GetElevation(), int32(0)},
+		{"any tags", space.HasAnyTags("player", "enemy/bat"), true},
+		{"tag pattern", space.FilterByTagPattern("enemy/*").Length(), 1},
+		{"bounding rect", boundingRectOf(space), [4]int32{4, 8, 0, 0}},
+		{"read-only layer", AsReadOnly(m).GetLayer(), DefaultLayer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+}
+
+func TestLayersAsymmetricMasks(t *testing.T) {
+
+	playerLayer, ghostLayer := RegisterLayer("test-player"), RegisterLayer("test-ghost")
+
+	tests := []struct {
+		name          string
+		layerA, maskA uint32
+		layerB, maskB uint32
+		want          bool
+	}{
+		{"defaults", DefaultLayer, AllLayers, DefaultLayer, AllLayers, true},
+		{"both see each other", playerLayer, ghostLayer, ghostLayer, playerLayer, true},
+		{"only A sees B", playerLayer, ghostLayer, ghostLayer, 0, false},
+		{"only B sees A", playerLayer, 0, ghostLayer, playerLayer, false},
+		{"neither sees the other", playerLayer, playerLayer, ghostLayer, ghostLayer, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			a, b := NewRectangle(0, 0, 10, 10), NewRectangle(5, 0, 10, 10)
+			a.SetLayer(tt.layerA)
+			a.SetMask(tt.maskA)
+			b.SetLayer(tt.layerB)
+			b.SetMask(tt.maskB)
+
+			if got := LayersCollide(a, b); got != tt.want {
+				t.Errorf("LayersCollide(a, b) = %v, want %v", got, tt.want)
+			}
+			if got := LayersCollide(b, a); got != tt.want {
+				t.Errorf("LayersCollide(b, a) = %v, want %v", got, tt.want)
+			}
+
+			space := NewSpace()
+			space.Add(a, b)
+			for _, shape := range []Shape{a, b} {
+				if got := space.IsColliding(shape); got != tt.want {
+					t.Errorf("IsColliding() = %v, want %v", got, tt.want)
+				}
+				if got := space.GetCollidingShapes(shape).Length() > 0; got != tt.want {
+					t.Errorf("GetCollidingShapes() found Shapes = %v, want %v", got, tt.want)
+				}
+			}
+			if got := space.WouldBeColliding(a, 1, 0); got != tt.want {
+				t.Errorf("WouldBeColliding() = %v, want %v", got, tt.want)
+			}
+
+			// a moves into b from out of the way.
+			a.SetXY(-20, 0)
+			alone := NewSpace()
+			alone.Add(b)
+			if col := alone.Resolve(a, 30, 0); col.Colliding() != tt.want {
+				t.Errorf("Resolve().Colliding() = %v, want %v", col.Colliding(), tt.want)
+			}
+
+		})
+	}
+
+}
@@ -0,0 +1,189 @@
+package resolv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadLevel(t *testing.T) {
+
+	text := `# A level, with comments.
+rect 0 224 320 16 solid ground   # The floor.
+
+circle 160 100 12 hazard "spiky ball"
+space
+    # A stack of crates.
+    rect 32 160 16 16 solid crate
+    space
+        line 32 144 48 144 "top#edge"
+    end
+end
+`
+
+	space, err := LoadLevel(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if space.Length() != 3 {
+		t.Fatalf("LoadLevel() gave %d Shapes, want 3", space.Length())
+	}
+	floor, ok := space.Get(0).(*Rectangle)
+	if !ok || floor.X != 0 || floor.Y != 224 || floor.W != 320 || floor.H != 16 || !floor.HasTags("solid", "ground") ||
+		len(floor.GetTags()) != 2 {
+		t.Errorf("the floor is %v, with tags %v", space.Get(0), space.Get(0).GetTags())
+	}
+	ball, ok := space.Get(1).(*Circle)
+	if !ok || ball.X != 160 || ball.Y != 100 || ball.Radius != 12 || !ball.HasTags("hazard", "spiky ball") {
+		t.Errorf("the ball is %v, with tags %v", space.Get(1), space.Get(1).GetTags())
+	}
+	crates, ok := space.Get(2).(*Space)
+	if !ok || crates.Length() != 2 {
+		t.Fatalf("the nested Space is %v", space.Get(2))
+	}
+	if inner, ok := crates.Get(1).(*Space); !ok || inner.Length() != 1 || !inner.Get(0).HasTags("top#edge") {
+		t.Errorf("the Space nested in the nested Space is %v", crates.Get(1))
+	}
+
+}
+
+func TestLoadLevelInclude(t *testing.T) {
+
+	file, err := os.Open("testdata/level_main.lvl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	space, err := LoadLevel(file, LevelIncludeDir("testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The included Shapes are added where the include directive is.
+	want := []string{"ground", "enemy", "boss room", "ceiling"}
+	if space.Length() != 4 {
+		t.Fatalf("LoadLevel() gave %d Shapes, want 4", space.Length())
+	}
+	for i, shape := range space.Shapes() {
+		if nested, ok := shape.(*Space); ok {
+			shape = nested.Get(0)
+		}
+		if !shape.HasTags(want[i]) {
+			t.Errorf("Shape %d has tags %v, want %q", i, shape.GetTags(), want[i])
+		}
+	}
+
+}
+
+func TestLoadLevelErrors(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		text     string
+		wantName string
+		wantLine int
+		contains string
+	}{
+		{"unknown shape", "rect 0 0 8 8\ntriangle 0 0 8", "", 2, "unknown shape type"},
+		{"too few numbers", "# Comment.\n\ncircle 0 0", "", 3, "takes 3 numbers"},
+		{"malformed number", "rect 0 0 eight 8", "", 1, "malformed number"},
+		{"number out of range", "rect 0 0 8 4294967296", "", 1, "malformed number"},
+		{"unclosed quote", "rect 0 0 8 8\nrect 0 0 8 8 \"solid", "", 2, "malformed quoted field"},
+		{"end without space", "rect 0 0 8 8\nend", "", 2, "end without space"},
+		{"space without end", "space\nrect 0 0 8 8", "", 2, "space without end"},
+		{"space with arguments", "space 1", "", 1, "space takes no arguments"},
+		{"invalid shape", "\nrect 0 0 -8 8", "", 2, ""},
+		{"missing include", "rect 0 0 8 8\ninclude missing.lvl", "", 2, ""},
+		{"include without a path", "include", "", 1, "include takes one path"},
+		{"error in an include", "rect 0 0 8 8\ninclude level_bad.lvl", "testdata/level_bad.lvl", 3, "takes 3 numbers"},
+		{"recursive include", "include level_loop.lvl", "testdata/level_loop.lvl", 2, "includes itself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space, err := LoadLevel(strings.NewReader(tt.text), LevelIncludeDir("testdata"))
+			var levelErr *LevelError
+			if !errors.As(err, &levelErr) {
+				t.Fatalf("LoadLevel() = %v, %v, want a *LevelError", space, err)
+			}
+			if levelErr.Name != tt.wantName || levelErr.Line != tt.wantLine || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("LoadLevel() error = %q in %q on line %d, want %q in %q on line %d", err, levelErr.Name,
+					levelErr.Line, tt.contains, tt.wantName, tt.wantLine)
+			}
+
+		})
+	}
+
+	var invalid *InvalidShapeError
+	if _, err := LoadLevel(strings.NewReader("rect 0 0 -8 8")); !errors.As(err, &invalid) {
+		t.Errorf("LoadLevel() of an invalid Shape = %v, want it to wrap an *InvalidShapeError", err)
+	}
+
+}
+
+func TestSaveLevelRoundTrip(t *testing.T) {
+
+	inner := NewSpace()
+	inner.Add(NewLine(32, 144, 48, 144, WithTags("top edge")), NewCircle(-40, -8, 3))
+	crates := NewSpace()
+	crates.Add(NewRectangle(32, 160, 16, 16, WithTags("solid", "crate")), inner)
+	space := NewSpace()
+	space.Add(
+		NewRectangle(0, 224, 320, 16, WithTags("solid", "ground")),
+		NewCircle(160, 100, 12, WithTags("hazard", "#1", `"quoted"`, "")),
+		crates,
+		NewRectangle(-16, -16, 0, 0),
+	)
+
+	var saved bytes.Buffer
+	if err := SaveLevel(&saved, space); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLevel(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Fatalf("LoadLevel() of the saved level failed: %v\n%s", err, saved.String())
+	}
+
+	// Saving the loaded level again gives the same text, and the loaded level has the same Shapes, tags, and nesting.
+	var again bytes.Buffer
+	if err := SaveLevel(&again, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != saved.String() {
+		t.Errorf("saving the loaded level gave:\n%s\nwant:\n%s", again.String(), saved.String())
+	}
+
+	var compare func(got, want *Space)
+	compare = func(got, want *Space) {
+		if got.Length() != want.Length() {
+			t.Fatalf("the loaded Space has %d Shapes, want %d", got.Length(), want.Length())
+		}
+		for i, shape := range want.Shapes() {
+			if nested, ok := shape.(*Space); ok {
+				loadedNested, ok := got.Get(i).(*Space)
+				if !ok {
+					t.Fatalf("Shape %d was loaded as %v, want a Space", i, got.Get(i))
+				}
+				compare(loadedNested, nested)
+				continue
+			}
+			if fmt.Sprint(got.Get(i)) != fmt.Sprint(shape) || strings.Join(got.Get(i).GetTags(), ",") !=
+				strings.Join(shape.GetTags(), ",") {
+				t.Errorf("Shape %d was loaded as %v with tags %q, want %v with tags %q", i, got.Get(i),
+					got.Get(i).GetTags(), shape, shape.GetTags())
+			}
+		}
+	}
+	compare(loaded, space)
+
+	if err := SaveLevel(&saved, NewSpace()); err != nil {
+		t.Errorf("SaveLevel() of an empty Space = %v", err)
+	}
+
+}
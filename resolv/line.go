@@ -1,42 +1,94 @@
 package resolv
 
-import (
-	"math"
-	"sort"
-)
+import "sort"
 
-// Line represents a line, from one point to another.
+// Line represents a line, from one point to another. Its ends can be set directly through X, Y, X2, and Y2, but
+// SetEndpoints() (or Move() and SetXY()) should be preferred, so that the Line's version (see BasicShape.Version()) and
+// the Spaces watching it are kept up to date.
 type Line struct {
 	BasicShape
 	X2, Y2 int32
+
+	// The length of the Line is cached along with the delta it was worked out for whenever the ends are set through the
+	// Line's functions (moving the Line doesn't change it); see cacheLength().
+	lengthDX, lengthDY int64
+	length             int32
+	lengthCached       bool
 }
 
-// NewLine returns a new Line instance.
-func NewLine(x, y, x2, y2 int32) *Line {
+// NewLine returns a new Line instance, configured with the options provided (like WithTags()).
+func NewLine(x, y, x2, y2 int32, opts ...ShapeOption) *Line {
 	l := &Line{}
+	l.init(x, y, x2, y2, opts)
+	return l
+}
+
+// init sets up the zero Line provided like NewLine() does.
+func (l *Line) init(x, y, x2, y2 int32, opts []ShapeOption) {
 	l.X = x
 	l.Y = y
 	l.X2 = x2
 	l.Y2 = y2
-	return l
+	l.cacheLength()
+	applyShapeOptions(l, opts)
 }
 
-// BUG(SolarLune): Line.IsColliding() and Line.GetIntersectionPoints() doesn't work with Circles.
-// BUG(SolarLune): Line.IsColliding() and Line.GetIntersectionPoints() fail if testing two lines that intersect along the exact same slope.
+// BUG(SolarLune): Line.GetIntersectionPoints() doesn't work with Circles.
+// BUG(SolarLune): Line.GetIntersectionPoints() fails if testing two lines that intersect along the exact same slope.
 
-// IsColliding returns if the Line is colliding with the other Shape. Currently, Circle-Line collision is missing.
+// IsColliding returns if the Line is colliding with the other Shape. Every pair of Shapes is tested the same way whichever
+// Shape is tested against the other, so the result is always the same as other.IsColliding(l).
 func (l *Line) IsColliding(other Shape) bool {
+	return ShapesColliding(l, other)
+}
+
+// lineRectangleColliding returns whether the Line crosses or is within the Rectangle.
+func lineRectangleColliding(l *Line, b *Rectangle) bool {
+	// The exact test is expensive, so Rectangles that aren't anywhere near the Line are ruled out first.
+	if !lineBounds(l).overlaps(rectangleBounds(b)) {
+		return false
+	}
+	if len(l.GetIntersectionPoints(b)) > 0 {
+		return true
+	}
+	// The Line could also be wholly within the Rectangle.
+	return (l.X >= b.X && l.Y >= b.Y && l.X < b.X+b.W && l.Y < b.Y+b.H) || (l.X2 >= b.X && l.Y2 >= b.Y && l.X2 < b.X+b.W && l.Y2 < b.Y+b.H)
+}
+
+// orientation returns whether the point (x, y) is to one side of the line through a and b (1), the other side (-1), or on
+// it (0).
+func orientation(ax, ay, bx, by, x, y int32) int {
+	cross := (int64(bx)-int64(ax))*(int64(y)-int64(ay)) - (int64(by)-int64(ay))*(int64(x)-int64(ax))
+	if cross > 0 {
+		return 1
+	} else if cross < 0 {
+		return -1
+	}
+	return 0
+}
+
+// onSegment returns whether the point (x, y), which is known to be on the line through a and b, is between them.
+func onSegment(ax, ay, bx, by, x, y int32) bool {
+	return ((x >= ax && x <= bx) || (x >= bx && x <= ax)) && ((y >= ay && y <= by) || (y >= by && y <= ay))
+}
 
-	intersectionPoints := l.GetIntersectionPoints(other)
+// segmentsIntersect returns whether the two Lines cross or touch, including Lines overlapping along the same slope. It's
+// exact, and gives the same answer whichever order the Lines are passed in.
+func segmentsIntersect(a, b *Line) bool {
 
-	colliding := len(intersectionPoints) > 0
+	o1 := orientation(a.X, a.Y, a.X2, a.Y2, b.X, b.Y)
+	o2 := orientation(a.X, a.Y, a.X2, a.Y2, b.X2, b.Y2)
+	o3 := orientation(b.X, b.Y, b.X2, b.Y2, a.X, a.Y)
+	o4 := orientation(b.X, b.Y, b.X2, b.Y2, a.X2, a.Y2)
 
-	r, ok := other.(*Rectangle)
-	if ok && !colliding {
-		return (l.X >= r.X && l.Y >= r.Y && l.X < r.X+r.W && l.Y < r.Y+r.H) || (l.X2 >= r.X && l.Y2 >= r.Y && l.X2 < r.X+r.W && l.Y2 < r.Y+r.H)
+	if o1 != o2 && o3 != o4 {
+		return true
 	}
 
-	return colliding
+	return (o1 == 0 && onSegment(a.X, a.Y, a.X2, a.Y2, b.X, b.Y)) ||
+		(o2 == 0 && onSegment(a.X, a.Y, a.X2, a.Y2, b.X2, b.Y2)) ||
+		(o3 == 0 && onSegment(b.X, b.Y, b.X2, b.Y2, a.X, a.Y)) ||
+		(o4 == 0 && onSegment(b.X, b.Y, b.X2, b.Y2, a.X2, a.Y2))
 
 }
 
@@ -57,20 +109,24 @@ func (l *Line) GetIntersectionPoints(other Shape) []IntersectionPoint {
 
 	case *Line:
 
-		det := (l.X2-l.X)*(b.Y2-b.Y) - (b.X2-b.X)*(l.Y2-l.Y)
+		// The products are worked out in int64 so that they don't overflow, and converted to float64 once for the division.
+		ldx, ldy := int64(l.X2)-int64(l.X), int64(l.Y2)-int64(l.Y)
+		bdx, bdy := int64(b.X2)-int64(b.X), int64(b.Y2)-int64(b.Y)
+		ox, oy := int64(l.X)-int64(b.X), int64(l.Y)-int64(b.Y)
+
+		det := ldx*bdy - bdx*ldy
 
 		if det != 0 {
 
 			// MAGIC MATH; the extra + 1 here makes it so that corner cases (literally aiming the line through the corners of the
 			// hollow square in world5) works!
 
-			lambda := (float32((l.Y-b.Y)*(b.X2-b.X)-(l.X-b.X)*(b.Y2-b.Y)) + 1) / float32(det)
+			lambda := float64(oy*bdx-ox*bdy+1) / float64(det)
 
-			gamma := (float32((l.Y-b.Y)*(l.X2-l.X)-(l.X-b.X)*(l.Y2-l.Y)) + 1) / float32(det)
+			gamma := float64(oy*ldx-ox*ldy+1) / float64(det)
 
 			if (0 < lambda && lambda < 1) && (0 < gamma && gamma < 1) {
-				dx, dy := l.GetDelta()
-				intersections = append(intersections, IntersectionPoint{l.X + int32(lambda*float32(dx)), l.Y + int32(lambda*float32(dy)), other})
+				intersections = append(intersections, IntersectionPoint{l.X + int32(lambda*float64(ldx)), l.Y + int32(lambda*float64(ldy)), other})
 			}
 
 		}
@@ -92,7 +148,7 @@ func (l *Line) GetIntersectionPoints(other Shape) []IntersectionPoint {
 		side.Y2 = b.Y
 		intersections = append(intersections, l.GetIntersectionPoints(side)...)
 	case *Space:
-		for _, shape := range *b {
+		for _, shape := range b.shapes {
 			intersections = append(intersections, l.GetIntersectionPoints(shape)...)
 		}
 	case *Circle:
@@ -101,28 +157,23 @@ func (l *Line) GetIntersectionPoints(other Shape) []IntersectionPoint {
 		break
 	}
 
-	// fmt.Println("WARNING! Object ", other, " isn't a valid shape for collision testing against Line ", l, "!")
-
 	sort.Slice(intersections, func(i, j int) bool {
-		return Distance(l.X, l.Y, intersections[i].X, intersections[i].Y) < Distance(l.X, l.Y, intersections[j].X, intersections[j].Y)
+		return Distance64(l.X, l.Y, intersections[i].X, intersections[i].Y) < Distance64(l.X, l.Y, intersections[j].X, intersections[j].Y)
 	})
 
 	return intersections
 
 }
 
-// WouldBeColliding returns if the Line would be colliding if it were moved by the designated delta X and Y values.
+// WouldBeColliding returns if the Line would be colliding if it were moved by the designated delta X and Y values. The
+// Line itself isn't changed in the process.
 func (l *Line) WouldBeColliding(other Shape, dx, dy int32) bool {
-	l.X += dx
-	l.Y += dy
-	l.X2 += dx
-	l.Y2 += dy
-	isColliding := l.IsColliding(other)
-	l.X -= dx
-	l.Y -= dy
-	l.X2 -= dx
-	l.Y2 -= dy
-	return isColliding
+	moved := *l
+	moved.X += dx
+	moved.Y += dy
+	moved.X2 += dx
+	moved.Y2 += dy
+	return collidingAs(l, &moved, other)
 }
 
 // SetXY sets the position of the Line, also moving the end point of the line (so it wholly moves the line to the
@@ -134,6 +185,7 @@ func (l *Line) SetXY(x, y int32) {
 	l.Y = y
 	l.X2 += dx
 	l.Y2 += dy
+	l.NotifyMove(l, dx, dy)
 }
 
 // Move moves the Line by the values specified.
@@ -142,6 +194,7 @@ func (l *Line) Move(x, y int32) {
 	l.Y += y
 	l.X2 += x
 	l.Y2 += y
+	l.NotifyMove(l, x, y)
 }
 
 // Center returns the center X and Y values of the Line.
@@ -153,42 +206,85 @@ func (l *Line) Center() (int32, int32) {
 
 }
 
-// GetLength returns the length of the Line.
+// GetLength returns the length of the Line. The length is cached whenever the ends of the Line are set through its
+// functions, so that static Lines don't have to work out a square root each time; if the ends were set directly, it's
+// worked out each time until they're next set through the Line's functions. GetLength() only reads the cache, so it can
+// be called on the same Line from multiple goroutines at once (like through a SyncSpace).
 func (l *Line) GetLength() int32 {
+	dx, dy := int64(l.X2)-int64(l.X), int64(l.Y2)-int64(l.Y)
+	if l.lengthCached && dx == l.lengthDX && dy == l.lengthDY {
+		return l.length
+	}
 	return Distance(l.X, l.Y, l.X2, l.Y2)
 }
 
+// cacheLength works out the length of the Line and caches it for GetLength(), once the ends of the Line have been set.
+func (l *Line) cacheLength() {
+	l.lengthDX, l.lengthDY = int64(l.X2)-int64(l.X), int64(l.Y2)-int64(l.Y)
+	l.length = Distance(l.X, l.Y, l.X2, l.Y2)
+	l.lengthCached = true
+}
+
+// SetEndpoints sets both ends of the Line at once. If the start of the Line moves, the functions registered with OnMove()
+// are called for it; either way, the Line's version goes up if it changed.
+func (l *Line) SetEndpoints(x, y, x2, y2 int32) {
+	dx, dy := x-l.X, y-l.Y
+	reshaped := x2-x != l.X2-l.X || y2-y != l.Y2-l.Y
+	l.X, l.Y, l.X2, l.Y2 = x, y, x2, y2
+	if reshaped {
+		l.cacheLength()
+	}
+	l.NotifyMove(l, dx, dy)
+	if reshaped {
+		l.changed()
+	}
+}
+
 // SetLength sets the length of the Line to the value provided.
 func (l *Line) SetLength(length int32) {
 
-	ln := l.GetLength()
-	xd := int32(float32(l.X2-l.X) / float32(ln) * float32(length))
-	yd := int32(float32(l.Y2-l.Y) / float32(ln) * float32(length))
+	ln := float64(l.GetLength())
+	xd := int32(float64(l.X2-l.X) * float64(length) / ln)
+	yd := int32(float64(l.Y2-l.Y) * float64(length) / ln)
 
 	l.X2 = l.X + xd
 	l.Y2 = l.Y + yd
+	l.cacheLength()
+	l.changed()
 }
 
 // GetBoundingRectangle returns a rectangle centered on the center point of the Line that would fully contain the Line.
 func (l *Line) GetBoundingRectangle() *Rectangle {
+	r := &Rectangle{}
+	l.GetBoundingRectInto(r)
+	return r
+}
+
+// GetBoundingRectInto sets the position and size of the Rectangle provided to the Line's bounding rectangle, without
+// allocating a new one. The rest of dst (its tags, Data, etc.) is left as it is.
+func (l *Line) GetBoundingRectInto(dst *Rectangle) {
 
-	w := int32(math.Abs(float64(l.X2 - l.X)))
-	h := int32(math.Abs(float64(l.Y2 - l.Y)))
+	dst.W = abs32(l.X2 - l.X)
+	dst.H = abs32(l.Y2 - l.Y)
 
-	x := l.X
+	dst.X = l.X
 
 	if l.X2 < l.X {
-		x = l.X2
+		dst.X = l.X2
 	}
 
-	y := l.Y
+	dst.Y = l.Y
 
 	if l.Y2 < l.Y {
-		y = l.Y2
+		dst.Y = l.Y2
 	}
 
-	return NewRectangle(x, y, w, h)
+}
 
+// GetBoundingRect returns a rectangle that would fully contain the Line. It's the same as GetBoundingRectangle(), and
+// allows the Line to fulfill the Shape interface.
+func (l *Line) GetBoundingRect() *Rectangle {
+	return l.GetBoundingRectangle()
 }
 
 // GetBoundingCircle returns a circle centered on the Line's central point that would fully contain the Line.
@@ -196,8 +292,8 @@ func (l *Line) GetBoundingCircle() *Circle {
 
 	x, y := l.Center()
 
-	diameter := int32(math.Abs(float64(l.X2 - l.X)))
-	d2 := int32(math.Abs(float64(l.Y2 - l.Y)))
+	diameter := abs32(l.X2 - l.X)
+	d2 := abs32(l.Y2 - l.Y)
 
 	if d2 > diameter {
 		diameter = d2
@@ -213,3 +309,11 @@ func (l *Line) GetDelta() (int32, int32) {
 	dy := l.Y2 - l.Y
 	return dx, dy
 }
+
+// Clone returns a copy of the Line. The tags of the copy are separate from the original's, while Data is shared between
+// them.
+func (l *Line) Clone() *Line {
+	clone := *l
+	clone.BasicShape = l.BasicShape.clone()
+	return &clone
+}
@@ -0,0 +1,84 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLineLengthCache(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		change func(l *Line)
+		want   int32
+		cached bool
+	}{
+		{"new", func(l *Line) {}, 5, true},
+		{"Move", func(l *Line) { l.Move(100, -20) }, 5, true},
+		{"SetXY", func(l *Line) { l.SetXY(-40, 7) }, 5, true},
+		{"SetEndpoints", func(l *Line) { l.SetEndpoints(0, 0, 6, 8) }, 10, true},
+		{"SetEndpoints, moved", func(l *Line) { l.SetEndpoints(10, 10, 15, 22) }, 13, true},
+		{"SetLength", func(l *Line) { l.SetLength(15) }, 15, true},
+		{"end set directly", func(l *Line) { l.X2, l.Y2 = 8, 15 }, 17, false},
+		{"end set directly, then moved", func(l *Line) {
+			l.X2 = 12
+			l.Move(1, 1)
+		}, 12, false},
+		{"end set directly, then SetEndpoints", func(l *Line) {
+			l.X2 = 12
+			l.SetEndpoints(l.X, l.Y, 20, 21)
+		}, 29, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			l := NewLine(0, 0, 3, 4)
+			l.GetLength()
+			tt.change(l)
+
+			if got := l.GetLength(); got != tt.want {
+				t.Errorf("GetLength() = %d, want %d", got, tt.want)
+			}
+			if want := int32(math.Hypot(float64(l.X2-l.X), float64(l.Y2-l.Y))); l.GetLength() != want {
+				t.Errorf("GetLength() = %d, but the Line is %d long", l.GetLength(), want)
+			}
+
+			// Only the functions that set the Line's ends fill the cache; GetLength() never does.
+			cached := l.lengthCached && l.lengthDX == int64(l.X2-l.X) && l.lengthDY == int64(l.Y2-l.Y)
+			if cached != tt.cached {
+				t.Errorf("the length is cached = %v, want %v", cached, tt.cached)
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkLineLength(b *testing.B) {
+
+	var lines []*Line
+	for i := int32(0); i < 2000; i++ {
+		lines = append(lines, NewLine(i, i*2, i*3+7, -i))
+	}
+
+	// Working the length out each time, as GetLength() did before the length was cached.
+	b.Run("Distance", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, l := range lines {
+				Distance(l.X, l.Y, l.X2, l.Y2)
+			}
+		}
+	})
+
+	b.Run("GetLength", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, l := range lines {
+				l.GetLength()
+			}
+		}
+	})
+
+}
@@ -0,0 +1,120 @@
+package resolv
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpaceMetrics is a summary of the collision work done by a Space, for monitoring it (see PublishExpvar() and
+// ReportMetrics()). The rates are measured over the time since the previous summary, and Stats holds the totals collected
+// since stats were enabled for the Space or last reset.
+type SpaceMetrics struct {
+	Shapes                     int
+	BroadPhaseCandidatesPerSec float64
+	NarrowPhaseTestsPerSec     float64
+	ResolvesPerSec             float64
+	AverageResolveIterations   float64
+	Stats                      SpaceStats
+}
+
+// MetricsSink is anything that can record the SpaceMetrics of named Spaces, like an adapter setting Prometheus gauges.
+// See Space.ReportMetrics().
+type MetricsSink interface {
+	RecordSpaceMetrics(name string, metrics SpaceMetrics)
+}
+
+// metricsRate turns the counters of a Space's stats into rates, by comparing them with the counters from the last time
+// it was asked for SpaceMetrics. Each consumer of SpaceMetrics has its own, so that they don't interfere with each
+// other's rates.
+type metricsRate struct {
+	stats *spaceStats
+
+	mutex                       sync.Mutex
+	at                          time.Time
+	candidates, tests, resolves int64
+}
+
+func (r *metricsRate) metrics() SpaceMetrics {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stats := r.stats.snapshot()
+	metrics := SpaceMetrics{
+		Shapes:                   int(atomic.LoadInt64(&r.stats.shapes)),
+		AverageResolveIterations: stats.AverageResolveIterations(),
+		Stats:                    stats,
+	}
+
+	now := time.Now()
+	if elapsed := now.Sub(r.at).Seconds(); !r.at.IsZero() && elapsed > 0 {
+		// If the stats were reset since last time, the counters are measured from zero instead.
+		if stats.BroadPhaseCandidates < r.candidates || stats.NarrowPhaseTests < r.tests || stats.Resolves < r.resolves {
+			r.candidates, r.tests, r.resolves = 0, 0, 0
+		}
+		metrics.BroadPhaseCandidatesPerSec = float64(stats.BroadPhaseCandidates-r.candidates) / elapsed
+		metrics.NarrowPhaseTestsPerSec = float64(stats.NarrowPhaseTests-r.tests) / elapsed
+		metrics.ResolvesPerSec = float64(stats.Resolves-r.resolves) / elapsed
+	}
+
+	r.at, r.candidates, r.tests, r.resolves = now, stats.BroadPhaseCandidates, stats.NarrowPhaseTests, stats.Resolves
+	return metrics
+
+}
+
+// metricsRate returns the Space's metricsRate used by ReportMetrics(), enabling stats for the Space if needed.
+func (sp *Space) metricsRate() *metricsRate {
+	sp.EnableStats()
+	if sp.reportRate == nil || sp.reportRate.stats != sp.stats {
+		sp.reportRate = &metricsRate{stats: sp.stats}
+	}
+	return sp.reportRate
+}
+
+// ReportMetrics records the Space's SpaceMetrics to the MetricsSink provided under the name given, enabling stats for
+// the Space if they aren't already (see EnableStats()). It's meant to be called periodically (like once a second) from
+// the goroutine that uses the Space; the rates are measured over the time since it was last called, so the first call
+// reports rates of zero.
+func (sp *Space) ReportMetrics(name string, sink MetricsSink) {
+	sink.RecordSpaceMetrics(name, sp.metricsRate().metrics())
+}
+
+// expvarMutex guards publishing names to expvar, so that checking whether a name is taken and taking it happen together.
+var expvarMutex sync.Mutex
+
+// PublishExpvar publishes the Space's SpaceMetrics through the expvar package under the name provided, enabling stats
+// for the Space if they aren't already (see EnableStats()), and returns the name used. As expvar names can only be
+// published once, if the name is already taken (like by another Space), a suffix is added to make it unique ("room",
+// then "room#2", "room#3", and so on). The metrics are read whenever expvar is (like when /debug/vars is requested),
+// with the rates measured over the time since they were last read; this is safe to do while the Space is in use.
+// Publishing can't be undone, and disabling stats for the Space afterwards freezes the published metrics.
+func (sp *Space) PublishExpvar(name string) string {
+
+	sp.EnableStats()
+	rate := &metricsRate{stats: sp.stats}
+
+	expvarMutex.Lock()
+	defer expvarMutex.Unlock()
+
+	published := name
+	for i := 2; expvar.Get(published) != nil; i++ {
+		published = name + "#" + strconv.Itoa(i)
+	}
+
+	expvar.Publish(published, expvar.Func(func() interface{} {
+		m := rate.metrics()
+		return map[string]interface{}{
+			"shapes":                     m.Shapes,
+			"broadPhaseCandidatesPerSec": m.BroadPhaseCandidatesPerSec,
+			"narrowPhaseTestsPerSec":     m.NarrowPhaseTestsPerSec,
+			"resolvesPerSec":             m.ResolvesPerSec,
+			"averageResolveIterations":   m.AverageResolveIterations,
+		}
+	}))
+
+	return published
+
+}
@@ -0,0 +1,170 @@
+package resolv
+
+import "sort"
+
+// MovingPlatform is a solid Shape that moves around a Space (like a lift), carrying the Shapes riding on top of it along
+// with it, and pushing the Shapes in its way out of it; see MoveWithRiders().
+type MovingPlatform struct {
+	Shape Shape
+}
+
+// NewMovingPlatform returns a new MovingPlatform moving the Shape provided, which should be in the Space it moves around.
+func NewMovingPlatform(shape Shape) *MovingPlatform {
+	return &MovingPlatform{Shape: shape}
+}
+
+// MoveWithRiders moves the platform by the delta provided, horizontally and then vertically, along with the Shapes in the
+// Space that have all of the rider tags provided (like players and enemies; if no rider tags are provided, any Shape in
+// the Space can ride, walls included). Shapes resting on top of the platform (that is, within a pixel of it) are moved
+// along with it, and Shapes in its way are pushed ahead of it, so that they don't end up inside it. Each of them is moved
+// with its movement resolved against the Space, so riders don't get carried into walls; a rider that's stopped by a wall
+// just stays behind.
+// The platform itself isn't stopped by anything. A Shape in its way that can't be pushed all of the way out of it (like a
+// rider squashed against a ceiling by a platform moving up) is crushed: it's left against whatever stopped it, still
+// overlapping the platform, and returned, so the game can deal with it (like by killing the player). Each crushed Shape is
+// returned once. Shapes already overlapping the platform before it moves (like ones crushed by an earlier move) are
+// pushed out of it after it moves, where they can be, and are returned as crushed again if they can't. Only riders
+// standing on the platform itself are carried, not ones standing on other riders.
+func (m *MovingPlatform) MoveWithRiders(space *Space, dx, dy int32, riderTags ...string) []Shape {
+
+	var crushed []Shape
+	if dx != 0 {
+		crushed = m.moveAxis(space, dx, 0, riderTags, crushed)
+	}
+	if dy != 0 {
+		crushed = m.moveAxis(space, 0, dy, riderTags, crushed)
+	}
+	return crushed
+
+}
+
+// moveAxis moves the platform and its riders along a single axis, appending the riders it crushes to the slice provided.
+func (m *MovingPlatform) moveAxis(space *Space, dx, dy int32, riderTags []string, crushed []Shape) []Shape {
+
+	platform := m.Shape
+
+	var pushed, carried, overlapping []Shape
+	var need []int32
+
+	for _, shape := range space.shapes {
+
+		if shape == platform || !shape.HasTags(riderTags...) {
+			continue
+		}
+
+		if platform.IsColliding(shape) {
+			overlapping = append(overlapping, shape)
+			continue
+		}
+
+		// A Shape in the platform's way has to be pushed by however much of the movement is left after the platform
+		// reaches it.
+		if col := Resolve(platform, shape, dx, dy); col.Colliding() {
+			pushed = append(pushed, shape)
+			need = append(need, dx-col.ResolveX+dy-col.ResolveY)
+			continue
+		}
+
+		if shape.WouldBeColliding(platform, 0, 1) {
+			carried = append(carried, shape)
+		}
+
+	}
+
+	// The Shapes furthest along the movement are moved first, so that riders next to each other don't stop each other.
+	sort.Stable(byLead{pushed, need, dx + dy, dx != 0})
+	sort.Stable(byLead{carried, nil, dx + dy, dx != 0})
+	sort.Stable(byLead{overlapping, nil, dx + dy, dx != 0})
+
+	// The pushed Shapes move out of the way before the platform moves, and the carried Shapes follow it after, so that
+	// the platform never stands in the way of either.
+	for i, shape := range pushed {
+		needX, needY := need[i], int32(0)
+		if dx == 0 {
+			needX, needY = 0, need[i]
+		}
+		col := resolveDelta(space, shape, needX, needY)
+		shape.Move(col.ResolveX, col.ResolveY)
+		if col.Colliding() && (col.ResolveX != needX || col.ResolveY != needY) && !containsShape(crushed, shape) {
+			crushed = append(crushed, shape)
+		}
+	}
+
+	platform.Move(dx, dy)
+
+	// Shapes that were already inside the platform are pushed out of it once it's moved, as resolving them against it
+	// beforehand would push them back into it.
+	for _, shape := range overlapping {
+		outX, outY := clearance(platform, shape, sign32(dx), sign32(dy))
+		// The platform could have moved far enough out of the Shape that it's closer to back out the other way.
+		if backX, backY := clearance(platform, shape, -sign32(dx), -sign32(dy)); abs32(backX+backY) < abs32(outX+outY) {
+			outX, outY = backX, backY
+		}
+		col := resolveDelta(space, shape, outX, outY)
+		shape.Move(col.ResolveX, col.ResolveY)
+		if platform.IsColliding(shape) && !containsShape(crushed, shape) {
+			crushed = append(crushed, shape)
+		}
+	}
+
+	for _, shape := range carried {
+		col := resolveDelta(space, shape, dx, dy)
+		shape.Move(col.ResolveX, col.ResolveY)
+	}
+
+	return crushed
+
+}
+
+// clearance returns how far the Shape has to move along the direction provided (a unit vector along one axis) to be clear
+// of the platform, which is nowhere if it isn't overlapping it.
+func clearance(platform, shape Shape, dirX, dirY int32) (int32, int32) {
+
+	var a, b Rectangle
+	boundingRectInto(platform, &a)
+	boundingRectInto(shape, &b)
+	limit := int64(a.W) + int64(b.W)
+	if dirX == 0 {
+		limit = int64(a.H) + int64(b.H)
+	}
+
+	for k := int64(0); k <= limit; k++ {
+		x, y := int32(int64(dirX)*k), int32(int64(dirY)*k)
+		if !shape.WouldBeColliding(platform, x, y) {
+			return x, y
+		}
+	}
+	return int32(int64(dirX) * limit), int32(int64(dirY) * limit)
+
+}
+
+// byLead sorts Shapes (and the pushes that go with them, if any) by how far along a movement along a single axis they
+// are, furthest first.
+type byLead struct {
+	shapes     []Shape
+	need       []int32
+	delta      int32
+	horizontal bool
+}
+
+func (b byLead) Len() int { return len(b.shapes) }
+
+func (b byLead) Less(i, j int) bool {
+	return b.lead(b.shapes[i]) > b.lead(b.shapes[j])
+}
+
+func (b byLead) Swap(i, j int) {
+	b.shapes[i], b.shapes[j] = b.shapes[j], b.shapes[i]
+	if b.need != nil {
+		b.need[i], b.need[j] = b.need[j], b.need[i]
+	}
+}
+
+func (b byLead) lead(shape Shape) int64 {
+	x, y := shape.GetXY()
+	position := int64(y)
+	if b.horizontal {
+		position = int64(x)
+	}
+	return position * int64(sign32(b.delta))
+}
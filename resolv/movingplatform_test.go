@@ -0,0 +1,112 @@
+package resolv
+
+import "testing"
+
+func TestMovingPlatformCrushesAgainstCeiling(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		dy          int32
+		frames      int
+		wantCrushed []bool
+		wantRiderY  int32
+	}{
+		// The rider reaches the ceiling on the third frame, and stays crushed while the lift keeps going up.
+		{"lift keeps rising", -3, 5, []bool{false, false, true, true, true}, 84},
+		// Once the lift stops pushing into it, a crushed rider isn't crushed any more.
+		{"lift rises then drops", -3, 3, []bool{false, false, true}, 84},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			lift := NewRectangle(0, 100, 32, 8)
+			rider := NewRectangle(8, 92, 8, 8, WithTags("rider"))
+			ceiling := NewRectangle(0, 80, 32, 4)
+			space.Add(lift, rider, ceiling)
+			platform := NewMovingPlatform(lift)
+
+			for frame := 0; frame < tt.frames; frame++ {
+				crushed := platform.MoveWithRiders(space, 0, tt.dy, "rider")
+				if got := len(crushed) == 1 && crushed[0] == rider; got != tt.wantCrushed[frame] {
+					t.Errorf("frame %d: crushed = %v, want %v", frame, crushed, tt.wantCrushed[frame])
+				}
+			}
+			if rider.Y != tt.wantRiderY {
+				t.Errorf("rider ended up at Y = %d, want %d", rider.Y, tt.wantRiderY)
+			}
+
+			if tt.name == "lift rises then drops" {
+				for frame := 0; frame < 3; frame++ {
+					if crushed := platform.MoveWithRiders(space, 0, 4, "rider"); len(crushed) > 0 {
+						t.Errorf("dropping frame %d: crushed %v", frame, crushed)
+					}
+				}
+				if lift.IsColliding(rider) {
+					t.Error("rider is still inside the lift after it dropped away")
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestMovingPlatformRiders(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		dx, dy       int32
+		riderX       int32
+		riderY       int32
+		wantX, wantY int32
+	}{
+		{"carried right", 5, 0, 8, 92, 13, 92},
+		{"carried down", 0, 4, 8, 92, 8, 96},
+		{"pushed right", 6, 0, 34, 100, 38, 100},
+		{"pushed up", 0, -6, 8, 90, 8, 86},
+		{"not touching", 5, 0, 8, 60, 8, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			space := NewSpace()
+			lift := NewRectangle(0, 100, 32, 8)
+			rider := NewRectangle(tt.riderX, tt.riderY, 8, 8)
+			space.Add(lift, rider)
+			if crushed := NewMovingPlatform(lift).MoveWithRiders(space, tt.dx, tt.dy); len(crushed) > 0 {
+				t.Errorf("crushed %v", crushed)
+			}
+			if rider.X != tt.wantX || rider.Y != tt.wantY {
+				t.Errorf("rider at %d, %d, want %d, %d", rider.X, rider.Y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+
+}
+
+func BenchmarkMoveWithRiders(b *testing.B) {
+
+	space := NewSpace()
+	lift := NewRectangle(0, 1000, 256, 8)
+	space.Add(lift)
+	for i := int32(0); i < 16; i++ {
+		space.Add(NewRectangle(i*16, 992, 8, 8, WithTags("rider")))
+	}
+	for i := int32(0); i < 200; i++ {
+		space.Add(NewRectangle(i*32, 0, 16, 16))
+	}
+	platform := NewMovingPlatform(lift)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dy := int32(1)
+		if i%2 == 0 {
+			dy = -1
+		}
+		platform.MoveWithRiders(space, 0, dy, "rider")
+	}
+
+}
@@ -0,0 +1,64 @@
+package resolv
+
+import "fmt"
+
+// AddNamed adds the Shape to the Space under the name provided, so it can be found later with GetByName(). If another
+// Shape in the Space already has the name, the Shape isn't added and an error wrapping ErrDuplicateName is returned. A
+// Shape's name is forgotten when it's removed from the Space, so the name can be reused afterwards.
+func (sp *Space) AddNamed(name string, shape Shape) error {
+	if _, taken := sp.names[name]; taken {
+		return fmt.Errorf("%w: %q", ErrDuplicateName, name)
+	}
+	if err := sp.Add(shape); err != nil {
+		return err
+	}
+	sp.setName(name, shape)
+	return nil
+}
+
+func (sp *Space) setName(name string, shape Shape) {
+	if sp.names == nil {
+		sp.names = map[string]Shape{}
+		sp.nameOf = map[Shape]string{}
+	}
+	if old, named := sp.nameOf[shape]; named {
+		delete(sp.names, old)
+	}
+	sp.names[name] = shape
+	sp.nameOf[shape] = name
+	sp.changed()
+}
+
+// SetName names (or renames) a Shape that's already in the Space. The Shape's old name, if any, is freed up. If the Shape
+// isn't in the Space, an error wrapping ErrNotInSpace is returned; if another Shape already has the name, an error
+// wrapping ErrDuplicateName is.
+func (sp *Space) SetName(shape Shape, name string) error {
+	if !sp.Contains(shape) {
+		return fmt.Errorf("%w: %v", ErrNotInSpace, shape)
+	}
+	if other, taken := sp.names[name]; taken && other != shape {
+		return fmt.Errorf("%w: %q", ErrDuplicateName, name)
+	}
+	sp.setName(name, shape)
+	return nil
+}
+
+// GetByName returns the Shape in the Space with the name provided, or nil if there isn't one.
+func (sp *Space) GetByName(name string) Shape {
+	return sp.names[name]
+}
+
+// Name returns the name of the Shape in the Space, and whether it has one.
+func (sp *Space) Name(shape Shape) (string, bool) {
+	name, named := sp.nameOf[shape]
+	return name, named
+}
+
+// forgetName forgets the Shape's name once it's no longer in the Space, so a Shape that was added more than once keeps
+// its name until its last copy is removed.
+func (sp *Space) forgetName(shape Shape) {
+	if name, named := sp.nameOf[shape]; named && !sp.Contains(shape) {
+		delete(sp.names, name)
+		delete(sp.nameOf, shape)
+	}
+}
@@ -0,0 +1,281 @@
+package resolv
+
+import (
+	"sort"
+	"strings"
+)
+
+type normalizeOptions struct {
+	removeEmpty     bool
+	mergeDuplicates bool
+	mergeAdjacent   bool
+}
+
+// NormalizeOption is an option that changes what Space.Normalize() does.
+type NormalizeOption func(*normalizeOptions)
+
+// NormalizeRemoveEmpty sets whether Space.Normalize() removes Shapes with no area: Rectangles without a positive width
+// and height, Circles without a positive radius, Lines whose ends are at the same point, and empty Spaces. This is on by
+// default.
+func NormalizeRemoveEmpty(remove bool) NormalizeOption {
+	return func(o *normalizeOptions) {
+		o.removeEmpty = remove
+	}
+}
+
+// NormalizeMergeDuplicates sets whether Space.Normalize() removes Shapes that exactly coincide with an earlier Shape in the
+// Space: the same kind of Shape in the same place with the same size, tags, layer, mask, and elevation (Lines going
+// between the same points in either direction coincide). The earlier Shape is kept as it is, Data and all. This is on by
+// default.
+func NormalizeMergeDuplicates(merge bool) NormalizeOption {
+	return func(o *normalizeOptions) {
+		o.mergeDuplicates = merge
+	}
+}
+
+// NormalizeMergeAdjacent sets whether Space.Normalize() merges Rectangles that share a whole edge (as Rectangles are
+// half-open, a Rectangle ending at X = 16 and one starting at X = 16 share an edge) and have the same tags, layer, mask,
+// and elevation, into single Rectangles covering exactly the same area, like LoadTMX() does for tiles. Whichever
+// Rectangle comes first in the Space is grown to cover both, keeping its Data, and the other is removed. This is off by
+// default.
+func NormalizeMergeAdjacent(merge bool) NormalizeOption {
+	return func(o *normalizeOptions) {
+		o.mergeAdjacent = merge
+	}
+}
+
+// NormalizeReport describes what Space.Normalize() changed. EmptyRemoved holds the Shapes removed for having no area,
+// DuplicatesRemoved the Shapes removed for coinciding with another Shape, MergedAway the Rectangles removed for being
+// merged into another Rectangle, and Grown the Rectangles that were grown to cover Rectangles merged into them.
+type NormalizeReport struct {
+	EmptyRemoved      []Shape
+	DuplicatesRemoved []Shape
+	MergedAway        []*Rectangle
+	Grown             []*Rectangle
+}
+
+// Changed returns whether Normalize() changed anything.
+func (r NormalizeReport) Changed() bool {
+	return len(r.EmptyRemoved) > 0 || len(r.DuplicatesRemoved) > 0 || len(r.MergedAway) > 0
+}
+
+// isEmptyShape returns whether the Shape has no area.
+func isEmptyShape(shape Shape) bool {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return s.W <= 0 || s.H <= 0
+	case *Circle:
+		return s.Radius <= 0
+	case *Line:
+		return s.X == s.X2 && s.Y == s.Y2
+	case *Space:
+		return len(s.shapes) == 0
+	}
+	return false
+}
+
+// normalizeAttrs is what besides their geometry Shapes have to share to be merged by Normalize().
+type normalizeAttrs struct {
+	tags        string
+	layer, mask uint32
+	elevation   int32
+}
+
+func basicAttrs(b *BasicShape) normalizeAttrs {
+	return normalizeAttrs{strings.Join(b.tags.sorted, "\x00"), b.GetLayer(), b.GetMask(), b.Elevation}
+}
+
+// duplicateKey identifies a built-in Shape by its kind, geometry, and attributes, so that Shapes that exactly coincide
+// have the same key.
+type duplicateKey struct {
+	kind       byte
+	a, b, c, d int32
+	attrs      normalizeAttrs
+}
+
+// duplicateKeyOf returns the duplicateKey of the Shape, and false if it isn't one of the built-in Shapes it can be worked
+// out for.
+func duplicateKeyOf(shape Shape) (duplicateKey, bool) {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return duplicateKey{'r', s.X, s.Y, s.W, s.H, basicAttrs(&s.BasicShape)}, true
+	case *Circle:
+		return duplicateKey{'c', s.X, s.Y, s.Radius, 0, basicAttrs(&s.BasicShape)}, true
+	case *Line:
+		x, y, x2, y2 := s.X, s.Y, s.X2, s.Y2
+		if x2 < x || (x2 == x && y2 < y) {
+			x, y, x2, y2 = x2, y2, x, y
+		}
+		return duplicateKey{'l', x, y, x2, y2, basicAttrs(&s.BasicShape)}, true
+	}
+	return duplicateKey{}, false
+}
+
+// Normalize cleans up the Shapes directly within the Space (nested Spaces are left as they are), like after importing a
+// level made with an external tool, and returns a report of what it changed. By default, it removes Shapes with no area
+// (see NormalizeRemoveEmpty()) and Shapes that exactly coincide with another one (see NormalizeMergeDuplicates());
+// merging adjacent Rectangles (see NormalizeMergeAdjacent()) has to be turned on. With every option off, the Space is
+// left untouched. The order of the remaining Shapes is kept, and removed Shapes are removed like with RemoveWhere(), so
+// the functions registered with OnRemove() are called for them.
+func (sp *Space) Normalize(opts ...NormalizeOption) NormalizeReport {
+
+	options := &normalizeOptions{removeEmpty: true, mergeDuplicates: true}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	report := NormalizeReport{}
+	removed := make([]bool, len(sp.shapes))
+
+	if options.removeEmpty {
+		for i, shape := range sp.shapes {
+			if isEmptyShape(shape) {
+				removed[i] = true
+				report.EmptyRemoved = append(report.EmptyRemoved, shape)
+			}
+		}
+	}
+
+	if options.mergeDuplicates {
+		seen := map[duplicateKey]bool{}
+		for i, shape := range sp.shapes {
+			if removed[i] {
+				continue
+			}
+			if key, ok := duplicateKeyOf(shape); ok {
+				if seen[key] {
+					removed[i] = true
+					report.DuplicatesRemoved = append(report.DuplicatesRemoved, shape)
+				}
+				seen[key] = true
+			}
+		}
+	}
+
+	if options.mergeAdjacent {
+		sp.mergeAdjacent(removed, &report)
+	}
+
+	if !report.Changed() {
+		return report
+	}
+
+	if sp.iterating > 0 {
+		for i, shape := range sp.shapes {
+			if removed[i] {
+				sp.deferred = append(sp.deferred, shape)
+			}
+		}
+		return report
+	}
+
+	// Shapes are removed by their indexes rather than by themselves, so that when a Shape is in the Space more than once,
+	// only its duplicates are removed.
+	index := -1
+	sp.compact(func(Shape) bool {
+		index++
+		return removed[index]
+	})
+
+	return report
+
+}
+
+// mergeAdjacent merges the Rectangles in the Space that share an edge and have the same attributes (see
+// NormalizeMergeAdjacent()), marking the Rectangles merged away as removed.
+func (sp *Space) mergeAdjacent(removed []bool, report *NormalizeReport) {
+
+	type candidate struct {
+		rect  *Rectangle
+		index int
+	}
+
+	groups := map[normalizeAttrs][]*candidate{}
+	for i, shape := range sp.shapes {
+		if rect, ok := shape.(*Rectangle); ok && !removed[i] {
+			attrs := basicAttrs(&rect.BasicShape)
+			groups[attrs] = append(groups[attrs], &candidate{rect, i})
+		}
+	}
+
+	grown := map[*Rectangle]bool{}
+
+	for _, group := range groups {
+
+		// Rectangles are merged into rows, then columns, and so on, until there's nothing left to merge.
+		for horizontal, merged := true, true; merged || !horizontal; horizontal = !horizontal {
+
+			if horizontal {
+				merged = false
+			}
+
+			// Along the direction being merged in, pos and size are the Rectangles' position and size, and the cross
+			// position and size have to match for Rectangles to be merged.
+			pos := func(r *Rectangle) (int32, int32, int32, int32) {
+				if horizontal {
+					return r.X, r.W, r.Y, r.H
+				}
+				return r.Y, r.H, r.X, r.W
+			}
+
+			sort.Slice(group, func(i, j int) bool {
+				pi, _, ci, cSizeI := pos(group[i].rect)
+				pj, _, cj, cSizeJ := pos(group[j].rect)
+				if ci != cj {
+					return ci < cj
+				}
+				if cSizeI != cSizeJ {
+					return cSizeI < cSizeJ
+				}
+				return pi < pj
+			})
+
+			kept := group[:0]
+			for _, c := range group {
+
+				if len(kept) > 0 {
+					last := kept[len(kept)-1]
+					lp, ls, lc, lcs := pos(last.rect)
+					p, s, cp, cs := pos(c.rect)
+					if lc == cp && lcs == cs && lp+ls == p {
+						// The Rectangle that's earlier in the Space is the one kept.
+						if c.index < last.index {
+							last, c = c, last
+							kept[len(kept)-1] = last
+						}
+						// The Rectangle is grown and moved through SetXY(), so that the functions registered with OnMove()
+						// are called if it moves (when the Rectangle kept was the one further along).
+						if horizontal {
+							last.rect.W = ls + s
+							last.rect.SetXY(lp, last.rect.Y)
+						} else {
+							last.rect.H = ls + s
+							last.rect.SetXY(last.rect.X, lp)
+						}
+						last.rect.changed()
+						removed[c.index] = true
+						report.MergedAway = append(report.MergedAway, c.rect)
+						grown[last.rect] = true
+						delete(grown, c.rect)
+						merged = true
+						continue
+					}
+				}
+
+				kept = append(kept, c)
+
+			}
+			group = kept
+
+		}
+
+	}
+
+	for _, shape := range sp.shapes {
+		if rect, ok := shape.(*Rectangle); ok && grown[rect] {
+			report.Grown = append(report.Grown, rect)
+			delete(grown, rect)
+		}
+	}
+
+}
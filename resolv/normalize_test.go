@@ -0,0 +1,215 @@
+package resolv
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestNormalizeMergeAdjacent(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		rects     [][4]int32
+		want      [4]int32
+		wantMoves int
+	}{
+		{"row in order", [][4]int32{{0, 0, 8, 8}, {8, 0, 8, 8}, {16, 0, 8, 8}}, [4]int32{0, 0, 24, 8}, 0},
+		{"row reversed", [][4]int32{{16, 0, 8, 8}, {8, 0, 8, 8}, {0, 0, 8, 8}}, [4]int32{0, 0, 24, 8}, 1},
+		{"column reversed", [][4]int32{{0, 8, 8, 8}, {0, 0, 8, 8}}, [4]int32{0, 0, 8, 16}, 1},
+		{"block", [][4]int32{{8, 8, 8, 8}, {0, 0, 8, 8}, {8, 0, 8, 8}, {0, 8, 8, 8}}, [4]int32{0, 0, 16, 16}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			for _, r := range tt.rects {
+				space.Add(NewRectangle(r[0], r[1], r[2], r[3]))
+			}
+			kept := space.Get(0).(*Rectangle)
+			moves := 0
+			kept.OnMove(func(Shape, int32, int32) { moves++ })
+			version := kept.Version()
+
+			space.Normalize(NormalizeMergeAdjacent(true))
+
+			if space.Length() != 1 || space.Get(0) != kept {
+				t.Fatalf("Normalize() left %d shapes, want only the first", space.Length())
+			}
+			if got := [4]int32{kept.X, kept.Y, kept.W, kept.H}; got != tt.want {
+				t.Errorf("merged into %v, want %v", got, tt.want)
+			}
+			if moves != tt.wantMoves {
+				t.Errorf("OnMove() called %d times, want %d", moves, tt.wantMoves)
+			}
+			if kept.Version() == version {
+				t.Error("the grown Rectangle's version didn't change")
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkNormalizeMergeAdjacent(b *testing.B) {
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		space := NewSpace()
+		for y := int32(0); y < 32; y++ {
+			for x := int32(0); x < 32; x++ {
+				space.Add(NewRectangle(x*8, y*8, 8, 8))
+			}
+		}
+		b.StartTimer()
+		space.Normalize(NormalizeMergeAdjacent(true))
+	}
+
+}
+
+func TestNormalizeOptions(t *testing.T) {
+
+	// newSpace returns a fresh Space holding an empty Rectangle, Circle, and Line, a Rectangle and a Line with a
+	// duplicate each (the Line's duplicate going the other way), a look-alike that differs only by its tags, and two
+	// adjacent Rectangles.
+	newSpace := func() (*Space, []Shape) {
+		shapes := []Shape{
+			NewRectangle(0, 0, 0, 8),
+			NewRectangle(100, 0, 8, 8),
+			NewCircle(50, 50, 0),
+			NewLine(4, 4, 4, 4),
+			NewRectangle(100, 0, 8, 8),
+			NewRectangle(100, 0, 8, 8, WithTags("solid")),
+			NewLine(0, 40, 16, 48),
+			NewLine(16, 48, 0, 40),
+			NewRectangle(200, 0, 8, 8),
+			NewRectangle(208, 0, 8, 8),
+		}
+		space := NewSpace()
+		space.Add(shapes...)
+		return space, shapes
+	}
+
+	tests := []struct {
+		name                            string
+		opts                            []NormalizeOption
+		wantEmpty, wantDupes, wantMerge int
+	}{
+		{"defaults", nil, 3, 2, 0},
+		{"all off", []NormalizeOption{NormalizeRemoveEmpty(false), NormalizeMergeDuplicates(false)}, 0, 0, 0},
+		{"only empty", []NormalizeOption{NormalizeMergeDuplicates(false)}, 3, 0, 0},
+		{"only duplicates", []NormalizeOption{NormalizeRemoveEmpty(false)}, 0, 2, 0},
+		{"only adjacent", []NormalizeOption{
+			NormalizeRemoveEmpty(false), NormalizeMergeDuplicates(false), NormalizeMergeAdjacent(true),
+		}, 0, 0, 1},
+		{"everything", []NormalizeOption{NormalizeMergeAdjacent(true)}, 3, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space, shapes := newSpace()
+			version := space.Version()
+			var before []string
+			for _, shape := range shapes {
+				before = append(before, fmt.Sprint(shape))
+			}
+
+			report := space.Normalize(tt.opts...)
+
+			if len(report.EmptyRemoved) != tt.wantEmpty || len(report.DuplicatesRemoved) != tt.wantDupes ||
+				len(report.MergedAway) != tt.wantMerge || len(report.Grown) != tt.wantMerge {
+				t.Errorf("Normalize() report = %d empty, %d duplicates, %d merged, %d grown, want %d, %d, %d, %d",
+					len(report.EmptyRemoved), len(report.DuplicatesRemoved), len(report.MergedAway), len(report.Grown),
+					tt.wantEmpty, tt.wantDupes, tt.wantMerge, tt.wantMerge)
+			}
+			removed := tt.wantEmpty + tt.wantDupes + tt.wantMerge
+			if space.Length() != len(shapes)-removed {
+				t.Errorf("Normalize() left %d Shapes, want %d", space.Length(), len(shapes)-removed)
+			}
+			if report.Changed() != (removed > 0) {
+				t.Errorf("Changed() = %v, want %v", report.Changed(), removed > 0)
+			}
+			for _, shape := range report.DuplicatesRemoved {
+				if shape == shapes[1] || shape == shapes[6] {
+					t.Errorf("Normalize() removed the first of the duplicates, %v", shape)
+				}
+			}
+			if !space.Contains(shapes[5]) {
+				t.Error("Normalize() removed a Rectangle with different tags as a duplicate")
+			}
+
+			// The Shapes left are in their original order.
+			last := -1
+			space.ForEach(func(shape Shape) bool {
+				for i := last + 1; i < len(shapes); i++ {
+					if shapes[i] == shape {
+						last = i
+						return true
+					}
+				}
+				t.Errorf("Normalize() reordered the Shapes: %v", space.Shapes())
+				return false
+			})
+
+			if removed == 0 {
+				if space.Version() != version {
+					t.Error("Normalize() changed the Space's version without changing anything")
+				}
+				for i, shape := range shapes {
+					if fmt.Sprint(shape) != before[i] || space.Get(i) != shape {
+						t.Errorf("Normalize() changed %v", shape)
+					}
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestNormalizeMergeKeepsCollisions(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(14))
+
+	for level := 0; level < 20; level++ {
+
+		// A random tile map, with the tiles tagged so that not every pair of neighbours can be merged.
+		space := NewSpace()
+		for y := int32(0); y < 12; y++ {
+			for x := int32(0); x < 12; x++ {
+				switch rng.Intn(4) {
+				case 0:
+					continue
+				case 1:
+					space.Add(NewRectangle(x*8, y*8, 8, 8, WithTags("hazard")))
+				default:
+					space.Add(NewRectangle(x*8, y*8, 8, 8, WithTags("solid")))
+				}
+			}
+		}
+		original := space.Clone()
+
+		space.Normalize(NormalizeMergeAdjacent(true))
+		if space.Length() >= original.Length() && original.Length() > 1 {
+			t.Errorf("level %d: Normalize() didn't merge any of the %d tiles", level, original.Length())
+		}
+
+		// The probes have some area, as Shapes without any (which Normalize() removes) on the edge between two tiles don't
+		// collide with either tile, but do with the Rectangle they're merged into.
+		for i := 0; i < 500; i++ {
+			probe := NewRectangle(rng.Int31n(112)-8, rng.Int31n(112)-8, 1+rng.Int31n(12), 1+rng.Int31n(12))
+			for _, tag := range []string{"solid", "hazard"} {
+				want := original.FilterByTags(tag).IsColliding(probe)
+				if got := space.FilterByTags(tag).IsColliding(probe); got != want {
+					t.Fatalf("level %d: %v colliding with %q Shapes = %v after merging, want %v", level, probe, tag,
+						got, want)
+				}
+			}
+		}
+
+	}
+
+}
@@ -0,0 +1,103 @@
+package resolv
+
+// moveListener is a function registered with BasicShape.OnMove().
+type moveListener struct {
+	fn      func(s Shape, dx, dy int32)
+	removed bool
+}
+
+// moveListeners holds the functions registered with BasicShape.OnMove(). The list is copied whenever a function is
+// registered or removed rather than changed in place, so that a list being called through isn't affected by functions
+// registering or removing listeners.
+type moveListeners struct {
+	list []*moveListener
+}
+
+// shapeListeners holds the functions watching a BasicShape, created when the first one is registered.
+type shapeListeners struct {
+	move  *moveListeners // Registered with OnMove().
+	watch *moveListeners // Registered by Spaces with watch().
+}
+
+// OnMove registers a function to be called whenever the Shape moves, with the Shape and how far it moved, and returns a
+// function that removes it again (removing it more than once is fine). Multiple functions can be registered, and are
+// called in the order they were registered. It can be used to keep things like spatial indexes or sprites in sync with
+// the Shape.
+// The functions are called by the Move() and SetXY() functions of the built-in Shapes, once the Shape is in its new
+// position; they aren't called for moves that don't change the Shape's position. As a Space's Move() and SetXY() move
+// each of its Shapes, they call the functions of each Shape once. Functions can be removed (including by themselves)
+// and registered while the functions are being called; changes apply from the next move on.
+// Custom Shapes embedding BasicShape should call NotifyMove() from their own Move() and SetXY() functions, as
+// BasicShape's don't know which Shape to pass to the functions. Clones of the Shape don't have the functions registered
+// on it.
+func (b *BasicShape) OnMove(listener func(s Shape, dx, dy int32)) (remove func()) {
+	if b.listeners == nil {
+		b.listeners = &shapeListeners{}
+	}
+	return addMoveListener(&b.listeners.move, listener)
+}
+
+// addMoveListener adds the function to the list, returning a function that removes it again.
+func addMoveListener(listeners **moveListeners, listener func(s Shape, dx, dy int32)) (remove func()) {
+
+	l := &moveListener{fn: listener}
+
+	list := []*moveListener{l}
+	if *listeners != nil {
+		list = append(append(make([]*moveListener, 0, len((*listeners).list)+1), (*listeners).list...), l)
+	}
+	*listeners = &moveListeners{list}
+
+	return func() {
+		if l.removed {
+			return
+		}
+		l.removed = true
+		list := make([]*moveListener, 0, len((*listeners).list)-1)
+		for _, other := range (*listeners).list {
+			if other != l {
+				list = append(list, other)
+			}
+		}
+		if len(list) == 0 {
+			*listeners = nil
+		} else {
+			*listeners = &moveListeners{list}
+		}
+	}
+
+}
+
+// NotifyMove bumps the BasicShape's version (see Version()) and calls the functions registered with OnMove() for the
+// Shape provided (which should be the Shape embedding the BasicShape) having moved by dx and dy, unless it didn't move
+// at all. The built-in Shapes call it from their Move() and SetXY() functions; custom Shapes should do the same.
+func (b *BasicShape) NotifyMove(shape Shape, dx, dy int32) {
+	if dx|dy != 0 {
+		b.version++
+		if b.listeners != nil {
+			b.notifyMove(shape, dx, dy)
+		}
+	}
+}
+
+// notifyMove calls the functions watching the BasicShape and those registered with OnMove(). It's kept from being
+// inlined into NotifyMove() so that NotifyMove() is inlined into the Shapes' Move() and SetXY() functions, which then
+// cost nothing more than a nil check when there are no functions.
+//
+//go:noinline
+func (b *BasicShape) notifyMove(shape Shape, dx, dy int32) {
+	if b.listeners.watch != nil {
+		b.listeners.watch.notify(nil, 0, 0)
+	}
+	if b.listeners.move != nil {
+		b.listeners.move.notify(shape, dx, dy)
+	}
+}
+
+func (ml *moveListeners) notify(shape Shape, dx, dy int32) {
+	for _, l := range ml.list {
+		if !l.removed {
+			l.fn(shape, dx, dy)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package resolv
+
+// ShapeOption is an option that configures a Shape as it's created by one of the Shape constructors, like
+// NewRectangle(). The same options work with every constructor, and can be given in any order:
+//
+//	wall := resolv.NewRectangle(0, 0, 16, 16, resolv.WithTags("solid"), resolv.WithLayer(solidLayer))
+type ShapeOption func(Shape)
+
+// WithTags adds the tags provided to the Shape.
+func WithTags(tags ...string) ShapeOption {
+	return func(s Shape) {
+		s.AddTags(tags...)
+	}
+}
+
+// WithData sets the Data of the Shape to the value provided.
+func WithData(data interface{}) ShapeOption {
+	return func(s Shape) {
+		s.SetData(data)
+	}
+}
+
+// WithLayer sets the collision layer bits of the Shape (see BasicShape.SetLayer()).
+func WithLayer(layer uint32) ShapeOption {
+	return func(s Shape) {
+		if s, ok := s.(interface{ SetLayer(uint32) }); ok {
+			s.SetLayer(layer)
+		}
+	}
+}
+
+// WithMask sets the collision mask of the Shape (see BasicShape.SetMask()).
+func WithMask(mask uint32) ShapeOption {
+	return func(s Shape) {
+		if s, ok := s.(interface{ SetMask(uint32) }); ok {
+			s.SetMask(mask)
+		}
+	}
+}
+
+// WithElevation sets the elevation of the Shape (see BasicShape.SetElevation()).
+func WithElevation(elevation int32) ShapeOption {
+	return func(s Shape) {
+		if s, ok := s.(interface{ SetElevation(int32) }); ok {
+			s.SetElevation(elevation)
+		}
+	}
+}
+
+// applyShapeOptions sets up a Shape that was just created by one of the constructors, giving it its ID and applying the
+// options provided.
+func applyShapeOptions(s Shape, opts []ShapeOption) {
+	if identified, ok := s.(interface{ ID() uint64 }); ok {
+		identified.ID()
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+}
+
+// SpaceOption is an option that configures a Space as it's created by NewSpace().
+type SpaceOption func(*Space)
+
+// WithCapacity gives the Space room for n Shapes, so that adding up to that many Shapes doesn't have to grow the Space
+// (see Space.Grow()).
+func WithCapacity(n int) SpaceOption {
+	return func(sp *Space) {
+		sp.Grow(n)
+	}
+}
+
+// WithSpatialHash makes the Space use a spatial hash with square cells of the size provided to find candidate pairs in
+// GetCollidingPairs(), rather than testing every pair of Shapes. This is much faster for Spaces with many Shapes spread
+// out over a large area. The cell size should be around the size of a typical Shape. Candidate pairs are found with the
+// Shapes' bounding rectangles, so custom Shapes need to return accurate ones from GetBoundingRect(). A cell size of 0
+// turns the spatial hash off again.
+func WithSpatialHash(cellSize int32) SpaceOption {
+	return func(sp *Space) {
+		sp.hashCellSize = cellSize
+	}
+}
@@ -0,0 +1,78 @@
+package resolv
+
+import "testing"
+
+func TestShapeOptions(t *testing.T) {
+
+	data := &struct{}{}
+
+	constructors := map[string]func(opts ...ShapeOption) Shape{
+		"Rectangle": func(opts ...ShapeOption) Shape { return NewRectangle(0, 0, 8, 8, opts...) },
+		"Circle":    func(opts ...ShapeOption) Shape { return NewCircle(0, 0, 4, opts...) },
+		"Line":      func(opts ...ShapeOption) Shape { return NewLine(0, 0, 8, 8, opts...) },
+	}
+
+	options := []struct {
+		name   string
+		option ShapeOption
+		check  func(s Shape) bool
+	}{
+		{"WithTags", WithTags("solid", "ground"), func(s Shape) bool { return s.HasTags("solid", "ground") }},
+		{"WithData", WithData(data), func(s Shape) bool { return s.GetData() == data }},
+		{"WithLayer", WithLayer(4), func(s Shape) bool { return shapeLayer(s) == 4 }},
+		{"WithMask", WithMask(6), func(s Shape) bool { return shapeMask(s) == 6 }},
+		{"WithElevation", WithElevation(2), func(s Shape) bool { return shapeElevation(s) == 2 }},
+	}
+
+	for name, construct := range constructors {
+
+		for _, o := range options {
+			if s := construct(o.option); !o.check(s) {
+				t.Errorf("%s with %s wasn't configured", name, o.name)
+			}
+		}
+
+		// All of the options together, forwards and backwards, configure the Shape the same way.
+		forwards, backwards := []ShapeOption{}, []ShapeOption{}
+		for i := range options {
+			forwards = append(forwards, options[i].option)
+			backwards = append(backwards, options[len(options)-1-i].option)
+		}
+		for _, opts := range [][]ShapeOption{forwards, backwards} {
+			s := construct(opts...)
+			for _, o := range options {
+				if !o.check(s) {
+					t.Errorf("%s with every option wasn't configured by %s", name, o.name)
+				}
+			}
+		}
+
+		if s := construct(); s.HasTags("solid") || s.GetData() != nil || shapeLayer(s) != DefaultLayer ||
+			shapeMask(s) != AllLayers || shapeElevation(s) != 0 {
+			t.Errorf("%s without options isn't configured with the defaults", name)
+		}
+
+	}
+
+}
+
+func TestSpaceOptions(t *testing.T) {
+
+	for _, opts := range [][]SpaceOption{
+		{WithCapacity(1024), WithSpatialHash(64)},
+		{WithSpatialHash(64), WithCapacity(1024)},
+	} {
+		sp := NewSpace(opts...)
+		if cap(sp.shapes) < 1024 {
+			t.Errorf("WithCapacity() gave the Space room for %d Shapes, want 1024", cap(sp.shapes))
+		}
+		if sp.hashCellSize != 64 {
+			t.Errorf("WithSpatialHash() set the cell size to %d, want 64", sp.hashCellSize)
+		}
+	}
+
+	if sp := NewSpace(); sp.hashCellSize != 0 {
+		t.Errorf("NewSpace() without options uses a spatial hash of %d", sp.hashCellSize)
+	}
+
+}
@@ -0,0 +1,162 @@
+package resolv
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelWorkers returns the number of goroutines to use for a parallel query over n items.
+func parallelWorkers(workers, n int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// parallelChunk returns the range of the n items that the given worker handles when they're split into chunks of the
+// size provided. As the chunk size is rounded up, the last workers may get fewer items, or none.
+func parallelChunk(worker, chunk, n int) (start, end int) {
+	start = worker * chunk
+	if start > n {
+		start = n
+	}
+	end = start + chunk
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+// GetCollidingShapesParallel returns a Space comprised of Shapes that collide with the checking Shape, like
+// GetCollidingShapes() does, but splits the Shapes in the Space between the number of goroutines provided (or
+// GOMAXPROCS goroutines, if workers is 0 or less). The result is the same as GetCollidingShapes(), in the same order.
+// The Shapes mustn't be changed while the query is running.
+func (sp *Space) GetCollidingShapesParallel(shape Shape, workers int) *Space {
+
+	defer sp.stats.timeSince(sp.stats.now())
+
+	workers = parallelWorkers(workers, len(sp.shapes))
+	results := make([][]Shape, workers)
+	chunk := (len(sp.shapes) + workers - 1) / workers
+
+	// Like with GetCollidingShapes(), a wrapped Shape (like a TypedShape) skips the Shape it wraps.
+	self := unwrapShape(shape)
+
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < workers; w++ {
+
+		start, end := parallelChunk(w, chunk, len(sp.shapes))
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			for _, other := range sp.shapes[start:end] {
+				sp.stats.candidate()
+				if unwrapShape(other) != self && sp.canCollide(shape, other) {
+					sp.stats.test()
+					if shape.IsColliding(other) {
+						results[w] = append(results[w], other)
+					}
+				}
+			}
+		}(w, start, end)
+
+	}
+
+	wg.Wait()
+
+	newSpace := newResultSpace()
+	for _, result := range results {
+		newSpace.Add(result...)
+	}
+	return newSpace
+
+}
+
+// FilterParallel returns a Space comprised of the Shapes that return true for the predicate provided, like Filter()
+// does, but splits the Shapes in the Space between the number of goroutines provided (or GOMAXPROCS goroutines, if
+// workers is 0 or less). This is meant for expensive predicates (like ones looking into each Shape's Data) over large
+// Spaces. The result is the same as Filter(), in the same order. As the predicate is called from several goroutines at
+// once, it must be safe to call concurrently; the Shapes mustn't be changed while the query is running.
+func (sp *Space) FilterParallel(workers int, predicate func(Shape) bool) *Space {
+
+	workers = parallelWorkers(workers, len(sp.shapes))
+	results := make([][]Shape, workers)
+	chunk := (len(sp.shapes) + workers - 1) / workers
+
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < workers; w++ {
+
+		start, end := parallelChunk(w, chunk, len(sp.shapes))
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			for _, shape := range sp.shapes[start:end] {
+				if predicate(shape) {
+					results[w] = append(results[w], shape)
+				}
+			}
+		}(w, start, end)
+
+	}
+
+	wg.Wait()
+
+	newSpace := newResultSpace()
+	for _, result := range results {
+		newSpace.Add(result...)
+	}
+	return newSpace
+
+}
+
+// GetCollidingPairsParallel returns every pair of Shapes in the Space that are colliding with each other, like
+// GetCollidingPairs() does, but splits the work between the number of goroutines provided (or GOMAXPROCS goroutines, if
+// workers is 0 or less). The result is the same as GetCollidingPairs(), in the same order. The Shapes mustn't be changed
+// while the query is running.
+func (sp *Space) GetCollidingPairsParallel(workers int) []CollisionPair {
+
+	defer sp.stats.timeSince(sp.stats.now())
+
+	leaves := sp.pairLeaves()
+	workers = parallelWorkers(workers, len(leaves))
+
+	// Earlier leaves are tested against more leaves than later ones, so rather than splitting the leaves into even
+	// chunks, each worker takes the next untested leaf as it goes.
+	results := make([][]CollisionPair, len(leaves))
+	next := make(chan int, len(leaves))
+	for i := range leaves {
+		next <- i
+	}
+	close(next)
+
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = sp.appendPairs(leaves, nil, i)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	pairs := []CollisionPair{}
+	for _, result := range results {
+		pairs = append(pairs, result...)
+	}
+	return pairs
+
+}
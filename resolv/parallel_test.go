@@ -0,0 +1,145 @@
+package resolv
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFilterParallelMatchesFilter(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(25))
+
+	for _, size := range []int{0, 1, 7, 64, 1000} {
+
+		space := NewSpace()
+		for i := 0; i < size; i++ {
+			space.Add(randomShape(rng, true))
+		}
+
+		for round := 0; round < 20; round++ {
+
+			// A random predicate that only depends on the Shape, so calling it concurrently is safe, keeping about
+			// 1 in every modulo Shapes.
+			seed, modulo := rng.Int63(), 1+rng.Intn(5)
+			predicate := func(shape Shape) bool {
+				h := uint64(seed) ^ uint64(shapeBoundingRect(shape).X)*0x9e3779b97f4a7c15
+				return h>>32%uint64(modulo) == 0
+			}
+			want := space.Filter(predicate)
+
+			for _, workers := range []int{-1, 0, 1, 2, 3, 8, size + 5} {
+
+				var calls int64
+				got := space.FilterParallel(workers, func(shape Shape) bool {
+					atomic.AddInt64(&calls, 1)
+					return predicate(shape)
+				})
+
+				if calls != int64(size) {
+					t.Fatalf("FilterParallel(%d) called the predicate %d times for %d Shapes", workers, calls, size)
+				}
+				if got.Length() != want.Length() {
+					t.Fatalf("FilterParallel(%d) returned %d Shapes, want %d", workers, got.Length(), want.Length())
+				}
+				for i := 0; i < want.Length(); i++ {
+					if got.Get(i) != want.Get(i) {
+						t.Fatalf("FilterParallel(%d) returned %v at %d, want %v", workers, got.Get(i), i, want.Get(i))
+					}
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+func BenchmarkFilterParallel(b *testing.B) {
+
+	space := NewSpace()
+	for i := 0; i < 50000; i++ {
+		space.Add(NewRectangle(int32(i%256)*16, int32(i/256)*16, 16, 16, WithData(i)))
+	}
+
+	// An expensive predicate looking into each Shape's Data.
+	predicate := func(shape Shape) bool {
+		n := shape.GetData().(int)
+		for i := 0; i < 100; i++ {
+			n = n*31 + i
+		}
+		return n%3 == 0
+	}
+
+	b.Run("Filter", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.Filter(predicate)
+		}
+	})
+
+	b.Run("FilterParallel", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.FilterParallel(0, predicate)
+		}
+	})
+
+}
+
+// scalingSpace returns a Space of 10000 Rectangles scattered over an area where they overlap a few others each, along with
+// a large Rectangle overlapping about a tenth of them.
+func scalingSpace() (*Space, *Rectangle) {
+	rng := rand.New(rand.NewSource(363))
+	space := NewSpace()
+	for i := 0; i < 10000; i++ {
+		space.Add(NewRectangle(rng.Int31n(2000), rng.Int31n(2000), 8+rng.Int31n(16), 8+rng.Int31n(16)))
+	}
+	return space, NewRectangle(0, 0, 632, 632)
+}
+
+func BenchmarkGetCollidingShapesParallel(b *testing.B) {
+
+	space, probe := scalingSpace()
+
+	b.Run("GetCollidingShapes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.GetCollidingShapes(probe)
+		}
+	})
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				space.GetCollidingShapesParallel(probe, workers)
+			}
+		})
+	}
+
+}
+
+func BenchmarkGetCollidingPairsParallel(b *testing.B) {
+
+	space, _ := scalingSpace()
+
+	b.Run("GetCollidingPairs", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.GetCollidingPairs()
+		}
+	})
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				space.GetCollidingPairsParallel(workers)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,107 @@
+package resolv
+
+import "math"
+
+// PathFollower moves a Shape along a path of waypoints (like a patrolling enemy), a set distance each tick, with the
+// movement resolved against the other Shapes in the Space; see FollowPath(). The Shape's position (GetXY()) is what's
+// moved onto each waypoint.
+type PathFollower struct {
+	Shape     Shape
+	Space     *Space
+	Waypoints [][2]int32
+
+	// Speed is the most the Shape moves by each tick, in pixels.
+	Speed int32
+
+	// Loop makes the path start over from the first waypoint once the last one's reached, rather than ending there.
+	Loop bool
+
+	index int
+	done  bool
+}
+
+// FollowPath returns a new PathFollower moving the Shape provided along the waypoints provided at the speed provided,
+// starting with the first waypoint. The path is followed once, unless Loop is set on the PathFollower. The Shape should
+// be in the Space, so that it doesn't collide with itself.
+func FollowPath(space *Space, shape Shape, waypoints [][2]int32, speed int32) *PathFollower {
+	return &PathFollower{Shape: shape, Space: space, Waypoints: waypoints, Speed: speed, done: len(waypoints) == 0}
+}
+
+// Advance moves the Shape towards the current waypoint by up to Speed pixels, moving on to the next waypoint once it's
+// reached (with whatever's left of Speed for that tick carrying on towards it, so the Shape doesn't slow down at
+// waypoints). Waypoints that the Shape is already on, like both ends of a zero-length segment, are passed straight
+// through.
+// If something's in the way, the Shape stops against it, and Advance returns true and the Collision with it; the Shape
+// tries again on the next call, so it carries on once the way is clear. A waypoint that can't be reached at all (like one
+// inside a wall) blocks the Shape on every call, until it's skipped with Skip().
+func (p *PathFollower) Advance() (blocked bool, col Collision) {
+
+	budget := float64(p.Speed)
+
+	// Each waypoint is visited at most once per call, so that a looping path whose waypoints are all in the same place
+	// can't loop forever.
+	for visited := 0; !p.done && budget > 0 && visited <= len(p.Waypoints); visited++ {
+
+		x, y := p.Shape.GetXY()
+		target := p.Waypoints[p.index]
+		dx, dy := int64(target[0])-int64(x), int64(target[1])-int64(y)
+		distance := math.Hypot(float64(dx), float64(dy))
+
+		if distance > budget {
+			// The Shape can only get part of the way there this tick.
+			dx = int64(math.Round(float64(dx) * budget / distance))
+			dy = int64(math.Round(float64(dy) * budget / distance))
+			if dx == 0 && dy == 0 {
+				break
+			}
+		}
+
+		col := resolveDelta(p.Space, p.Shape, int32(dx), int32(dy))
+		p.Shape.Move(col.ResolveX, col.ResolveY)
+		if col.Colliding() {
+			return true, col
+		}
+
+		if distance > budget {
+			break
+		}
+
+		budget -= distance
+		p.next()
+
+	}
+
+	return false, Collision{}
+
+}
+
+// next moves on to the next waypoint, ending the path after the last one unless it loops.
+func (p *PathFollower) next() {
+	p.index++
+	if p.index >= len(p.Waypoints) {
+		if p.Loop {
+			p.index = 0
+		} else {
+			p.index = len(p.Waypoints) - 1
+			p.done = true
+		}
+	}
+}
+
+// Skip gives up on the current waypoint (like one the Shape is stuck on), moving on to the next one.
+func (p *PathFollower) Skip() {
+	if !p.done {
+		p.next()
+	}
+}
+
+// Index returns the index of the waypoint the Shape is currently moving towards (or, once the path's done, the last
+// one).
+func (p *PathFollower) Index() int {
+	return p.index
+}
+
+// Done returns whether the Shape has reached the end of the path. A looping path is never done.
+func (p *PathFollower) Done() bool {
+	return p.done
+}
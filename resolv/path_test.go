@@ -0,0 +1,161 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFollowPath(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		waypoints [][2]int32
+		speed     int32
+		wantTicks int
+	}{
+		{"square", [][2]int32{{30, 0}, {30, 30}, {0, 30}, {0, 0}}, 3, 40},
+		{"speed not dividing the segments", [][2]int32{{10, 0}, {10, 10}}, 3, 7},
+		{"diagonal", [][2]int32{{30, 40}}, 5, 10},
+		{"zero-length segments", [][2]int32{{0, 0}, {20, 0}, {20, 0}, {20, 0}, {20, 20}}, 4, 10},
+		{"one waypoint where the Shape is", [][2]int32{{0, 0}}, 4, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			shape := NewRectangle(0, 0, 8, 8)
+			space.Add(shape)
+			path := FollowPath(space, shape, tt.waypoints, tt.speed)
+
+			ticks := 0
+			for !path.Done() && ticks < 1000 {
+				x, y := shape.GetXY()
+				if blocked, _ := path.Advance(); blocked {
+					t.Fatalf("tick %d: Advance() was blocked on an empty path", ticks)
+				}
+				ticks++
+				nx, ny := shape.GetXY()
+				if moved := math.Hypot(float64(nx-x), float64(ny-y)); moved > float64(tt.speed)+1 {
+					t.Fatalf("tick %d: the Shape moved %v pixels at a speed of %d", ticks, moved, tt.speed)
+				}
+			}
+
+			last := tt.waypoints[len(tt.waypoints)-1]
+			if x, y := shape.GetXY(); x != last[0] || y != last[1] {
+				t.Errorf("the path ended with the Shape at %d, %d, want %v", x, y, last)
+			}
+			if ticks != tt.wantTicks {
+				t.Errorf("the path took %d ticks, want %d", ticks, tt.wantTicks)
+			}
+			if path.Index() != len(tt.waypoints)-1 {
+				t.Errorf("Index() at the end = %d, want %d", path.Index(), len(tt.waypoints)-1)
+			}
+
+			// Advancing a finished path does nothing.
+			if blocked, _ := path.Advance(); blocked || shape.X != last[0] || shape.Y != last[1] {
+				t.Errorf("Advance() after the end moved the Shape to %v, blocked = %v", shape, blocked)
+			}
+
+		})
+	}
+
+	if path := FollowPath(NewSpace(), NewRectangle(0, 0, 8, 8), nil, 4); !path.Done() {
+		t.Error("a path without waypoints isn't done")
+	}
+
+}
+
+func TestFollowPathLoop(t *testing.T) {
+
+	space := NewSpace()
+	shape := NewRectangle(0, 0, 8, 8)
+	space.Add(shape)
+	path := FollowPath(space, shape, [][2]int32{{20, 0}, {0, 0}}, 4)
+	path.Loop = true
+
+	// The path goes there and back every 10 ticks, forever.
+	for tick := 1; tick <= 100; tick++ {
+		path.Advance()
+		if path.Done() {
+			t.Fatalf("tick %d: a looping path is done", tick)
+		}
+		want := int32(tick%10) * 4
+		if want > 20 {
+			want = 40 - want
+		}
+		if shape.X != want || shape.Y != 0 {
+			t.Fatalf("tick %d: the Shape is at %d, %d, want %d, 0", tick, shape.X, shape.Y, want)
+		}
+	}
+
+	// A looping path whose waypoints are all where the Shape is doesn't loop forever within a call.
+	still := FollowPath(space, shape, [][2]int32{{0, 0}, {0, 0}, {0, 0}}, 4)
+	still.Loop = true
+	shape.SetXY(0, 0)
+	if blocked, _ := still.Advance(); blocked || still.Done() {
+		t.Errorf("Advance() on a path that goes nowhere returned blocked = %v, done = %v", blocked, still.Done())
+	}
+
+}
+
+func TestFollowPathBlocked(t *testing.T) {
+
+	space := NewSpace()
+	shape := NewRectangle(0, 0, 8, 8)
+	wall := NewRectangle(40, -20, 16, 60)
+	crate := NewRectangle(20, 0, 8, 8)
+	space.Add(shape, wall, crate)
+
+	// The second waypoint is inside the wall, so the Shape gets stuck against it until the waypoint's skipped.
+	path := FollowPath(space, shape, [][2]int32{{10, 0}, {44, 0}, {0, 20}}, 4)
+
+	var col Collision
+	blocked := false
+	for tick := 0; tick < 20 && !blocked; tick++ {
+		blocked, col = path.Advance()
+	}
+	if !blocked || col.ShapeB != crate || shape.X != 12 {
+		t.Fatalf("Advance() = %v, %v with the Shape at %v, want it blocked by the crate at x = 12", blocked, col.ShapeB,
+			shape)
+	}
+
+	// Once the crate's moved out of the way, the Shape carries on, until it's stuck against the wall.
+	crate.SetXY(200, 200)
+	for tick := 0; tick < 20; tick++ {
+		blocked, col = path.Advance()
+	}
+	if !blocked || col.ShapeB != wall || shape.X != 32 || path.Index() != 1 {
+		t.Fatalf("Advance() = %v, %v with the Shape at %v, heading to waypoint %d, want it stuck against the wall at x = 32",
+			blocked, col.ShapeB, shape, path.Index())
+	}
+
+	path.Skip()
+	for tick := 0; tick < 20 && !path.Done(); tick++ {
+		if blocked, _ := path.Advance(); blocked {
+			t.Fatalf("Advance() was blocked after skipping the waypoint in the wall, with the Shape at %v", shape)
+		}
+	}
+	if !path.Done() || shape.X != 0 || shape.Y != 20 {
+		t.Errorf("after skipping the waypoint in the wall, the Shape ended at %v, done = %v", shape, path.Done())
+	}
+
+}
+
+func BenchmarkPathAdvance(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 64; i++ {
+		space.Add(NewRectangle(i%8*64+40, i/8*64+40, 16, 16))
+	}
+	shape := NewRectangle(0, 0, 8, 8)
+	space.Add(shape)
+	path := FollowPath(space, shape, [][2]int32{{400, 0}, {400, 16}, {0, 16}, {0, 0}}, 3)
+	path.Loop = true
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		path.Advance()
+	}
+
+}
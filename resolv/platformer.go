@@ -0,0 +1,308 @@
+package resolv
+
+// PlatformerBody moves a Shape around a Space like the player of a platformer: it falls with gravity, jumps, and runs,
+// with movement resolved against the other Shapes in the Space one axis at a time (horizontally, then vertically), so
+// that it slides along floors and walls rather than sticking to them. Which Shapes it collides with follows their
+// collision layers and masks, and elevation (see LayersCollide()), like Space.Resolve().
+// Speeds are in pixels per frame, and can be fractions of a pixel; the fractions are carried over between frames, so
+// slow movement isn't lost to the Shape's whole-pixel position.
+type PlatformerBody struct {
+	Shape Shape
+	Space *Space
+
+	// Gravity is added to the body's vertical speed each frame it's in the air, up to MaxFallSpeed.
+	Gravity      float64
+	MaxFallSpeed float64
+
+	// JumpImpulse is the upward speed the body jumps with.
+	JumpImpulse float64
+
+	// MoveSpeed is the horizontal speed the body runs at with an inputX of 1 (or -1).
+	MoveSpeed float64
+
+	// CoyoteFrames is how many frames after running off a ledge the body can still jump, to make jumps at the very edge
+	// of platforms more forgiving.
+	CoyoteFrames int
+
+	// LadderTag is the tag of the Shapes the body can climb (see UpdateClimbing()). Ladders shouldn't collide with the
+	// body's Shape (like by giving them a mask of 0), so that the body can pass through them.
+	LadderTag string
+
+	// ClimbSpeed is the speed the body climbs at with an inputY of 1 (or -1); it moves at the same speed sideways while
+	// climbing.
+	ClimbSpeed float64
+
+	// LadderTopBoost is the upward speed the body is given when it climbs off the top of a ladder, so that it makes it up
+	// onto the platform above.
+	LadderTopBoost float64
+
+	vx, vy     float64
+	remX, remY float64
+	onGround   bool
+	wall       int
+	airFrames  int
+	climbing   bool
+	ladderLock bool
+}
+
+// NewPlatformerBody returns a new PlatformerBody moving the Shape provided around the Space provided, with a gravity of
+// 0.5, a max fall speed of 8, a jump impulse of 8, a move speed of 2, 6 coyote frames, and climbing Shapes tagged
+// "ladder" at a speed of 1.5, with a boost of 4 off their tops. The Shape should be in the Space, so that the body
+// doesn't collide with its own Shape.
+func NewPlatformerBody(space *Space, shape Shape) *PlatformerBody {
+	p := &PlatformerBody{
+		Shape:        shape,
+		Space:        space,
+		Gravity:      0.5,
+		MaxFallSpeed: 8,
+		JumpImpulse:  8,
+		MoveSpeed:    2,
+		CoyoteFrames: 6,
+
+		LadderTag:      "ladder",
+		ClimbSpeed:     1.5,
+		LadderTopBoost: 4,
+	}
+	p.onGround = p.probe(0, 1) || p.onLadderTop()
+	return p
+}
+
+// Update moves the body for a frame. inputX is the horizontal input, from -1 (left) to 1 (right), which sets how fast the
+// body runs; jumpPressed is whether the body should jump, which it does if it's on the ground (or ran off it within the
+// last CoyoteFrames frames). The body doesn't climb ladders, though it can stand on top of them; see UpdateClimbing().
+func (p *PlatformerBody) Update(inputX float64, jumpPressed bool) {
+	p.UpdateClimbing(inputX, 0, jumpPressed)
+}
+
+// UpdateClimbing moves the body for a frame like Update() does, but also climbs ladders (Shapes with the LadderTag) with
+// the vertical input, inputY, from -1 (up) to 1 (down). Pressing up or down while overlapping a ladder grabs onto it
+// (though pressing down while standing on the ground doesn't, so that the body doesn't grab a ladder it's standing at
+// the foot of), as does pressing down while standing on top of one; while climbing, gravity is suspended, and the body
+// moves with the input at ClimbSpeed. The body lets go of the ladder when it jumps, climbs down onto the ground, or moves
+// off of the ladder.
+// Climbing off the top of the ladder boosts the body upwards by LadderTopBoost; when not climbing, the tops of ladders
+// are floors that the body can land on from above (but pass up through from below), so the body lands on top of the
+// ladder, level with the platform it leads up to, rather than falling back down it. Until it lands or up is let go of,
+// the body doesn't grab onto a ladder again, so that it doesn't catch the ladder it just let go of.
+// The body's movement is resolved against the Space as always while climbing, so it can't clip into the floor at the top
+// of a ladder, for example.
+func (p *PlatformerBody) UpdateClimbing(inputX, inputY float64, jumpPressed bool) {
+
+	if p.ladderLock && (inputY >= 0 || p.onGround) {
+		p.ladderLock = false
+	}
+
+	if !p.climbing && inputY != 0 && !p.ladderLock && (inputY > 0 && p.onLadderTop() || !(inputY > 0 && p.onGround) && p.onLadder()) {
+		p.climbing = true
+		p.vx, p.vy, p.remX, p.remY = 0, 0, 0, 0
+	}
+
+	if p.climbing {
+
+		if !jumpPressed {
+			p.climb(inputX, inputY)
+			return
+		}
+
+		// Jumping lets go of the ladder; as the body's holding onto it, it can jump just like from the ground.
+		p.climbing = false
+		p.ladderLock = true
+		p.airFrames = 0
+		p.vy = -p.JumpImpulse
+		jumpPressed = false
+
+	}
+
+	if p.onGround {
+		p.airFrames = 0
+	} else {
+		p.airFrames++
+	}
+
+	p.vx = inputX * p.MoveSpeed
+
+	if jumpPressed && (p.onGround || p.airFrames <= p.CoyoteFrames) {
+		p.vy = -p.JumpImpulse
+		// The coyote frames are used up by jumping, so that the body can't jump again in mid-air.
+		p.airFrames = p.CoyoteFrames + 1
+	}
+
+	p.vy += p.Gravity
+	if p.vy > p.MaxFallSpeed {
+		p.vy = p.MaxFallSpeed
+	}
+
+	p.move()
+
+	if p.onGround && p.vy > 0 {
+		p.vy, p.remY = 0, 0
+	}
+
+}
+
+// climb moves the body along the ladder it's holding onto for a frame, letting go of it if the body climbs off of it or
+// down onto the ground.
+func (p *PlatformerBody) climb(inputX, inputY float64) {
+
+	p.airFrames = 0
+	p.vx = clampUnit(inputX) * p.ClimbSpeed
+	p.vy = clampUnit(inputY) * p.ClimbSpeed
+
+	p.move()
+
+	switch {
+	case !p.onLadder():
+		p.climbing = false
+		if p.vy < 0 {
+			p.vy = -p.LadderTopBoost
+			p.ladderLock = true
+		} else {
+			p.vy = 0
+		}
+	case p.onGround && inputY > 0:
+		p.climbing = false
+		p.vy, p.remY = 0, 0
+	}
+
+}
+
+// move moves the body by its speed for a frame, horizontally and then vertically, stopping it on each axis where it's
+// blocked, and then checks what it's touching.
+func (p *PlatformerBody) move() {
+
+	if dx := carry(&p.remX, p.vx); dx != 0 {
+		col := resolveDelta(p.Space, p.Shape, dx, 0)
+		p.Shape.Move(col.ResolveX, 0)
+		if col.Colliding() {
+			p.vx, p.remX = 0, 0
+		}
+	}
+
+	if dy := carry(&p.remY, p.vy); dy != 0 {
+		landed := false
+		if dy > 0 && !p.climbing {
+			dy, landed = p.landOnLadders(dy)
+		}
+		col := resolveDelta(p.Space, p.Shape, 0, dy)
+		p.Shape.Move(0, col.ResolveY)
+		if col.Colliding() || landed {
+			p.vy, p.remY = 0, 0
+		}
+	}
+
+	p.onGround = p.probe(0, 1) || p.onLadderTop()
+
+	p.wall = 0
+	if p.probe(-1, 0) {
+		p.wall = -1
+	} else if p.probe(1, 0) {
+		p.wall = 1
+	}
+
+}
+
+// onLadder returns whether the body's Shape is overlapping a ladder.
+func (p *PlatformerBody) onLadder() bool {
+	ladders := p.Space.OverlappingTagged(p.Shape, p.LadderTag)
+	onLadder := ladders.Length() > 0
+	ladders.Release()
+	return onLadder
+}
+
+// landOnLadders returns how far the body can fall, out of the distance provided, before landing on the top of a ladder,
+// and whether it lands on one.
+func (p *PlatformerBody) landOnLadders(dy int32) (int32, bool) {
+
+	var bounds, ladder Rectangle
+	boundingRectInto(p.Shape, &bounds)
+	bottom := bounds.Y + bounds.H
+
+	// Only the ladders with their tops between the body's bottom and where its bottom falls to can be landed on.
+	swept := NewRectangle(bounds.X, bottom, bounds.W, dy)
+	ladders := p.Space.OverlappingTagged(swept, p.LadderTag)
+	defer ladders.Release()
+
+	landed := false
+	for _, shape := range ladders.shapes {
+		boundingRectInto(shape, &ladder)
+		if ladder.Y >= bottom && ladder.Y-bottom < dy {
+			dy = ladder.Y - bottom
+			landed = true
+		}
+	}
+	return dy, landed
+
+}
+
+// onLadderTop returns whether the body is standing on top of a ladder.
+func (p *PlatformerBody) onLadderTop() bool {
+
+	var bounds, ladder Rectangle
+	boundingRectInto(p.Shape, &bounds)
+	bottom := bounds.Y + bounds.H
+
+	below := NewRectangle(bounds.X, bottom, bounds.W, 1)
+	ladders := p.Space.OverlappingTagged(below, p.LadderTag)
+	defer ladders.Release()
+
+	for _, shape := range ladders.shapes {
+		if boundingRectInto(shape, &ladder); ladder.Y == bottom {
+			return true
+		}
+	}
+	return false
+
+}
+
+// clampUnit clamps v to the range -1 to 1.
+func clampUnit(v float64) float64 {
+	if v < -1 {
+		return -1
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// probe returns whether the body's Shape would be colliding with something in the Space if it moved by the delta
+// provided.
+func (p *PlatformerBody) probe(dx, dy int32) bool {
+	return p.Shape.WouldBeColliding(p.Space, dx, dy)
+}
+
+// OnGround returns whether the body is standing on something, as of the last Update().
+func (p *PlatformerBody) OnGround() bool {
+	return p.onGround
+}
+
+// Climbing returns whether the body is climbing a ladder, as of the last Update() (see UpdateClimbing()).
+func (p *PlatformerBody) Climbing() bool {
+	return p.climbing
+}
+
+// OnWall returns which side the body is touching a wall on, as of the last Update(): -1 for a wall on its left, 1 for a
+// wall on its right, and 0 if it isn't touching one. If it's touching walls on both sides, the left one is reported.
+func (p *PlatformerBody) OnWall() int {
+	return p.wall
+}
+
+// Velocity returns the body's horizontal and vertical speed, in pixels per frame; positive values are right and down.
+func (p *PlatformerBody) Velocity() (float64, float64) {
+	return p.vx, p.vy
+}
+
+// SetVelocity sets the body's horizontal and vertical speed, in pixels per frame (like for knockback). The horizontal speed
+// is replaced by the input on the next Update(), while the vertical speed carries on under gravity.
+func (p *PlatformerBody) SetVelocity(vx, vy float64) {
+	p.vx, p.vy = vx, vy
+}
+
+// carry adds the speed provided to the remainder, returning the whole pixels to move by and leaving the fraction of a
+// pixel in the remainder for the next frame.
+func carry(remainder *float64, speed float64) int32 {
+	*remainder += speed
+	whole := int32(*remainder)
+	*remainder -= float64(whole)
+	return whole
+}
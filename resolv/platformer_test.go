@@ -0,0 +1,275 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+// platformerLevel returns a level with a floor along y = 100 and a ledge 30 pixels above it from x = 64 to 128, and a
+// PlatformerBody standing on the floor to the left of the ledge.
+func platformerLevel() (*Space, *Rectangle, *PlatformerBody) {
+	space := NewSpace()
+	space.Add(
+		NewRectangle(-64, 100, 320, 16),
+		NewRectangle(64, 70, 64, 30),
+	)
+	player := NewRectangle(16, 84, 8, 16)
+	space.Add(player)
+	return space, player, NewPlatformerBody(space, player)
+}
+
+func TestPlatformerJumpOntoLedge(t *testing.T) {
+
+	// simulate runs right and jumps on the first frame, stopping once the body is over the ledge, and records where the
+	// body is each frame.
+	simulate := func() (positions [][2]int32, body *PlatformerBody, player *Rectangle) {
+		_, player, body = platformerLevel()
+		if !body.OnGround() {
+			t.Fatal("the body doesn't start on the ground")
+		}
+		for frame := 0; frame < 90; frame++ {
+			inputX := 1.0
+			if player.X >= 88 {
+				inputX = 0
+			}
+			body.Update(inputX, frame == 0)
+			positions = append(positions, [2]int32{player.X, player.Y})
+			if frame == 0 {
+				if _, vy := body.Velocity(); vy >= 0 || body.OnGround() {
+					t.Fatalf("after jumping, the body has a vertical speed of %v, on the ground = %v", vy, body.OnGround())
+				}
+			}
+		}
+		return positions, body, player
+	}
+
+	positions, body, player := simulate()
+
+	if !body.OnGround() || player.Y+player.H != 70 || player.X < 64 || player.X+player.W > 128 {
+		t.Fatalf("the body ended up at %v, on the ground = %v, want it standing on the ledge", player, body.OnGround())
+	}
+	if vx, vy := body.Velocity(); vx != 0 || vy != 0 {
+		t.Errorf("Velocity() on the ledge = %v, %v, want 0, 0", vx, vy)
+	}
+
+	// Each frame, the body moves by no more than its speeds allow.
+	peak := int32(84)
+	for i := 1; i < len(positions); i++ {
+		dx, dy := positions[i][0]-positions[i-1][0], positions[i][1]-positions[i-1][1]
+		if dx < 0 || dx > 2 || dy < -8 || dy > 8 {
+			t.Fatalf("frame %d: the body moved by %d, %d", i, dx, dy)
+		}
+		if positions[i][1] < peak {
+			peak = positions[i][1]
+		}
+	}
+	if peak > 84-30-16 {
+		t.Errorf("the jump only reached y = %d, not high enough to clear the ledge", peak)
+	}
+
+	// The same inputs always give the same frames.
+	again, _, _ := simulate()
+	for i := range positions {
+		if again[i] != positions[i] {
+			t.Fatalf("frame %d: the body was at %v the first time and %v the second", i, positions[i], again[i])
+		}
+	}
+
+}
+
+func TestPlatformerWallsAndFalling(t *testing.T) {
+
+	_, player, body := platformerLevel()
+
+	// Without jumping, running right stops at the side of the ledge.
+	for frame := 0; frame < 60; frame++ {
+		body.Update(1, false)
+	}
+	if player.X+player.W != 64 || body.OnWall() != 1 || !body.OnGround() {
+		t.Fatalf("running into the ledge left the body at %v, OnWall() = %d, OnGround() = %v", player, body.OnWall(),
+			body.OnGround())
+	}
+	if vx, _ := body.Velocity(); vx != 0 {
+		t.Errorf("Velocity() against the wall = %v horizontally, want 0", vx)
+	}
+	body.Update(-1, false)
+	if body.OnWall() != 0 {
+		t.Errorf("OnWall() after moving away = %d, want 0", body.OnWall())
+	}
+
+	// Dropped from high up, the body falls no faster than MaxFallSpeed, and lands on the floor.
+	player.SetXY(16, -400)
+	body.Update(0, false)
+	for frame := 0; frame < 120 && !body.OnGround(); frame++ {
+		before := player.Y
+		body.Update(0, false)
+		if _, vy := body.Velocity(); vy > body.MaxFallSpeed || player.Y-before > int32(body.MaxFallSpeed) {
+			t.Fatalf("the body fell %d pixels at a speed of %v, more than MaxFallSpeed", player.Y-before, vy)
+		}
+	}
+	if !body.OnGround() || player.Y+player.H != 100 {
+		t.Errorf("the body landed at %v, on the ground = %v, want it on the floor", player, body.OnGround())
+	}
+
+}
+
+func TestPlatformerCoyoteTime(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		jumpFrame  int
+		wantJumped bool
+	}{
+		{"running off the ledge", 0, true},
+		{"just off the ledge", 3, true},
+		{"too late", 8, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			_, player, body := platformerLevel()
+			player.SetXY(120, 54)
+			body.Update(0, false)
+			if !body.OnGround() {
+				t.Fatal("the body doesn't start on the ledge")
+			}
+
+			// Run right until the body is past the ledge's edge, then count frames in the air until jumping.
+			for player.X < 128 {
+				body.Update(1, false)
+			}
+			for frame := 0; frame < tt.jumpFrame; frame++ {
+				body.Update(1, false)
+			}
+			body.Update(1, true)
+
+			if _, vy := body.Velocity(); (vy < 0) != tt.wantJumped {
+				t.Errorf("jumping %d frames after running off the ledge gave a vertical speed of %v, want jumped = %v",
+					tt.jumpFrame, vy, tt.wantJumped)
+			}
+
+			// Coyote time can only be used once: jumping again in mid-air does nothing.
+			if tt.wantJumped {
+				body.Update(1, false)
+				_, before := body.Velocity()
+				body.Update(1, true)
+				if _, vy := body.Velocity(); vy != before+body.Gravity {
+					t.Errorf("jumping again in mid-air changed the vertical speed from %v to %v", before, vy)
+				}
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkPlatformerUpdate(b *testing.B) {
+
+	space, _, body := platformerLevel()
+	for i := int32(0); i < 200; i++ {
+		space.Add(NewRectangle(i*32+200, 100-i%4*16, 16, 16))
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		body.Update(1, i%60 == 0)
+	}
+
+}
+
+// ladderLevel returns a level with a floor along y = 100 and a platform above it along y = 40, with a ladder going up
+// from the floor through a gap in the platform, and a PlatformerBody standing on the floor to the left of the ladder.
+func ladderLevel() (*Space, *Rectangle, *Rectangle, *PlatformerBody) {
+	space := NewSpace()
+	ladder := NewRectangle(40, 40, 16, 60, WithTags("ladder"), WithMask(0))
+	space.Add(
+		NewRectangle(-64, 100, 320, 16),
+		NewRectangle(-64, 40, 104, 16),
+		NewRectangle(56, 40, 144, 16),
+		ladder,
+	)
+	player := NewRectangle(20, 84, 8, 16)
+	space.Add(player)
+	return space, ladder, player, NewPlatformerBody(space, player)
+}
+
+func TestPlatformerLadder(t *testing.T) {
+
+	space, ladder, player, body := ladderLevel()
+
+	// Walking past the ladder without pressing up or down doesn't grab it.
+	for player.X < 44 {
+		body.UpdateClimbing(1, 0, false)
+		if body.Climbing() {
+			t.Fatalf("walking past the ladder grabbed it, at %v", player)
+		}
+	}
+
+	// Climbing up the ladder, the body never clips into the platform around its top, climbs no faster than
+	// ClimbSpeed, and gets off at the top, landing on the ladder's top level with the platform.
+	grabbed := false
+	for frame := 0; frame < 120; frame++ {
+		before := player.Y
+		body.UpdateClimbing(0, -1, false)
+		grabbed = grabbed || body.Climbing()
+		if body.Climbing() && float64(before-player.Y) > math.Ceil(body.ClimbSpeed) {
+			t.Fatalf("frame %d: the body climbed %d pixels at a ClimbSpeed of %v", frame, before-player.Y,
+				body.ClimbSpeed)
+		}
+		if blocking := space.Filter(func(shape Shape) bool {
+			return shape != player && shape != ladder && player.IsColliding(shape)
+		}); blocking.Length() > 0 {
+			t.Fatalf("frame %d: the body at %v clipped into %v", frame, player, blocking.Shapes())
+		}
+	}
+	if !grabbed {
+		t.Fatal("pressing up on the ladder didn't grab it")
+	}
+	if body.Climbing() || !body.OnGround() || player.Y+player.H != 40 {
+		t.Fatalf("after climbing, the body is at %v, climbing = %v, on the ground = %v, want it on the ladder's top",
+			player, body.Climbing(), body.OnGround())
+	}
+
+	// Holding up on the ladder's top doesn't grab it again; letting go and walking off it onto the platform works.
+	for frame := 0; frame < 10; frame++ {
+		body.UpdateClimbing(0, -1, false)
+		if body.Climbing() || player.Y+player.H != 40 {
+			t.Fatalf("frame %d: holding up on top of the ladder moved the body to %v, climbing = %v", frame, player,
+				body.Climbing())
+		}
+	}
+	for player.X < 70 {
+		body.UpdateClimbing(1, 0, false)
+	}
+	if !body.OnGround() || player.Y+player.H != 40 {
+		t.Fatalf("walking off the ladder's top left the body at %v", player)
+	}
+
+	// Pressing down on the ladder's top grabs it, and climbing down onto the floor lets go of it.
+	player.SetXY(44, 24)
+	body.UpdateClimbing(0, 0, false)
+	body.UpdateClimbing(0, 1, false)
+	if !body.Climbing() {
+		t.Fatal("pressing down on top of the ladder didn't grab it")
+	}
+	for frame := 0; frame < 120 && body.Climbing(); frame++ {
+		body.UpdateClimbing(0, 1, false)
+	}
+	if body.Climbing() || !body.OnGround() || player.Y+player.H != 100 {
+		t.Errorf("after climbing down, the body is at %v, climbing = %v, on the ground = %v", player, body.Climbing(),
+			body.OnGround())
+	}
+
+	// Jumping lets go of the ladder.
+	body.UpdateClimbing(0, -1, false)
+	body.UpdateClimbing(0, -1, false)
+	if !body.Climbing() {
+		t.Fatal("pressing up at the foot of the ladder didn't grab it")
+	}
+	body.UpdateClimbing(0, 0, true)
+	if _, vy := body.Velocity(); body.Climbing() || vy >= 0 {
+		t.Errorf("jumping off the ladder left climbing = %v, with a vertical speed of %v", body.Climbing(), vy)
+	}
+
+}
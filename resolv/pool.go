@@ -0,0 +1,65 @@
+package resolv
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	pooling    int32
+	resultPool = sync.Pool{
+		New: func() interface{} {
+			return NewSpace()
+		},
+	}
+)
+
+// EnablePooling sets whether the Spaces returned by the query functions on Space (like Filter(), FilterByTags(),
+// GetCollidingShapes(), and Cull()) are taken from a pool of Spaces rather than newly allocated. Once done with a Space
+// from a query, call Release() on it to return it to the pool, so that later queries reuse it rather than allocating;
+// Spaces that aren't released are simply garbage collected, like with pooling off. Pooling is off by default, so that
+// code keeping the results of queries around keeps working.
+// With pooling on, a released Space mustn't be used anymore, as it'll be handed out again by a later query. To avoid the
+// pool altogether, see GetCollidingShapesInto() and FilterInto(), which reuse a Space of your own.
+func EnablePooling(enable bool) {
+	var value int32
+	if enable {
+		value = 1
+	}
+	atomic.StoreInt32(&pooling, value)
+}
+
+// newResultSpace returns an empty Space for the results of a query, taken from the pool if pooling is on (see
+// EnablePooling()).
+func newResultSpace() *Space {
+	var sp *Space
+	if atomic.LoadInt32(&pooling) == 0 {
+		sp = NewSpace()
+	} else {
+		sp = resultPool.Get().(*Space)
+		sp.pooled = true
+	}
+	sp.result = true
+	return sp
+}
+
+// Release returns a Space returned by a query (like Filter() or GetCollidingShapes()) to the pool it was taken from when
+// pooling is on (see EnablePooling()), so that it can be reused by a later query. The Space mustn't be used once it's
+// released. Releasing any other Space, or a Space that was already released, does nothing, so it's always safe to release
+// the results of queries once done with them.
+func (sp *Space) Release() {
+
+	if !sp.pooled {
+		return
+	}
+
+	// The Space is emptied, and anything set on it since it was handed out (like OnAdd() functions, or the functions of
+	// Spaces it's nested in watching it) is forgotten, so that it's handed out again like a new Space, only without having
+	// to grow its slice of Shapes again. Query results never watch their Shapes (see Version()), but Unwatch() makes sure
+	// none of its Shapes are left holding on to it.
+	sp.Unwatch()
+	sp.truncate()
+	*sp = Space{shapes: sp.shapes}
+	resultPool.Put(sp)
+
+}
@@ -0,0 +1,116 @@
+package resolv
+
+import "testing"
+
+// poolingSpace returns a Space of Rectangles in a row, some of them tagged, along with a Rectangle colliding with the
+// first few of them.
+func poolingSpace() (*Space, *Rectangle) {
+	space := NewSpace()
+	for i := int32(0); i < 64; i++ {
+		var opts []ShapeOption
+		if i%2 == 0 {
+			opts = append(opts, WithTags("solid"))
+		}
+		space.Add(NewRectangle(i*8, 0, 8, 8, opts...))
+	}
+	return space, NewRectangle(4, 4, 24, 8)
+}
+
+func TestReleaseReuse(t *testing.T) {
+
+	EnablePooling(true)
+	defer EnablePooling(false)
+
+	space, probe := poolingSpace()
+
+	// The pool can drop Spaces (like when the garbage collector runs), so a released Space is only usually reused.
+	reused := false
+	for i := 0; i < 100 && !reused; i++ {
+
+		first := space.GetCollidingShapes(probe)
+		if first.Length() != 4 {
+			t.Fatalf("GetCollidingShapes() found %d Shapes, want 4", first.Length())
+		}
+		first.OnAdd(func(Shape) { t.Error("the OnAdd() function of a released Space was called") })
+		first.Version()
+		first.Release()
+
+		second := space.FilterByTags("solid")
+		reused = second == first
+		if second.Length() != 32 || second.root != nil || len(second.onAdd) != 0 {
+			t.Fatalf("FilterByTags() after Release() gave %d Shapes, root %v, and %d OnAdd() functions", second.Length(),
+				second.root, len(second.onAdd))
+		}
+		second.Release()
+
+	}
+	if !reused {
+		t.Error("a released Space was never reused by a later query")
+	}
+
+	// Neither the Shapes in the released Spaces nor the pool hold on to each other.
+	for _, shape := range space.Shapes() {
+		if n := watching(shape.(*Rectangle)); n != 0 {
+			t.Fatalf("%d Spaces are watching %v after their release", n, shape)
+		}
+	}
+
+}
+
+func TestReleaseNotPooled(t *testing.T) {
+
+	space, probe := poolingSpace()
+
+	tests := []struct {
+		name  string
+		space *Space
+		want  int
+	}{
+		{"query with pooling off", space.GetCollidingShapes(probe), 4},
+		{"Space of its own", space, 64},
+		{"query with pooling off, released twice", space.FilterByTags("solid"), 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.space.Release()
+			tt.space.Release()
+			if tt.space.Length() != tt.want {
+				t.Errorf("after Release(), the Space has %d Shapes, want %d", tt.space.Length(), tt.want)
+			}
+		})
+	}
+
+	// Released with pooling on, a Space that wasn't taken from the pool still isn't put in it.
+	EnablePooling(true)
+	defer EnablePooling(false)
+	space.Release()
+	if space.Length() != 64 {
+		t.Errorf("after Release() with pooling on, the Space has %d Shapes, want 64", space.Length())
+	}
+
+}
+
+func BenchmarkPooling(b *testing.B) {
+
+	space, probe := poolingSpace()
+
+	for _, bb := range []struct {
+		name    string
+		pooling bool
+	}{
+		{"unpooled", false},
+		{"pooled", true},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			EnablePooling(bb.pooling)
+			defer EnablePooling(false)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				space.GetCollidingShapes(probe).Release()
+				space.FilterByTags("solid").Release()
+			}
+		})
+	}
+
+}
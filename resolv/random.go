@@ -0,0 +1,50 @@
+package resolv
+
+import "math/rand"
+
+// RandomShape returns a random Shape from the Space, chosen using the random number generator provided, or nil if the
+// Space is empty.
+func (sp *Space) RandomShape(rng *rand.Rand) Shape {
+	if len(sp.shapes) == 0 {
+		return nil
+	}
+	return sp.shapes[rng.Intn(len(sp.shapes))]
+}
+
+// FindFreePosition tries up to maxAttempts random positions for the Shape provided, such that its bounding rectangle is
+// within the region provided, and returns the first position at which it doesn't collide with anything in the Space
+// (useful for spawning pickups, for example). If a position is found, the Shape is moved there and ok is true. If no free
+// position is found (or the Shape doesn't fit within the region), the Shape is left where it was and ok is false. The
+// random number generator is provided, so the results can be made deterministic.
+func (sp *Space) FindFreePosition(rng *rand.Rand, within *Rectangle, shape Shape, maxAttempts int) (x, y int32, ok bool) {
+
+	bounds := shapeBoundingRect(shape)
+	spanX := within.W - bounds.W
+	spanY := within.H - bounds.H
+
+	if spanX < 0 || spanY < 0 {
+		return 0, 0, false
+	}
+
+	startX, startY := shape.GetXY()
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+
+		dx := within.X + rng.Int31n(spanX+1) - bounds.X
+		dy := within.Y + rng.Int31n(spanY+1) - bounds.Y
+
+		shape.Move(dx, dy)
+		colliding := sp.IsColliding(shape)
+
+		if !colliding {
+			x, y = shape.GetXY()
+			return x, y, true
+		}
+
+		shape.SetXY(startX, startY)
+
+	}
+
+	return 0, 0, false
+
+}
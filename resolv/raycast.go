@@ -0,0 +1,116 @@
+package resolv
+
+import "sort"
+
+// Ray is a ray cast from X, Y to X2, Y2, like a bullet's path; see Space.Raycast().
+type Ray struct {
+	X, Y, X2, Y2 int32
+}
+
+// RaycastHit is a Shape hit by a Ray, along with the point where the Ray first reaches it, and how far along the Ray that
+// point is.
+type RaycastHit struct {
+	Shape    Shape
+	X, Y     int32
+	Distance float64
+}
+
+// Raycast returns the first Shape in the Space with all of the tags provided that the Ray hits, and whether it hit one at
+// all. If no tags are provided, any Shape can be hit. Shapes that contain the Ray's start (like the shooter's own Shape)
+// aren't hit. If the Ray reaches multiple Shapes at the same point, the first one in the Space is returned.
+func (sp *Space) Raycast(ray Ray, tags ...string) (RaycastHit, bool) {
+	hits := sp.raycastHits(ray, func(shape Shape) bool { return shape.HasTags(tags...) })
+	if len(hits) == 0 {
+		return RaycastHit{}, false
+	}
+	return hits[0], true
+}
+
+// RaycastPiercing casts a Ray that pierces through Shapes (like a railgun), returning the Shapes it hits in order along
+// it. The Ray hits up to maxPierces Shapes with any of the pierce tags (like enemies), stopping at the last of them, and
+// stops at the first Shape with any of the stop tags (like a wall) before that; the Shape it stops at is the last hit
+// returned, and nothing past it is. With a maxPierces of 0, the Ray stops short of the first Shape with the pierce tags,
+// without hitting it. Shapes with neither (like decorations) are hit, but don't count against maxPierces. A Shape with
+// both stop and pierce tags stops the Ray. If maxPierces is negative, the Ray pierces through any number of Shapes with
+// the pierce tags. Like with Raycast(), Shapes that contain the Ray's start aren't hit.
+func (sp *Space) RaycastPiercing(ray Ray, maxPierces int, stopTags []string, pierceTags []string) []RaycastHit {
+
+	hits := sp.raycastHits(ray, nil)
+
+	pierced := 0
+	for i, hit := range hits {
+		if hasAnyTags(hit.Shape, stopTags...) {
+			return hits[:i+1]
+		}
+		if hasAnyTags(hit.Shape, pierceTags...) {
+			if maxPierces >= 0 && pierced >= maxPierces {
+				return hits[:i]
+			}
+			pierced++
+			if pierced == maxPierces {
+				return hits[:i+1]
+			}
+		}
+	}
+
+	return hits
+
+}
+
+// raycastHits returns the Shapes in the Space that the Ray hits (and that the filter function returns true for, if it
+// isn't nil), sorted by how far along the Ray they're hit, and then by their order in the Space.
+func (sp *Space) raycastHits(ray Ray, filter func(Shape) bool) []RaycastHit {
+
+	start := NewRectangle(ray.X, ray.Y, 1, 1)
+
+	var hits []RaycastHit
+	for _, shape := range sp.shapes {
+		if filter != nil && !filter(shape) || start.IsColliding(shape) {
+			continue
+		}
+		if x, y, ok := firstContact(shape, ray.X, ray.Y, ray.X2, ray.Y2); ok {
+			hits = append(hits, RaycastHit{Shape: shape, X: x, Y: y, Distance: Distance64(ray.X, ray.Y, x, y)})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Distance < hits[j].Distance })
+	return hits
+
+}
+
+// firstContact returns the first point along the line from x, y to x2, y2 where it reaches the Shape provided, and
+// whether it reaches it at all.
+func firstContact(shape Shape, x, y, x2, y2 int32) (int32, int32, bool) {
+
+	segment := NewLine(x, y, x2, y2)
+	// The Shape tests against the line (rather than the other way around), as the Circle-Line test is implemented on the
+	// Circle's side.
+	if !shape.IsColliding(segment) {
+		return 0, 0, false
+	}
+
+	dx, dy := x2-x, y2-y
+	n := absInt64(int64(dx))
+	if m := absInt64(int64(dy)); m > n {
+		n = m
+	}
+
+	// The shortest part of the line that reaches the Shape is found by bisection, as a longer part reaches it if a
+	// shorter one does.
+	low, high := int64(0), n
+	for low < high {
+		mid := low + (high-low)/2
+		segment.SetEndpoints(x, y, x+stepAlong(dx, mid, n), y+stepAlong(dy, mid, n))
+		if shape.IsColliding(segment) {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	if n == 0 {
+		return x, y, true
+	}
+	return x + stepAlong(dx, low, n), y + stepAlong(dy, low, n), true
+
+}
@@ -0,0 +1,107 @@
+package resolv
+
+import "testing"
+
+// raycastRow returns a Space with Shapes in a row along the X axis, tagged as provided, 20 pixels apart starting from
+// X = 20.
+func raycastRow(tags ...string) (*Space, []Shape) {
+	space := NewSpace()
+	var shapes []Shape
+	for i, tag := range tags {
+		shape := NewRectangle(int32(i+1)*20, 0, 8, 8, WithTags(tag))
+		shapes = append(shapes, shape)
+		space.Add(shape)
+	}
+	return space, shapes
+}
+
+func TestRaycastPiercing(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		row        []string
+		maxPierces int
+		want       []int
+	}{
+		{"interleaved, stops at wall", []string{"enemy", "deco", "enemy", "wall", "enemy"}, 5, []int{0, 1, 2, 3}},
+		{"interleaved, budget spent before wall", []string{"enemy", "deco", "enemy", "wall", "enemy"}, 1, []int{0}},
+		{"two of three enemies", []string{"enemy", "enemy", "deco", "enemy"}, 2, []int{0, 1}},
+		{"no pierces", []string{"deco", "enemy", "wall"}, 0, []int{0}},
+		{"unlimited", []string{"enemy", "enemy", "enemy"}, -1, []int{0, 1, 2}},
+		{"wall first", []string{"wall", "enemy"}, 3, []int{0}},
+		{"nothing hit", nil, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space, shapes := raycastRow(tt.row...)
+			hits := space.RaycastPiercing(Ray{0, 4, 200, 4}, tt.maxPierces, []string{"wall"}, []string{"enemy"})
+
+			if len(hits) != len(tt.want) {
+				t.Fatalf("RaycastPiercing() hit %d shapes, want %d", len(hits), len(tt.want))
+			}
+			for i, hit := range hits {
+				if hit.Shape != shapes[tt.want[i]] {
+					t.Errorf("hit %d is %v, want %v", i, hit.Shape, shapes[tt.want[i]])
+				}
+				if i > 0 && hit.Distance < hits[i-1].Distance {
+					t.Errorf("hit %d is nearer than the hit before it", i)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestRaycast(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		ray     Ray
+		tags    []string
+		wantHit int
+		wantX   int32
+	}{
+		{"first shape", Ray{0, 4, 200, 4}, nil, 0, 20},
+		{"tagged", Ray{0, 4, 200, 4}, []string{"wall"}, 1, 40},
+		{"backwards", Ray{100, 4, 0, 4}, nil, 2, 67},
+		{"starting inside", Ray{22, 4, 200, 4}, nil, 1, 40},
+		{"missing", Ray{0, 40, 200, 40}, nil, -1, 0},
+	}
+
+	space, shapes := raycastRow("enemy", "wall", "enemy")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, ok := space.Raycast(tt.ray, tt.tags...)
+			if tt.wantHit < 0 {
+				if ok {
+					t.Fatalf("Raycast() hit %v", hit.Shape)
+				}
+				return
+			}
+			if !ok || hit.Shape != shapes[tt.wantHit] || hit.X != tt.wantX {
+				t.Errorf("Raycast() = %v at %d, want %v at %d", hit.Shape, hit.X, shapes[tt.wantHit], tt.wantX)
+			}
+		})
+	}
+
+}
+
+func BenchmarkRaycastPiercing(b *testing.B) {
+
+	tags := make([]string, 100)
+	for i := range tags {
+		tags[i] = "enemy"
+	}
+	space, _ := raycastRow(tags...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		space.RaycastPiercing(Ray{0, 4, 2100, 4}, 10, []string{"wall"}, []string{"enemy"})
+	}
+
+}
@@ -0,0 +1,269 @@
+package resolv
+
+// ReadOnlyShape is a view of a Shape that can only be queried, not changed: it has the Shape's getters and collision
+// tests, but nothing that moves the Shape or changes its tags, Data, layers, etc. Use AsReadOnly() to get one, and
+// Space.ReadOnly() to hand out query access to a whole Space (like to AI code that shouldn't be moving things around).
+// A ReadOnlyShape always reflects the Shape's current state.
+type ReadOnlyShape interface {
+	IsColliding(Shape) bool
+	WouldBeColliding(Shape, int32, int32) bool
+	GetTags() []string
+	HasTags(...string) bool
+	HasAnyTags(...string) bool
+	HasTagMatching(string) bool
+	GetData() interface{}
+	GetXY() (int32, int32)
+	GetLayer() uint32
+	GetMask() uint32
+	GetBoundingRect() *Rectangle
+	GetElevation() int32
+}
+
+// AsReadOnly returns a read-only view of the Shape provided, or nil if it's nil. The view only has the functions of
+// ReadOnlyShape, so the Shape can't be gotten back from it with a type assertion. Views of the built-in Shapes don't
+// allocate; a Space's view is its ReadOnlySpace (see Space.ReadOnly()).
+func AsReadOnly(shape Shape) ReadOnlyShape {
+	switch s := shape.(type) {
+	case nil:
+		return nil
+	case *Rectangle:
+		return readOnlyRectangle{s}
+	case *Circle:
+		return readOnlyCircle{s}
+	case *Line:
+		return readOnlyLine{s}
+	case *Space:
+		return s.ReadOnly()
+	}
+	return readOnlyShape{shape}
+}
+
+// The views of the built-in Shapes each only hold a pointer, so that they can be stored in a ReadOnlyShape without
+// allocating.
+
+type readOnlyRectangle struct{ s *Rectangle }
+
+func (r readOnlyRectangle) IsColliding(other Shape) bool { return r.s.IsColliding(other) }
+func (r readOnlyRectangle) WouldBeColliding(other Shape, dx, dy int32) bool {
+	return r.s.WouldBeColliding(other, dx, dy)
+}
+func (r readOnlyRectangle) GetTags() []string                  { return r.s.GetTags() }
+func (r readOnlyRectangle) HasTags(tags ...string) bool        { return r.s.HasTags(tags...) }
+func (r readOnlyRectangle) HasAnyTags(tags ...string) bool     { return r.s.HasAnyTags(tags...) }
+func (r readOnlyRectangle) HasTagMatching(pattern string) bool { return r.s.HasTagMatching(pattern) }
+func (r readOnlyRectangle) GetData() interface{}               { return r.s.GetData() }
+func (r readOnlyRectangle) GetXY() (int32, int32)              { return r.s.GetXY() }
+func (r readOnlyRectangle) GetLayer() uint32                   { return r.s.GetLayer() }
+func (r readOnlyRectangle) GetMask() uint32                    { return r.s.GetMask() }
+func (r readOnlyRectangle) GetBoundingRect() *Rectangle        { return r.s.GetBoundingRect() }
+func (r readOnlyRectangle) GetElevation() int32                { return r.s.GetElevation() }
+func (r readOnlyRectangle) String() string                     { return r.s.String() }
+
+type readOnlyCircle struct{ s *Circle }
+
+func (c readOnlyCircle) IsColliding(other Shape) bool { return c.s.IsColliding(other) }
+func (c readOnlyCircle) WouldBeColliding(other Shape, dx, dy int32) bool {
+	return c.s.WouldBeColliding(other, dx, dy)
+}
+func (c readOnlyCircle) GetTags() []string                  { return c.s.GetTags() }
+func (c readOnlyCircle) HasTags(tags ...string) bool        { return c.s.HasTags(tags...) }
+func (c readOnlyCircle) HasAnyTags(tags ...string) bool     { return c.s.HasAnyTags(tags...) }
+func (c readOnlyCircle) HasTagMatching(pattern string) bool { return c.s.HasTagMatching(pattern) }
+func (c readOnlyCircle) GetData() interface{}               { return c.s.GetData() }
+func (c readOnlyCircle) GetXY() (int32, int32)              { return c.s.GetXY() }
+func (c readOnlyCircle) GetLayer() uint32                   { return c.s.GetLayer() }
+func (c readOnlyCircle) GetMask() uint32                    { return c.s.GetMask() }
+func (c readOnlyCircle) GetBoundingRect() *Rectangle        { return c.s.GetBoundingRect() }
+func (c readOnlyCircle) GetElevation() int32                { return c.s.GetElevation() }
+func (c readOnlyCircle) String() string                     { return c.s.String() }
+
+type readOnlyLine struct{ s *Line }
+
+func (l readOnlyLine) IsColliding(other Shape) bool { return l.s.IsColliding(other) }
+func (l readOnlyLine) WouldBeColliding(other Shape, dx, dy int32) bool {
+	return l.s.WouldBeColliding(other, dx, dy)
+}
+func (l readOnlyLine) GetTags() []string                  { return l.s.GetTags() }
+func (l readOnlyLine) HasTags(tags ...string) bool        { return l.s.HasTags(tags...) }
+func (l readOnlyLine) HasAnyTags(tags ...string) bool     { return l.s.HasAnyTags(tags...) }
+func (l readOnlyLine) HasTagMatching(pattern string) bool { return l.s.HasTagMatching(pattern) }
+func (l readOnlyLine) GetData() interface{}               { return l.s.GetData() }
+func (l readOnlyLine) GetXY() (int32, int32)              { return l.s.GetXY() }
+func (l readOnlyLine) GetLayer() uint32                   { return l.s.GetLayer() }
+func (l readOnlyLine) GetMask() uint32                    { return l.s.GetMask() }
+func (l readOnlyLine) GetBoundingRect() *Rectangle        { return l.s.GetBoundingRect() }
+func (l readOnlyLine) GetElevation() int32                { return l.s.GetElevation() }
+func (l readOnlyLine) String() string                     { return l.s.String() }
+
+// readOnlyShape is the view of custom Shapes.
+type readOnlyShape struct{ s Shape }
+
+func (r readOnlyShape) IsColliding(other Shape) bool { return r.s.IsColliding(other) }
+func (r readOnlyShape) WouldBeColliding(other Shape, dx, dy int32) bool {
+	return r.s.WouldBeColliding(other, dx, dy)
+}
+func (r readOnlyShape) GetTags() []string              { return r.s.GetTags() }
+func (r readOnlyShape) HasTags(tags ...string) bool    { return r.s.HasTags(tags...) }
+func (r readOnlyShape) HasAnyTags(tags ...string) bool { return hasAnyTags(r.s, tags...) }
+func (r readOnlyShape) HasTagMatching(pattern string) bool {
+	return hasTagMatching(r.s, compileTagPattern(pattern))
+}
+func (r readOnlyShape) GetData() interface{}        { return r.s.GetData() }
+func (r readOnlyShape) GetXY() (int32, int32)       { return r.s.GetXY() }
+func (r readOnlyShape) GetLayer() uint32            { return shapeLayer(r.s) }
+func (r readOnlyShape) GetMask() uint32             { return shapeMask(r.s) }
+func (r readOnlyShape) GetBoundingRect() *Rectangle { return shapeBoundingRect(r.s) }
+func (r readOnlyShape) GetElevation() int32         { return shapeElevation(r.s) }
+
+// ReadOnlySpace is a read-only view of a Space, created with Space.ReadOnly(). It only has functions that query the
+// Space, and the Shapes it returns are ReadOnlyShapes, so neither the Space nor its Shapes can be changed through it.
+// It's a view rather than a copy, so it always reflects the Space's current Shapes, as changed through the Space itself.
+// Spaces returned by its queries (like GetCollidingShapes()) are new Spaces, like with Space, but handed out as
+// ReadOnlySpaces as well. A ReadOnlySpace is also the ReadOnlyShape of its Space (see AsReadOnly()).
+type ReadOnlySpace struct {
+	sp *Space
+}
+
+// ReadOnly returns a read-only view of the Space. See ReadOnlySpace.
+func (sp *Space) ReadOnly() ReadOnlySpace {
+	return ReadOnlySpace{sp}
+}
+
+// Length returns the number of Shapes in the Space. See Space.Length().
+func (ro ReadOnlySpace) Length() int {
+	return ro.sp.Length()
+}
+
+// Get returns the Shape at the index provided. See Space.Get().
+func (ro ReadOnlySpace) Get(index int) ReadOnlyShape {
+	return AsReadOnly(ro.sp.Get(index))
+}
+
+// ForEach calls the function provided for each Shape in the Space, in order, stopping early if the function returns
+// false. See Space.ForEach().
+func (ro ReadOnlySpace) ForEach(forEach func(ReadOnlyShape) bool) {
+	ro.sp.ForEach(func(shape Shape) bool {
+		return forEach(AsReadOnly(shape))
+	})
+}
+
+// GetByID returns the Shape in the Space with the ID provided, or nil if there's no such Shape. See Space.GetByID().
+func (ro ReadOnlySpace) GetByID(id uint64) ReadOnlyShape {
+	return AsReadOnly(ro.sp.GetByID(id))
+}
+
+// GetByName returns the Shape in the Space with the name provided, or nil if there isn't one. See Space.GetByName().
+func (ro ReadOnlySpace) GetByName(name string) ReadOnlyShape {
+	return AsReadOnly(ro.sp.GetByName(name))
+}
+
+// Contains returns true if the Shape provided is in the Space. See Space.Contains().
+func (ro ReadOnlySpace) Contains(shape Shape) bool {
+	return ro.sp.Contains(shape)
+}
+
+// IsColliding returns whether the Shape provided is colliding with something in the Space. See Space.IsColliding().
+func (ro ReadOnlySpace) IsColliding(shape Shape) bool {
+	return ro.sp.IsColliding(shape)
+}
+
+// WouldBeColliding returns whether the Shape provided would be colliding with something in the Space if it moved by dx
+// and dy. See Space.WouldBeColliding().
+func (ro ReadOnlySpace) WouldBeColliding(shape Shape, dx, dy int32) bool {
+	return ro.sp.WouldBeColliding(shape, dx, dy)
+}
+
+// GetCollidingShapes returns the Shapes in the Space colliding with the Shape provided. See Space.GetCollidingShapes().
+func (ro ReadOnlySpace) GetCollidingShapes(shape Shape) ReadOnlySpace {
+	return ro.sp.GetCollidingShapes(shape).ReadOnly()
+}
+
+// LineOfSight returns true if there's a clear line of sight between the centers of the from and to Shapes. See
+// Space.LineOfSight().
+func (ro ReadOnlySpace) LineOfSight(from, to Shape, blockerTags ...string) bool {
+	return ro.sp.LineOfSight(from, to, blockerTags...)
+}
+
+// LineOfSightXY returns true if there's a clear line of sight between the two points provided. See
+// Space.LineOfSightXY().
+func (ro ReadOnlySpace) LineOfSightXY(x, y, x2, y2 int32, blockerTags ...string) bool {
+	return ro.sp.LineOfSightXY(x, y, x2, y2, blockerTags...)
+}
+
+// Filter returns the Shapes in the Space for which the function provided returns true. See Space.Filter().
+func (ro ReadOnlySpace) Filter(filterFunc func(ReadOnlyShape) bool) ReadOnlySpace {
+	return ro.sp.Filter(func(shape Shape) bool {
+		return filterFunc(AsReadOnly(shape))
+	}).ReadOnly()
+}
+
+// FilterByTags returns the Shapes in the Space that have all of the tags provided. See Space.FilterByTags().
+func (ro ReadOnlySpace) FilterByTags(tags ...string) ReadOnlySpace {
+	return ro.sp.FilterByTags(tags...).ReadOnly()
+}
+
+// FilterByAnyTags returns the Shapes in the Space that have any of the tags provided. See Space.FilterByAnyTags().
+func (ro ReadOnlySpace) FilterByAnyTags(tags ...string) ReadOnlySpace {
+	return ro.sp.FilterByAnyTags(tags...).ReadOnly()
+}
+
+// CountByTags returns the number of Shapes in the Space that have all of the tags provided. See Space.CountByTags().
+func (ro ReadOnlySpace) CountByTags(tags ...string) int {
+	return ro.sp.CountByTags(tags...)
+}
+
+// GetTags returns the tags of the Space's root Shape. See Space.GetTags().
+func (ro ReadOnlySpace) GetTags() []string {
+	return ro.sp.GetTags()
+}
+
+// HasTags returns true if all of the Shapes in the Space have the tags provided. See Space.HasTags().
+func (ro ReadOnlySpace) HasTags(tags ...string) bool {
+	return ro.sp.HasTags(tags...)
+}
+
+// HasAnyTags returns true if any of the Shapes in the Space has any of the tags provided. See Space.HasAnyTags().
+func (ro ReadOnlySpace) HasAnyTags(tags ...string) bool {
+	return ro.sp.HasAnyTags(tags...)
+}
+
+// HasTagMatching returns true if all of the Shapes in the Space have a tag matching the pattern provided. See
+// Space.HasTagMatching().
+func (ro ReadOnlySpace) HasTagMatching(pattern string) bool {
+	return ro.sp.HasTagMatching(pattern)
+}
+
+// GetData returns the Data of the Space's root Shape. See Space.GetData().
+func (ro ReadOnlySpace) GetData() interface{} {
+	return ro.sp.GetData()
+}
+
+// GetXY returns the position of the Space's root Shape. See Space.GetXY().
+func (ro ReadOnlySpace) GetXY() (int32, int32) {
+	return ro.sp.GetXY()
+}
+
+// GetLayer returns the layer of the Space's root Shape. See Space.GetLayer().
+func (ro ReadOnlySpace) GetLayer() uint32 {
+	return ro.sp.GetLayer()
+}
+
+// GetMask returns the mask of the Space's root Shape. See Space.GetMask().
+func (ro ReadOnlySpace) GetMask() uint32 {
+	return ro.sp.GetMask()
+}
+
+// GetElevation returns the elevation of the Space's root Shape. See Space.GetElevation().
+func (ro ReadOnlySpace) GetElevation() int32 {
+	return ro.sp.GetElevation()
+}
+
+// GetBoundingRect returns a Rectangle wholly containing the Shapes in the Space. See Space.GetBoundingRect().
+func (ro ReadOnlySpace) GetBoundingRect() *Rectangle {
+	return ro.sp.GetBoundingRect()
+}
+
+// String returns a description of the Space. See Space.String().
+func (ro ReadOnlySpace) String() string {
+	return ro.sp.String()
+}
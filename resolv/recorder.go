@@ -0,0 +1,416 @@
+package resolv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// recordedShape is a Shape as written by a Recorder. Coords holds X, Y, W, and H for Rectangles, X, Y, and Radius for
+// Circles, and X, Y, X2, and Y2 for Lines.
+type recordedShape struct {
+	Type   string   `json:"type"`
+	ID     uint64   `json:"id"`
+	Coords []int32  `json:"coords"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+type recordedCollision struct {
+	ResolveX    int32  `json:"resolveX"`
+	ResolveY    int32  `json:"resolveY"`
+	Teleporting bool   `json:"teleporting,omitempty"`
+	ShapeB      uint64 `json:"shapeB,omitempty"`
+}
+
+// record is a single operation written by a Recorder, one per line. Op is "add", "remove", "move", "tags", "clear", or
+// "resolve".
+type record struct {
+	Op     string             `json:"op"`
+	Shape  *recordedShape     `json:"shape,omitempty"`
+	ID     uint64             `json:"id,omitempty"`
+	Coords []int32            `json:"coords,omitempty"`
+	Tags   []string           `json:"tags,omitempty"`
+	DX     int32              `json:"dx,omitempty"`
+	DY     int32              `json:"dy,omitempty"`
+	Result *recordedCollision `json:"result,omitempty"`
+}
+
+// recordShape returns the Shape as written by a Recorder, or nil if it isn't one of the Shapes a Recorder can record.
+func recordShape(shape Shape) *recordedShape {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return &recordedShape{"rect", s.ID(), []int32{s.X, s.Y, s.W, s.H}, s.tags.Strings()}
+	case *Circle:
+		return &recordedShape{"circle", s.ID(), []int32{s.X, s.Y, s.Radius}, s.tags.Strings()}
+	case *Line:
+		return &recordedShape{"line", s.ID(), []int32{s.X, s.Y, s.X2, s.Y2}, s.tags.Strings()}
+	}
+	return nil
+}
+
+// shape creates the Shape described by the recordedShape.
+func (rs *recordedShape) shape() (Shape, error) {
+
+	counts := map[string]int{"rect": 4, "circle": 3, "line": 4}
+	if count, known := counts[rs.Type]; !known || len(rs.Coords) != count {
+		return nil, fmt.Errorf("malformed %q shape", rs.Type)
+	}
+
+	c := rs.Coords
+	var shape Shape
+	switch rs.Type {
+	case "rect":
+		shape = &Rectangle{BasicShape: BasicShape{X: c[0], Y: c[1]}, W: c[2], H: c[3]}
+	case "circle":
+		shape = &Circle{BasicShape: BasicShape{X: c[0], Y: c[1]}, Radius: c[2]}
+	case "line":
+		line := &Line{BasicShape: BasicShape{X: c[0], Y: c[1]}, X2: c[2], Y2: c[3]}
+		line.cacheLength()
+		shape = line
+	}
+	shape.(interface{ SetID(uint64) }).SetID(rs.ID)
+	if len(rs.Tags) > 0 {
+		shape.AddTags(rs.Tags...)
+	}
+	return shape, nil
+
+}
+
+// position returns the position of the recordedShape, as shapePosition() would return it.
+func (rs *recordedShape) position() []int32 {
+	if rs.Type == "line" || len(rs.Coords) < 2 {
+		return rs.Coords
+	}
+	return rs.Coords[:2]
+}
+
+// shapePosition returns the position of one of the Shapes a Recorder can record: X and Y, plus X2 and Y2 for Lines.
+func shapePosition(shape Shape) []int32 {
+	if line, ok := shape.(*Line); ok {
+		return []int32{line.X, line.Y, line.X2, line.Y2}
+	}
+	x, y := shape.GetXY()
+	return []int32{x, y}
+}
+
+// setShapePosition sets the position of the Shape to one returned by shapePosition().
+func setShapePosition(shape Shape, position []int32) error {
+	if line, ok := shape.(*Line); ok && len(position) == 4 {
+		line.X, line.Y, line.X2, line.Y2 = position[0], position[1], position[2], position[3]
+		line.cacheLength()
+		return nil
+	}
+	if len(position) != 2 {
+		return fmt.Errorf("malformed position %v", position)
+	}
+	shape.SetXY(position[0], position[1])
+	return nil
+}
+
+// trackedShape is a Shape in a recorded Space as it was last recorded.
+type trackedShape struct {
+	position []int32
+	tags     []string
+}
+
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func samePosition(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Recorder records the operations done on a Space, so that they can be checked against with Replay() later, like to
+// find where two runs of a lockstep game diverged. Attach it to a Space with Space.SetRecorder(). It records every
+// Shape added to or removed from the Space (including the Space being cleared), every call to Space.Resolve() along with
+// its result, and the Shapes in the Space that moved or had their tags changed since the last recorded operation (as
+// Shapes don't tell their Space when they change, the Recorder checks for changes before recording each operation, which
+// takes time proportional to the number of Shapes in the Space).
+// Shapes are identified by their IDs (see BasicShape.ID()). Only Rectangles, Circles, and Lines can be recorded; other
+// Shapes, like nested Spaces, make the Recorder fail (see Err()).
+// Operations are written one per line as JSON objects, as they happen.
+type Recorder struct {
+	w       *bufio.Writer
+	enc     *json.Encoder
+	space   *Space
+	tracked map[Shape]trackedShape
+	err     error
+}
+
+// NewRecorder returns a pointer to a new Recorder writing to the Writer provided.
+func NewRecorder(w io.Writer) *Recorder {
+	bw := bufio.NewWriter(w)
+	return &Recorder{w: bw, enc: json.NewEncoder(bw), tracked: map[Shape]trackedShape{}}
+}
+
+// Err returns the first error the Recorder ran into, like failing to write or being asked to record a Shape it can't, or
+// nil if there wasn't one. Once it runs into an error, the Recorder stops recording.
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+// Flush writes any operations the Recorder has buffered to its Writer, returning the Recorder's error, if any.
+func (r *Recorder) Flush() error {
+	if r.err == nil {
+		if err := r.w.Flush(); err != nil {
+			r.err = err
+		}
+	}
+	return r.err
+}
+
+func (r *Recorder) write(rec record) {
+	if r.err == nil {
+		if err := r.enc.Encode(rec); err != nil {
+			r.err = fmt.Errorf("resolv: can't record %s: %w", rec.Op, err)
+		}
+	}
+}
+
+// sync records the Shapes in the Space that moved or had their tags changed since they were last recorded.
+func (r *Recorder) sync() {
+	for _, shape := range r.space.shapes {
+		last, ok := r.tracked[shape]
+		if !ok {
+			continue
+		}
+		if position := shapePosition(shape); !samePosition(position, last.position) {
+			last.position = position
+			r.write(record{Op: "move", ID: recordShape(shape).ID, Coords: position})
+		}
+		if tags := shape.GetTags(); !sameTags(tags, last.tags) {
+			last.tags = tags
+			r.write(record{Op: "tags", ID: recordShape(shape).ID, Tags: tags})
+		}
+		r.tracked[shape] = last
+	}
+}
+
+func (r *Recorder) add(shape Shape) {
+	rs := recordShape(shape)
+	if rs == nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("resolv: can't record adding %T to a Space", shape)
+		}
+		return
+	}
+	r.sync()
+	r.tracked[shape] = trackedShape{shapePosition(shape), rs.Tags}
+	r.write(record{Op: "add", Shape: rs})
+}
+
+func (r *Recorder) remove(shape Shape) {
+	if _, ok := r.tracked[shape]; !ok {
+		return
+	}
+	// The Shape may still be in the Space if it was in it more than once.
+	if !r.space.Contains(shape) {
+		delete(r.tracked, shape)
+	}
+	r.sync()
+	r.write(record{Op: "remove", ID: recordShape(shape).ID})
+}
+
+// clear records the Space being cleared. It's called once the Space's Shapes are gone, so there's nothing left to sync.
+func (r *Recorder) clear() {
+	r.tracked = map[Shape]trackedShape{}
+	r.write(record{Op: "clear"})
+}
+
+func (r *Recorder) resolve(shape Shape, dx, dy int32, res Collision) {
+
+	rs := recordShape(shape)
+	if rs == nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("resolv: can't record resolving %T", shape)
+		}
+		return
+	}
+
+	r.sync()
+
+	result := &recordedCollision{ResolveX: res.ResolveX, ResolveY: res.ResolveY, Teleporting: res.Teleporting}
+	if res.ShapeB != nil {
+		if other := recordShape(res.ShapeB); other != nil {
+			result.ShapeB = other.ID
+		}
+	}
+	r.write(record{Op: "resolve", Shape: rs, DX: dx, DY: dy, Result: result})
+
+}
+
+// SetRecorder attaches the Recorder provided to the Space, so that it records the operations done on the Space from now
+// on, starting with adding the Shapes already in it. Passing nil detaches the Space's Recorder. A Recorder can only be
+// attached to one Space at a time, and should be flushed (see Recorder.Flush()) once recording is done.
+func (sp *Space) SetRecorder(rec *Recorder) {
+	sp.recorder = rec
+	if rec == nil {
+		return
+	}
+	rec.space = sp
+	rec.tracked = map[Shape]trackedShape{}
+	for _, shape := range sp.shapes {
+		rec.add(shape)
+	}
+}
+
+// ReplayError is the error Replay() returns when replaying a recording goes differently than it did when it was
+// recorded (or the recording is malformed). Record is the number of the record (starting from 1) where that happened,
+// Shape is the Shape involved, if any, and Reason describes what went differently.
+type ReplayError struct {
+	Record int
+	Shape  Shape
+	Reason string
+}
+
+func (e *ReplayError) Error() string {
+	if e.Shape != nil {
+		return fmt.Sprintf("resolv: replay diverged at record %d (%v): %s", e.Record, e.Shape, e.Reason)
+	}
+	return fmt.Sprintf("resolv: replay diverged at record %d: %s", e.Record, e.Reason)
+}
+
+// Replay reads a recording made by a Recorder, and does the recorded operations on the Space provided (which should start
+// out the same way the recorded Space did, usually empty), checking that each goes the same way it did when it was
+// recorded. Shapes being resolved must be where they were when they were recorded, and the results of Space.Resolve()
+// must match the recorded ones. Replay stops at the first difference, returning a *ReplayError describing it.
+func Replay(r io.Reader, sp *Space) error {
+
+	dec := json.NewDecoder(r)
+	shapes := map[uint64]Shape{}
+	for _, shape := range sp.shapes {
+		if rs := recordShape(shape); rs != nil {
+			shapes[rs.ID] = shape
+		}
+	}
+
+	for n := 1; ; n++ {
+
+		rec := record{}
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return &ReplayError{Record: n, Reason: fmt.Sprintf("can't read record: %v", err)}
+		}
+
+		fail := func(shape Shape, format string, args ...interface{}) error {
+			return &ReplayError{n, shape, fmt.Sprintf(format, args...)}
+		}
+
+		switch rec.Op {
+
+		case "add":
+			if rec.Shape == nil {
+				return fail(nil, "add without a shape")
+			}
+			shape, err := rec.Shape.shape()
+			if err != nil {
+				return fail(nil, "%v", err)
+			}
+			if existing, ok := shapes[rec.Shape.ID]; ok {
+				// Shapes re-added are put back how they were recorded, as they could have changed outside of the Space.
+				shape = existing
+				if err := setShapePosition(shape, rec.Shape.position()); err != nil {
+					return fail(shape, "%v", err)
+				}
+				shape.ClearTags()
+				if len(rec.Shape.Tags) > 0 {
+					shape.AddTags(rec.Shape.Tags...)
+				}
+			}
+			shapes[rec.Shape.ID] = shape
+			if err := sp.Add(shape); err != nil {
+				return fail(shape, "can't add shape: %v", err)
+			}
+
+		case "remove":
+			shape, ok := shapes[rec.ID]
+			if !ok {
+				return fail(nil, "removing unknown shape %d", rec.ID)
+			}
+			if !sp.Contains(shape) {
+				return fail(shape, "removing a shape that isn't in the space")
+			}
+			sp.Remove(shape)
+
+		case "clear":
+			sp.Clear()
+
+		case "tags":
+			shape, ok := shapes[rec.ID]
+			if !ok {
+				return fail(nil, "tagging unknown shape %d", rec.ID)
+			}
+			shape.ClearTags()
+			if len(rec.Tags) > 0 {
+				shape.AddTags(rec.Tags...)
+			}
+
+		case "move":
+			shape, ok := shapes[rec.ID]
+			if !ok {
+				return fail(nil, "moving unknown shape %d", rec.ID)
+			}
+			if err := setShapePosition(shape, rec.Coords); err != nil {
+				return fail(shape, "%v", err)
+			}
+
+		case "resolve":
+			if rec.Shape == nil || rec.Result == nil {
+				return fail(nil, "resolve without a shape or result")
+			}
+			shape, ok := shapes[rec.Shape.ID]
+			if !ok {
+				// Shapes resolved without being in the Space are created as they were recorded.
+				var err error
+				if shape, err = rec.Shape.shape(); err != nil {
+					return fail(nil, "%v", err)
+				}
+				shapes[rec.Shape.ID] = shape
+			}
+			if sp.Contains(shape) {
+				if got := recordShape(shape).Coords; !samePosition(got, rec.Shape.Coords) {
+					return fail(shape, "resolving by (%d, %d) from %v, but it was recorded at %v", rec.DX, rec.DY, got, rec.Shape.Coords)
+				}
+			} else if err := setShapePosition(shape, rec.Shape.position()); err != nil {
+				// Shapes outside of the Space aren't recorded moving, so they're put back where they were recorded.
+				return fail(shape, "%v", err)
+			}
+
+			res := sp.Resolve(shape, rec.DX, rec.DY)
+			got := recordedCollision{ResolveX: res.ResolveX, ResolveY: res.ResolveY, Teleporting: res.Teleporting}
+			if res.ShapeB != nil {
+				if other := recordShape(res.ShapeB); other != nil {
+					got.ShapeB = other.ID
+				}
+			}
+			if got != *rec.Result {
+				return fail(shape, "resolving by (%d, %d) gave %+v, but %+v was recorded", rec.DX, rec.DY, got, *rec.Result)
+			}
+
+		default:
+			return fail(nil, "unknown operation %q", rec.Op)
+
+		}
+
+	}
+
+}
@@ -0,0 +1,92 @@
+package resolv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		run      func(sp *Space, player *Rectangle)
+		wantTags []string
+	}{
+		{"clear and re-add", func(sp *Space, player *Rectangle) {
+			sp.Clear()
+			sp.Add(NewRectangle(16, 0, 8, 8), player)
+		}, nil},
+		{"clear without callbacks", func(sp *Space, player *Rectangle) {
+			sp.ClearWithoutCallbacks()
+			sp.Add(player)
+		}, nil},
+		{"clear with OnRemove", func(sp *Space, player *Rectangle) {
+			sp.OnRemove(func(Shape) {})
+			sp.Clear()
+			sp.Add(NewRectangle(24, 0, 8, 8), player)
+		}, nil},
+		{"moved while out of the space", func(sp *Space, player *Rectangle) {
+			sp.Remove(player)
+			player.Move(0, 40)
+			sp.Add(player)
+		}, nil},
+		{"tags changed", func(sp *Space, player *Rectangle) {
+			player.AddTags("hot", "player")
+			player.RemoveTags("player")
+		}, []string{"hot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var buf bytes.Buffer
+			rec := NewRecorder(&buf)
+
+			sp := NewSpace()
+			sp.SetRecorder(rec)
+			player := NewRectangle(0, 0, 8, 8)
+			sp.Add(NewRectangle(40, 0, 8, 8), player)
+			sp.Resolve(player, 60, 0)
+			tt.run(sp, player)
+			sp.Resolve(player, 60, 0)
+
+			if err := rec.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			replayed := NewSpace()
+			if err := Replay(&buf, replayed); err != nil {
+				t.Fatalf("Replay() = %v", err)
+			}
+			if replayed.Length() != sp.Length() {
+				t.Fatalf("replayed %d shapes, want %d", replayed.Length(), sp.Length())
+			}
+			got := replayed.Get(replayed.Length() - 1).GetTags()
+			if !sameTags(got, tt.wantTags) {
+				t.Errorf("replayed tags = %v, want %v", got, tt.wantTags)
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkRecorderResolve(b *testing.B) {
+
+	var buf bytes.Buffer
+	sp := NewSpace()
+	for i := int32(0); i < 100; i++ {
+		sp.Add(NewRectangle(i*16, 32, 8, 8))
+	}
+	player := NewRectangle(0, 0, 8, 8)
+	sp.Add(player)
+	sp.SetRecorder(NewRecorder(&buf))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		sp.Resolve(player, 0, 40)
+	}
+
+}
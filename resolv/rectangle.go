@@ -1,41 +1,58 @@
 package resolv
 
-// Rectangle represents a rectangle.
+// Rectangle represents a rectangle. A Rectangle occupies the half-open area [X, X+W) × [Y, Y+H), so its rightmost column
+// is X+W-1 and its bottom row is Y+H-1. This means that Rectangles that only abut (like a 16-wide Rectangle at X = 0 and
+// another at X = 16) aren't colliding, which is what tiles laid out in a grid need.
 type Rectangle struct {
 	BasicShape
 	W, H int32
 }
 
-// NewRectangle creates a new Rectangle and returns a pointer to it.
-func NewRectangle(x, y, w, h int32) *Rectangle {
-	r := &Rectangle{W: w, H: h}
+// NewRectangle creates a new Rectangle and returns a pointer to it. A negative width or height is normalized by moving the
+// Rectangle's position to the other side and making the size positive, so NewRectangle(10, 0, -4, 2) is the same as
+// NewRectangle(6, 0, 4, 2). Sizes that can't be normalized are left as they are; see Validate(). The Rectangle is
+// configured with the options provided (like WithTags()).
+func NewRectangle(x, y, w, h int32, opts ...ShapeOption) *Rectangle {
+	r := &Rectangle{}
+	r.init(x, y, w, h, opts)
+	return r
+}
+
+// init sets up the zero Rectangle provided like NewRectangle() does, so that Rectangles allocated elsewhere (like from a
+// ShapeArena) are set up the same way.
+func (r *Rectangle) init(x, y, w, h int32, opts []ShapeOption) {
+	x, w = normalizeSpan(x, w)
+	y, h = normalizeSpan(y, h)
+	r.W, r.H = w, h
 	r.X = x
 	r.Y = y
-	return r
+	applyShapeOptions(r, opts)
 }
 
 // IsColliding returns whether the Rectangle is colliding with the specified other Shape or not, including the other Shape
-// being wholly contained within the Rectangle.
+// being wholly contained within the Rectangle. Shapes the Rectangle doesn't know (custom Shapes without a function
+// registered for the pair; see RegisterCollisionFuncFor()) are asked to test themselves against the Rectangle through
+// their own IsColliding() functions, so they mustn't call the Rectangle's IsColliding() from it (ShapesColliding() is fine).
 func (r *Rectangle) IsColliding(other Shape) bool {
-
-	switch b := other.(type) {
-	case *Rectangle:
-		return r.X > b.X-r.W && r.Y > b.Y-r.H && r.X < b.X+b.W && r.Y < b.Y+b.H
-	default:
-		return b.IsColliding(r)
+	if colliding, ok := knownCollision(r, other); ok {
+		return colliding
 	}
+	return other.IsColliding(r)
+}
 
+// rectanglesColliding returns whether the two Rectangles overlap. Both ends are exclusive, as the Rectangles are
+// half-open (see Rectangle).
+func rectanglesColliding(r, b *Rectangle) bool {
+	return r.X > b.X-r.W && r.Y > b.Y-r.H && r.X < b.X+b.W && r.Y < b.Y+b.H
 }
 
 // WouldBeColliding returns whether the Rectangle would be colliding with the other Shape if it were to move in the
-// specified direction.
+// specified direction. The Rectangle itself isn't changed in the process.
 func (r *Rectangle) WouldBeColliding(other Shape, dx, dy int32) bool {
-	r.X += dx
-	r.Y += dy
-	isColliding := r.IsColliding(other)
-	r.X -= dx
-	r.Y -= dy
-	return isColliding
+	moved := *r
+	moved.X += dx
+	moved.Y += dy
+	return collidingAs(r, &moved, other)
 }
 
 // Center returns the center point of the Rectangle.
@@ -48,6 +65,17 @@ func (r *Rectangle) Center() (int32, int32) {
 
 }
 
+// GetBoundingRect returns a new Rectangle with the same position and size as the Rectangle.
+func (r *Rectangle) GetBoundingRect() *Rectangle {
+	return NewRectangle(r.X, r.Y, r.W, r.H)
+}
+
+// GetBoundingRectInto sets the position and size of the Rectangle provided to those of the Rectangle, without allocating
+// a new one. The rest of dst (its tags, Data, etc.) is left as it is.
+func (r *Rectangle) GetBoundingRectInto(dst *Rectangle) {
+	dst.X, dst.Y, dst.W, dst.H = r.X, r.Y, r.W, r.H
+}
+
 // GetBoundingCircle returns a circle that wholly contains the Rectangle.
 func (r *Rectangle) GetBoundingCircle() *Circle {
 
@@ -56,3 +84,25 @@ func (r *Rectangle) GetBoundingCircle() *Circle {
 	return c
 
 }
+
+// SetXY sets the position of the Rectangle, calling the functions registered with OnMove().
+func (r *Rectangle) SetXY(x, y int32) {
+	dx, dy := x-r.X, y-r.Y
+	r.X, r.Y = x, y
+	r.NotifyMove(r, dx, dy)
+}
+
+// Move moves the Rectangle by the delta X and Y values provided, calling the functions registered with OnMove().
+func (r *Rectangle) Move(dx, dy int32) {
+	r.X += dx
+	r.Y += dy
+	r.NotifyMove(r, dx, dy)
+}
+
+// Clone returns a copy of the Rectangle. The tags of the copy are separate from the original's, while Data is shared between
+// them.
+func (r *Rectangle) Clone() *Rectangle {
+	clone := *r
+	clone.BasicShape = r.BasicShape.clone()
+	return &clone
+}
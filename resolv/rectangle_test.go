@@ -0,0 +1,143 @@
+package resolv
+
+import "testing"
+
+// testCross is a custom Shape that tests Rectangles itself, the way custom Shapes did before collision functions could be
+// registered.
+type testCross struct {
+	testPoint
+	tested int
+}
+
+func (c *testCross) IsColliding(other Shape) bool {
+	if r, ok := other.(*Rectangle); ok {
+		c.tested++
+		return c.X >= r.X && c.Y >= r.Y && c.X < r.X+r.W && c.Y < r.Y+r.H
+	}
+	return ShapesColliding(c, other)
+}
+
+func TestRectangleDelegatesToUnknownShapes(t *testing.T) {
+
+	rect := NewRectangle(0, 0, 8, 8)
+
+	tests := []struct {
+		name       string
+		x, y       int32
+		want       bool
+		wantTested int
+	}{
+		{"inside", 4, 4, true, 1},
+		{"outside", 8, 4, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cross := &testCross{testPoint: testPoint{BasicShape{X: tt.x, Y: tt.y}}}
+			if got := rect.IsColliding(cross); got != tt.want {
+				t.Errorf("IsColliding() = %v, want %v", got, tt.want)
+			}
+			if cross.tested != tt.wantTested {
+				t.Errorf("the custom Shape tested itself %d times, want %d", cross.tested, tt.wantTested)
+			}
+		})
+	}
+
+}
+
+func TestRectangleUnknownShapeStrict(t *testing.T) {
+
+	SetStrictShapes(true)
+	defer SetStrictShapes(false)
+	defer func() {
+		if _, ok := recover().(UnsupportedShapePairError); !ok {
+			t.Error("testing an unsupported pair in strict mode didn't panic with an UnsupportedShapePairError")
+		}
+	}()
+
+	// The blob tests itself through ShapesColliding(), which has no function for the pair.
+	NewRectangle(0, 0, 8, 8).IsColliding(&testBlob{testPoint{BasicShape{X: 4, Y: 4}}})
+
+}
+
+func BenchmarkRectangleIsColliding(b *testing.B) {
+
+	rect := NewRectangle(0, 0, 8, 8)
+	benchmarks := []struct {
+		name  string
+		other Shape
+	}{
+		{"rectangle", NewRectangle(4, 4, 8, 8)},
+		{"delegated", &testCross{testPoint: testPoint{BasicShape{X: 4, Y: 4}}}},
+	}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rect.IsColliding(bb.other)
+			}
+		})
+	}
+
+}
+
+func TestRectangleTileGrid(t *testing.T) {
+
+	const size = 16
+	grid := NewSpace()
+	var tiles [4][4]*Rectangle
+	for y := range tiles {
+		for x := range tiles[y] {
+			tiles[y][x] = NewRectangle(int32(x)*size, int32(y)*size, size, size)
+			grid.Add(tiles[y][x])
+		}
+	}
+
+	// Tiles only share edges and corners with their neighbours, so none of them collide.
+	for y := range tiles {
+		for x, tile := range tiles[y] {
+			if got := grid.GetCollidingShapes(tile); got.Length() != 0 {
+				t.Errorf("tile %d, %d collides with %v", x, y, got.Shapes())
+			}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		shape Shape
+		want  int
+	}{
+		{"tile-sized, on a tile", NewRectangle(size, size, size, size), 1},
+		{"tile-sized, one pixel over", NewRectangle(size+1, size, size, size), 2},
+		{"tile-sized, one pixel over both ways", NewRectangle(size+1, size+1, size, size), 4},
+		{"abutting the grid's right edge", NewRectangle(4*size, 0, size, size), 0},
+		{"abutting the grid's bottom-right corner", NewRectangle(4*size, 4*size, size, size), 0},
+		{"single pixel in the last column", NewRectangle(size-1, 0, 1, 1), 1},
+		// Circles are tested against a tile's last column and row, so they have to reach a pixel further to touch the
+		// tiles before them than the tiles after them.
+		{"Circle reaching the last column", NewCircle(size+size/2, size/2, size/2+1), 4},
+		{"Circle stopping short of the last column", NewCircle(size+size/2, size/2, size/2), 3},
+		{"Circle at a corner", NewCircle(size, size, 1), 3},
+		{"Circle reaching across a corner", NewCircle(size, size, 2), 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grid.GetCollidingShapes(tt.shape).Length(); got != tt.want {
+				t.Errorf("GetCollidingShapes() found %d tiles, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// Resolving into a tile stops the Rectangle abutting it, where it no longer collides.
+	player := NewRectangle(-40, 0, size, size)
+	col := grid.Resolve(player, 40, 0)
+	if col.ResolveX != 24 {
+		t.Fatalf("Resolve() = %d, want 24", col.ResolveX)
+	}
+	player.Move(col.ResolveX, col.ResolveY)
+	if grid.IsColliding(player) {
+		t.Errorf("a Rectangle moved by Resolve() collides with the grid at %d", player.X)
+	}
+
+}
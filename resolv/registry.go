@@ -0,0 +1,179 @@
+package resolv
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// collisionTable holds the functions registered with RegisterCollisionFuncFor(). Each Shape type with a function
+// registered for it gets an index into types, and the function for a pair of types is at funcs[a*len(types)+b], so the
+// pair can be found both ways around. Finding a type's index is a quick scan, as there are only ever a few types.
+type collisionTable struct {
+	types []reflect.Type
+	funcs []func(a, b Shape) bool
+}
+
+var (
+	// collisionFuncs is the current collisionTable. It's replaced rather than changed when a function is registered, so
+	// that it can be read without locking while Shapes are being tested from other goroutines.
+	collisionFuncs      atomic.Value // *collisionTable
+	collisionFuncsMutex sync.Mutex
+)
+
+func (ct *collisionTable) index(t reflect.Type) int {
+	for i, registered := range ct.types {
+		if registered == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup returns the function registered for the pair of types, or nil if there isn't one.
+func (ct *collisionTable) lookup(typeA, typeB reflect.Type) func(a, b Shape) bool {
+	a := ct.index(typeA)
+	if a < 0 {
+		return nil
+	}
+	b := ct.index(typeB)
+	if b < 0 {
+		return nil
+	}
+	return ct.funcs[a*len(ct.types)+b]
+}
+
+// The built-in Shapes register the functions that test them against each other like any other Shapes would.
+func init() {
+	RegisterCollisionFuncFor((*Rectangle)(nil), (*Rectangle)(nil), func(a, b Shape) bool {
+		return rectanglesColliding(a.(*Rectangle), b.(*Rectangle))
+	})
+	RegisterCollisionFuncFor((*Circle)(nil), (*Circle)(nil), func(a, b Shape) bool {
+		return circlesColliding(a.(*Circle), b.(*Circle))
+	})
+	RegisterCollisionFuncFor((*Circle)(nil), (*Rectangle)(nil), func(a, b Shape) bool {
+		return circleRectangleColliding(a.(*Circle), b.(*Rectangle))
+	})
+	RegisterCollisionFuncFor((*Circle)(nil), (*Line)(nil), func(a, b Shape) bool {
+		c, l := a.(*Circle), b.(*Line)
+		// The exact test is expensive, so Lines that aren't anywhere near the Circle are ruled out first.
+		return circleBounds(c).overlaps(lineBounds(l)) && c.isCollidingWithLine(l)
+	})
+	RegisterCollisionFuncFor((*Line)(nil), (*Line)(nil), func(a, b Shape) bool {
+		l, o := a.(*Line), b.(*Line)
+		return lineBounds(l).overlaps(lineBounds(o)) && segmentsIntersect(l, o)
+	})
+	RegisterCollisionFuncFor((*Line)(nil), (*Rectangle)(nil), func(a, b Shape) bool {
+		return lineRectangleColliding(a.(*Line), b.(*Rectangle))
+	})
+}
+
+// RegisterCollisionFuncFor registers the function provided for testing Shapes of the types of a and b (which are only
+// used for their types, so typed nil pointers like (*Hexagon)(nil) are fine) for collision against each other. The
+// function is called with a Shape of a's type first; it's also used for the pair the other way around, with the Shapes
+// swapped to match. Registering a function for a pair again replaces the earlier function, including the functions the
+// built-in Shapes (Rectangles, Circles, and Lines) register for each other.
+// This is how custom Shapes can collide with the built-in ones: every Shape pair is tested with the function registered
+// for it (see ShapesColliding()), and pairs without one fall back to the handling set up with SetUnknownShapeHandler(),
+// SetStrictShapes(), and SetBoundingRectFallback(). See RegisterCollisionFunc() for a generic version. Functions can be
+// registered at any time, though it's meant to be done once, before collision testing starts.
+func RegisterCollisionFuncFor(a, b Shape, fn func(a, b Shape) bool) {
+	registerCollisionFunc(reflect.TypeOf(a), reflect.TypeOf(b), fn)
+}
+
+func registerCollisionFunc(typeA, typeB reflect.Type, fn func(a, b Shape) bool) {
+
+	collisionFuncsMutex.Lock()
+	defer collisionFuncsMutex.Unlock()
+
+	old, _ := collisionFuncs.Load().(*collisionTable)
+	if old == nil {
+		old = &collisionTable{}
+	}
+
+	ct := &collisionTable{types: append([]reflect.Type(nil), old.types...)}
+	for _, t := range []reflect.Type{typeA, typeB} {
+		if ct.index(t) < 0 {
+			ct.types = append(ct.types, t)
+		}
+	}
+	types := ct.types
+	ct.funcs = make([]func(a, b Shape) bool, len(types)*len(types))
+	for a := range old.types {
+		for b := range old.types {
+			ct.funcs[a*len(types)+b] = old.funcs[a*len(old.types)+b]
+		}
+	}
+
+	indexA, indexB := ct.index(typeA), ct.index(typeB)
+	ct.funcs[indexA*len(types)+indexB] = fn
+	if indexA != indexB {
+		ct.funcs[indexB*len(types)+indexA] = func(a, b Shape) bool { return fn(b, a) }
+	}
+
+	collisionFuncs.Store(ct)
+
+}
+
+// registeredCollision returns whether the Shapes provided are colliding according to the function registered for their
+// types, and false for ok if there isn't one.
+func registeredCollision(a, b Shape) (colliding, ok bool) {
+	ct, _ := collisionFuncs.Load().(*collisionTable)
+	if ct == nil {
+		return false, false
+	}
+	fn := ct.lookup(reflect.TypeOf(a), reflect.TypeOf(b))
+	if fn == nil {
+		return false, false
+	}
+	return fn(a, b), true
+}
+
+// knownCollision returns whether the Shapes provided are colliding, if that's known without asking either of them: wrapped
+// Shapes (like TypedShape) are tested as the Shapes they wrap, Spaces as the compound Shapes they are, and any other pair
+// with the function registered for it. ok is false if there's no function registered for the pair.
+func knownCollision(a, b Shape) (colliding, ok bool) {
+
+	if colliding, ok := registeredCollision(a, b); ok {
+		return colliding, true
+	}
+
+	if innerA, innerB := unwrapShape(a), unwrapShape(b); innerA != a || innerB != b {
+		return knownCollision(innerA, innerB)
+	}
+	if sp, isSpace := a.(*Space); isSpace {
+		return sp.IsColliding(b), true
+	}
+	if sp, isSpace := b.(*Space); isSpace {
+		return sp.IsColliding(a), true
+	}
+	return false, false
+
+}
+
+// ShapesColliding returns whether the two Shapes are colliding, using the function registered for the pair's types (see
+// RegisterCollisionFuncFor()); Spaces are tested as compound Shapes (see Space.IsColliding()). If there isn't one, the
+// Shapes are handled like a built-in Shape tested against a Shape it doesn't know (see SetUnknownShapeHandler()), with
+// the built-in Shape of the two (if any) first. Custom Shapes can implement their IsColliding() functions by calling
+// ShapesColliding().
+func ShapesColliding(a, b Shape) bool {
+
+	if colliding, ok := knownCollision(a, b); ok {
+		return colliding
+	}
+
+	a, b = unwrapShape(a), unwrapShape(b)
+	if isBuiltInShape(b) && !isBuiltInShape(a) {
+		return unknownShapePair(b, a)
+	}
+	return unknownShapePair(a, b)
+
+}
+
+func isBuiltInShape(shape Shape) bool {
+	switch shape.(type) {
+	case *Rectangle, *Circle, *Line, *Space:
+		return true
+	}
+	return false
+}
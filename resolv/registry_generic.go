@@ -0,0 +1,15 @@
+//go:build go1.18
+
+package resolv
+
+import "reflect"
+
+// RegisterCollisionFunc registers the function provided for testing Shapes of types A and B for collision against each
+// other, like RegisterCollisionFuncFor() does, but taking the types as type parameters:
+//
+//	resolv.RegisterCollisionFunc[*Hexagon, *resolv.Circle](func(a, b resolv.Shape) bool {
+//		return a.(*Hexagon).collidesWithCircle(b.(*resolv.Circle))
+//	})
+func RegisterCollisionFunc[A, B Shape](fn func(a, b Shape) bool) {
+	registerCollisionFunc(reflect.TypeOf((*A)(nil)).Elem(), reflect.TypeOf((*B)(nil)).Elem(), fn)
+}
@@ -0,0 +1,185 @@
+package resolv
+
+import (
+	"sync"
+	"testing"
+)
+
+// testPoint is a custom Shape, a single point, for testing how custom Shapes are handled.
+type testPoint struct {
+	BasicShape
+}
+
+func (p *testPoint) IsColliding(other Shape) bool { return ShapesColliding(p, other) }
+
+func (p *testPoint) WouldBeColliding(other Shape, dx, dy int32) bool {
+	moved := *p
+	moved.X += dx
+	moved.Y += dy
+	return ShapesColliding(&moved, other)
+}
+
+func (p *testPoint) GetBoundingRect() *Rectangle { return NewRectangle(p.X, p.Y, 1, 1) }
+
+// testBlob is a custom Shape with no collision function registered for it.
+type testBlob struct {
+	testPoint
+}
+
+func (b *testBlob) IsColliding(other Shape) bool { return ShapesColliding(b, other) }
+
+func init() {
+	RegisterCollisionFuncFor((*testPoint)(nil), (*Rectangle)(nil), func(a, b Shape) bool {
+		p, r := a.(*testPoint), b.(*Rectangle)
+		return p.X >= r.X && p.Y >= r.Y && p.X < r.X+r.W && p.Y < r.Y+r.H
+	})
+}
+
+func TestRegisteredCollisionFunc(t *testing.T) {
+
+	inside := &testPoint{BasicShape{X: 4, Y: 4}}
+	outside := &testPoint{BasicShape{X: 40, Y: 4}}
+	rect := NewRectangle(0, 0, 8, 8)
+	space := NewSpace()
+	space.Add(rect)
+
+	tests := []struct {
+		name string
+		got  func(p *testPoint) bool
+	}{
+		{"ShapesColliding", func(p *testPoint) bool { return ShapesColliding(p, rect) }},
+		{"ShapesColliding swapped", func(p *testPoint) bool { return ShapesColliding(rect, p) }},
+		{"custom IsColliding", func(p *testPoint) bool { return p.IsColliding(rect) }},
+		{"Rectangle.IsColliding", func(p *testPoint) bool { return rect.IsColliding(p) }},
+		{"Rectangle.WouldBeColliding", func(p *testPoint) bool { return rect.WouldBeColliding(p, 0, 0) }},
+		{"Space.IsColliding", func(p *testPoint) bool { return space.IsColliding(p) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got(inside) {
+				t.Error("point inside the Rectangle isn't colliding")
+			}
+			if tt.got(outside) {
+				t.Error("point outside the Rectangle is colliding")
+			}
+		})
+	}
+
+}
+
+func TestUnregisteredCustomPair(t *testing.T) {
+
+	blob := &testBlob{testPoint{BasicShape{X: 4, Y: 4}}}
+	circle := NewCircle(4, 4, 8)
+
+	tests := []struct {
+		name     string
+		fallback bool
+		want     bool
+	}{
+		{"no fallback", false, false},
+		{"bounding rect fallback", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetBoundingRectFallback(tt.fallback)
+			defer SetBoundingRectFallback(false)
+			if got := blob.IsColliding(circle); got != tt.want {
+				t.Errorf("IsColliding() = %v, want %v", got, tt.want)
+			}
+			if got := ShapesColliding(circle, blob); got != tt.want {
+				t.Errorf("ShapesColliding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+}
+
+func TestOverrideBuiltInPair(t *testing.T) {
+
+	saved := collisionFuncs.Load()
+	defer collisionFuncs.Store(saved)
+
+	rect := NewRectangle(0, 0, 8, 8)
+	// The Circle is just off the Rectangle's corner, so their bounds overlap, but they aren't colliding.
+	circle := NewCircle(9, 9, 2)
+	space := NewSpace()
+	space.Add(rect)
+
+	if rect.IsColliding(circle) {
+		t.Fatal("Rectangle and Circle are colliding before overriding their pair")
+	}
+
+	RegisterCollisionFuncFor((*Rectangle)(nil), (*Circle)(nil), func(a, b Shape) bool {
+		a.(*Rectangle).AddTags("tested")
+		return true
+	})
+
+	tests := []struct {
+		name string
+		got  func() bool
+	}{
+		{"Rectangle.IsColliding", func() bool { return rect.IsColliding(circle) }},
+		{"Circle.IsColliding", func() bool { return circle.IsColliding(rect) }},
+		{"ShapesColliding swapped", func() bool { return ShapesColliding(circle, rect) }},
+		{"Space.IsColliding", func() bool { return space.IsColliding(circle) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rect.ClearTags()
+			if !tt.got() {
+				t.Error("the overriding function wasn't used")
+			}
+			if !rect.HasTags("tested") {
+				t.Error("the overriding function wasn't called with the Rectangle first")
+			}
+		})
+	}
+
+}
+
+func TestRegisterCollisionFuncConcurrently(t *testing.T) {
+
+	rect := NewRectangle(0, 0, 8, 8)
+	point := &testPoint{BasicShape{X: 4, Y: 4}}
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if g == 0 {
+					RegisterCollisionFuncFor((*testBlob)(nil), (*Line)(nil), func(a, b Shape) bool { return false })
+				}
+				ShapesColliding(point, rect)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+}
+
+func BenchmarkShapesColliding(b *testing.B) {
+
+	rect := NewRectangle(0, 0, 8, 8)
+	benchmarks := []struct {
+		name  string
+		other Shape
+	}{
+		{"built-in", NewCircle(4, 4, 2)},
+		{"registered", &testPoint{BasicShape{X: 4, Y: 4}}},
+	}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ShapesColliding(rect, bb.other)
+			}
+		})
+	}
+
+}
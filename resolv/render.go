@@ -0,0 +1,99 @@
+package resolv
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// maxRenderSize is the largest width or height of an image rendered by RenderPNG(); anything beyond it is clipped.
+const maxRenderSize = 4096
+
+// overlayAlpha is the alpha the first Space is drawn with by RenderPNGOverlay().
+const overlayAlpha = 0.4
+
+// alphaDrawer is a Drawer that draws with another Drawer, with the colors made more transparent by the alpha provided.
+type alphaDrawer struct {
+	Drawer
+	alpha float64
+}
+
+func (d alphaDrawer) fade(c color.Color) color.Color {
+	r, g, b, a := c.RGBA()
+	fade := func(v uint32) uint16 { return uint16(float64(v) * d.alpha) }
+	return color.RGBA64{fade(r), fade(g), fade(b), fade(a)}
+}
+
+func (d alphaDrawer) DrawRect(x, y, w, h int32, c color.Color) {
+	d.Drawer.DrawRect(x, y, w, h, d.fade(c))
+}
+
+func (d alphaDrawer) DrawCircle(x, y, radius int32, c color.Color) {
+	d.Drawer.DrawCircle(x, y, radius, d.fade(c))
+}
+
+func (d alphaDrawer) DrawLine(x, y, x2, y2 int32, c color.Color) {
+	d.Drawer.DrawLine(x, y, x2, y2, d.fade(c))
+}
+
+// RenderPNG draws the Shapes in the Space (including the Shapes within nested Spaces) into a new image fitting the Space's
+// bounding rectangle, scaled by the scale provided, for reproducing collision bugs without a game window (in CI, for
+// example). Rectangles and Circles are drawn filled, over a black background; the options are the same ones
+// Space.DebugDraw() takes, so DrawTagColor() sets the color of Shapes by their tags. Images are limited to 4096 pixels
+// on each side, with anything beyond that clipped. See WritePNG() to save the image.
+func RenderPNG(sp *Space, scale float64, opts ...DebugDrawOption) *image.RGBA {
+	return renderSpaces([]*Space{sp}, []float64{1}, scale, opts)
+}
+
+// RenderPNGOverlay draws two Spaces into the same image, like RenderPNG() does, with the first drawn translucently and the
+// second drawn over it, for comparing a Space from before something happened (like a Resolve()) with the Space after it.
+// The image fits both Spaces.
+func RenderPNGOverlay(before, after *Space, scale float64, opts ...DebugDrawOption) *image.RGBA {
+	return renderSpaces([]*Space{before, after}, []float64{overlayAlpha, 1}, scale, opts)
+}
+
+// WritePNG draws the Space with RenderPNG() and writes the image to the Writer provided in PNG format.
+func WritePNG(w io.Writer, sp *Space, scale float64, opts ...DebugDrawOption) error {
+	return png.Encode(w, RenderPNG(sp, scale, opts...))
+}
+
+func renderSpaces(spaces []*Space, alphas []float64, scale float64, opts []DebugDrawOption) *image.RGBA {
+
+	if scale <= 0 {
+		scale = 1
+	}
+
+	all := NewSpace()
+	for _, sp := range spaces {
+		all.shapes = append(all.shapes, sp.shapes...)
+	}
+	bounds := all.GetBoundingRect()
+
+	// The far edges of the bounds are included, as Circles and Lines are drawn up to their far edges. A negative size
+	// means the bounds were too big to hold in an int32.
+	size := func(l int32) int {
+		pixels := math.Ceil(float64(l)*scale) + 1
+		if l < 0 || pixels > maxRenderSize {
+			return maxRenderSize
+		}
+		return int(pixels)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size(bounds.W), size(bounds.H)))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	drawer := &ImageDrawer{Image: img, OffsetX: -bounds.X, OffsetY: -bounds.Y, Scale: scale, Fill: true}
+	for i, sp := range spaces {
+		if alphas[i] == 1 {
+			sp.DebugDraw(drawer, opts...)
+		} else {
+			sp.DebugDraw(alphaDrawer{drawer, alphas[i]}, opts...)
+		}
+	}
+
+	return img
+
+}
@@ -1,7 +1,20 @@
 package resolv
 
+import "fmt"
+
 // Shape is a basic interface that describes a Shape that can be passed to collision testing and resolution functions and
 // exist in the same Space.
+// Shapes can also have any of the following functions, which are looked for with type assertions, so that custom Shapes
+// only need the ones they use. The built-in Shapes have all of them (mostly through BasicShape); Shapes without them are
+// treated as below:
+//   - GetLayer() uint32 and SetLayer(uint32): the Shape is on DefaultLayer (see LayersCollide()).
+//   - GetMask() uint32 and SetMask(uint32): the Shape's mask is AllLayers.
+//   - GetElevation() int32 and SetElevation(int32): the Shape is on elevation 0 (see ElevationsCollide()).
+//   - HasAnyTags(...string) bool: each of the tags is checked with HasTags().
+//   - HasTagMatching(string) bool: the pattern is matched against GetTags().
+//   - GetBoundingRect() *Rectangle: the Shape's bounding rectangle is an empty one at its position.
+//
+// Setting a layer, mask, or elevation on a Shape without the setter for it (like with WithLayer()) does nothing.
 type Shape interface {
 	IsColliding(Shape) bool
 	WouldBeColliding(Shape, int32, int32) bool
@@ -21,65 +34,90 @@ type Shape interface {
 // position and tags. It is embedded in other Shapes.
 type BasicShape struct {
 	X, Y int32
-	tags []string
+	tags Tags
 	Data interface{}
+
+	// Elevation is the height level the Shape is on, for games with things like bridges. By default, Shapes in a Space
+	// only collide with Shapes on the same elevation; see Space.SetElevationFilter().
+	Elevation int32
+
+	// The layer and mask are stored flipped against their defaults, so that a zero BasicShape is on DefaultLayer and
+	// collides with AllLayers.
+	layer, mask uint32
+
+	id uint64
+
+	version   uint64
+	listeners *shapeListeners
+
+	// tagGeneration is the generation the BasicShape's tags last changed at; see tagIndex.
+	tagGeneration uint64
+
+	// arena is the ShapeArena the Shape was allocated from, if any.
+	arena *ShapeArena
 }
 
-// GetTags returns a reference to the the string array representing the tags on the BasicShape.
+// GetTags returns the tags on the BasicShape, in sorted order. The slice returned is a copy, so changing it doesn't
+// change the BasicShape's tags.
 func (b *BasicShape) GetTags() []string {
-	return b.tags
+	return b.tags.Strings()
 }
 
-// AddTags adds the specified tags to the BasicShape.
+// GetTagsUnsafe returns the tags on the BasicShape, in sorted order, without copying them like GetTags() does, for hot
+// paths where the allocation matters. The slice returned is the BasicShape's own, so it mustn't be changed (appending to
+// it, sorting it, etc.), and it's only valid until the BasicShape's tags next change.
+func (b *BasicShape) GetTagsUnsafe() []string {
+	return b.tags.sorted
+}
+
+// TagSet returns a copy of the set of tags on the BasicShape, for set operations like Union() and Intersect().
+func (b *BasicShape) TagSet() Tags {
+	return b.tags.clone()
+}
+
+// AddTags adds the specified tags to the BasicShape. Tags the BasicShape already has aren't added again.
 func (b *BasicShape) AddTags(tags ...string) {
-	if b.tags == nil {
-		b.tags = []string{}
-	}
-	b.tags = append(b.tags, tags...)
+	b.tags.Add(tags...)
+	b.tagsChanged()
+	b.changed()
 }
 
 // RemoveTags removes the specified tags from the BasicShape.
 func (b *BasicShape) RemoveTags(tags ...string) {
-
-	for _, t := range tags {
-
-		for i := len(b.tags) - 1; i >= 0; i-- {
-
-			if t == b.tags[i] {
-				b.tags = append(b.tags[:i], b.tags[i+1:]...)
-			}
-
-		}
-
-	}
-
+	b.tags.Remove(tags...)
+	b.tagsChanged()
+	b.changed()
 }
 
 // ClearTags clears the tags active on the BasicShape.
 func (b *BasicShape) ClearTags() {
-	b.tags = []string{}
+	b.tags = Tags{}
+	b.tagsChanged()
+	b.changed()
 }
 
 // HasTags returns true if the Shape has all of the tags provided.
 func (b *BasicShape) HasTags(tags ...string) bool {
+	return b.tags.HasAll(tags...)
+}
 
-	hasTags := true
+// HasAnyTags returns true if the Shape has at least one of the tags provided. If no tags are provided, it returns false.
+func (b *BasicShape) HasAnyTags(tags ...string) bool {
+	return b.tags.HasAny(tags...)
+}
 
-	for _, t1 := range tags {
-		found := false
-		for _, shapeTag := range b.tags {
-			if t1 == shapeTag {
-				found = true
-				continue
-			}
-		}
-		if !found {
-			hasTags = false
-			break
+// hasAnyTags returns whether the Shape has at least one of the tags provided, checking them one at a time with HasTags()
+// if the Shape doesn't have a HasAnyTags() function.
+func hasAnyTags(shape Shape, tags ...string) bool {
+	if s, ok := shape.(interface{ HasAnyTags(...string) bool }); ok {
+		return s.HasAnyTags(tags...)
+	}
+	for _, tag := range tags {
+		if shape.HasTags(tag) {
+			return true
 		}
 	}
-
-	return hasTags
+	return false
 }
 
 // GetData returns the data on the Shape.
@@ -90,6 +128,7 @@ func (b *BasicShape) GetData() interface{} {
 // SetData sets the data on the Shape.
 func (b *BasicShape) SetData(data interface{}) {
 	b.Data = data
+	b.changed()
 }
 
 // GetXY returns the position of the Shape.
@@ -97,14 +136,129 @@ func (b *BasicShape) GetXY() (int32, int32) {
 	return b.X, b.Y
 }
 
-// SetXY sets the position of the Shape.
+// SetXY sets the position of the Shape. It doesn't call the functions registered with OnMove(); see NotifyMove().
 func (b *BasicShape) SetXY(x, y int32) {
 	b.X = x
 	b.Y = y
+	b.changed()
 }
 
-// Move moves the Shape by the delta X and Y values provided.
+// Move moves the Shape by the delta X and Y values provided. It doesn't call the functions registered with OnMove(); see
+// NotifyMove().
 func (b *BasicShape) Move(x, y int32) {
 	b.X += x
 	b.Y += y
+	b.changed()
+}
+
+// GetLayer returns the collision layer bits of the Shape. Shapes are on DefaultLayer unless set otherwise.
+func (b *BasicShape) GetLayer() uint32 {
+	return b.layer ^ DefaultLayer
+}
+
+// SetLayer sets the collision layer bits of the Shape. A Shape can be on multiple layers at once by combining layer bits.
+func (b *BasicShape) SetLayer(layer uint32) {
+	b.layer = layer ^ DefaultLayer
+	b.changed()
+}
+
+// GetMask returns the collision mask of the Shape, which is the set of layers it can collide with. Shapes collide with
+// AllLayers unless set otherwise.
+func (b *BasicShape) GetMask() uint32 {
+	return ^b.mask
+}
+
+// SetMask sets the collision mask of the Shape, which is the set of layers it can collide with.
+func (b *BasicShape) SetMask(mask uint32) {
+	b.mask = ^mask
+	b.changed()
+}
+
+// clone returns a copy of the BasicShape that doesn't share its tags or ID with the original.
+func (b *BasicShape) clone() BasicShape {
+	c := *b
+	c.tags = b.tags.clone()
+	c.listeners = nil
+	c.arena = nil
+	c.id = 0
+	c.ID()
+	return c
+}
+
+// cloneShape returns a copy of the Shape provided. The built-in Shapes and Spaces are copied through their Clone()
+// functions; any other Shapes have to implement Clone() Shape to be cloned, or cloneShape panics.
+func cloneShape(shape Shape) Shape {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return s.Clone()
+	case *Circle:
+		return s.Clone()
+	case *Line:
+		return s.Clone()
+	case *Space:
+		return s.Clone()
+	case interface{ Clone() Shape }:
+		return s.Clone()
+	}
+	panic(fmt.Sprintf("ERROR! Shape %v can't be cloned, as it doesn't implement Clone() Shape!", shape))
+}
+
+// GetElevation returns the elevation of the Shape.
+func (b *BasicShape) GetElevation() int32 {
+	return b.Elevation
+}
+
+// SetElevation sets the elevation of the Shape.
+func (b *BasicShape) SetElevation(elevation int32) {
+	b.Elevation = elevation
+	b.changed()
+}
+
+// collidingAs returns whether moved, a displaced copy of the Shape provided, is colliding with the other Shape. This lets
+// WouldBeColliding() test a hypothetical position without changing the Shape. When the other Shape is a Space, the copy
+// is tested against it as if it were the Shape itself, so that the Shape is still skipped if it's in that Space.
+func collidingAs(shape, moved, other Shape) bool {
+	if sp, ok := other.(*Space); ok {
+		return sp.isCollidingAs(shape, moved)
+	}
+	return moved.IsColliding(other)
+}
+
+// shapeWrapper is implemented by Shapes that wrap another Shape (like TypedShape), so that collision testing can test the
+// wrapped Shape instead, which the built-in Shapes know how to handle.
+type shapeWrapper interface {
+	unwrapShape() Shape
+}
+
+// unwrapShape returns the Shape wrapped by the Shape provided (through any number of wrappers), or the Shape itself if it
+// doesn't wrap one.
+func unwrapShape(shape Shape) Shape {
+	for {
+		w, ok := shape.(shapeWrapper)
+		if !ok {
+			return shape
+		}
+		shape = w.unwrapShape()
+	}
+}
+
+// boundingRectInto sets the position and size of the Rectangle provided to the bounding rectangle of the Shape. Shapes
+// that have a GetBoundingRectInto() method (like all of the built-in Shapes) fill it in without allocating.
+func boundingRectInto(shape Shape, dst *Rectangle) {
+	if s, ok := shape.(interface{ GetBoundingRectInto(*Rectangle) }); ok {
+		s.GetBoundingRectInto(dst)
+		return
+	}
+	r := shapeBoundingRect(shape)
+	dst.X, dst.Y, dst.W, dst.H = r.X, r.Y, r.W, r.H
+}
+
+// shapeBoundingRect returns the bounding rectangle of the Shape, or an empty Rectangle at its position if it doesn't have
+// a GetBoundingRect() function.
+func shapeBoundingRect(shape Shape) *Rectangle {
+	if s, ok := shape.(interface{ GetBoundingRect() *Rectangle }); ok {
+		return s.GetBoundingRect()
+	}
+	x, y := shape.GetXY()
+	return &Rectangle{BasicShape: BasicShape{X: x, Y: y}}
 }
@@ -0,0 +1,194 @@
+package resolv
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// builtInShapes returns one of each built-in Shape, near each other.
+func builtInShapes() []Shape {
+	return []Shape{NewRectangle(0, 0, 10, 10), NewCircle(20, 5, 5), NewLine(30, 0, 40, 10)}
+}
+
+func TestWouldBeCollidingMatchesMoving(t *testing.T) {
+
+	deltas := [][2]int32{{0, 0}, {5, 0}, {10, 0}, {15, 0}, {20, 0}, {25, 5}, {-5, 0}, {0, 10}, {30, -5}}
+
+	for i := range builtInShapes() {
+		for j := range builtInShapes() {
+			if i == j {
+				continue
+			}
+			for _, d := range deltas {
+
+				shapes := builtInShapes()
+				shape, other := shapes[i], shapes[j]
+				x, y := shape.GetXY()
+
+				got := shape.WouldBeColliding(other, d[0], d[1])
+				if nx, ny := shape.GetXY(); nx != x || ny != y {
+					t.Fatalf("WouldBeColliding() moved %v", shape)
+				}
+
+				shape.Move(d[0], d[1])
+				if want := shape.IsColliding(other); got != want {
+					t.Errorf("%v.WouldBeColliding(%v, %d, %d) = %v, want %v", builtInShapes()[i], other, d[0], d[1],
+						got, want)
+				}
+
+			}
+		}
+	}
+
+}
+
+// TestWouldBeCollidingConcurrentReader is meant to be run with the race detector (go test -race), to show that
+// WouldBeColliding() doesn't write to the Shape.
+func TestWouldBeCollidingConcurrentReader(t *testing.T) {
+
+	shapes := builtInShapes()
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, shape := range shapes {
+				if x, y := shape.GetXY(); x%10 != 0 || y%5 != 0 {
+					t.Errorf("read %v at %d, %d while it was being tested", shape, x, y)
+					return
+				}
+			}
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		for _, shape := range shapes {
+			for _, other := range shapes {
+				if shape != other {
+					shape.WouldBeColliding(other, 3, 7)
+				}
+			}
+		}
+	}
+	close(done)
+	wg.Wait()
+
+}
+
+// randomShape returns a random built-in Shape (or a Space of them) within a small area, so that many pairs collide.
+func randomShape(rng *rand.Rand, nest bool) Shape {
+	x, y := rng.Int31n(64)-32, rng.Int31n(64)-32
+	switch rng.Intn(4) {
+	case 0:
+		return NewRectangle(x, y, rng.Int31n(24), rng.Int31n(24))
+	case 1:
+		return NewCircle(x, y, rng.Int31n(16))
+	case 2:
+		return NewLine(x, y, x+rng.Int31n(48)-24, y+rng.Int31n(48)-24)
+	}
+	if !nest {
+		return NewCircle(x, y, rng.Int31n(16))
+	}
+	space := NewSpace()
+	space.Add(randomShape(rng, false), randomShape(rng, false))
+	return space
+}
+
+func TestCollisionSymmetry(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(6))
+	for i := 0; i < 20000; i++ {
+		a, b := randomShape(rng, true), randomShape(rng, true)
+		if ab, ba := a.IsColliding(b), b.IsColliding(a); ab != ba {
+			t.Fatalf("%v.IsColliding(%v) = %v, but the other way around = %v", a, b, ab, ba)
+		}
+	}
+
+}
+
+func TestBoundingRectNotShared(t *testing.T) {
+
+	nested := NewSpace()
+	nested.Add(NewRectangle(50, 50, 4, 4))
+	shapes := append(builtInShapes(), nested)
+
+	for _, shape := range shapes {
+
+		want := *shapeBoundingRect(shape)
+
+		// Changing the Rectangle returned by GetBoundingRect(), or filled in by GetBoundingRectInto(), doesn't change the
+		// Shape or the bounding rectangles handed out afterwards.
+		first := shapeBoundingRect(shape)
+		first.X, first.W = 1000, -5
+		first.Move(7, 7)
+		var into Rectangle
+		boundingRectInto(shape, &into)
+		into.Y, into.H = -1000, 99
+
+		for i, got := range []*Rectangle{shapeBoundingRect(shape), {}} {
+			if i == 1 {
+				boundingRectInto(shape, got)
+			}
+			if got == first || got.X != want.X || got.Y != want.Y || got.W != want.W || got.H != want.H {
+				t.Errorf("after changing its bounding rectangle, %v has the bounding rectangle %v, want %v", shape, got,
+					&want)
+			}
+		}
+
+		// The Rectangle filled in keeps its own tags and Data.
+		dst := NewRectangle(0, 0, 0, 0, WithTags("dst"), WithData(1))
+		boundingRectInto(shape, dst)
+		if !dst.HasTags("dst") || dst.GetData() != 1 || shape.HasTags("dst") {
+			t.Errorf("GetBoundingRectInto() changed the tags or Data of %v or the Rectangle filled in", shape)
+		}
+
+	}
+
+}
+
+func BenchmarkBoundingRectPairScan(b *testing.B) {
+
+	rng := rand.New(rand.NewSource(392))
+	var shapes []Shape
+	for i := 0; i < 5000; i++ {
+		shape := randomShape(rng, false)
+		shape.SetXY(rng.Int31n(4000), rng.Int31n(4000))
+		shapes = append(shapes, shape)
+	}
+
+	// Each Shape's bounds are tested against those of the Shapes after it, as a broad phase would before testing the pairs
+	// that overlap for collisions.
+	b.Run("GetBoundingRect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j, shape := range shapes {
+				bounds := shapeBoundingRect(shape)
+				for _, other := range shapes[j+1:] {
+					boundsOverlap(bounds, shapeBoundingRect(other))
+				}
+			}
+		}
+	})
+
+	b.Run("GetBoundingRectInto", func(b *testing.B) {
+		var bounds, otherBounds Rectangle
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j, shape := range shapes {
+				boundingRectInto(shape, &bounds)
+				for _, other := range shapes[j+1:] {
+					boundingRectInto(other, &otherBounds)
+					boundsOverlap(&bounds, &otherBounds)
+				}
+			}
+		}
+	})
+
+}
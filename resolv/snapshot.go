@@ -0,0 +1,126 @@
+package resolv
+
+import "fmt"
+
+// Snapshot holds the positions (and sizes) of the Shapes in a Space at a point in time, as taken by
+// Space.SnapshotPositions(). It can be used to put the Shapes back where they were with Space.RestorePositions(), like
+// when rolling back a game state for netcode. Snapshots are values that don't change, so they can be kept around freely.
+// The Shapes within nested Spaces are captured individually.
+type Snapshot struct {
+	entries []snapshotEntry
+}
+
+type snapshotEntry struct {
+	shape Shape
+	x, y  int32
+	// a and b hold the size of the Shape: a Rectangle's width and height, a Circle's radius, or a Line's end point.
+	a, b int32
+}
+
+// ShapeDelta describes how far a Shape moved between two Snapshots.
+type ShapeDelta struct {
+	Shape  Shape
+	DX, DY int32
+}
+
+func snapshotOf(shape Shape) snapshotEntry {
+	e := snapshotEntry{shape: shape}
+	e.x, e.y = shape.GetXY()
+	switch s := shape.(type) {
+	case *Rectangle:
+		e.a, e.b = s.W, s.H
+	case *Circle:
+		e.a = s.Radius
+	case *Line:
+		e.a, e.b = s.X2, s.Y2
+	}
+	return e
+}
+
+// restore puts the Shape back how it was captured. It's moved through its own functions, so that its version goes up
+// and the functions registered with OnMove() are called for it, as for any other move; the size is set first, so that
+// they see the Shape as it's restored.
+func (e snapshotEntry) restore() {
+	switch s := e.shape.(type) {
+	case *Rectangle:
+		resized := s.W != e.a || s.H != e.b
+		s.W, s.H = e.a, e.b
+		s.SetXY(e.x, e.y)
+		if resized {
+			s.changed()
+		}
+	case *Circle:
+		resized := s.Radius != e.a
+		s.Radius = e.a
+		s.SetXY(e.x, e.y)
+		if resized {
+			s.changed()
+		}
+	case *Line:
+		s.SetEndpoints(e.x, e.y, e.a, e.b)
+	default:
+		s.SetXY(e.x, e.y)
+	}
+}
+
+// SnapshotPositions returns a Snapshot of the positions and sizes of every Shape in the Space (including the Shapes within
+// nested Spaces).
+func (sp *Space) SnapshotPositions() Snapshot {
+	leaves := sp.pairLeaves()
+	snapshot := Snapshot{entries: make([]snapshotEntry, len(leaves))}
+	for i, leaf := range leaves {
+		snapshot.entries[i] = snapshotOf(leaf.shape)
+	}
+	return snapshot
+}
+
+// RestorePositions puts every Shape captured in the Snapshot back to the position and size it had when the Snapshot was
+// taken. Shapes are moved like with their SetXY() functions, so their versions go up (see Space.Version()) and the
+// functions registered with OnMove() are called for the Shapes that move. Shapes added to the Space after the Snapshot
+// was taken aren't in it, so they're left as they are. If a Shape in the Snapshot is no longer in the Space, nothing is
+// restored and an error wrapping ErrNotInSpace is returned.
+func (sp *Space) RestorePositions(snapshot Snapshot) error {
+
+	current := map[Shape]bool{}
+	for _, leaf := range sp.pairLeaves() {
+		current[leaf.shape] = true
+	}
+
+	for _, e := range snapshot.entries {
+		if !current[e.shape] {
+			return fmt.Errorf("resolv: can't restore snapshot: %w: %v", ErrNotInSpace, e.shape)
+		}
+	}
+
+	for _, e := range snapshot.entries {
+		e.restore()
+	}
+
+	return nil
+
+}
+
+// Len returns the number of Shapes captured in the Snapshot.
+func (s Snapshot) Len() int {
+	return len(s.entries)
+}
+
+// Diff returns how far each Shape captured in both Snapshots moved from this Snapshot to the other one. Shapes that
+// didn't move, or that are only in one of the Snapshots, aren't included. The deltas are in the order of this Snapshot.
+func (s Snapshot) Diff(other Snapshot) []ShapeDelta {
+
+	positions := make(map[Shape]snapshotEntry, len(other.entries))
+	for _, e := range other.entries {
+		positions[e.shape] = e
+	}
+
+	deltas := []ShapeDelta{}
+	for _, e := range s.entries {
+		if o, exists := positions[e.shape]; exists && (o.x != e.x || o.y != e.y) {
+			deltas = append(deltas, ShapeDelta{e.shape, o.x - e.x, o.y - e.y})
+		}
+	}
+
+	return deltas
+
+}
@@ -0,0 +1,70 @@
+package resolv
+
+import "testing"
+
+func TestRestorePositionsNotifies(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		shape     Shape
+		change    func(Shape)
+		wantMoves int
+	}{
+		{"rectangle moved", NewRectangle(0, 0, 8, 8), func(s Shape) { s.Move(5, 3) }, 1},
+		{"rectangle resized", NewRectangle(0, 0, 8, 8), func(s Shape) { s.(*Rectangle).W = 20 }, 0},
+		{"circle moved and resized", NewCircle(0, 0, 4), func(s Shape) { s.Move(-2, 0); s.(*Circle).Radius = 9 }, 1},
+		{"line moved", NewLine(0, 0, 10, 10), func(s Shape) { s.Move(0, 7) }, 1},
+		{"line reshaped", NewLine(0, 0, 10, 10), func(s Shape) { s.(*Line).X2 = 30 }, 0},
+		{"unchanged", NewRectangle(0, 0, 8, 8), func(Shape) {}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			space.Add(tt.shape)
+			want := snapshotOf(tt.shape)
+			snapshot := space.SnapshotPositions()
+
+			tt.change(tt.shape)
+			before := space.Version()
+
+			moves := 0
+			tt.shape.(interface {
+				OnMove(func(Shape, int32, int32)) func()
+			}).OnMove(func(Shape, int32, int32) { moves++ })
+
+			if err := space.RestorePositions(snapshot); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := snapshotOf(tt.shape); got != want {
+				t.Errorf("restored %+v, want %+v", got, want)
+			}
+			if moves != tt.wantMoves {
+				t.Errorf("OnMove() called %d times, want %d", moves, tt.wantMoves)
+			}
+			if changed := space.Version() != before; changed != (tt.name != "unchanged") {
+				t.Errorf("Space version changed = %v", changed)
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkRestorePositions(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 1000; i++ {
+		space.Add(NewRectangle(i*8, 0, 8, 8))
+	}
+	snapshot := space.SnapshotPositions()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		space.RestorePositions(snapshot)
+	}
+
+}
@@ -0,0 +1,48 @@
+package resolv
+
+import "sort"
+
+// Sort sorts the Shapes in the Space using the function provided, which should return whether Shape a comes before
+// Shape b. The sort is stable, so Shapes that are equal keep their order. As Space.Resolve() and other queries that stop
+// at the first match go through the Shapes in order, sorting the Space by something other than the order the Shapes were
+// added in (like ByPosition) makes their results the same no matter how the Space was built.
+func (sp *Space) Sort(less func(a, b Shape) bool) {
+	order := make([]int, len(sp.shapes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return less(sp.shapes[order[i]], sp.shapes[order[j]])
+	})
+	sp.permute(order)
+}
+
+// KeepSorted sorts the Space using the function provided (see Sort()), and keeps it sorted from then on by inserting
+// Shapes added to the Space in order, after any Shapes equal to them. Passing nil stops keeping the Space sorted. Note that
+// changes to the Shapes themselves (like moving them when sorting ByPosition) don't re-sort the Space; call Sort() again
+// for that.
+func (sp *Space) KeepSorted(less func(a, b Shape) bool) {
+	sp.sortedBy = less
+	if less != nil {
+		sp.Sort(less)
+	}
+}
+
+// ByPosition is a function for Space.Sort() that sorts Shapes by their position, top to bottom and then left to right.
+func ByPosition(a, b Shape) bool {
+	ax, ay := a.GetXY()
+	bx, by := b.GetXY()
+	if ay != by {
+		return ay < by
+	}
+	return ax < bx
+}
+
+// ByBoundingArea is a function for Space.Sort() that sorts Shapes by the area of their bounding rectangles, smallest
+// first.
+func ByBoundingArea(a, b Shape) bool {
+	var ra, rb Rectangle
+	boundingRectInto(a, &ra)
+	boundingRectInto(b, &rb)
+	return int64(ra.W)*int64(ra.H) < int64(rb.W)*int64(rb.H)
+}
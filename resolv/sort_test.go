@@ -0,0 +1,77 @@
+package resolv
+
+import "testing"
+
+func TestSortStable(t *testing.T) {
+
+	// a, b, and c are at the same position, and all but big have the same area, so those have to keep the order they
+	// were added in.
+	a, b, c := NewRectangle(10, 10, 4, 4), NewRectangle(10, 10, 2, 8), NewRectangle(10, 10, 8, 2)
+	top, big := NewRectangle(50, 0, 4, 4), NewRectangle(0, 40, 10, 10)
+
+	tests := []struct {
+		name string
+		less func(a, b Shape) bool
+		want []Shape
+	}{
+		{"ByPosition", ByPosition, []Shape{top, a, b, c, big}},
+		{"ByBoundingArea", ByBoundingArea, []Shape{a, top, b, c, big}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			space.Add(big, a, top, b, c)
+			space.Sort(tt.less)
+			for i, shape := range tt.want {
+				if space.Get(i) != shape {
+					t.Errorf("Sort() put %v at %d, want %v", space.Get(i), i, shape)
+				}
+			}
+
+			// Shapes added to a Space kept sorted go after the Shapes equal to them.
+			kept := NewSpace()
+			kept.KeepSorted(tt.less)
+			kept.Add(big, a, top, b, c)
+			for i, shape := range tt.want {
+				if kept.Get(i) != shape {
+					t.Errorf("KeepSorted() put %v at %d, want %v", kept.Get(i), i, shape)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestSortIgnoresInsertionOrder(t *testing.T) {
+
+	shapes := []Shape{
+		NewRectangle(0, 0, 4, 4), NewRectangle(8, 0, 4, 4), NewRectangle(0, 8, 4, 4), NewCircle(20, 4, 2),
+	}
+
+	fresh := NewSpace()
+	fresh.Add(shapes...)
+	fresh.Sort(ByPosition)
+
+	// The same level, after Shapes were removed and added again.
+	rebuilt := NewSpace()
+	rebuilt.Add(shapes...)
+	rebuilt.Remove(shapes[0], shapes[2])
+	rebuilt.Add(shapes[2], shapes[0])
+	rebuilt.Sort(ByPosition)
+
+	for i := range shapes {
+		if fresh.Get(i) != rebuilt.Get(i) {
+			t.Errorf("Shape %d differs between the Spaces: %v, %v", i, fresh.Get(i), rebuilt.Get(i))
+		}
+	}
+
+	player := NewRectangle(-10, 0, 4, 12)
+	a, b := fresh.Resolve(player, 40, 0), rebuilt.Resolve(player, 40, 0)
+	if a.ShapeB != b.ShapeB || a.ResolveX != b.ResolveX {
+		t.Errorf("Resolve() differs between the Spaces: %+v, %+v", a, b)
+	}
+
+}
@@ -0,0 +1,168 @@
+package resolv
+
+import (
+	"sort"
+	"sync"
+)
+
+// sortedX is the index of a Space kept sorted by X with KeepSortedX(): the indexes of the Space's Shapes, ordered by their
+// left edges. It's kept apart from the Space's Shapes, so that keeping it up to date doesn't reorder them.
+type sortedX struct {
+	// mutex guards the index, as it's brought up to date by queries, which can run at the same time as each other (like
+	// through a SyncSpace).
+	mutex sync.Mutex
+
+	// version is the version of the Space (see Space.Version()) as of when the index was last brought up to date, and
+	// stale is whether Shapes have been added to the Space or removed from it (or reordered) since then.
+	version uint64
+	stale   bool
+
+	// order holds the indexes of the Space's Shapes, by left edge, and lefts holds their left edges, in the same order.
+	order []int
+	lefts []int64
+
+	// prunable is whether the bounds of all of the Shapes in the Space are known, so that queries can skip the Shapes
+	// that are too far to the left or right of the Shape tested. maxWidth is the widest of the Shapes' bounds.
+	prunable bool
+	maxWidth int64
+}
+
+// KeepSortedX keeps an index of the Shapes in the Space sorted by their left edges, for Spaces whose Shapes are spread out
+// horizontally (like the levels of side-scrolling games). With it, IsColliding(), WouldBeColliding(),
+// GetCollidingShapes(), and Resolve() binary search for the Shapes the tested Shape could reach on the X axis, and only
+// test those, rather than going through the whole Space. The results are the same as testing every Shape in the Space in
+// order; the Shapes in the Space themselves aren't reordered.
+// Shapes that move are re-sorted in the index lazily: the next query after any Shape in the Space changes makes sure the
+// index is still sorted (which is quick, if it is), and sorts it again if it isn't. Queries can still run at the same
+// time as each other (like through a SyncSpace), as the index is locked while it's brought up to date.
+// While the Space has any Shapes whose bounds aren't known (like nested Spaces), queries test every Shape.
+func (sp *Space) KeepSortedX() {
+	sp.sortedX = &sortedX{stale: true}
+	sp.updateSortedX()
+}
+
+// invalidate marks the index as needing to be rebuilt, as Shapes were added to the Space, removed from it, or reordered.
+func (s *sortedX) invalidate() {
+	if s != nil {
+		s.stale = true
+	}
+}
+
+func leftEdge(shape Shape) int64 {
+	if bounds, ok := shapeBounds(shape); ok {
+		return bounds.minX
+	}
+	var r Rectangle
+	boundingRectInto(shape, &r)
+	return int64(r.X)
+}
+
+// Len, Less, and Swap sort the index by left edge.
+func (s *sortedX) Len() int           { return len(s.order) }
+func (s *sortedX) Less(i, j int) bool { return s.lefts[i] < s.lefts[j] }
+func (s *sortedX) Swap(i, j int) {
+	s.order[i], s.order[j] = s.order[j], s.order[i]
+	s.lefts[i], s.lefts[j] = s.lefts[j], s.lefts[i]
+}
+
+// updateSortedX brings the index up to date if the Space has changed since it was last updated, returning whether queries
+// can skip Shapes by their X position. The index must be locked.
+func (sp *Space) updateSortedX() bool {
+
+	s := sp.sortedX
+	version := sp.Version()
+
+	if version == s.version && !s.stale {
+		return s.prunable
+	}
+
+	if s.stale {
+		s.order = s.order[:0]
+		for i := range sp.shapes {
+			s.order = append(s.order, i)
+		}
+		s.lefts = make([]int64, len(s.order))
+	}
+
+	s.prunable, s.maxWidth = true, 0
+	sorted := true
+
+	for i, index := range s.order {
+		shape := sp.shapes[index]
+		bounds, ok := shapeBounds(shape)
+		if !ok {
+			s.prunable = false
+			s.lefts[i] = leftEdge(shape)
+		} else {
+			s.lefts[i] = bounds.minX
+			if width := bounds.maxX - bounds.minX; width > s.maxWidth {
+				s.maxWidth = width
+			}
+		}
+		if i > 0 && s.lefts[i] < s.lefts[i-1] {
+			sorted = false
+		}
+	}
+
+	if !sorted {
+		sort.Sort(s)
+	}
+
+	s.version, s.stale = version, false
+	return s.prunable
+
+}
+
+// nearX holds the Shapes found by shapesNearX(), and is pooled so that queries on Spaces kept sorted by X don't allocate.
+type nearX struct {
+	indexes []int
+	shapes  []Shape
+}
+
+var nearXPool = sync.Pool{
+	New: func() interface{} {
+		return &nearX{}
+	},
+}
+
+// release returns the nearX to the pool once the query using it is done.
+func (n *nearX) release() {
+	for i := range n.shapes {
+		n.shapes[i] = nil
+	}
+	n.indexes, n.shapes = n.indexes[:0], n.shapes[:0]
+	nearXPool.Put(n)
+}
+
+// shapesNearX returns the Shapes in the Space that could overlap the X range provided, in the order they're in within the
+// Space, if the Space is kept sorted by X (see KeepSortedX()). Otherwise, it returns nil, and every Shape in the Space
+// has to be tested. The nearX returned has to be released once the query is done with it.
+func (sp *Space) shapesNearX(minX, maxX int64) *nearX {
+
+	s := sp.sortedX
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !sp.updateSortedX() {
+		return nil
+	}
+
+	// A Shape can only reach the range if its left edge is within the range, or no further to the left of it than the
+	// widest Shape in the Space.
+	from := minX - s.maxWidth
+	start := sort.Search(len(s.lefts), func(i int) bool { return s.lefts[i] >= from })
+	end := start + sort.Search(len(s.lefts)-start, func(i int) bool { return s.lefts[start+i] > maxX })
+
+	near := nearXPool.Get().(*nearX)
+	near.indexes = append(near.indexes, s.order[start:end]...)
+	sort.Ints(near.indexes)
+	for _, index := range near.indexes {
+		near.shapes = append(near.shapes, sp.shapes[index])
+	}
+	return near
+
+}
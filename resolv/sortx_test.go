@@ -0,0 +1,153 @@
+package resolv
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// randomLevel returns n Shapes spread out along a long, side-scrolling level.
+func randomLevel(rng *rand.Rand, n int) []Shape {
+	shapes := make([]Shape, 0, n)
+	for i := 0; i < n; i++ {
+		x, y := rng.Int31n(int32(n)*16), rng.Int31n(240)
+		switch i % 3 {
+		case 0:
+			shapes = append(shapes, NewRectangle(x, y, 1+rng.Int31n(64), 1+rng.Int31n(32)))
+		case 1:
+			shapes = append(shapes, NewCircle(x, y, 1+rng.Int31n(16)))
+		default:
+			shapes = append(shapes, NewLine(x, y, x+rng.Int31n(48)-24, y+rng.Int31n(48)-24))
+		}
+	}
+	return shapes
+}
+
+func TestKeepSortedXMatchesBruteForce(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		shapes int
+		moves  int
+	}{
+		{"static", 200, 0},
+		{"moving", 200, 50},
+		{"single", 1, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			rng := rand.New(rand.NewSource(1))
+			shapes := randomLevel(rng, tt.shapes)
+			brute, sorted := NewSpace(), NewSpace()
+			brute.Add(shapes...)
+			sorted.Add(shapes...)
+			sorted.KeepSortedX()
+
+			for round := 0; round <= tt.moves; round++ {
+
+				if round > 0 {
+					shapes[rng.Intn(len(shapes))].Move(rng.Int31n(200)-100, rng.Int31n(20)-10)
+				}
+
+				probe := NewRectangle(rng.Int31n(int32(tt.shapes)*16), rng.Int31n(240), 24, 24)
+				dx, dy := rng.Int31n(400)-200, rng.Int31n(40)-20
+
+				if got, want := sorted.IsColliding(probe), brute.IsColliding(probe); got != want {
+					t.Fatalf("round %d: IsColliding() = %v, want %v", round, got, want)
+				}
+				if got, want := sorted.GetCollidingShapes(probe).Length(), brute.GetCollidingShapes(probe).Length(); got != want {
+					t.Fatalf("round %d: GetCollidingShapes() found %d, want %d", round, got, want)
+				}
+				got, want := sorted.Resolve(probe, dx, dy), brute.Resolve(probe, dx, dy)
+				if got.ResolveX != want.ResolveX || got.ResolveY != want.ResolveY || got.Colliding() != want.Colliding() {
+					t.Fatalf("round %d: Resolve() = %+v, want %+v", round, got, want)
+				}
+
+			}
+
+		})
+	}
+
+}
+
+func TestKeepSortedXQueriesKeepOrder(t *testing.T) {
+
+	space := NewSpace()
+	space.Add(randomLevel(rand.New(rand.NewSource(4)), 50)...)
+	space.KeepSortedX()
+	want := space.Shapes()
+
+	// Moving Shapes out of order has to re-sort the index, but not the Space itself.
+	space.Get(0).Move(800, 0)
+	space.Get(10).Move(-800, 0)
+	version := space.Version()
+
+	probe := NewRectangle(100, 100, 24, 24)
+	space.IsColliding(probe)
+	space.GetCollidingShapes(probe)
+	space.Resolve(probe, 200, 0)
+
+	if got := space.Version(); got != version {
+		t.Errorf("Version() after queries = %d, want %d", got, version)
+	}
+	for i, shape := range want {
+		if got := space.Get(i); got != shape {
+			t.Fatalf("Get(%d) after queries = %v, want %v", i, got, shape)
+		}
+	}
+
+}
+
+func TestSyncSpaceSortedXConcurrentQueries(t *testing.T) {
+
+	ss := NewSyncSpace()
+	ss.Add(randomLevel(rand.New(rand.NewSource(2)), 300)...)
+	ss.Write(func(sp *Space) { sp.KeepSortedX() })
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			probe := NewRectangle(int32(g)*100, 0, 16, 16)
+			for i := 0; i < 200; i++ {
+				if g == 0 {
+					ss.Write(func(sp *Space) { sp.Get(i%sp.Length()).Move(-50, 0) })
+				}
+				ss.Resolve(probe, 64, 64)
+				ss.IsColliding(probe)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+}
+
+func BenchmarkKeepSortedXLongLevel(b *testing.B) {
+
+	for _, bb := range []struct {
+		name   string
+		sorted bool
+	}{{"brute", false}, {"sortedX", true}} {
+		b.Run(bb.name, func(b *testing.B) {
+
+			rng := rand.New(rand.NewSource(3))
+			space := NewSpace()
+			space.Add(randomLevel(rng, 10000)...)
+			if bb.sorted {
+				space.KeepSortedX()
+			}
+			player := NewRectangle(80000, 100, 16, 16)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				space.Resolve(player, 32, 8)
+			}
+
+		})
+	}
+
+}
@@ -1,135 +1,940 @@
 package resolv
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDuplicateShape is returned by Space.Add() when adding a Shape that's already in a strict Space.
+	ErrDuplicateShape = errors.New("shape is already in the space")
+	// ErrNotInSpace is returned when a Shape needs to be in a Space, but isn't.
+	ErrNotInSpace = errors.New("shape is not in the space")
+	// ErrDuplicateName is returned when naming a Shape in a Space with a name another Shape in it already has.
+	ErrDuplicateName = errors.New("name is already in use in the space")
+	// ErrSelfAddition is returned by Space.Add() when adding a Space to itself, either directly or by adding a Space that
+	// (through nested Spaces) contains it, which would make the Space contain itself.
+	ErrSelfAddition = errors.New("space can't contain itself")
+	// ErrInvalidShape is wrapped by the InvalidShapeErrors returned when validating Shapes (see Rectangle.Validate(), for
+	// example), and so by anything that validates Shapes, like Space.AddChecked() and LoadTMX().
+	ErrInvalidShape = errors.New("invalid shape")
+	// ErrUnsupportedShapePair is wrapped by the UnsupportedShapePairError panicked with in strict mode (see
+	// SetStrictShapes()).
+	ErrUnsupportedShapePair = errors.New("unsupported shape pair")
+)
 
 /*A Space represents a collection that holds Shapes for collision detection in the same common space. A Space is arbitrarily large -
 you can use one Space for a single level, room, or area in your game, or split it up if it makes more sense for your game design.
-Technically, a Space is just a slice of Shapes. Spaces fulfill the required functions for Shapes, which means you can also use them
-as compound shapes themselves. In these cases, the first Shape is the "root" or pivot from which attempts to move the Shape will
-be focused. In other words, Space.SetXY(40, 40) will move all Shapes in the Space in such a way that the first Shape will be at
+Internally, a Space holds a slice of Shapes, along with some indexes to speed up working with them. Spaces fulfill the required
+functions for Shapes, which means you can also use them as compound shapes themselves. In these cases, the first Shape is the "root" or pivot from which attempts to move the Shape will
+be focused (unless another Shape is made the root with SetRoot()). In other words, Space.SetXY(40, 40) will move all Shapes in the Space in such a way that the first Shape will be at
 40, 40, and all other Shapes retain their original spacing relative to it.*/
-type Space []Shape
+type Space struct {
+	shapes  []Shape
+	tags    tagIndex
+	handles handleTable
+	queued  []Shape
+	strict  bool
+	members map[Shape]int
 
-// NewSpace creates a new Space for shapes to exist in and be tested against in.
-func NewSpace() *Space {
+	onAdd    []func(Shape)
+	onRemove []func(Shape)
+
+	// iterating is how many ForEach() calls are iterating over the Space, and deferred and deferredH are the Shapes and
+	// Handles to remove once they're done.
+	iterating int
+	deferred  []Shape
+	deferredH []Handle
+
+	stats      *spaceStats
+	reportRate *metricsRate
+	recorder   *Recorder
+
+	root Shape
+
+	sortedBy func(a, b Shape) bool
+	sortedX  *sortedX
+
+	names  map[string]Shape
+	nameOf map[Shape]string
+
+	elevationFilter func(a, b Shape) bool
+
+	hashCellSize int32
+
+	version  uint64
+	watchers *moveListeners
+	watched  map[Shape]func()
+
+	// nestsResults is whether any query results have been nested in the Space, and pulled is the sum of the versions of
+	// the Shapes the Space doesn't watch (see Space.Version()) as of when it was last asked for its version.
+	nestsResults bool
+	pulled       uint64
+
+	// result is whether the Space holds the results of a query (like Filter()), rather than being a Space of its own.
+	result bool
+
+	// arenaShapes is how many of the Shapes in the Space were allocated from a ShapeArena.
+	arenaShapes int
+
+	// pooled is whether the Space was taken from the pool of query results, and so can be released back to it.
+	pooled bool
+}
+
+// NewSpace creates a new Space for shapes to exist in and be tested against in, configured with the options provided (like
+// WithCapacity()).
+func NewSpace(opts ...SpaceOption) *Space {
 	sp := &Space{}
+	for _, opt := range opts {
+		opt(sp)
+	}
+	return sp
+}
+
+// NewSpaceWithCapacity creates a new Space with room for n Shapes, so that adding up to that many Shapes doesn't have to
+// grow the Space.
+func NewSpaceWithCapacity(n int) *Space {
+	sp := &Space{shapes: make([]Shape, 0, n)}
 	return sp
 }
 
-// Add adds the designated Shapes to the Space. You cannot add the Space to itself.
-func (sp *Space) Add(shapes ...Shape) {
+// Grow makes sure the Space has room for at least n more Shapes, so that adding them doesn't have to grow the Space
+// again, like slices.Grow.
+func (sp *Space) Grow(n int) {
+	if n > cap(sp.shapes)-len(sp.shapes) {
+		shapes := make([]Shape, len(sp.shapes), len(sp.shapes)+n)
+		copy(shapes, sp.shapes)
+		sp.shapes = shapes
+	}
+}
+
+// AddBulk adds the designated Shapes to the Space, like Add() does. It's faster than adding the Shapes one at a time
+// when adding a lot of Shapes at once (like when loading a level), as the Space grows only once, and the Space's indexes
+// are rebuilt when they're next needed rather than updated for each Shape.
+func (sp *Space) AddBulk(shapes []Shape) error {
+	sp.Grow(len(shapes))
+	sp.tags.invalidate()
+	return sp.Add(shapes...)
+}
+
+// Add adds the designated Shapes to the Space. A Space can't contain itself, so adding the Space to itself, or adding a
+// Space that contains it (even through other nested Spaces), is skipped, and an error wrapping ErrSelfAddition is
+// returned once all of the other Shapes have been added. Likewise, if the Space is strict (see SetStrict()), Shapes that
+// are already in the Space are skipped, and an error wrapping ErrDuplicateShape is returned.
+func (sp *Space) Add(shapes ...Shape) error {
+	var err error
 	for _, shape := range shapes {
-		if shape == sp {
-			panic(fmt.Sprintf("ERROR! Space %s cannot add itself!", shape))
+		if sp.wouldContainItself(shape) {
+			err = fmt.Errorf("%w: %v", ErrSelfAddition, shape)
+			continue
+		}
+		if sp.strict && sp.members[shape] > 0 {
+			err = fmt.Errorf("%w: %v", ErrDuplicateShape, shape)
+			continue
+		}
+		sp.appendShape(shape)
+	}
+	return err
+}
+
+// AddChecked adds the designated Shapes to the Space only if all of them can be added, returning an error otherwise
+// without adding any of them. It returns an error wrapping ErrInvalidShape if a Shape fails validation (see
+// Rectangle.Validate(), for example), ErrSelfAddition if adding a Shape would make the Space contain itself, and, if the
+// Space is strict, ErrDuplicateShape if a Shape is already in the Space or is passed more than once.
+func (sp *Space) AddChecked(shapes ...Shape) error {
+
+	for _, shape := range shapes {
+		if err := validateShape(shape); err != nil {
+			return err
+		}
+	}
+
+	if err := sp.checkAdd(shapes); err != nil {
+		return err
+	}
+
+	return sp.Add(shapes...)
+
+}
+
+// checkAdd returns an error if any of the Shapes provided can't be added to the Space: ErrSelfAddition if adding it
+// would make the Space contain itself, and, if the Space is strict, ErrDuplicateShape if it's already in the Space or is
+// passed more than once.
+func (sp *Space) checkAdd(shapes []Shape) error {
+
+	var seen map[Shape]bool
+	if sp.strict {
+		seen = make(map[Shape]bool, len(shapes))
+	}
+
+	for _, shape := range shapes {
+		if sp.wouldContainItself(shape) {
+			return fmt.Errorf("%w: %v", ErrSelfAddition, shape)
+		}
+		if sp.strict {
+			if sp.members[shape] > 0 || seen[shape] {
+				return fmt.Errorf("%w: %v", ErrDuplicateShape, shape)
+			}
+			seen[shape] = true
+		}
+	}
+
+	return nil
+
+}
+
+// AddUnique adds the designated Shapes to the Space, skipping any that are already in it (or that appear earlier in the
+// Shapes provided), and returns how many Shapes were added. Like with Add(), Spaces that would make the Space contain
+// itself are skipped as well.
+func (sp *Space) AddUnique(shapes ...Shape) (added int) {
+	sp.trackMembers()
+	for _, shape := range shapes {
+		if sp.members[shape] == 0 && !sp.wouldContainItself(shape) {
+			sp.appendShape(shape)
+			added++
+		}
+	}
+	return added
+}
+
+// wouldContainItself returns whether adding the Shape provided to the Space would make the Space contain itself. Only
+// Spaces are walked, so checking any other Shape is cheap.
+func (sp *Space) wouldContainItself(shape Shape) bool {
+	other, ok := shape.(*Space)
+	if !ok {
+		return false
+	}
+	if other == sp {
+		return true
+	}
+	for _, inner := range other.shapes {
+		if sp.wouldContainItself(inner) {
+			return true
 		}
-		*sp = append(*sp, shape)
 	}
+	return false
 }
 
-// Remove removes the designated Shapes from the Space.
+// SetStrict sets whether the Space is strict. A strict Space doesn't allow a Shape to be added to it more than once,
+// with Add() returning ErrDuplicateShape for any Shapes that are already in it. Turning strict mode on doesn't remove
+// duplicates that are already in the Space.
+func (sp *Space) SetStrict(strict bool) {
+	sp.strict = strict
+	if strict {
+		sp.trackMembers()
+	}
+}
+
+// trackMembers starts keeping a count of how many times each Shape is in the Space, which lets the Space tell whether
+// it contains a Shape without searching for it.
+func (sp *Space) trackMembers() {
+	if sp.members == nil {
+		sp.members = make(map[Shape]int, len(sp.shapes))
+		for _, shape := range sp.shapes {
+			sp.members[shape]++
+		}
+	}
+}
+
+// Remove removes the designated Shapes from the Space. Shapes after a removed Shape move down to fill its place right
+// away, so removing Shapes while looping over the Space by index (or while working through the results of a query on the
+// Space) skips over the Shape after the removed one. Use QueueRemove() and Flush() to remove Shapes safely in that case.
+// The exception is removing Shapes while iterating with ForEach() (or All()), which is safe: the Shapes are removed once
+// the iteration is done.
+// If a Shape is in the Space more than once, only its first occurrence is removed for each time it's passed to Remove();
+// use RemoveAll() to remove every occurrence. The order of the remaining Shapes is kept, and all of the Shapes are removed
+// in a single pass over the Space.
 func (sp *Space) Remove(shapes ...Shape) {
 
+	if sp.iterating > 0 {
+		sp.deferred = append(sp.deferred, shapes...)
+		return
+	}
+
+	if len(shapes) == 1 {
+		if index := sp.IndexOf(shapes[0]); index >= 0 {
+			sp.removeAt(index)
+		}
+		return
+	}
+
+	pending := make(map[Shape]int, len(shapes))
 	for _, shape := range shapes {
+		pending[shape]++
+	}
 
-		for deleteIndex, s := range *sp {
+	sp.compact(func(shape Shape) bool {
+		if pending[shape] > 0 {
+			pending[shape]--
+			return true
+		}
+		return false
+	})
 
+}
+
+// RemoveChecked removes the designated Shapes from the Space like Remove() does, but only if all of them are in the
+// Space; otherwise, none of them are removed, and an error wrapping ErrNotInSpace is returned. A Shape passed more than
+// once has to be in the Space that many times.
+func (sp *Space) RemoveChecked(shapes ...Shape) error {
+
+	counts := make(map[Shape]int, len(shapes))
+	for _, shape := range shapes {
+		counts[shape]++
+	}
+
+	for _, shape := range sp.shapes {
+		if counts[shape] > 0 {
+			counts[shape]--
+		}
+	}
+
+	for _, shape := range shapes {
+		if counts[shape] > 0 {
+			return fmt.Errorf("%w: %v", ErrNotInSpace, shape)
+		}
+	}
+
+	sp.Remove(shapes...)
+	return nil
+
+}
+
+// RemoveAll removes every occurrence of the Shape provided from the Space, returning how many were removed. The order of
+// the remaining Shapes is kept. Like with Remove(), Shapes removed while iterating with ForEach() are removed once it's
+// done.
+func (sp *Space) RemoveAll(shape Shape) int {
+
+	if sp.iterating > 0 {
+		count := 0
+		for _, s := range sp.shapes {
 			if s == shape {
-				s := *sp
-				s[deleteIndex] = nil
-				s = append(s[:deleteIndex], s[deleteIndex+1:]...)
-				*sp = s
-				break
+				sp.deferred = append(sp.deferred, shape)
+				count++
 			}
+		}
+		return count
+	}
 
+	return sp.compact(func(s Shape) bool {
+		return s == shape
+	})
+
+}
+
+// RemoveWhere removes every Shape in the Space for which the function provided returns true in a single pass over the
+// Space, returning how many Shapes were removed. The order of the remaining Shapes is kept. This is much faster than
+// removing the Shapes one at a time with Remove(). Removing every Shape is the same as Clear(), and removing none leaves
+// the Space untouched. Like with Remove(), Shapes removed while iterating with ForEach() are removed once it's done.
+func (sp *Space) RemoveWhere(predicate func(Shape) bool) int {
+
+	if sp.iterating > 0 {
+		count := 0
+		for _, shape := range sp.shapes {
+			if predicate(shape) {
+				sp.deferred = append(sp.deferred, shape)
+				count++
+			}
 		}
+		return count
+	}
 
+	return sp.compact(predicate)
+
+}
+
+// RemoveByTags removes every Shape in the Space that has all of the specified tags in a single pass over the Space,
+// returning how many Shapes were removed. See RemoveWhere().
+func (sp *Space) RemoveByTags(tags ...string) int {
+	return sp.RemoveWhere(func(s Shape) bool {
+		return s.HasTags(tags...)
+	})
+}
+
+// QueueRemove queues the designated Shapes to be removed from the Space the next time Flush() is called, rather than
+// removing them immediately. This allows Shapes to be removed while looping over the Space without disturbing the loop.
+func (sp *Space) QueueRemove(shapes ...Shape) {
+	sp.queued = append(sp.queued, shapes...)
+}
+
+// Flush removes the Shapes queued through QueueRemove() from the Space, in the order they were queued.
+func (sp *Space) Flush() {
+	queued := sp.queued
+	sp.queued = nil
+	sp.Remove(queued...)
+}
+
+// Combine moves all of the Shapes from the other Space into this one, moving each of them by the offset provided. The
+// other Space is left empty. This is useful to build a level out of pieces, each of which is its own Space.
+// Either every Shape is moved over, or none are: if any of them can't be added to this Space (see AddChecked()), or the
+// other Space is this one, an error wrapping ErrDuplicateShape or ErrSelfAddition is returned, and both Spaces and the
+// Shapes are left as they are.
+func (sp *Space) Combine(other *Space, offsetX, offsetY int32) error {
+
+	if other == sp {
+		return fmt.Errorf("%w: can't combine %v with itself", ErrSelfAddition, other)
+	}
+	if err := sp.checkAdd(other.shapes); err != nil {
+		return err
 	}
 
+	shapes := append([]Shape(nil), other.shapes...)
+	other.Clear()
+	for _, shape := range shapes {
+		shape.Move(offsetX, offsetY)
+		sp.appendShape(shape)
+	}
+
+	return nil
+
 }
 
-// Clear "resets" the Space, cleaning out the Space of references to Shapes.
+// CombineClone adds copies of all of the Shapes from the other Space into this one, moving each of the copies by the
+// offset provided. The other Space is left as it is, so it can be combined into Spaces again (like a prefab). Shapes are
+// copied with their Clone() functions. Combining a Space with itself returns an error wrapping ErrSelfAddition, and adds
+// nothing.
+func (sp *Space) CombineClone(other *Space, offsetX, offsetY int32) error {
+	if other == sp {
+		return fmt.Errorf("%w: can't combine %v with itself", ErrSelfAddition, other)
+	}
+	for _, shape := range other.shapes {
+		clone := cloneShape(shape)
+		clone.Move(offsetX, offsetY)
+		sp.appendShape(clone)
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the Space, with every Shape in it copied (including the Shapes within any nested Spaces).
+// The copies have their own positions and tags, so they can be moved or resolved without affecting the original Shapes,
+// but share Data with the originals. Shapes other than the built-in ones have to implement Clone() Shape to be copied,
+// or Clone panics. The copy has the same settings as the Space: its root, the names of its Shapes, whether it's strict,
+// its elevation filter, its spatial hash, and how it's kept sorted. The functions registered with OnAdd() and OnRemove()
+// aren't copied.
+func (sp *Space) Clone() *Space {
+
+	c := NewSpace(WithSpatialHash(sp.hashCellSize))
+
+	// A Shape in the Space more than once is copied once, and the copy put in the new Space as many times.
+	clones := make(map[Shape]Shape, len(sp.shapes))
+	for _, shape := range sp.shapes {
+		clone, copied := clones[shape]
+		if !copied {
+			clone = cloneShape(shape)
+			clones[shape] = clone
+		}
+		c.Add(clone)
+	}
+
+	c.root = clones[sp.root]
+	c.SetStrict(sp.strict)
+	for name, shape := range sp.names {
+		c.setName(name, clones[shape])
+	}
+	c.elevationFilter = sp.elevationFilter
+
+	// The Shapes were added in the Space's order, so they're already sorted.
+	c.sortedBy = sp.sortedBy
+	if sp.sortedX != nil {
+		c.KeepSortedX()
+	}
+
+	return c
+
+}
+
+// CloneWithoutData returns a deep copy of the Space like Clone() does, but with the Data of every copied Shape set to nil
+// rather than shared with the originals.
+func (sp *Space) CloneWithoutData() *Space {
+	c := sp.Clone()
+	c.SetAllData(nil)
+	return c
+}
+
+// OnAdd registers a function to be called whenever a Shape is added to the Space, through any of the Space's functions.
+// The function is called once the Shape is in the Space, so the Space can be queried from it. Multiple functions can be
+// registered, and are called in the order they were registered.
+func (sp *Space) OnAdd(listener func(Shape)) {
+	sp.onAdd = append(sp.onAdd, listener)
+}
+
+// OnRemove registers a function to be called whenever a Shape is removed from the Space, through any of the Space's
+// functions (including Clear(), which calls it once for each Shape). The function is called once the Shape is out of the
+// Space, so the Space can be queried from it. Multiple functions can be registered, and are called in the order they were
+// registered.
+func (sp *Space) OnRemove(listener func(Shape)) {
+	sp.onRemove = append(sp.onRemove, listener)
+}
+
+func (sp *Space) added(shape Shape) {
+	sp.stats.setShapes(len(sp.shapes))
+	sp.watchShape(shape)
+	sp.sortedX.invalidate()
+	sp.changed()
+	if sp.recorder != nil {
+		sp.recorder.add(shape)
+	}
+	for _, listener := range sp.onAdd {
+		listener(shape)
+	}
+}
+
+func (sp *Space) removed(shape Shape) {
+	sp.stats.setShapes(len(sp.shapes))
+	sp.forgetName(shape)
+	sp.unwatchShape(shape)
+	sp.sortedX.invalidate()
+	sp.changed()
+	if sp.recorder != nil {
+		sp.recorder.remove(shape)
+	}
+	for _, listener := range sp.onRemove {
+		listener(shape)
+	}
+}
+
+// Clear "resets" the Space, cleaning out the Space of references to Shapes. The functions registered with OnRemove() are
+// called for each Shape that was in the Space.
 func (sp *Space) Clear() {
-	*sp = make(Space, 0)
+	shapes := sp.shapes
+	sp.ClearWithoutCallbacks()
+	if len(sp.onRemove) > 0 {
+		for _, shape := range shapes {
+			sp.removed(shape)
+		}
+	}
+}
+
+// ClearWithoutCallbacks "resets" the Space like Clear() does, but without calling the functions registered with
+// OnRemove(), which can be useful when clearing large Spaces.
+func (sp *Space) ClearWithoutCallbacks() {
+	sp.releaseArenaShapes()
+	sp.shapes = make([]Shape, 0)
+	sp.resetIndexes()
+}
+
+// truncate empties the Space like ClearWithoutCallbacks() does, but keeps the Space's slice of Shapes (and its indexes'
+// maps), so that filling the Space again doesn't allocate.
+func (sp *Space) truncate() {
+	sp.releaseArenaShapes()
+	for i := range sp.shapes {
+		sp.shapes[i] = nil
+	}
+	sp.shapes = sp.shapes[:0]
+	sp.resetIndexes()
 }
 
-// IsColliding returns whether the provided Shape is colliding with something in this Space.
+// resetIndexes resets the Space's indexes once its Shapes have been cleared out.
+func (sp *Space) resetIndexes() {
+	sp.stats.setShapes(0)
+	sp.tags.invalidate()
+	sp.sortedX.invalidate()
+	sp.handles.clear()
+	if sp.recorder != nil {
+		sp.recorder.clear()
+	}
+	sp.root = nil
+	sp.names = nil
+	sp.nameOf = nil
+	for shape := range sp.members {
+		delete(sp.members, shape)
+	}
+	for shape, remove := range sp.watched {
+		remove()
+		delete(sp.watched, shape)
+	}
+	sp.nestsResults = false
+	sp.changed()
+}
+
+// IsColliding returns whether the provided Shape is colliding with something in this Space. Shapes whose collision layers
+// and masks don't match (see LayersCollide) or that are on different elevations (see SetElevationFilter) are skipped, as
+// they are in the other collision functions on Space.
 func (sp *Space) IsColliding(shape Shape) bool {
+	return sp.isCollidingAs(shape, shape)
+}
 
-	for _, other := range *sp {
+// isCollidingAs returns whether the tested Shape is colliding with something in this Space, skipping the Shape provided
+// rather than the tested one. This allows a displaced copy of a Shape to be tested in its place.
+func (sp *Space) isCollidingAs(shape, tested Shape) bool {
+	return sp.collidingShapesAs(shape, tested, 1, nil) > 0
+}
 
-		if other != shape {
+// collidingShapesAs adds the Shapes in this Space that the tested Shape is colliding with to out (if it isn't nil),
+// skipping the Shape provided rather than the tested one, and returns how many it found. It stops once max Shapes have
+// been found, unless max is 0 or less.
+func (sp *Space) collidingShapesAs(shape, tested Shape, max int, out *Space) int {
 
-			if shape.IsColliding(other) {
-				return true
+	defer sp.stats.timeSince(sp.stats.now())
+	defer traceEnd("Space.IsColliding", traceStart())
+
+	// A wrapped Shape (like a TypedShape) is tested as the Shape it wraps, so that it skips itself in the Space.
+	shape, tested = unwrapShape(shape), unwrapShape(tested)
+
+	// Shapes whose bounds don't overlap the tested Shape's are skipped without testing them, when both of their bounds
+	// are known.
+	bounds, bounded := shapeBounds(tested)
+
+	shapes := sp.shapes
+	if bounded {
+		broad := traceStart()
+		if near := sp.shapesNearX(bounds.minX, bounds.maxX); near != nil {
+			defer near.release()
+			shapes = near.shapes
+		}
+		traceEnd("Space.IsColliding/broad", broad)
+	}
+
+	found := 0
+
+	for _, other := range shapes {
+
+		sp.stats.candidate()
+
+		if other != shape && sp.canCollide(shape, other) {
+
+			if bounded {
+				if otherBounds, ok := shapeBounds(other); ok && !bounds.overlaps(otherBounds) {
+					continue
+				}
+			}
+
+			sp.stats.test()
+			if collidingAs(shape, tested, other) {
+				if out != nil {
+					out.Add(other)
+				}
+				found++
+				if found == max {
+					break
+				}
 			}
 
 		}
 
 	}
 
-	return false
+	return found
 
 }
 
 // GetCollidingShapes returns a Space comprised of Shapes that collide with the checking Shape.
 func (sp *Space) GetCollidingShapes(shape Shape) *Space {
+	return sp.GetCollidingShapesInto(shape, newResultSpace())
+}
+
+// GetCollidingShapesN returns a Space comprised of up to max Shapes that collide with the checking Shape, in the order
+// they're in within the Space, like GetCollidingShapes() does. It stops going through the Space once it's found max of
+// them, so it's cheaper when only a few are needed (like "is this attack hitting any enemy", or "the first 3 enemies
+// it's hitting"). If max is 0 or less, there's no limit, and it returns the same Shapes as GetCollidingShapes().
+// IsColliding() is the same as checking whether GetCollidingShapesN() with a max of 1 returns a Shape.
+func (sp *Space) GetCollidingShapesN(shape Shape, max int) *Space {
+	out := newResultSpace()
+	sp.collidingShapesAs(shape, shape, max, out)
+	return out
+}
+
+// GetCollidingShapesInto puts the Shapes that collide with the checking Shape into the Space provided and returns it,
+// like GetCollidingShapes() does with a new Space. The Space provided is emptied first like with ClearWithoutCallbacks(),
+// but keeps its slice of Shapes, so reusing the same Space for each call (like each frame) doesn't allocate once it's
+// grown large enough. If out is nil, a new Space is used. out mustn't be the Space itself.
+func (sp *Space) GetCollidingShapesInto(shape Shape, out *Space) *Space {
+
+	if out == nil {
+		out = NewSpace()
+	} else {
+		out.truncate()
+	}
+
+	sp.collidingShapesAs(shape, shape, 0, out)
+
+	return out
+
+}
+
+// GetCollidingPairs returns every pair of Shapes in the Space that are colliding with each other. Each pair is reported
+// exactly once, and a Shape is never paired with itself. Spaces contained within the Space are treated as compound
+// Shapes, so their member Shapes are reported rather than the Space itself; members of the same compound Space aren't
+// tested against each other. See WithSpatialHash() for speeding this up for Spaces with many Shapes.
+func (sp *Space) GetCollidingPairs() []CollisionPair {
+
+	defer sp.stats.timeSince(sp.stats.now())
+
+	leaves := sp.pairLeaves()
+	if sp.hashCellSize > 0 {
+		return sp.hashedPairs(leaves)
+	}
+
+	pairs := []CollisionPair{}
+
+	for i := range leaves {
+		pairs = sp.appendPairs(leaves, pairs, i)
+	}
+
+	return pairs
 
-	newSpace := NewSpace()
+}
+
+// pairLeaf is a Shape to be tested by GetCollidingPairs(), along with the index of the member of the Space it belongs to.
+type pairLeaf struct {
+	shape Shape
+	group int
+}
+
+type pairLeaves []pairLeaf
 
-	for _, other := range *sp {
-		if other != shape {
-			if shape.IsColliding(other) {
-				newSpace.Add(other)
+// pairLeaves returns the Shapes in the Space, with the members of nested Spaces flattened out.
+func (sp *Space) pairLeaves() pairLeaves {
+
+	leaves := pairLeaves{}
+
+	var flatten func(shape Shape, group int)
+	flatten = func(shape Shape, group int) {
+		if inner, ok := shape.(*Space); ok {
+			for _, s := range inner.shapes {
+				flatten(s, group)
 			}
+			return
 		}
+		leaves = append(leaves, pairLeaf{shape, group})
 	}
 
-	return newSpace
+	for i, shape := range sp.shapes {
+		flatten(shape, i)
+	}
 
+	return leaves
+
+}
+
+// appendPairs appends the colliding pairs made up of the leaf at index i and the leaves after it to the pairs provided.
+func (sp *Space) appendPairs(leaves pairLeaves, pairs []CollisionPair, i int) []CollisionPair {
+	for _, b := range leaves[i+1:] {
+		pairs = sp.appendPair(leaves[i], b, pairs)
+	}
+	return pairs
 }
 
-// Resolve runs Resolve() using the checking Shape, checking against all other Shapes in the Space. The first Collision
-// that returns true is the Collision that gets returned.
+// appendPair appends the pair of leaves provided to the pairs provided if they're colliding.
+func (sp *Space) appendPair(a, b pairLeaf, pairs []CollisionPair) []CollisionPair {
+	sp.stats.candidate()
+	if a.group == b.group || a.shape == b.shape || !sp.canCollide(a.shape, b.shape) {
+		return pairs
+	}
+	sp.stats.test()
+	if a.shape.IsColliding(b.shape) {
+		pairs = append(pairs, CollisionPair{a.shape, b.shape})
+	}
+	return pairs
+}
+
+// Resolve runs Resolve() using the checking Shape, checking against all other Shapes in the Space. The Collision
+// returned is the one with the Shape nearest along the movement, the one the checking Shape would reach first, so it
+// can't pass through a nearer Shape because a further one comes before it in the Space. If several Shapes stop the
+// checking Shape at the same point, the first of them in the Space is returned. If nothing's in the way, the Collision
+// returned is empty (see resolveDelta()).
 func (sp *Space) Resolve(checkingShape Shape, deltaX, deltaY int32) Collision {
 
+	defer sp.stats.timeSince(sp.stats.now())
+	defer traceEnd("Space.Resolve", traceStart())
+	sp.stats.resolve()
+
+	checkingShape = unwrapShape(checkingShape)
+
 	res := Collision{}
 
-	for _, other := range *sp {
+	// Only the Shapes within reach of the area the checking Shape sweeps through can stop it.
+	broad := traceStart()
+	shapes := sp.shapes
+	if bounds, ok := shapeBounds(checkingShape); ok {
+		minX, maxX := bounds.minX, bounds.maxX
+		if deltaX < 0 {
+			minX += int64(deltaX)
+		} else {
+			maxX += int64(deltaX)
+		}
+		if near := sp.shapesNearX(minX, maxX); near != nil {
+			defer near.release()
+			shapes = near.shapes
+		}
+	}
+	traceEnd("Space.Resolve/broad", broad)
+
+	for _, other := range shapes {
 
-		if other != checkingShape && checkingShape.WouldBeColliding(other, int32(deltaX), int32(deltaY)) {
-			res = Resolve(checkingShape, other, deltaX, deltaY)
-			if res.Colliding() {
-				break
+		sp.stats.candidate()
+
+		if other != checkingShape && sp.canCollide(checkingShape, other) {
+			sp.stats.test()
+			// The whole path is checked, so even a Shape that isn't colliding at the destination can stop the movement.
+			collision, steps := resolve(checkingShape, other, deltaX, deltaY)
+			sp.stats.resolveSteps(steps)
+			if collision.Colliding() && (!res.Colliding() || nearer(collision, res, deltaX, deltaY)) {
+				res = collision
 			}
 		}
 
 	}
 
+	if sp.recorder != nil {
+		sp.recorder.resolve(checkingShape, deltaX, deltaY, res)
+	}
+
 	return res
 
 }
 
+// nearer returns whether the Collision a stops the movement by the delta provided before the Collision b does, going by
+// how far along the movement each lets the Shape get. Collisions that push the Shape back come before ones that don't.
+func nearer(a, b Collision, deltaX, deltaY int32) bool {
+	progress := func(c Collision) int64 {
+		return int64(c.ResolveX)*int64(deltaX) + int64(c.ResolveY)*int64(deltaY)
+	}
+	return progress(a) < progress(b)
+}
+
+// resolveDelta resolves moving the Shape by the delta provided against the Space with Space.Resolve(). As Space.Resolve()
+// returns an empty Collision when nothing's in the way, the Collision's ResolveX and ResolveY are filled in with the
+// full delta then, so they're always how far the Shape can move.
+func resolveDelta(space *Space, shape Shape, dx, dy int32) Collision {
+	col := space.Resolve(shape, dx, dy)
+	if !col.Colliding() {
+		col.ResolveX, col.ResolveY, col.ShapeA = dx, dy, shape
+	}
+	return col
+}
+
 // Filter filters out a Space, returning a new Space comprised of Shapes that return true for the boolean function you provide.
 // This can be used to focus on a set of object for collision testing or resolution, or lower the number of Shapes to test
 // by filtering some out beforehand.
 func (sp *Space) Filter(filterFunc func(Shape) bool) *Space {
-	subSpace := NewSpace()
-	for _, shape := range *sp {
+	return sp.FilterInto(filterFunc, newResultSpace())
+}
+
+// FilterInto puts the Shapes that return true for the function provided into the Space provided and returns it, like
+// Filter() does with a new Space. Like with GetCollidingShapesInto(), the Space provided is emptied first but keeps its
+// slice of Shapes, so reusing it doesn't allocate. If out is nil, a new Space is used. out mustn't be the Space itself.
+func (sp *Space) FilterInto(filterFunc func(Shape) bool, out *Space) *Space {
+	if out == nil {
+		out = NewSpace()
+	} else {
+		out.truncate()
+	}
+	for _, shape := range sp.shapes {
 		if filterFunc(shape) {
-			subSpace.Add(shape)
+			out.Add(shape)
 		}
 	}
-	return subSpace
+	return out
 }
 
 // FilterByTags filters a Space out, creating a new Space that has just the Shapes that have all of the specified tags.
+// The Space keeps an index of the tags on its Shapes to speed this up, so filtering doesn't have to check every Shape;
+// while the index is out of date, tags kept as bits (see TagID()) are tested with bit operations instead.
 func (sp *Space) FilterByTags(tags ...string) *Space {
-	return sp.Filter(func(s Shape) bool {
-		if s.HasTags(tags...) {
-			return true
+
+	if len(tags) == 0 {
+		return sp.Filter(func(s Shape) bool { return true })
+	}
+
+	// When all of the tags are kept as bits (see TagID()) and the index would have to be rebuilt (as the tags of its
+	// Shapes changed), testing each Shape's bits is much faster than rebuilding it.
+	if mask, ok := tagMask(tags); ok && !sp.tags.current() {
+		return sp.filterByTagBits(tags, mask)
+	}
+
+	subSpace := newResultSpace()
+	for _, index := range sp.tags.filter(sp.shapes, tags) {
+		subSpace.Add(sp.shapes[index])
+	}
+	return subSpace
+
+}
+
+// FilterByAnyTags filters a Space out, creating a new Space that has just the Shapes that have at least one of the
+// specified tags. If no tags are provided, the returned Space is empty.
+func (sp *Space) FilterByAnyTags(tags ...string) *Space {
+
+	subSpace := newResultSpace()
+	for _, index := range sp.tags.filterAny(sp.shapes, tags) {
+		subSpace.Add(sp.shapes[index])
+	}
+	return subSpace
+
+}
+
+// OverlappingTagged returns a new Space containing the Shapes in the Space that have the tag provided and that the Shape
+// provided is colliding with (other than the Shape itself), like the ladders or water a body is in. It finds the tagged
+// Shapes like FilterByTags() does, so only they're tested, rather than every Shape in the Space. Unlike the collision
+// functions, it ignores collision layers and elevation, so that it finds Shapes that the Shape passes through (like a
+// ladder on a layer that the Shape's mask doesn't include) too.
+func (sp *Space) OverlappingTagged(shape Shape, tag string) *Space {
+
+	overlapping := newResultSpace()
+	add := func(other Shape) {
+		if other != shape && shape.IsColliding(other) {
+			overlapping.Add(other)
 		}
-		return false
-	})
+	}
+
+	tags := []string{tag}
+	if mask, ok := tagMask(tags); ok && !sp.tags.current() {
+		for _, other := range sp.shapes {
+			if set, ok := indexableTagSet(other); ok {
+				if set.bits&mask == mask {
+					add(other)
+				}
+			} else if other.HasTags(tag) {
+				add(other)
+			}
+		}
+		return overlapping
+	}
+
+	for _, index := range sp.tags.filter(sp.shapes, tags) {
+		add(sp.shapes[index])
+	}
+	return overlapping
+
+}
+
+// CountByTags returns how many Shapes in the Space have all of the specified tags, without creating a new Space like
+// FilterByTags() does. If no tags are provided, it returns the number of Shapes in the Space.
+func (sp *Space) CountByTags(tags ...string) int {
+
+	switch len(tags) {
+	case 0:
+		return len(sp.shapes)
+	case 1:
+		return sp.tags.count(sp.shapes, tags[0])
+	}
+	return sp.tags.countAll(sp.shapes, tags)
+
+}
+
+// CountByAnyTags returns how many Shapes in the Space have at least one of the specified tags. If no tags are provided,
+// it returns 0.
+func (sp *Space) CountByAnyTags(tags ...string) int {
+
+	if len(tags) == 1 {
+		return sp.tags.count(sp.shapes, tags[0])
+	}
+
+	count := 0
+	for _, shape := range sp.shapes {
+		if hasAnyTags(shape, tags...) {
+			count++
+		}
+	}
+	return count
+
+}
+
+// TagCensus returns every tag present on the Shapes in the Space, along with how many Shapes have it. Shapes without tags
+// don't show up in the census, so an empty Space (or a Space of untagged Shapes) returns an empty map. For nested Spaces,
+// the tags of their root Shape are counted (see Space.GetTags()).
+func (sp *Space) TagCensus() map[string]int {
+	return sp.tags.census(sp.shapes)
 }
 
 // FilterOutByTags filters a Space out, creating a new Space that has just the Shapes that don't have all of the specified tags.
@@ -142,9 +947,32 @@ func (sp *Space) FilterOutByTags(tags ...string) *Space {
 	})
 }
 
+// FindByData returns the first Shape in the Space whose Data is equal to the data provided, or nil if there isn't one. This
+// is useful to find the Shape belonging to an entity when Data points to it. Data values that can't be compared (like
+// slices or maps) never match.
+func (sp *Space) FindByData(data interface{}) Shape {
+	for _, shape := range sp.shapes {
+		if dataEqual(shape.GetData(), data) {
+			return shape
+		}
+	}
+	return nil
+}
+
+// FilterByDataFunc filters a Space out, creating a new Space comprised of Shapes whose Data returns true for the boolean
+// function you provide. The function is called with nil for Shapes that don't have any Data set.
+func (sp *Space) FilterByDataFunc(filterFunc func(interface{}) bool) *Space {
+	return sp.Filter(func(s Shape) bool {
+		return filterFunc(s.GetData())
+	})
+}
+
 // Contains returns true if the Shape provided exists within the Space.
 func (sp *Space) Contains(shape Shape) bool {
-	for _, s := range *sp {
+	if sp.members != nil {
+		return sp.members[shape] > 0
+	}
+	for _, s := range sp.shapes {
 		if s == shape {
 			return true
 		}
@@ -152,12 +980,48 @@ func (sp *Space) Contains(shape Shape) bool {
 	return false
 }
 
-func (sp *Space) String() string {
-	str := ""
-	for _, s := range *sp {
-		str += fmt.Sprintf("%v   ", s)
+// LineOfSight returns true if there's a clear line of sight between the centers of the from and to Shapes, meaning that no
+// Shape in the Space that has all of the blocker tags provided intersects the line between them. The from and to Shapes
+// themselves never block the line. If no blocker tags are provided, any Shape in the Space can block sight. A Shape that
+// just touches the line (like a Circle exactly tangent to it) counts as blocking it.
+func (sp *Space) LineOfSight(from, to Shape, blockerTags ...string) bool {
+	x, y := shapeCenter(from)
+	x2, y2 := shapeCenter(to)
+	return sp.lineOfSight(NewLine(x, y, x2, y2), blockerTags, from, to)
+}
+
+// LineOfSightXY returns true if there's a clear line of sight from one pair of X and Y values to another, meaning that no
+// Shape in the Space that has all of the blocker tags provided intersects the line between them. See LineOfSight.
+func (sp *Space) LineOfSightXY(x, y, x2, y2 int32, blockerTags ...string) bool {
+	return sp.lineOfSight(NewLine(x, y, x2, y2), blockerTags)
+}
+
+func (sp *Space) lineOfSight(sight *Line, blockerTags []string, ignore ...Shape) bool {
+
+	for _, shape := range sp.shapes {
+
+		ignored := false
+		for _, i := range ignore {
+			if shape == i {
+				ignored = true
+				break
+			}
+		}
+
+		if ignored || !shape.HasTags(blockerTags...) {
+			continue
+		}
+
+		// The blocker tests against the sight line (rather than the other way around), as the Circle-Line test
+		// is implemented on the Circle's side.
+		if shape.IsColliding(sight) {
+			return false
+		}
+
 	}
-	return str
+
+	return true
+
 }
 
 /* -----------------------------
@@ -169,17 +1033,25 @@ that return singular values look at the first shape as a "root" of sorts.
 */
 
 // WouldBeColliding returns true if any of the Shapes within the Space would be colliding should they move along the delta
-// X and Y values provided (dx and dy).
+// X and Y values provided (dx and dy). If the other Shape is in the Space, it's skipped, rather than tested against
+// itself.
 func (sp *Space) WouldBeColliding(other Shape, dx, dy int32) bool {
 
-	for _, shape := range *sp {
+	other = unwrapShape(other)
+
+	for _, shape := range sp.shapes {
+
+		sp.stats.candidate()
 
 		if shape == other {
-			return false
+			continue
 		}
 
-		if shape.WouldBeColliding(other, dx, dy) {
-			return true
+		if sp.canCollide(shape, other) {
+			sp.stats.test()
+			if shape.WouldBeColliding(other, dx, dy) {
+				return true
+			}
 		}
 
 	}
@@ -188,40 +1060,104 @@ func (sp *Space) WouldBeColliding(other Shape, dx, dy int32) bool {
 
 }
 
-// GetTags returns the tag list of the first Shape within the Space. If there are no Shapes within the Space,
+// SetRoot sets the Shape provided as the root of the Space, which is the Shape the Space uses for its position, tags,
+// and other values when used as a compound Shape. The Shape has to be in the Space, or ErrNotInSpace is returned. Setting
+// the root to nil makes the Space use its first Shape as the root again, which is also what happens when the root is
+// removed from the Space.
+func (sp *Space) SetRoot(shape Shape) error {
+	if shape != nil && !sp.Contains(shape) {
+		return fmt.Errorf("%w: %v", ErrNotInSpace, shape)
+	}
+	sp.root = shape
+	sp.changed()
+	return nil
+}
+
+// Root returns the root Shape of the Space: the Shape set with SetRoot(), or the first Shape in the Space if one wasn't
+// set. If there aren't any Shapes within the Space, it returns nil.
+func (sp *Space) Root() Shape {
+	if sp.root != nil {
+		return sp.root
+	}
+	if len(sp.shapes) > 0 {
+		return sp.shapes[0]
+	}
+	return nil
+}
+
+// GetTags returns the tag list of the root Shape within the Space (see Root()). If there are no Shapes within the Space,
 // it returns an empty array of string type.
 func (sp *Space) GetTags() []string {
-	if len(*sp) > 0 {
-		return (*sp)[0].GetTags()
+	if root := sp.Root(); root != nil {
+		return root.GetTags()
 	}
 	return []string{}
 }
 
-// AddTags sets the provided tags on all Shapes contained within the Space.
+// GetTagsUnsafe returns the tag list of the root Shape within the Space like GetTags(), but without copying it if the
+// root Shape has a GetTagsUnsafe() function (like the built-in Shapes do), so the slice returned mustn't be changed (see
+// BasicShape.GetTagsUnsafe()). If there are no Shapes within the Space, it returns nil.
+func (sp *Space) GetTagsUnsafe() []string {
+	switch root := sp.Root().(type) {
+	case nil:
+		return nil
+	case interface{ GetTagsUnsafe() []string }:
+		return root.GetTagsUnsafe()
+	default:
+		return root.GetTags()
+	}
+}
+
+// AddTags sets the provided tags on all Shapes contained within the Space. As GetTags() only reads the root Shape, use
+// AddRootTags() to tag the Space as a whole without tagging each of its parts.
 func (sp *Space) AddTags(tags ...string) {
-	for _, shape := range *sp {
+	for _, shape := range sp.shapes {
 		shape.AddTags(tags...)
 	}
 }
 
+// AddRootTags sets the provided tags on just the root Shape within the Space (see Root()); if the root is itself a Space,
+// its root is tagged, and so on. These are the tags GetTags() returns. If there aren't any Shapes within the Space,
+// nothing happens.
+func (sp *Space) AddRootTags(tags ...string) {
+	switch root := sp.Root().(type) {
+	case nil:
+	case *Space:
+		root.AddRootTags(tags...)
+	default:
+		root.AddTags(tags...)
+	}
+}
+
 // RemoveTags removes the provided tags from all Shapes contained within the Space.
 func (sp *Space) RemoveTags(tags ...string) {
-	for _, shape := range *sp {
+	for _, shape := range sp.shapes {
 		shape.RemoveTags(tags...)
 	}
 }
 
 // ClearTags removes all tags from all Shapes within the Space.
 func (sp *Space) ClearTags() {
-	for _, shape := range *sp {
+	for _, shape := range sp.shapes {
 		shape.ClearTags()
 	}
 }
 
-// HasTags returns true if all of the Shapes contained within the Space have the tags specified.
+// HasTags returns true if all of the Shapes contained within the Space have the tags specified; it's the same as
+// AllHaveTags(). An empty Space has no tags, so HasTags always returns false for it, rather than being vacuously true.
 func (sp *Space) HasTags(tags ...string) bool {
+	return sp.AllHaveTags(tags...)
+}
 
-	for _, shape := range *sp {
+// AllHaveTags returns true if every Shape contained within the Space has all of the tags specified. It returns false for
+// an empty Space.
+func (sp *Space) AllHaveTags(tags ...string) bool {
+
+	if len(sp.shapes) == 0 {
+		return false
+	}
+
+	for _, shape := range sp.shapes {
 		if !shape.HasTags(tags...) {
 			return false
 		}
@@ -230,49 +1166,103 @@ func (sp *Space) HasTags(tags ...string) bool {
 
 }
 
-// GetData returns the pointer to the object contained in the Data field of the first Shape within the Space. If there aren't
-// any Shapes within the Space, it returns nil.
+// AnyHasTags returns true if at least one Shape contained within the Space has all of the tags specified. It returns
+// false for an empty Space. See HasAnyTags() for matching any one of several tags instead.
+func (sp *Space) AnyHasTags(tags ...string) bool {
+
+	for _, shape := range sp.shapes {
+		if shape.HasTags(tags...) {
+			return true
+		}
+	}
+	return false
+
+}
+
+// HasAnyTags returns true if any of the Shapes contained within the Space has at least one of the tags specified. Unlike
+// HasTags, which requires every Shape to have the tags, this is true as soon as a single Shape matches, so a compound
+// Space counts as having a tag when any part of it has it. An empty Space doesn't have any tags.
+func (sp *Space) HasAnyTags(tags ...string) bool {
+
+	for _, shape := range sp.shapes {
+		if hasAnyTags(shape, tags...) {
+			return true
+		}
+	}
+	return false
+
+}
+
+// GetData returns the pointer to the object contained in the Data field of the root Shape within the Space (see Root()). If
+// there aren't any Shapes within the Space, it returns nil.
 func (sp *Space) GetData() interface{} {
 
-	if len(*sp) > 0 {
-		return (*sp)[0].GetData()
+	if root := sp.Root(); root != nil {
+		return root.GetData()
 	}
 	return nil
 
 }
 
 // SetData sets the pointer provided to the Data field of all Shapes within the Space.
+//
+// Deprecated: SetData sets the Data of every Shape, while GetData() only reads the root Shape's, which is easy to get
+// wrong; use SetAllData() or SetRootData() to say which is meant. SetData is the same as SetAllData(), and is kept so the
+// Space still fulfills the Shape interface.
 func (sp *Space) SetData(data interface{}) {
+	sp.SetAllData(data)
+}
+
+// SetAllData sets the pointer provided to the Data field of all Shapes within the Space (including the Shapes within
+// nested Spaces), so they all share it. GetData() returns it afterwards.
+func (sp *Space) SetAllData(data interface{}) {
 
-	for _, shape := range *sp {
-		shape.SetData(data)
+	for _, shape := range sp.shapes {
+		if inner, ok := shape.(*Space); ok {
+			inner.SetAllData(data)
+		} else {
+			shape.SetData(data)
+		}
 	}
 
 }
 
-// GetXY returns the X and Y position of the first Shape in the Space. If there aren't any Shapes within the Space, it
-// returns 0, 0.
+// SetRootData sets the pointer provided to the Data field of just the root Shape within the Space (see Root()); if the
+// root is itself a Space, its root's Data is set, and so on. The Data of the other Shapes is left as it is. GetData()
+// returns it afterwards. If there aren't any Shapes within the Space, nothing happens.
+func (sp *Space) SetRootData(data interface{}) {
+	switch root := sp.Root().(type) {
+	case nil:
+	case *Space:
+		root.SetRootData(data)
+	default:
+		root.SetData(data)
+	}
+}
+
+// GetXY returns the X and Y position of the root Shape in the Space (see Root()). If there aren't any Shapes within the
+// Space, it returns 0, 0.
 func (sp *Space) GetXY() (int32, int32) {
 
-	if len(*sp) > 0 {
-		return (*sp)[0].GetXY()
+	if root := sp.Root(); root != nil {
+		return root.GetXY()
 	}
 	return 0, 0
 
 }
 
-// SetXY sets the X and Y position of all Shapes within the Space to the position provided using the first Shape's position as
-// reference. Basically, it moves the first Shape within the Space to the target location and then moves all other Shapes
-// by the same delta movement.
+// SetXY sets the X and Y position of all Shapes within the Space to the position provided using the root Shape's position as
+// reference. Basically, it moves the root Shape within the Space (see Root()) to the target location and then moves all
+// other Shapes by the same delta movement.
 func (sp *Space) SetXY(x, y int32) {
 
-	if len(*sp) > 0 {
+	if len(sp.shapes) > 0 {
 
 		x0, y0 := sp.GetXY()
 		dx := x - x0
 		dy := y - y0
 
-		for _, shape := range *sp {
+		for _, shape := range sp.shapes {
 			shape.Move(dx, dy)
 		}
 
@@ -280,19 +1270,208 @@ func (sp *Space) SetXY(x, y int32) {
 
 }
 
+// GetLayer returns the collision layer bits of the root Shape in the Space (see Root()). If there aren't any Shapes within
+// the Space, it returns DefaultLayer.
+func (sp *Space) GetLayer() uint32 {
+	if root := sp.Root(); root != nil {
+		return shapeLayer(root)
+	}
+	return DefaultLayer
+}
+
+// SetLayer sets the collision layer bits of all Shapes within the Space.
+func (sp *Space) SetLayer(layer uint32) {
+	for _, shape := range sp.shapes {
+		if s, ok := shape.(interface{ SetLayer(uint32) }); ok {
+			s.SetLayer(layer)
+		}
+	}
+}
+
+// GetMask returns the collision mask of the root Shape in the Space (see Root()). If there aren't any Shapes within the
+// Space, it returns AllLayers.
+func (sp *Space) GetMask() uint32 {
+	if root := sp.Root(); root != nil {
+		return shapeMask(root)
+	}
+	return AllLayers
+}
+
+// SetMask sets the collision mask of all Shapes within the Space.
+func (sp *Space) SetMask(mask uint32) {
+	for _, shape := range sp.shapes {
+		if s, ok := shape.(interface{ SetMask(uint32) }); ok {
+			s.SetMask(mask)
+		}
+	}
+}
+
+// GetBoundingRect returns a Rectangle that wholly contains the bounding rectangles of all of the Shapes within the Space
+// (including the Shapes within any nested Spaces). If there aren't any Shapes within the Space, it returns a Rectangle
+// with a width and height of 0 at 0, 0.
+func (sp *Space) GetBoundingRect() *Rectangle {
+	r := &Rectangle{}
+	sp.GetBoundingRectInto(r)
+	return r
+}
+
+// GetBoundingRectInto sets the position and size of the Rectangle provided to the Space's bounding rectangle (see
+// GetBoundingRect()), without allocating a new one. The rest of dst (its tags, Data, etc.) is left as it is.
+func (sp *Space) GetBoundingRectInto(dst *Rectangle) {
+
+	if len(sp.shapes) == 0 {
+		dst.X, dst.Y, dst.W, dst.H = 0, 0, 0, 0
+		return
+	}
+
+	var r Rectangle
+	boundingRectInto(sp.shapes[0], &r)
+	x, y, x2, y2 := r.X, r.Y, r.X+r.W, r.Y+r.H
+
+	for _, shape := range sp.shapes[1:] {
+		boundingRectInto(shape, &r)
+		if r.X < x {
+			x = r.X
+		}
+		if r.Y < y {
+			y = r.Y
+		}
+		if r.X+r.W > x2 {
+			x2 = r.X + r.W
+		}
+		if r.Y+r.H > y2 {
+			y2 = r.Y + r.H
+		}
+	}
+
+	dst.X, dst.Y, dst.W, dst.H = x, y, x2-x, y2-y
+
+}
+
 // Move moves all Shapes in the Space by the displacement provided.
 func (sp *Space) Move(dx, dy int32) {
-	for _, shape := range *sp {
+	for _, shape := range sp.shapes {
 		shape.Move(dx, dy)
 	}
 }
 
-// Length returns the length of the Space (number of Shapes contained within the Space). This is a convenience function, standing in for len(*space).
+// ForEach calls the function provided for each Shape in the Space, in order, stopping early if the function returns
+// false. Shapes can be safely removed with Remove() from within the function; they're removed once ForEach is done. Shapes
+// added from within the function are iterated over as well.
+func (sp *Space) ForEach(forEach func(Shape) bool) {
+
+	sp.iterating++
+
+	defer func() {
+		sp.iterating--
+		if sp.iterating == 0 && len(sp.deferred) > 0 {
+			deferred := sp.deferred
+			sp.deferred = nil
+			sp.Remove(deferred...)
+		}
+		if sp.iterating == 0 && len(sp.deferredH) > 0 {
+			deferred := sp.deferredH
+			sp.deferredH = nil
+			for _, h := range deferred {
+				sp.RemoveH(h)
+			}
+		}
+	}()
+
+	for i := 0; i < len(sp.shapes); i++ {
+		if !forEach(sp.shapes[i]) {
+			break
+		}
+	}
+
+}
+
+// MoveWhere moves the Shapes in the Space for which the function provided returns true by the displacement provided, in
+// place (without creating a new Space like Filter() followed by Move() would). Each Shape is tested and moved only once,
+// even if it's in the Space more than once.
+func (sp *Space) MoveWhere(dx, dy int32, predicate func(Shape) bool) {
+
+	// Only the Shapes in the Space more than once have to be remembered, so the set's only made if there are any, which
+	// is when there are fewer distinct Shapes than Shapes.
+	sp.trackMembers()
+	duplicates := len(sp.members) < len(sp.shapes)
+	var moved map[Shape]bool
+
+	for _, shape := range sp.shapes {
+		if duplicates && sp.members[shape] > 1 {
+			if moved[shape] {
+				continue
+			}
+			if moved == nil {
+				moved = map[Shape]bool{}
+			}
+			moved[shape] = true
+		}
+		if predicate(shape) {
+			shape.Move(dx, dy)
+		}
+	}
+
+}
+
+// MoveTagged moves the Shapes in the Space that have all of the specified tags by the displacement provided, in place.
+// Like with MoveWhere(), each Shape is moved only once, even if it's in the Space more than once.
+func (sp *Space) MoveTagged(dx, dy int32, tags ...string) {
+	sp.MoveWhere(dx, dy, func(shape Shape) bool {
+		return shape.HasTags(tags...)
+	})
+}
+
+// Length returns the length of the Space (number of Shapes contained within the Space). As the Space is a struct, this
+// replaces len(*space), which worked when a Space was a slice of Shapes.
 func (sp *Space) Length() int {
-	return len(*sp)
+	return len(sp.shapes)
 }
 
-// Get allows you to get a Shape by index from the Space easily. This is a convenience function, standing in for (*space)[index].
+// Get allows you to get a Shape by index from the Space easily. As the Space is a struct, this replaces (*space)[index],
+// which worked when a Space was a slice of Shapes.
 func (sp *Space) Get(index int) Shape {
-	return (*sp)[index]
+	return sp.shapes[index]
+}
+
+// Shapes returns a copy of the slice of Shapes in the Space, in order. As the Space is a struct, this replaces using the
+// Space as a slice of Shapes directly (like ranging over *space), which worked when a Space was a slice of Shapes. The
+// slice is a copy, so changing it doesn't change the Space.
+func (sp *Space) Shapes() []Shape {
+	return append([]Shape(nil), sp.shapes...)
+}
+
+// GetSafe returns the Shape at the index provided and true, or nil and false if the index is out of range, rather than
+// panicking like Get() does.
+func (sp *Space) GetSafe(index int) (Shape, bool) {
+	if index < 0 || index >= len(sp.shapes) {
+		return nil, false
+	}
+	return sp.shapes[index], true
+}
+
+// First returns the first Shape in the Space, or nil if the Space is empty.
+func (sp *Space) First() Shape {
+	if len(sp.shapes) == 0 {
+		return nil
+	}
+	return sp.shapes[0]
+}
+
+// Last returns the last Shape in the Space, or nil if the Space is empty.
+func (sp *Space) Last() Shape {
+	if len(sp.shapes) == 0 {
+		return nil
+	}
+	return sp.shapes[len(sp.shapes)-1]
+}
+
+// IndexOf returns the index of the first occurrence of the Shape provided in the Space, or -1 if it isn't in the Space.
+func (sp *Space) IndexOf(shape Shape) int {
+	for i, s := range sp.shapes {
+		if s == shape {
+			return i
+		}
+	}
+	return -1
 }
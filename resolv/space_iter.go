@@ -0,0 +1,16 @@
+//go:build go1.23
+
+package resolv
+
+import "iter"
+
+// All returns an iterator over the Shapes in the Space, allowing the Space to be looped over with range:
+//
+//	for shape := range space.All() {
+//		...
+//	}
+//
+// Like with ForEach(), Shapes can be safely removed with Remove() within the loop.
+func (sp *Space) All() iter.Seq[Shape] {
+	return sp.ForEach
+}
@@ -0,0 +1,1352 @@
+package resolv
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestSpaceResolveNearest(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		walls        [][4]int32
+		dx, dy       int32
+		wantX, wantY int32
+		wantWall     int
+	}{
+		{"far wall added first", [][4]int32{{40, 0, 4, 16}, {20, 0, 4, 16}}, 60, 0, 12, 0, 1},
+		{"near wall added first", [][4]int32{{20, 0, 4, 16}, {40, 0, 4, 16}}, 60, 0, 12, 0, 0},
+		{"moving left", [][4]int32{{-40, 0, 4, 16}, {-20, 0, 4, 16}}, -60, 0, -16, 0, 1},
+		{"moving down", [][4]int32{{0, 50, 16, 4}, {0, 30, 16, 4}}, 0, 60, 0, 14, 1},
+		{"tie keeps Space order", [][4]int32{{20, 0, 4, 8}, {20, 8, 4, 8}}, 60, 0, 12, 0, 0},
+		{"nothing in the way", [][4]int32{{0, 40, 4, 4}}, 60, 0, 0, 0, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			var walls []Shape
+			for _, w := range tt.walls {
+				wall := NewRectangle(w[0], w[1], w[2], w[3])
+				walls = append(walls, wall)
+				space.Add(wall)
+			}
+			player := NewRectangle(0, 0, 8, 16)
+			space.Add(player)
+
+			view := space.View(func(Shape) bool { return true })
+
+			for name, col := range map[string]Collision{
+				"Space.Resolve()":     space.Resolve(player, tt.dx, tt.dy),
+				"SpaceView.Resolve()": view.Resolve(player, tt.dx, tt.dy),
+			} {
+				if tt.wantWall < 0 {
+					if col.Colliding() {
+						t.Errorf("%s collided with %v, want no collision", name, col.ShapeB)
+					}
+					continue
+				}
+				if col.ShapeB != walls[tt.wantWall] {
+					t.Errorf("%s ShapeB = %v, want wall %d", name, col.ShapeB, tt.wantWall)
+				}
+				if col.ResolveX != tt.wantX || col.ResolveY != tt.wantY {
+					t.Errorf("%s = %d, %d, want %d, %d", name, col.ResolveX, col.ResolveY, tt.wantX, tt.wantY)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestResolveDelta(t *testing.T) {
+
+	space := NewSpace()
+	player := NewRectangle(0, 0, 8, 8)
+	space.Add(player, NewRectangle(40, 0, 8, 8))
+
+	tests := []struct {
+		name         string
+		dx, dy       int32
+		wantX, wantY int32
+		colliding    bool
+	}{
+		{"free", 0, 20, 0, 20, false},
+		{"blocked", 60, 0, 32, 0, true},
+		{"zero", 0, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := resolveDelta(space, player, tt.dx, tt.dy)
+			if col.ResolveX != tt.wantX || col.ResolveY != tt.wantY || col.Colliding() != tt.colliding {
+				t.Errorf("resolveDelta() = %d, %d, %v, want %d, %d, %v", col.ResolveX, col.ResolveY, col.Colliding(),
+					tt.wantX, tt.wantY, tt.colliding)
+			}
+		})
+	}
+
+}
+
+func BenchmarkSpaceResolve(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 100; i++ {
+		space.Add(NewRectangle(1000-i*8, 0, 4, 16))
+	}
+	player := NewRectangle(0, 0, 8, 16)
+	space.Add(player)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		space.Resolve(player, 2000, 0)
+	}
+
+}
+
+func TestSpaceCombine(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		setup   func(sp, other *Space, shared Shape)
+		self    bool
+		wantErr error
+	}{
+		{"moves shapes over", func(sp, other *Space, shared Shape) {}, false, nil},
+		{"self", func(sp, other *Space, shared Shape) {}, true, ErrSelfAddition},
+		{"duplicate in strict space", func(sp, other *Space, shared Shape) {
+			sp.SetStrict(true)
+			sp.Add(shared)
+		}, false, ErrDuplicateShape},
+		{"other contains the space", func(sp, other *Space, shared Shape) {
+			other.Add(sp)
+		}, false, ErrSelfAddition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			sp, other := NewSpace(), NewSpace()
+			shared := NewRectangle(0, 0, 8, 8)
+			other.Add(shared, NewCircle(20, 20, 4))
+			tt.setup(sp, other, shared)
+			if tt.self {
+				other = sp
+			}
+			before, otherBefore := sp.Length(), other.Length()
+
+			err := sp.Combine(other, 5, 5)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Combine() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if sp.Length() != before || other.Length() != otherBefore {
+					t.Errorf("failed Combine() changed the Spaces: %d and %d shapes, want %d and %d", sp.Length(),
+						other.Length(), before, otherBefore)
+				}
+				if x, y := shared.GetXY(); x != 0 || y != 0 {
+					t.Errorf("failed Combine() moved a Shape to %d, %d", x, y)
+				}
+				return
+			}
+			if sp.Length() != before+otherBefore || other.Length() != 0 {
+				t.Errorf("Combine() left %d and %d shapes, want %d and 0", sp.Length(), other.Length(), before+otherBefore)
+			}
+			if x, y := shared.GetXY(); x != 5 || y != 5 {
+				t.Errorf("Combine() moved a Shape to %d, %d, want 5, 5", x, y)
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkSpaceCombine(b *testing.B) {
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		sp, other := NewSpace(), NewSpace()
+		for j := int32(0); j < 1000; j++ {
+			other.Add(NewRectangle(j*8, 0, 8, 8))
+		}
+		b.StartTimer()
+		sp.Combine(other, 16, 16)
+	}
+
+}
+
+func TestNameKeptWhileShapeRemains(t *testing.T) {
+
+	space := NewSpace()
+	door := NewRectangle(0, 0, 8, 8)
+	if err := space.AddNamed("boss_door", door); err != nil {
+		t.Fatal(err)
+	}
+	space.Add(door)
+
+	space.Remove(door)
+	if got := space.GetByName("boss_door"); got != door {
+		t.Fatalf("GetByName() after removing one copy = %v, want the Shape still in the Space", got)
+	}
+
+	space.Remove(door)
+	if got := space.GetByName("boss_door"); got != nil {
+		t.Errorf("GetByName() after removing every copy = %v, want nil", got)
+	}
+	if err := space.AddNamed("boss_door", NewRectangle(0, 0, 8, 8)); err != nil {
+		t.Errorf("AddNamed() reusing the name = %v", err)
+	}
+
+}
+
+func TestLineOfSight(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		blocker Shape
+		tags    []string
+		want    bool
+	}{
+		{"clear", NewCircle(50, 20, 5), nil, true},
+		{"blocked", NewRectangle(45, -5, 10, 10), nil, false},
+		{"tangent Circle", NewCircle(50, 10, 10), nil, false},
+		{"just past tangent", NewCircle(50, 11, 10), nil, true},
+		{"blocker without the tags", NewRectangle(45, -5, 10, 10), []string{"wall"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			from, to := NewRectangle(-4, -4, 8, 8), NewRectangle(96, -4, 8, 8)
+			space := NewSpace()
+			space.Add(from, to, tt.blocker)
+
+			if got := space.LineOfSight(from, to, tt.tags...); got != tt.want {
+				t.Errorf("LineOfSight() = %v, want %v", got, tt.want)
+			}
+			if got := space.LineOfSightXY(10, 0, 90, 0, tt.tags...); got != tt.want {
+				t.Errorf("LineOfSightXY() = %v, want %v", got, tt.want)
+			}
+
+		})
+	}
+
+	// The Shapes sight is between don't block it, but they do block sight given by coordinates.
+	from, to := NewRectangle(-4, -4, 8, 8), NewRectangle(96, -4, 8, 8)
+	space := NewSpace()
+	space.Add(from, to)
+	if !space.LineOfSight(from, to) {
+		t.Error("LineOfSight() = false, want the from and to Shapes ignored")
+	}
+	if space.LineOfSightXY(0, 0, 100, 0) {
+		t.Error("LineOfSightXY() = true, want the Shapes at the ends to block it")
+	}
+
+}
+
+func TestGetCollidingPairs(t *testing.T) {
+
+	for _, opts := range [][]SpaceOption{nil, {WithSpatialHash(16)}} {
+
+		a, b, c := NewRectangle(0, 0, 10, 10), NewRectangle(5, 5, 10, 10), NewRectangle(8, 0, 10, 10)
+		far := NewRectangle(100, 100, 10, 10)
+
+		// The compound Space's members overlap each other, but they should only be paired with far.
+		inner, innerOther := NewRectangle(105, 105, 4, 4), NewRectangle(104, 104, 4, 4)
+		compound := NewSpace()
+		compound.Add(inner, innerOther)
+
+		space := NewSpace(opts...)
+		space.Add(a, b, c, far, compound)
+
+		seen := map[[2]Shape]bool{}
+		for _, pair := range space.GetCollidingPairs() {
+			if pair.ShapeA == pair.ShapeB {
+				t.Errorf("GetCollidingPairs() paired %v with itself", pair.ShapeA)
+			}
+			if pair.ShapeA == compound || pair.ShapeB == compound {
+				t.Errorf("GetCollidingPairs() reported the compound Space rather than its members")
+			}
+			if seen[[2]Shape{pair.ShapeA, pair.ShapeB}] || seen[[2]Shape{pair.ShapeB, pair.ShapeA}] {
+				t.Errorf("GetCollidingPairs() reported %v and %v more than once", pair.ShapeA, pair.ShapeB)
+			}
+			seen[[2]Shape{pair.ShapeA, pair.ShapeB}] = true
+		}
+
+		for _, want := range [][2]Shape{{a, b}, {a, c}, {b, c}, {far, inner}, {far, innerOther}} {
+			if !seen[want] {
+				t.Errorf("GetCollidingPairs() didn't report %v and %v", want[0], want[1])
+			}
+		}
+		if len(seen) != 5 {
+			t.Errorf("GetCollidingPairs() reported %d pairs, want 5", len(seen))
+		}
+
+	}
+
+}
+
+func TestRemoveDuringIteration(t *testing.T) {
+
+	newSpace := func() (*Space, []Shape) {
+		space := NewSpace()
+		for i := int32(0); i < 5; i++ {
+			space.Add(NewRectangle(i*8, 0, 4, 4))
+		}
+		return space, space.Shapes()
+	}
+
+	t.Run("Remove while looping by index", func(t *testing.T) {
+		// This is the documented pitfall: the Shape after the removed one slides into its place and is skipped.
+		space, shapes := newSpace()
+		var visited []Shape
+		for i := 0; i < space.Length(); i++ {
+			visited = append(visited, space.Get(i))
+			if space.Get(i) == shapes[1] {
+				space.Remove(shapes[1])
+			}
+		}
+		if len(visited) != 4 || visited[2] != shapes[3] {
+			t.Errorf("looping by index visited %v, want %v skipped", visited, shapes[2])
+		}
+	})
+
+	t.Run("QueueRemove and Flush", func(t *testing.T) {
+		space, shapes := newSpace()
+		visited := 0
+		for i := 0; i < space.Length(); i++ {
+			visited++
+			if space.Get(i) == shapes[1] || space.Get(i) == shapes[3] {
+				space.QueueRemove(space.Get(i))
+			}
+		}
+		if visited != 5 || space.Length() != 5 {
+			t.Fatalf("QueueRemove() changed the Space before Flush(): visited %d, %d left", visited, space.Length())
+		}
+		space.Flush()
+		if space.Length() != 3 || space.Contains(shapes[1]) || space.Contains(shapes[3]) {
+			t.Errorf("Flush() left %v", space.Shapes())
+		}
+		space.Flush()
+		if space.Length() != 3 {
+			t.Errorf("flushing again removed more Shapes: %v left", space.Shapes())
+		}
+	})
+
+	t.Run("Remove within ForEach", func(t *testing.T) {
+		space, shapes := newSpace()
+		visited := 0
+		space.ForEach(func(shape Shape) bool {
+			visited++
+			space.Remove(shape)
+			if space.Length() != 5 {
+				t.Errorf("Remove() within ForEach() removed the Shape right away")
+			}
+			return true
+		})
+		if visited != len(shapes) || space.Length() != 0 {
+			t.Errorf("ForEach() visited %d Shapes and left %d, want %d visited and none left", visited, space.Length(),
+				len(shapes))
+		}
+	})
+
+}
+
+func TestCloneLeavesOriginalUntouched(t *testing.T) {
+
+	data := &struct{ hp int }{10}
+	player, wall := NewRectangle(0, 0, 8, 8), NewRectangle(20, 0, 8, 8)
+	player.AddTags("player")
+	player.SetData(data)
+	wall.AddTags("solid")
+	nested := NewSpace()
+	nested.Add(NewRectangle(0, 40, 8, 8), NewCircle(20, 40, 4))
+	space := NewSpace()
+	space.Add(player, wall, nested)
+
+	clone := space.Clone()
+	if clone.Length() != space.Length() {
+		t.Fatalf("Clone() has %d Shapes, want %d", clone.Length(), space.Length())
+	}
+	for i := 0; i < space.Length(); i++ {
+		if clone.Get(i) == space.Get(i) {
+			t.Errorf("Clone() shares Shape %d with the original", i)
+		}
+	}
+	clonedNested, ok := clone.Get(2).(*Space)
+	if !ok || clonedNested.Length() != 2 || clonedNested.Get(0) == nested.Get(0) {
+		t.Fatalf("Clone() didn't copy the nested Space's Shapes: %v", clone.Get(2))
+	}
+
+	clonedPlayer := clone.Get(0)
+	if !clonedPlayer.HasTags("player") || clonedPlayer.GetData() != data {
+		t.Errorf("Clone() didn't copy the tags and Data: %v, %v", clonedPlayer.HasTags("player"), clonedPlayer.GetData())
+	}
+	col := clone.Resolve(clonedPlayer, 30, 0)
+	if !col.Colliding() || col.ShapeB != clone.Get(1) {
+		t.Fatalf("Resolve() in the clone = %+v, want the cloned wall", col)
+	}
+	clonedPlayer.Move(col.ResolveX, col.ResolveY)
+	clonedPlayer.AddTags("moved")
+	clone.Remove(clone.Get(1))
+	clonedNested.Move(5, 5)
+
+	if x, y := player.GetXY(); x != 0 || y != 0 || player.HasTags("moved") {
+		t.Errorf("moving the clone's Shape moved the original to %d, %d", x, y)
+	}
+	if space.Length() != 3 || !space.Contains(wall) {
+		t.Errorf("removing from the clone changed the original: %v", space.Shapes())
+	}
+	if x, y := nested.Get(0).GetXY(); x != 0 || y != 40 {
+		t.Errorf("moving the cloned nested Space moved the original to %d, %d", x, y)
+	}
+
+	if got := space.CloneWithoutData().Get(0).GetData(); got != nil {
+		t.Errorf("CloneWithoutData() kept the Data %v", got)
+	}
+	if player.GetData() != data {
+		t.Error("CloneWithoutData() cleared the original's Data")
+	}
+
+}
+
+func TestSpaceRoot(t *testing.T) {
+
+	first, pivot, other := NewRectangle(0, 0, 8, 8), NewRectangle(10, 20, 8, 8), NewRectangle(40, 0, 8, 8)
+	first.AddTags("first")
+	pivot.AddTags("pivot")
+	pivot.SetData("pivot data")
+	space := NewSpace()
+	space.Add(first, pivot, other)
+
+	if space.Root() != first {
+		t.Fatalf("Root() = %v, want the first Shape when unset", space.Root())
+	}
+	if err := space.SetRoot(NewRectangle(0, 0, 1, 1)); !errors.Is(err, ErrNotInSpace) {
+		t.Errorf("SetRoot() with a Shape outside the Space = %v, want ErrNotInSpace", err)
+	}
+	if err := space.SetRoot(pivot); err != nil {
+		t.Fatalf("SetRoot() = %v", err)
+	}
+
+	if x, y := space.GetXY(); x != 10 || y != 20 {
+		t.Errorf("GetXY() = %d, %d, want the root's position", x, y)
+	}
+	if tags := space.GetTags(); len(tags) != 1 || tags[0] != "pivot" {
+		t.Errorf("GetTags() = %v, want the root's tags", tags)
+	}
+	if data := space.GetData(); data != "pivot data" {
+		t.Errorf("GetData() = %v, want the root's Data", data)
+	}
+	space.SetXY(0, 0)
+	if x, y := other.GetXY(); x != 30 || y != -20 {
+		t.Errorf("SetXY() moved the other Shapes to %d, %d, want them moved along with the root", x, y)
+	}
+
+	// Removing the first Shape doesn't change the root, but removing the root falls back to the first Shape left.
+	space.Remove(first)
+	if space.Root() != pivot {
+		t.Errorf("Root() after removing the first Shape = %v, want the root kept", space.Root())
+	}
+	space.Remove(pivot)
+	if space.Root() != other {
+		t.Errorf("Root() after removing the root = %v, want the first Shape left", space.Root())
+	}
+	space.Add(pivot)
+	if space.Root() != other {
+		t.Errorf("Root() after adding the old root back = %v, want it not to be the root again", space.Root())
+	}
+	space.Remove(other, pivot)
+	if space.Root() != nil {
+		t.Errorf("Root() of an empty Space = %v, want nil", space.Root())
+	}
+
+}
+
+func TestSpaceAccessors(t *testing.T) {
+
+	a, b, c := NewRectangle(0, 0, 8, 8), NewRectangle(8, 0, 8, 8), NewRectangle(16, 0, 8, 8)
+
+	empty := NewSpace()
+	if empty.First() != nil || empty.Last() != nil {
+		t.Errorf("First() and Last() of an empty Space = %v, %v, want nil", empty.First(), empty.Last())
+	}
+	if shape, ok := empty.GetSafe(0); ok || shape != nil {
+		t.Errorf("GetSafe(0) of an empty Space = %v, %v, want nil, false", shape, ok)
+	}
+
+	space := NewSpace()
+	space.Add(a, b, c)
+	if space.First() != a || space.Last() != c {
+		t.Errorf("First() and Last() = %v, %v, want %v, %v", space.First(), space.Last(), a, c)
+	}
+
+	for _, tt := range []struct {
+		index int
+		want  Shape
+	}{{-1, nil}, {0, a}, {2, c}, {3, nil}} {
+		shape, ok := space.GetSafe(tt.index)
+		if shape != tt.want || ok != (tt.want != nil) {
+			t.Errorf("GetSafe(%d) = %v, %v, want %v", tt.index, shape, ok, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		shape Shape
+		want  int
+	}{{a, 0}, {b, 1}, {c, 2}, {NewRectangle(0, 0, 8, 8), -1}} {
+		if got := space.IndexOf(tt.shape); got != tt.want {
+			t.Errorf("IndexOf(%v) = %d, want %d", tt.shape, got, tt.want)
+		}
+	}
+
+}
+
+func TestSpaceWouldBeCollidingSkipsOther(t *testing.T) {
+
+	// other is in the Space at the position given, and only the wall the Space holds last would run into it.
+	tests := []struct {
+		name     string
+		position int
+	}{
+		{"first", 0},
+		{"middle", 1},
+		{"last", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			other := NewRectangle(0, 0, 8, 8)
+			shapes := []Shape{NewRectangle(0, 40, 8, 8), NewRectangle(0, 80, 8, 8)}
+			shapes = append(shapes[:tt.position], append([]Shape{other}, shapes[tt.position:]...)...)
+			wall := NewRectangle(20, 0, 8, 8)
+			space := NewSpace()
+			space.Add(append(shapes, wall)...)
+
+			if !space.WouldBeColliding(other, -16, 0) {
+				t.Error("WouldBeColliding() = false, want the wall after other found")
+			}
+			if space.WouldBeColliding(other, 0, 20) {
+				t.Error("WouldBeColliding() = true with nothing in the way")
+			}
+
+		})
+	}
+
+}
+
+func TestSpaceTagsAndDataSemantics(t *testing.T) {
+
+	tagged := func(tags ...string) Shape {
+		shape := NewRectangle(0, 0, 8, 8)
+		shape.AddTags(tags...)
+		return shape
+	}
+
+	tests := []struct {
+		name            string
+		shapes          []Shape
+		hasTags, anyHas bool
+		rootTags        int
+	}{
+		{"empty", nil, false, false, 0},
+		{"all tagged", []Shape{tagged("solid"), tagged("solid", "ice")}, true, true, 1},
+		{"mixed", []Shape{tagged("ice"), tagged("solid")}, false, true, 1},
+		{"none tagged", []Shape{tagged(), tagged("ice")}, false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			space.Add(tt.shapes...)
+
+			if got := space.HasTags("solid"); got != tt.hasTags {
+				t.Errorf("HasTags() = %v, want %v", got, tt.hasTags)
+			}
+			if got := space.AllHaveTags("solid"); got != tt.hasTags {
+				t.Errorf("AllHaveTags() = %v, want %v", got, tt.hasTags)
+			}
+			if got := space.AnyHasTags("solid"); got != tt.anyHas {
+				t.Errorf("AnyHasTags() = %v, want %v", got, tt.anyHas)
+			}
+			if got := space.GetTags(); len(got) != tt.rootTags {
+				t.Errorf("GetTags() = %v, want the root's %d tags", got, tt.rootTags)
+			}
+
+			// GetData() only reads the root, while SetAllData() sets every Shape's Data.
+			if got := space.GetData(); got != nil {
+				t.Errorf("GetData() = %v, want nil", got)
+			}
+			space.SetAllData("data")
+			for i, shape := range tt.shapes {
+				if shape.GetData() != "data" {
+					t.Errorf("SetAllData() didn't set Shape %d's Data", i)
+				}
+			}
+			if len(tt.shapes) > 1 {
+				tt.shapes[1].SetData("second")
+			}
+			want := interface{}(nil)
+			if len(tt.shapes) > 0 {
+				want = "data"
+			}
+			if got := space.GetData(); got != want {
+				t.Errorf("GetData() = %v, want the root's Data (%v)", got, want)
+			}
+
+		})
+	}
+
+}
+
+func TestAddRejectsCycles(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		build func() (into, added *Space)
+	}{
+		{"direct", func() (*Space, *Space) {
+			a := NewSpace()
+			return a, a
+		}},
+		{"one level", func() (*Space, *Space) {
+			a, b := NewSpace(), NewSpace()
+			b.Add(a)
+			return a, b
+		}},
+		{"two levels", func() (*Space, *Space) {
+			a, b, c := NewSpace(), NewSpace(), NewSpace()
+			c.Add(a)
+			b.Add(NewRectangle(0, 0, 8, 8), c)
+			return a, b
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			into, added := tt.build()
+			shape := NewRectangle(0, 0, 8, 8)
+
+			if err := into.Add(shape, added); !errors.Is(err, ErrSelfAddition) {
+				t.Errorf("Add() = %v, want ErrSelfAddition", err)
+			}
+			if into.Contains(added) || !into.Contains(shape) {
+				t.Errorf("Add() left %v, want just the other Shape added", into.Shapes())
+			}
+
+			if err := into.AddChecked(NewRectangle(0, 0, 8, 8), added); !errors.Is(err, ErrSelfAddition) {
+				t.Errorf("AddChecked() = %v, want ErrSelfAddition", err)
+			}
+			if into.Length() != 1 {
+				t.Errorf("AddChecked() added Shapes despite the cycle: %v", into.Shapes())
+			}
+
+			// Nothing is left recursing forever.
+			into.IsColliding(shape)
+			into.Move(1, 1)
+
+		})
+	}
+
+	// Nesting without a cycle is fine.
+	a, b := NewSpace(), NewSpace()
+	b.Add(NewRectangle(0, 0, 8, 8))
+	if err := a.Add(b, b); err != nil {
+		t.Errorf("Add() of a nested Space = %v, want nil", err)
+	}
+
+}
+
+func BenchmarkSpaceAdd(b *testing.B) {
+
+	shapes := make([]Shape, 1000)
+	for i := range shapes {
+		shapes[i] = NewRectangle(int32(i)*8, 0, 8, 8)
+	}
+	space := NewSpace()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		space.truncate()
+		space.Add(shapes...)
+	}
+
+}
+
+// BenchmarkSpaceLoad loads a level of 50000 Shapes into a new Space, as a game would, comparing adding them one at a time
+// with making room for them first.
+func BenchmarkSpaceLoad(b *testing.B) {
+
+	const count = 50000
+	shapes := make([]Shape, count)
+	for i := range shapes {
+		shapes[i] = NewRectangle(int32(i%250)*16, int32(i/250)*16, 16, 16, WithTags("solid"))
+	}
+
+	b.Run("Add", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space := NewSpace()
+			for _, shape := range shapes {
+				space.Add(shape)
+			}
+			space.CountByTags("solid")
+		}
+	})
+
+	b.Run("NewSpaceWithCapacity", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space := NewSpaceWithCapacity(count)
+			for _, shape := range shapes {
+				space.Add(shape)
+			}
+			space.CountByTags("solid")
+		}
+	})
+
+	b.Run("AddBulk", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space := NewSpace()
+			space.AddBulk(shapes)
+			space.CountByTags("solid")
+		}
+	})
+
+}
+
+func TestAddBulkMatchesAdd(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(366))
+	var shapes []Shape
+	for i := 0; i < 300; i++ {
+		shape := randomShape(rng, true)
+		if i%3 == 0 {
+			shape.AddTags("solid")
+		}
+		shapes = append(shapes, shape)
+	}
+	// The same Shape twice, to be skipped by strict Spaces.
+	shapes = append(shapes, shapes[7])
+
+	// Both Spaces are set up with the same Shapes, so they can be compared Shape for Shape.
+	before := NewRectangle(-8, -8, 4, 4, WithTags("solid"))
+	handled := NewCircle(0, 0, 4)
+
+	tests := []struct {
+		name  string
+		setup func(sp *Space)
+	}{
+		{"plain", func(sp *Space) {}},
+		{"with Shapes already in it", func(sp *Space) { sp.Add(before) }},
+		{"strict", func(sp *Space) { sp.SetStrict(true) }},
+		{"kept sorted", func(sp *Space) { sp.KeepSorted(ByPosition) }},
+		{"with Handles", func(sp *Space) { sp.AddH(handled) }},
+		{"tag index built", func(sp *Space) {
+			sp.Add(before)
+			sp.FilterByTags("solid")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// build sets up a Space, adds the Shapes with add(), and records the Shapes its OnAdd() function is called for.
+			build := func(add func(sp *Space) error) (*Space, []Shape, error) {
+				sp := NewSpace()
+				tt.setup(sp)
+				var added []Shape
+				sp.OnAdd(func(shape Shape) { added = append(added, shape) })
+				err := add(sp)
+				return sp, added, err
+			}
+
+			one, oneAdded, oneErr := build(func(sp *Space) error {
+				var err error
+				for _, shape := range shapes {
+					if e := sp.Add(shape); e != nil {
+						err = e
+					}
+				}
+				// A Space adding itself is skipped either way.
+				if e := sp.Add(sp); e != nil {
+					err = e
+				}
+				return err
+			})
+			bulk, bulkAdded, bulkErr := build(func(sp *Space) error {
+				return sp.AddBulk(append(append([]Shape{}, shapes...), sp))
+			})
+
+			if (oneErr == nil) != (bulkErr == nil) || !errors.Is(bulkErr, ErrSelfAddition) {
+				t.Errorf("AddBulk() = %v, Add() = %v", bulkErr, oneErr)
+			}
+			if one.Length() != bulk.Length() || len(oneAdded) != len(bulkAdded) {
+				t.Fatalf("AddBulk() gave %d Shapes with %d OnAdd() calls, Add() gave %d with %d", bulk.Length(),
+					len(bulkAdded), one.Length(), len(oneAdded))
+			}
+			for i := 0; i < one.Length(); i++ {
+				if one.Get(i) != bulk.Get(i) {
+					t.Fatalf("AddBulk() put %v at %d, Add() put %v", bulk.Get(i), i, one.Get(i))
+				}
+			}
+			for i := range oneAdded {
+				if oneAdded[i] != bulkAdded[i] {
+					t.Fatalf("OnAdd() call %d was for %v with AddBulk(), but %v with Add()", i, bulkAdded[i], oneAdded[i])
+				}
+			}
+
+			// Queries through the Spaces' indexes find the same Shapes.
+			if o, b := one.FilterByTags("solid").Shapes(), bulk.FilterByTags("solid").Shapes(); len(o) != len(b) {
+				t.Errorf("FilterByTags() found %d Shapes after AddBulk(), but %d after Add()", len(b), len(o))
+			}
+			if o, b := one.CountByTags("solid"), bulk.CountByTags("solid"); o != b {
+				t.Errorf("CountByTags() = %d after AddBulk(), but %d after Add()", b, o)
+			}
+			probe := NewRectangle(-10, -10, 30, 30)
+			if o, b := one.GetCollidingShapes(probe).Length(), bulk.GetCollidingShapes(probe).Length(); o != b {
+				t.Errorf("GetCollidingShapes() found %d Shapes after AddBulk(), but %d after Add()", b, o)
+			}
+			if !bulk.Contains(shapes[7]) || bulk.IndexOf(shapes[7]) != one.IndexOf(shapes[7]) {
+				t.Errorf("IndexOf() = %d after AddBulk(), but %d after Add()", bulk.IndexOf(shapes[7]), one.IndexOf(shapes[7]))
+			}
+
+		})
+	}
+
+}
+
+func TestSpaceRemoveOrder(t *testing.T) {
+
+	a, b, c, d := NewRectangle(0, 0, 8, 8), NewRectangle(8, 0, 8, 8), NewRectangle(16, 0, 8, 8), NewCircle(0, 0, 4)
+
+	tests := []struct {
+		name   string
+		shapes []Shape
+		remove func(sp *Space) int
+		want   []Shape
+		count  int
+	}{
+		{"one", []Shape{a, b, c, d}, func(sp *Space) int { sp.Remove(b); return -1 }, []Shape{a, c, d}, -1},
+		{"several", []Shape{a, b, c, d}, func(sp *Space) int { sp.Remove(d, a); return -1 }, []Shape{b, c}, -1},
+		{"missing", []Shape{a, b}, func(sp *Space) int { sp.Remove(c); return -1 }, []Shape{a, b}, -1},
+		{"first occurrence of a duplicate", []Shape{a, b, a, c, a}, func(sp *Space) int {
+			sp.Remove(a)
+			return -1
+		}, []Shape{b, a, c, a}, -1},
+		{"duplicate passed twice", []Shape{a, b, a, c, a}, func(sp *Space) int {
+			sp.Remove(a, a)
+			return -1
+		}, []Shape{b, c, a}, -1},
+		{"RemoveAll", []Shape{a, b, a, c, a}, func(sp *Space) int { return sp.RemoveAll(a) }, []Shape{b, c}, 3},
+		{"RemoveAll missing", []Shape{a, b}, func(sp *Space) int { return sp.RemoveAll(c) }, []Shape{a, b}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space := NewSpace()
+			space.Add(tt.shapes...)
+			// The Space never holds nils, even while the Shapes are being removed.
+			space.OnRemove(func(Shape) {
+				for _, shape := range space.Shapes() {
+					if shape == nil {
+						t.Fatal("the Space held a nil Shape during removal")
+					}
+				}
+			})
+
+			if count := tt.remove(space); count != tt.count {
+				t.Errorf("RemoveAll() = %d, want %d", count, tt.count)
+			}
+			if space.Length() != len(tt.want) {
+				t.Fatalf("%d Shapes left, want %v", space.Length(), tt.want)
+			}
+			for i, shape := range tt.want {
+				if space.Get(i) != shape {
+					t.Errorf("Shape %d = %v, want %v", i, space.Get(i), shape)
+				}
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkSpaceRemoveMany(b *testing.B) {
+
+	shapes := make([]Shape, 1000)
+	for i := range shapes {
+		shapes[i] = NewRectangle(int32(i)*8, 0, 8, 8)
+	}
+	space := NewSpace()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		space.truncate()
+		space.Add(shapes...)
+		b.StartTimer()
+		space.Remove(shapes[:500]...)
+	}
+
+}
+
+func TestSpaceRootAndAllData(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		set              func(sp *Space)
+		wantGet          interface{}
+		wantRest         interface{}
+		rootTags, others int
+	}{
+		{"SetRootData", func(sp *Space) { sp.SetRootData("root") }, "root", "own", 0, 0},
+		{"SetAllData", func(sp *Space) { sp.SetAllData("all") }, "all", "all", 0, 0},
+		{"SetData", func(sp *Space) { sp.SetData("all") }, "all", "all", 0, 0},
+		{"AddRootTags", func(sp *Space) { sp.AddRootTags("boss") }, "own", "own", 1, 0},
+		{"AddTags", func(sp *Space) { sp.AddTags("boss") }, "own", "own", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// The root is nested, so that setting it has to go through the nested Space.
+			root, inner, other := NewRectangle(0, 0, 8, 8), NewRectangle(8, 0, 8, 8), NewRectangle(16, 0, 8, 8)
+			for _, shape := range []Shape{root, inner, other} {
+				shape.SetData("own")
+			}
+			nested := NewSpace()
+			nested.Add(root, inner)
+			space := NewSpace()
+			space.Add(nested, other)
+
+			tt.set(space)
+
+			if got := space.GetData(); got != tt.wantGet {
+				t.Errorf("GetData() = %v, want %v", got, tt.wantGet)
+			}
+			if got := root.GetData(); got != tt.wantGet {
+				t.Errorf("the root's Data = %v, want %v", got, tt.wantGet)
+			}
+			if inner.GetData() != tt.wantRest || other.GetData() != tt.wantRest {
+				t.Errorf("the other Shapes' Data = %v and %v, want %v", inner.GetData(), other.GetData(), tt.wantRest)
+			}
+			if got := len(space.GetTags()); got != tt.rootTags {
+				t.Errorf("GetTags() has %d tags, want %d", got, tt.rootTags)
+			}
+			if len(inner.GetTags()) != tt.others || len(other.GetTags()) != tt.others {
+				t.Errorf("the other Shapes have tags %v and %v, want %d", inner.GetTags(), other.GetTags(), tt.others)
+			}
+
+		})
+	}
+
+	// Setting the root's Data on an empty Space does nothing.
+	empty := NewSpace()
+	empty.SetRootData("root")
+	empty.AddRootTags("boss")
+	if empty.GetData() != nil || len(empty.GetTags()) != 0 {
+		t.Errorf("an empty Space has Data %v and tags %v, want none", empty.GetData(), empty.GetTags())
+	}
+
+}
+
+func TestIntoMatchesAllocating(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(19))
+	space := NewSpace()
+	for i := 0; i < 200; i++ {
+		space.Add(randomShape(rng, true))
+	}
+
+	sameShapes := func(a, b *Space) bool {
+		if a.Length() != b.Length() {
+			return false
+		}
+		for i := 0; i < a.Length(); i++ {
+			if a.Get(i) != b.Get(i) {
+				return false
+			}
+		}
+		return true
+	}
+
+	colliding, filtered := NewSpace(), NewSpace()
+	for i := 0; i < 500; i++ {
+
+		probe := randomShape(rng, false)
+		if got, want := space.GetCollidingShapesInto(probe, colliding), space.GetCollidingShapes(probe); got != colliding ||
+			!sameShapes(got, want) {
+			t.Fatalf("GetCollidingShapesInto(%v) = %v, want %v in the Space provided", probe, got.Shapes(), want.Shapes())
+		}
+
+		bounds := shapeBoundingRect(probe)
+		keep := func(shape Shape) bool { return shapeBoundingRect(shape).X < bounds.X }
+		if got, want := space.FilterInto(keep, filtered), space.Filter(keep); got != filtered || !sameShapes(got, want) {
+			t.Fatalf("FilterInto() = %v, want %v in the Space provided", got.Shapes(), want.Shapes())
+		}
+
+	}
+
+	if got := space.GetCollidingShapesInto(NewRectangle(-1000, -1000, 1, 1), colliding); got.Length() != 0 {
+		t.Errorf("GetCollidingShapesInto() kept %v from the previous call", got.Shapes())
+	}
+	if got := space.FilterInto(func(Shape) bool { return true }, nil); got == nil || got.Length() != space.Length() {
+		t.Errorf("FilterInto() with a nil Space = %v, want a new Space with every Shape", got)
+	}
+
+	// Once the Spaces reused have grown large enough, the Into variants don't allocate. Lines are left out, as testing them
+	// against Rectangles allocates by itself.
+	space = NewSpace()
+	for i := int32(0); i < 64; i++ {
+		nested := NewSpace()
+		nested.Add(NewRectangle(i%8*8, i/8*8, 8, 8), NewCircle(i%8*8+4, i/8*8+4, 4))
+		space.Add(NewRectangle(i%8*16-32, i/8*16-32, 8, 8), NewCircle(i%8*16-28, i/8*16-28, 6), nested)
+	}
+	probe := NewRectangle(-16, -16, 32, 32)
+	keep := func(shape Shape) bool { return shape != probe }
+	if space.GetCollidingShapesInto(probe, colliding).Length() == 0 {
+		t.Fatal("GetCollidingShapesInto() found nothing to test allocations with")
+	}
+	space.FilterInto(keep, filtered)
+	if allocs := testing.AllocsPerRun(100, func() { space.GetCollidingShapesInto(probe, colliding) }); allocs != 0 {
+		t.Errorf("GetCollidingShapesInto() made %v allocations, want 0", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { space.FilterInto(keep, filtered) }); allocs != 0 {
+		t.Errorf("FilterInto() made %v allocations, want 0", allocs)
+	}
+
+}
+
+func BenchmarkGetCollidingShapesInto(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 256; i++ {
+		space.Add(NewRectangle(i%16*16, i/16*16, 16, 16))
+	}
+	probe := NewRectangle(24, 24, 40, 40)
+
+	b.Run("GetCollidingShapes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.GetCollidingShapes(probe)
+		}
+	})
+
+	b.Run("GetCollidingShapesInto", func(b *testing.B) {
+		out := NewSpace()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.GetCollidingShapesInto(probe, out)
+		}
+	})
+
+}
+
+func BenchmarkFilterInto(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 256; i++ {
+		space.Add(NewRectangle(i%16*16, i/16*16, 16, 16))
+	}
+	keep := func(shape Shape) bool { return shape.(*Rectangle).X < 128 }
+
+	b.Run("Filter", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.Filter(keep)
+		}
+	})
+
+	b.Run("FilterInto", func(b *testing.B) {
+		out := NewSpace()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.FilterInto(keep, out)
+		}
+	})
+
+}
+
+func TestGetCollidingShapesN(t *testing.T) {
+
+	// A row of 10 overlapping Rectangles, all colliding with the probe, with ones the probe doesn't reach in between.
+	space := NewSpace()
+	for i := int32(0); i < 10; i++ {
+		space.Add(NewRectangle(i*4, 0, 4, 4), NewRectangle(i*4, 100, 4, 4))
+	}
+	space.EnableStats()
+	probe := NewRectangle(0, 0, 40, 4)
+	all := space.GetCollidingShapes(probe)
+
+	tests := []struct {
+		max, want     int
+		maxCandidates int64
+	}{
+		{-1, 10, 20},
+		{0, 10, 20},
+		{1, 1, 1},
+		{3, 3, 5},
+		{10, 10, 19},
+		{100, 10, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.max), func(t *testing.T) {
+
+			space.ResetStats()
+			got := space.GetCollidingShapesN(probe, tt.max)
+
+			if got.Length() != tt.want {
+				t.Fatalf("GetCollidingShapesN(%d) returned %d Shapes, want %d", tt.max, got.Length(), tt.want)
+			}
+			for i := 0; i < got.Length(); i++ {
+				if got.Get(i) != all.Get(i) {
+					t.Errorf("GetCollidingShapesN(%d) returned %v at %d, want %v", tt.max, got.Get(i), i, all.Get(i))
+				}
+			}
+			if candidates := space.Stats().BroadPhaseCandidates; candidates > tt.maxCandidates {
+				t.Errorf("GetCollidingShapesN(%d) went through %d Shapes, want it to stop after %d", tt.max, candidates,
+					tt.maxCandidates)
+			}
+
+		})
+	}
+
+	// IsColliding() is the max = 1 case, so it stops at the first Shape found too.
+	space.ResetStats()
+	if !space.IsColliding(probe) || space.Stats().BroadPhaseCandidates != 1 {
+		t.Errorf("IsColliding() went through %d Shapes, want 1", space.Stats().BroadPhaseCandidates)
+	}
+	miss := NewRectangle(0, 50, 4, 4)
+	if space.IsColliding(miss) || space.GetCollidingShapesN(miss, 1).Length() != 0 {
+		t.Error("IsColliding() or GetCollidingShapesN() found a Shape that isn't colliding")
+	}
+
+}
+
+func BenchmarkGetCollidingShapesN(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 1024; i++ {
+		space.Add(NewRectangle(i%32*8, i/32*8, 8, 8))
+	}
+	probe := NewRectangle(0, 0, 256, 256)
+
+	for _, max := range []int{1, 3, 0} {
+		b.Run(fmt.Sprint(max), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				space.GetCollidingShapesN(probe, max)
+			}
+		})
+	}
+
+}
+
+func TestOverlappingTagged(t *testing.T) {
+
+	body := NewRectangle(0, 0, 16, 16, WithTags("ladder"))
+	ladder := NewRectangle(8, -32, 8, 64, WithTags("ladder"), WithMask(0))
+	water := NewRectangle(-8, 8, 32, 32, WithTags("water", "ladder"))
+	wall := NewRectangle(4, 4, 8, 8)
+	farLadder := NewRectangle(100, 0, 8, 64, WithTags("ladder"))
+
+	space := NewSpace()
+	space.Add(body, ladder, water, wall, farLadder)
+
+	check := func(state string) {
+		got := space.OverlappingTagged(body, "ladder")
+		if got.Length() != 2 || got.Get(0) != ladder || got.Get(1) != water {
+			t.Errorf("OverlappingTagged() %s = %v, want the ladder and the water", state, got.Shapes())
+		}
+		if got := space.OverlappingTagged(body, "lava"); got.Length() != 0 {
+			t.Errorf("OverlappingTagged() of a tag nothing has %s = %v", state, got.Shapes())
+		}
+	}
+
+	// The Shapes are found both through the tag index, and while it's out of date.
+	space.FilterByTags("ladder")
+	check("with the tag index")
+	wall.AddTags("solid")
+	check("with the tag index out of date")
+
+}
+
+func BenchmarkOverlappingTagged(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 1024; i++ {
+		var opts []ShapeOption
+		if i%32 == 0 {
+			opts = append(opts, WithTags("ladder"))
+		}
+		space.Add(NewRectangle(i%32*16, i/32*16, 16, 16, opts...))
+	}
+	body := NewRectangle(4, 4, 8, 16)
+
+	b.Run("OverlappingTagged", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.OverlappingTagged(body, "ladder").Release()
+		}
+	})
+
+	b.Run("GetCollidingShapes then FilterByTags", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.GetCollidingShapes(body).FilterByTags("ladder")
+		}
+	})
+
+}
+
+func TestCloneSettings(t *testing.T) {
+
+	player, wall, bridge := NewRectangle(0, 0, 8, 8), NewRectangle(20, 0, 8, 8), NewRectangle(4, 0, 8, 8)
+	bridge.SetElevation(1)
+	space := NewSpace(WithSpatialHash(32))
+	space.Add(wall, player, bridge)
+	space.KeepSorted(ByPosition)
+	space.KeepSortedX()
+	space.SetStrict(true)
+	space.SetRoot(player)
+	space.SetName(wall, "wall")
+	space.SetElevationFilter(func(a, b Shape) bool { return true })
+
+	clone := space.Clone()
+	clonedPlayer, clonedWall, clonedBridge := clone.Get(0), clone.Get(2), clone.Get(1)
+
+	if clone.hashCellSize != 32 {
+		t.Errorf("the clone's spatial hash has cells of %d, want 32", clone.hashCellSize)
+	}
+	if clone.Root() != clonedPlayer {
+		t.Errorf("the clone's Root() = %v, want the cloned player", clone.Root())
+	}
+	if clone.GetByName("wall") != clonedWall {
+		t.Errorf("the clone's GetByName() = %v, want the cloned wall", clone.GetByName("wall"))
+	}
+	if name, _ := clone.Name(clonedWall); name != "wall" {
+		t.Errorf("the clone's Name() of the wall = %q, want \"wall\"", name)
+	}
+	if err := clone.Add(clonedPlayer); !errors.Is(err, ErrDuplicateShape) {
+		t.Errorf("adding a Shape to the strict clone twice = %v, want ErrDuplicateShape", err)
+	}
+	if !clone.IsColliding(clonedBridge) || !clonedBridge.IsColliding(clonedPlayer) {
+		t.Error("the clone didn't keep the elevation filter, so the bridge doesn't collide")
+	}
+
+	// Shapes added to the clone go in order, and queries use the sorted index.
+	added := NewRectangle(-8, -8, 4, 4)
+	clone.Add(added)
+	if clone.Get(0) != added {
+		t.Errorf("the Shape added to the clone went to index %d, want 0", clone.IndexOf(added))
+	}
+	if clone.sortedX == nil || !clone.updateSortedX() {
+		t.Error("the clone isn't kept sorted by X")
+	}
+
+	// The settings are the clone's own.
+	clone.SetRoot(nil)
+	clone.SetName(clonedPlayer, "player")
+	if space.Root() != player || space.GetByName("player") != nil {
+		t.Error("changing the clone's settings changed the original's")
+	}
+
+}
+
+func TestMoveWhereOnce(t *testing.T) {
+
+	tests := []struct {
+		name string
+		move func(space *Space)
+	}{
+		{"MoveWhere", func(space *Space) {
+			space.MoveWhere(4, 2, func(shape Shape) bool { return shape.HasTags("moving") })
+		}},
+		{"MoveTagged", func(space *Space) { space.MoveTagged(4, 2, "moving") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// The platform is in the Space three times, once through a Handle, and the crate once.
+			platform, crate := NewRectangle(0, 0, 32, 8, WithTags("moving")), NewRectangle(0, -8, 8, 8)
+			space := NewSpace()
+			space.Add(platform, crate, platform)
+			space.AddH(platform)
+
+			tt.move(space)
+
+			if x, y := platform.GetXY(); x != 4 || y != 2 {
+				t.Errorf("the platform in the Space three times moved to %d, %d, want 4, 2", x, y)
+			}
+			if x, y := crate.GetXY(); x != 0 || y != -8 {
+				t.Errorf("the untagged crate moved to %d, %d", x, y)
+			}
+
+		})
+	}
+
+	// The predicate is called once per Shape, too.
+	shape := NewRectangle(0, 0, 8, 8)
+	space := NewSpace()
+	space.Add(shape, shape)
+	calls := 0
+	space.MoveWhere(1, 0, func(Shape) bool {
+		calls++
+		return true
+	})
+	if calls != 1 || shape.X != 1 {
+		t.Errorf("MoveWhere() called the predicate %d times and moved the Shape to x = %d, want 1 and 1", calls, shape.X)
+	}
+
+}
+
+func BenchmarkMoveWhere(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 5000; i++ {
+		var opts []ShapeOption
+		if i%4 == 0 {
+			opts = append(opts, WithTags("moving"))
+		}
+		space.Add(NewRectangle(i%100*16, i/100*16, 16, 16, opts...))
+	}
+	tags := []string{"moving"}
+	moving := func(shape Shape) bool { return shape.HasTags(tags...) }
+
+	b.Run("Filter and Move", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.Filter(moving).Move(1, 0)
+		}
+	})
+
+	b.Run("MoveWhere", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.MoveWhere(1, 0, moving)
+		}
+	})
+
+	b.Run("MoveTagged", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			space.MoveTagged(1, 0, "moving")
+		}
+	})
+
+}
@@ -0,0 +1,78 @@
+package resolv
+
+import "sort"
+
+// maxHashCells is the most cells a Shape can be put in by the spatial hash; Shapes spanning more cells than that are
+// tested against every other Shape instead.
+const maxHashCells = 256
+
+// hashedPairs returns the colliding pairs among the leaves provided, using a spatial hash to only test pairs whose
+// bounding rectangles share a cell. The pairs are in the same order as testing every pair would give.
+func (sp *Space) hashedPairs(leaves pairLeaves) []CollisionPair {
+
+	broad := traceStart()
+
+	size := sp.hashCellSize
+	cells := map[chunkKey][]int{}
+	oversized := []int{}
+	var r Rectangle
+
+	for i, leaf := range leaves {
+		boundingRectInto(leaf.shape, &r)
+		// The far edges are included, as Shapes can touch them (like a Circle touching a Rectangle's edge).
+		x1, y1 := floorDiv(r.X, size), floorDiv(r.Y, size)
+		x2, y2 := floorDiv(r.X+r.W, size), floorDiv(r.Y+r.H, size)
+		if (int64(x2)-int64(x1)+1)*(int64(y2)-int64(y1)+1) > maxHashCells {
+			oversized = append(oversized, i)
+			continue
+		}
+		for y := y1; y <= y2; y++ {
+			for x := x1; x <= x2; x++ {
+				key := chunkKey{x, y}
+				cells[key] = append(cells[key], i)
+			}
+		}
+	}
+
+	seen := map[[2]int]bool{}
+	candidates := [][2]int{}
+	addCandidate := func(i, j int) {
+		if i > j {
+			i, j = j, i
+		}
+		if key := [2]int{i, j}; i != j && !seen[key] {
+			seen[key] = true
+			candidates = append(candidates, key)
+		}
+	}
+
+	for _, cell := range cells {
+		for a := range cell {
+			for _, j := range cell[a+1:] {
+				addCandidate(cell[a], j)
+			}
+		}
+	}
+
+	for _, i := range oversized {
+		for j := range leaves {
+			addCandidate(i, j)
+		}
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a][0] != candidates[b][0] {
+			return candidates[a][0] < candidates[b][0]
+		}
+		return candidates[a][1] < candidates[b][1]
+	})
+
+	traceEnd("Space.GetCollidingPairs/broad", broad)
+
+	pairs := []CollisionPair{}
+	for _, c := range candidates {
+		pairs = sp.appendPair(leaves[c[0]], leaves[c[1]], pairs)
+	}
+	return pairs
+
+}
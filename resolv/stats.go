@@ -0,0 +1,127 @@
+package resolv
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SpaceStats holds statistics on the collision work done by a Space, collected once EnableStats() has been called on it.
+// BroadPhaseCandidates is how many Shapes (or, for GetCollidingPairs(), pairs of Shapes) the broad phase put forward as
+// possibly colliding: the Shapes near the tested one (or every Shape in the Space, without an index to narrow them down),
+// or the pairs sharing a cell of the spatial hash. NarrowPhaseTests is how many times two Shapes were actually tested
+// against each other; candidates whose collision layers don't match or whose bounds don't overlap are dropped before
+// that, so the difference between the two is the work the cheap checks saved (WouldBeCollidingBatch() tests a candidate
+// once for each delta, though). Resolves is how many times Space.Resolve() was called, and ResolveIterations how many
+// steps it took to resolve those movements in total. Time is the total time spent in the Space's collision functions.
+type SpaceStats struct {
+	NarrowPhaseTests     int64
+	BroadPhaseCandidates int64
+	Resolves             int64
+	ResolveIterations    int64
+	Time                 time.Duration
+}
+
+// AverageResolveIterations returns the average number of steps it took to resolve a movement, or 0 if there weren't any.
+func (s SpaceStats) AverageResolveIterations() float64 {
+	if s.Resolves == 0 {
+		return 0
+	}
+	return float64(s.ResolveIterations) / float64(s.Resolves)
+}
+
+// spaceStats collects the statistics for a Space. All of its functions are no-ops on a nil *spaceStats, which is what a
+// Space has when stats are disabled, so collecting stats costs just a nil check when they're off. The counters are
+// updated atomically, as the parallel queries update them from multiple goroutines. shapes mirrors the number of Shapes
+// in the Space, so that it can be read from other goroutines (see PublishExpvar()); unlike the counters, it isn't reset.
+type spaceStats struct {
+	tests, candidates, resolves, steps, time int64
+	shapes                                   int64
+}
+
+func (s *spaceStats) setShapes(n int) {
+	if s != nil {
+		atomic.StoreInt64(&s.shapes, int64(n))
+	}
+}
+
+// snapshot returns the statistics collected so far.
+func (s *spaceStats) snapshot() SpaceStats {
+	return SpaceStats{
+		NarrowPhaseTests:     atomic.LoadInt64(&s.tests),
+		BroadPhaseCandidates: atomic.LoadInt64(&s.candidates),
+		Resolves:             atomic.LoadInt64(&s.resolves),
+		ResolveIterations:    atomic.LoadInt64(&s.steps),
+		Time:                 time.Duration(atomic.LoadInt64(&s.time)),
+	}
+}
+
+// candidate counts a Shape (or pair of Shapes) put forward by the broad phase.
+func (s *spaceStats) candidate() {
+	if s != nil {
+		atomic.AddInt64(&s.candidates, 1)
+	}
+}
+
+// test counts two Shapes being tested against each other.
+func (s *spaceStats) test() {
+	if s != nil {
+		atomic.AddInt64(&s.tests, 1)
+	}
+}
+
+func (s *spaceStats) resolve() {
+	if s != nil {
+		atomic.AddInt64(&s.resolves, 1)
+	}
+}
+
+func (s *spaceStats) resolveSteps(steps int) {
+	if s != nil {
+		atomic.AddInt64(&s.steps, int64(steps))
+	}
+}
+
+func (s *spaceStats) now() time.Time {
+	if s != nil {
+		return time.Now()
+	}
+	return time.Time{}
+}
+
+func (s *spaceStats) timeSince(start time.Time) {
+	if s != nil {
+		atomic.AddInt64(&s.time, int64(time.Since(start)))
+	}
+}
+
+// EnableStats starts collecting statistics on the collision work done by the Space, which can be read with Stats().
+func (sp *Space) EnableStats() {
+	if sp.stats == nil {
+		sp.stats = &spaceStats{shapes: int64(len(sp.shapes))}
+	}
+}
+
+// DisableStats stops collecting statistics on the collision work done by the Space, and discards the ones collected.
+func (sp *Space) DisableStats() {
+	sp.stats = nil
+}
+
+// Stats returns the statistics collected since stats were enabled for the Space or last reset. If stats aren't enabled,
+// it returns zeroed SpaceStats.
+func (sp *Space) Stats() SpaceStats {
+	if sp.stats == nil {
+		return SpaceStats{}
+	}
+	return sp.stats.snapshot()
+}
+
+// ResetStats resets the statistics collected for the Space to zero, like at the start of a frame.
+func (sp *Space) ResetStats() {
+	if s := sp.stats; s != nil {
+		atomic.StoreInt64(&s.tests, 0)
+		atomic.StoreInt64(&s.candidates, 0)
+		atomic.StoreInt64(&s.resolves, 0)
+		atomic.StoreInt64(&s.steps, 0)
+		atomic.StoreInt64(&s.time, 0)
+	}
+}
@@ -0,0 +1,103 @@
+package resolv
+
+import "testing"
+
+// statsLevel returns a Space holding a player, a Shape overlapping it, a Shape overlapping it that can't collide with
+// anything, and nine Shapes far away from it, along with the player.
+func statsLevel(opts ...SpaceOption) (*Space, *Rectangle) {
+	sp := NewSpace(opts...)
+	player := NewRectangle(0, 0, 8, 8)
+	masked := NewRectangle(4, 0, 8, 8)
+	masked.SetMask(0)
+	sp.Add(player, NewRectangle(6, 0, 8, 8), masked)
+	for i := int32(1); i <= 9; i++ {
+		sp.Add(NewRectangle(i*100, 0, 8, 8))
+	}
+	return sp, player
+}
+
+func TestSpaceStatsCandidates(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		opts           []SpaceOption
+		sorted         bool
+		query          func(sp *Space, player *Rectangle)
+		wantCandidates int64
+		wantTests      int64
+	}{
+		{"colliding shapes, no index", nil, false, func(sp *Space, player *Rectangle) {
+			sp.GetCollidingShapes(player)
+		}, 12, 1},
+		{"colliding shapes, sorted", nil, true, func(sp *Space, player *Rectangle) {
+			sp.GetCollidingShapes(player)
+		}, 3, 1},
+		{"resolve, sorted", nil, true, func(sp *Space, player *Rectangle) {
+			sp.Resolve(player, 0, 4)
+		}, 3, 1},
+		{"pairs, no index", nil, false, func(sp *Space, player *Rectangle) {
+			sp.GetCollidingPairs()
+		}, 66, 55},
+		{"pairs, spatial hash", []SpaceOption{WithSpatialHash(32)}, false, func(sp *Space, player *Rectangle) {
+			sp.GetCollidingPairs()
+		}, 3, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp, player := statsLevel(tt.opts...)
+			if tt.sorted {
+				sp.KeepSortedX()
+			}
+			sp.EnableStats()
+			tt.query(sp, player)
+			stats := sp.Stats()
+			if stats.BroadPhaseCandidates != tt.wantCandidates || stats.NarrowPhaseTests != tt.wantTests {
+				t.Errorf("candidates, tests = %d, %d, want %d, %d", stats.BroadPhaseCandidates, stats.NarrowPhaseTests,
+					tt.wantCandidates, tt.wantTests)
+			}
+		})
+	}
+
+}
+
+func TestSpaceMetricsCandidateRate(t *testing.T) {
+
+	sp, player := statsLevel()
+	rate := sp.metricsRate()
+	rate.metrics()
+	rate.at = rate.at.Add(-1e9)
+
+	sp.GetCollidingShapes(player)
+	m := rate.metrics()
+
+	if m.BroadPhaseCandidatesPerSec <= m.NarrowPhaseTestsPerSec || m.NarrowPhaseTestsPerSec <= 0 {
+		t.Errorf("candidates and tests per second = %v, %v, want more candidates than tests, and some tests",
+			m.BroadPhaseCandidatesPerSec, m.NarrowPhaseTestsPerSec)
+	}
+
+}
+
+func BenchmarkSpaceStats(b *testing.B) {
+
+	for _, bb := range []struct {
+		name    string
+		enabled bool
+	}{{"off", false}, {"on", true}} {
+		b.Run(bb.name, func(b *testing.B) {
+			sp, player := statsLevel()
+			for i := int32(0); i < 1000; i++ {
+				sp.Add(NewRectangle(i*16, 200, 8, 8))
+			}
+			if bb.enabled {
+				sp.EnableStats()
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sp.IsColliding(player)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,163 @@
+package resolv
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+type svgOptions struct {
+	color          color.Color
+	tagColors      []tagColor
+	highlighted    map[Shape]bool
+	highlightColor color.Color
+	gridSize       int32
+	gridColor      color.Color
+	margin         int32
+}
+
+// SVGOption is an option that changes how ExportSVG() draws a Space.
+type SVGOption func(*svgOptions)
+
+// SVGColor sets the stroke color of Shapes, if no other option gives them a color. The default is black.
+func SVGColor(c color.Color) SVGOption {
+	return func(o *svgOptions) {
+		o.color = c
+	}
+}
+
+// SVGTagColor sets the stroke color of Shapes with the tag provided. If a Shape has multiple tags with colors set, the
+// color set first is used.
+func SVGTagColor(tag string, c color.Color) SVGOption {
+	return func(o *svgOptions) {
+		o.tagColors = append(o.tagColors, tagColor{tag, c})
+	}
+}
+
+// SVGHighlight highlights the Shapes provided (like two Shapes that collided unexpectedly) by drawing them in the color
+// provided, with a thicker stroke, overriding any other color they'd have.
+func SVGHighlight(c color.Color, shapes ...Shape) SVGOption {
+	return func(o *svgOptions) {
+		o.highlightColor = c
+		for _, shape := range shapes {
+			o.highlighted[shape] = true
+		}
+	}
+}
+
+// SVGGrid draws a grid with cells of the size provided behind the Shapes, in the color provided.
+func SVGGrid(size int32, c color.Color) SVGOption {
+	return func(o *svgOptions) {
+		o.gridSize = size
+		o.gridColor = c
+	}
+}
+
+// SVGMargin sets the margin left around the Space's bounding rectangle in the SVG's viewBox. The default is 8.
+func SVGMargin(margin int32) SVGOption {
+	return func(o *svgOptions) {
+		o.margin = margin
+	}
+}
+
+// svgColor returns the color formatted as SVG stroke attributes.
+func svgColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0xff {
+		return fmt.Sprintf(`stroke="#%02x%02x%02x"`, n.R, n.G, n.B)
+	}
+	return fmt.Sprintf(`stroke="#%02x%02x%02x" stroke-opacity="%.3f"`, n.R, n.G, n.B, float64(n.A)/0xff)
+}
+
+// ExportSVG writes the Shapes in the Space to the Writer as an SVG image, for inspecting the Space in a browser while
+// debugging. Rectangles, Circles, and Lines are drawn as outlines, nested Spaces become SVG groups, and Shapes other than
+// the built-in ones are drawn as their bounding rectangles. Each Shape's description (see Rectangle.String(), for
+// example) is set as its title, so it shows up when hovering over it. The viewBox fits the Space's bounding rectangle,
+// plus a margin (see SVGMargin()). The output only depends on the Space and the options, so it's stable between runs.
+func ExportSVG(w io.Writer, sp *Space, opts ...SVGOption) error {
+
+	options := &svgOptions{color: color.Black, highlighted: map[Shape]bool{}, margin: 8}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	bounds := sp.GetBoundingRect()
+	x, y := bounds.X-options.margin, bounds.Y-options.margin
+	width, height := bounds.W+options.margin*2, bounds.H+options.margin*2
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="%d %d %d %d" width="%d" height="%d" fill="none">`+"\n",
+		x, y, width, height, width, height)
+
+	if options.gridSize > 0 {
+		fmt.Fprintf(bw, `  <g class="grid" %s stroke-width="0.5">`+"\n", svgColor(options.gridColor))
+		// The grid lines start at the first multiple of the grid size within the viewBox.
+		for gx := -floorDiv(-x, options.gridSize) * options.gridSize; gx <= x+width; gx += options.gridSize {
+			fmt.Fprintf(bw, `    <line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", gx, y, gx, y+height)
+		}
+		for gy := -floorDiv(-y, options.gridSize) * options.gridSize; gy <= y+height; gy += options.gridSize {
+			fmt.Fprintf(bw, `    <line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", x, gy, x+width, gy)
+		}
+		bw.WriteString("  </g>\n")
+	}
+
+	exportSVGShapes(bw, sp, options, "  ")
+
+	bw.WriteString("</svg>\n")
+
+	return bw.Flush()
+
+}
+
+func exportSVGShapes(w *bufio.Writer, sp *Space, options *svgOptions, indent string) {
+
+	for _, shape := range sp.shapes {
+
+		if inner, ok := shape.(*Space); ok {
+			w.WriteString(indent + "<g>\n")
+			exportSVGShapes(w, inner, options, indent+"  ")
+			w.WriteString(indent + "</g>\n")
+			continue
+		}
+
+		c := options.color
+		for _, tc := range options.tagColors {
+			if shape.HasTags(tc.tag) {
+				c = tc.color
+				break
+			}
+		}
+		stroke := svgColor(c)
+		if options.highlighted[shape] {
+			stroke = svgColor(options.highlightColor) + ` stroke-width="2"`
+		}
+
+		switch s := shape.(type) {
+		case *Rectangle:
+			fmt.Fprintf(w, `%s<rect x="%d" y="%d" width="%d" height="%d" %s>`, indent, s.X, s.Y, s.W, s.H, stroke)
+			writeSVGTitle(w, shape, "</rect>\n")
+		case *Circle:
+			fmt.Fprintf(w, `%s<circle cx="%d" cy="%d" r="%d" %s>`, indent, s.X, s.Y, s.Radius, stroke)
+			writeSVGTitle(w, shape, "</circle>\n")
+		case *Line:
+			fmt.Fprintf(w, `%s<line x1="%d" y1="%d" x2="%d" y2="%d" %s>`, indent, s.X, s.Y, s.X2, s.Y2, stroke)
+			writeSVGTitle(w, shape, "</line>\n")
+		default:
+			r := shapeBoundingRect(shape)
+			fmt.Fprintf(w, `%s<rect x="%d" y="%d" width="%d" height="%d" %s stroke-dasharray="2">`, indent, r.X, r.Y, r.W, r.H, stroke)
+			writeSVGTitle(w, shape, "</rect>\n")
+		}
+
+	}
+
+}
+
+// writeSVGTitle writes the Shape's description as an SVG title element, followed by the closing tag provided.
+func writeSVGTitle(w *bufio.Writer, shape Shape, closing string) {
+	w.WriteString("<title>")
+	xml.EscapeText(w, []byte(fmt.Sprint(shape)))
+	w.WriteString("</title>" + closing)
+}
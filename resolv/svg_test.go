@@ -0,0 +1,78 @@
+package resolv
+
+import (
+	"bytes"
+	"flag"
+	"image/color"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+func TestExportSVGGolden(t *testing.T) {
+
+	solid := NewRectangle(0, 0, 64, 16, WithTags("solid"))
+	coin := NewCircle(40, -8, 4, WithTags("coin"))
+	ramp := NewLine(0, 16, 32, 40)
+	player := NewRectangle(8, -16, 8, 16)
+
+	nested := NewSpace()
+	nested.Add(NewRectangle(48, 24, 8, 8), NewCircle(60, 36, 3))
+
+	space := NewSpace()
+	space.Add(solid, coin, ramp, player, nested)
+
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+	translucent := color.NRGBA{0, 0, 0xff, 0x80}
+
+	tests := []struct {
+		name string
+		opts []SVGOption
+	}{
+		{"default", nil},
+		{"colored", []SVGOption{
+			SVGColor(color.NRGBA{0x80, 0x80, 0x80, 0xff}),
+			SVGTagColor("solid", color.NRGBA{0, 0x80, 0, 0xff}),
+			SVGTagColor("coin", translucent),
+			SVGHighlight(red, player, solid),
+		}},
+		{"grid", []SVGOption{SVGGrid(16, translucent), SVGMargin(4)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var got bytes.Buffer
+			if err := ExportSVG(&got, space, tt.opts...); err != nil {
+				t.Fatalf("ExportSVG() error = %v", err)
+			}
+
+			var again bytes.Buffer
+			if err := ExportSVG(&again, space, tt.opts...); err != nil {
+				t.Fatalf("ExportSVG() error = %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), again.Bytes()) {
+				t.Fatal("ExportSVG() wrote different output for the same Space")
+			}
+
+			golden := filepath.Join("testdata", "svg_"+tt.name+".svg")
+			if *updateGolden {
+				if err := ioutil.WriteFile(golden, got.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run the tests with -update to create it)", err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("ExportSVG() differs from %s (run the tests with -update if the change is intended):\n%s",
+					golden, got.String())
+			}
+
+		})
+	}
+
+}
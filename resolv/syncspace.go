@@ -0,0 +1,129 @@
+package resolv
+
+import "sync"
+
+// SyncSpace wraps a Space so that it can be safely used from multiple goroutines at once, guarding it with a
+// sync.RWMutex. Queries that only read the Space and its Shapes (IsColliding, GetCollidingShapes, WouldBeColliding,
+// Resolve, Contains, Length, ForEach, Read, and Snapshot) take a read lock, so they can run at the same time as each
+// other. Everything that changes the Space (Add, Remove, Clear, and Write) takes the write lock, as does FilterByTags, as
+// it can rebuild the Space's tag index.
+// Shapes in a SyncSpace shouldn't be changed (moved, tagged, etc.) other than from within Write().
+type SyncSpace struct {
+	mutex sync.RWMutex
+	space *Space
+}
+
+// NewSyncSpace returns a pointer to a new, empty SyncSpace.
+func NewSyncSpace() *SyncSpace {
+	return &SyncSpace{space: NewSpace()}
+}
+
+// Add adds the designated Shapes to the Space. See Space.Add().
+func (ss *SyncSpace) Add(shapes ...Shape) error {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	return ss.space.Add(shapes...)
+}
+
+// Remove removes the designated Shapes from the Space. See Space.Remove().
+func (ss *SyncSpace) Remove(shapes ...Shape) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	ss.space.Remove(shapes...)
+}
+
+// Clear removes all Shapes from the Space. See Space.Clear().
+func (ss *SyncSpace) Clear() {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	ss.space.Clear()
+}
+
+// IsColliding returns whether the provided Shape is colliding with something in the Space. See Space.IsColliding().
+func (ss *SyncSpace) IsColliding(shape Shape) bool {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.space.IsColliding(shape)
+}
+
+// GetCollidingShapes returns a Space comprised of Shapes that collide with the checking Shape. See
+// Space.GetCollidingShapes().
+func (ss *SyncSpace) GetCollidingShapes(shape Shape) *Space {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.space.GetCollidingShapes(shape)
+}
+
+// WouldBeColliding returns true if any of the Shapes within the Space would be colliding with the other Shape should they
+// move along the delta X and Y values provided. See Space.WouldBeColliding().
+func (ss *SyncSpace) WouldBeColliding(other Shape, dx, dy int32) bool {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.space.WouldBeColliding(other, dx, dy)
+}
+
+// Resolve runs Resolve() using the checking Shape against all other Shapes in the Space. See Space.Resolve().
+func (ss *SyncSpace) Resolve(checkingShape Shape, deltaX, deltaY int32) Collision {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.space.Resolve(checkingShape, deltaX, deltaY)
+}
+
+// FilterByTags returns a new Space that has just the Shapes that have all of the specified tags. See
+// Space.FilterByTags().
+func (ss *SyncSpace) FilterByTags(tags ...string) *Space {
+	// Filtering by tags can rebuild the Space's tag index, so it takes the write lock.
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	return ss.space.FilterByTags(tags...)
+}
+
+// Contains returns true if the Shape provided exists within the Space.
+func (ss *SyncSpace) Contains(shape Shape) bool {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.space.Contains(shape)
+}
+
+// Length returns the number of Shapes contained within the Space.
+func (ss *SyncSpace) Length() int {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.space.Length()
+}
+
+// ForEach calls the function provided for each Shape in the Space under a read lock. The function must not change the
+// Space or its Shapes; use Write() for that.
+func (ss *SyncSpace) ForEach(forEach func(Shape) bool) {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	for _, shape := range ss.space.shapes {
+		if !forEach(shape) {
+			break
+		}
+	}
+}
+
+// Read calls the function provided with the underlying Space under a read lock, allowing several queries to be made
+// consistently. The function must not change the Space or its Shapes, and must not keep the Space around after returning.
+func (ss *SyncSpace) Read(read func(*Space)) {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	read(ss.space)
+}
+
+// Write calls the function provided with the underlying Space under the write lock, allowing the Space and its Shapes to
+// be changed. The function must not keep the Space around after returning.
+func (ss *SyncSpace) Write(write func(*Space)) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	write(ss.space)
+}
+
+// Snapshot returns a deep copy of the Space (see Space.Clone()), taken under a read lock. As the copy shares nothing but
+// Data with the SyncSpace, it can be queried and changed freely without any locking, while the SyncSpace keeps changing.
+func (ss *SyncSpace) Snapshot() *Space {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return ss.space.Clone()
+}
@@ -0,0 +1,118 @@
+package resolv
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TestSyncSpaceConcurrent is meant to be run with the race detector (go test -race).
+func TestSyncSpaceConcurrent(t *testing.T) {
+
+	ss := NewSyncSpace()
+	for i := int32(0); i < 50; i++ {
+		ss.Add(NewRectangle(i*16, 0, 8, 8))
+	}
+
+	// Lines cache their lengths, which mustn't be written to by read-locked queries; one has its end set directly, so its
+	// length isn't cached.
+	var lines []*Line
+	for i := int32(0); i < 10; i++ {
+		lines = append(lines, NewLine(i*80, -8, i*80+24, 16))
+	}
+	lines[0].X2 += 8
+	for _, line := range lines {
+		ss.Add(line)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(7)
+
+	go func() {
+		defer wg.Done()
+		for i := int32(0); i < 100; i++ {
+			ss.Add(NewRectangle(i*16, 32, 8, 8))
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		player := NewRectangle(0, 16, 8, 8)
+		for i := 0; i < 100; i++ {
+			ss.Resolve(player, 0, 32)
+			ss.WouldBeColliding(player, 0, 16)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		probe := NewRectangle(0, 0, 800, 40)
+		for i := 0; i < 100; i++ {
+			ss.GetCollidingShapes(probe)
+			ss.IsColliding(probe)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			snapshot := ss.Snapshot()
+			snapshot.Move(1, 0)
+			ss.Write(func(sp *Space) { sp.Get(i).Move(0, 1) })
+		}
+	}()
+
+	for g := 0; g < 2; g++ {
+		go func() {
+			defer wg.Done()
+			probe := NewLine(0, 4, 800, 12)
+			for i := 0; i < 100; i++ {
+				ss.GetCollidingShapes(probe)
+				ss.Resolve(probe, 0, 8)
+				ss.Read(func(sp *Space) {
+					for _, line := range lines {
+						line.GetLength()
+						// Yielding lets the other reader in while this one still holds the read lock.
+						runtime.Gosched()
+					}
+				})
+			}
+		}()
+	}
+
+	go func() {
+		defer wg.Done()
+		for i := int32(0); i < 40; i++ {
+			ss.Write(func(sp *Space) {
+				if line := lines[i%10]; i%2 == 0 {
+					line.SetEndpoints(i*40, -8, i*40+16, 16+i)
+				} else {
+					line.Y2++
+				}
+			})
+		}
+	}()
+
+	wg.Wait()
+
+	if got := ss.Length(); got != 160 {
+		t.Errorf("Length() = %d, want 160", got)
+	}
+
+}
+
+func TestSyncSpaceSnapshotIsIndependent(t *testing.T) {
+
+	ss := NewSyncSpace()
+	shape := NewRectangle(0, 0, 8, 8)
+	ss.Add(shape)
+
+	snapshot := ss.Snapshot()
+	snapshot.Get(0).Move(10, 10)
+	snapshot.Add(NewRectangle(0, 0, 8, 8))
+
+	if x, y := shape.GetXY(); x != 0 || y != 0 || ss.Length() != 1 {
+		t.Errorf("changing the Snapshot() changed the SyncSpace: Shape at %d, %d, %d Shapes", x, y, ss.Length())
+	}
+
+}
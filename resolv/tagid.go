@@ -0,0 +1,105 @@
+package resolv
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// bitTags is how many of the interned tags are also kept as bits in Tags sets, to test them with bit operations.
+const bitTags = 64
+
+var (
+	// tagIDs maps interned tags to their IDs. It's replaced rather than changed when a tag is interned, so that it can be
+	// read without locking.
+	tagIDs      atomic.Value // map[string]uint32
+	tagIDsMutex sync.Mutex
+)
+
+// TagID returns the ID of the tag provided, interning the tag (assigning it the next ID, counting up from 0) if it
+// hasn't been interned yet. The first 64 tags to be interned are also kept as bits alongside the tags on Shapes, so that
+// HasTags() and Space.FilterByTags() can test them with bit operations rather than comparing strings, whenever all of the
+// tags asked for are among them.
+// This happens automatically: the first 64 distinct tags added to Tags sets (and so to Shapes) are interned as they're
+// added. TagID() can be called ahead of time to make sure that the tags that are filtered by the most are among them.
+func TagID(name string) uint32 {
+	id, _ := internTag(name, true)
+	return id
+}
+
+// lookupTagID returns the ID of the tag provided, and whether it's been interned.
+func lookupTagID(tag string) (uint32, bool) {
+	ids, _ := tagIDs.Load().(map[string]uint32)
+	id, ok := ids[tag]
+	return id, ok
+}
+
+// internTag returns the ID of the tag provided, interning it if it hasn't been interned yet. Unless always is true, the
+// tag is only interned if it'd be kept as a bit; otherwise, internTag returns false if it isn't interned.
+func internTag(tag string, always bool) (uint32, bool) {
+
+	if id, ok := lookupTagID(tag); ok {
+		return id, true
+	}
+
+	tagIDsMutex.Lock()
+	defer tagIDsMutex.Unlock()
+
+	ids, _ := tagIDs.Load().(map[string]uint32)
+	if id, ok := ids[tag]; ok {
+		return id, true
+	}
+	if !always && len(ids) >= bitTags {
+		return 0, false
+	}
+
+	interned := make(map[string]uint32, len(ids)+1)
+	for name, id := range ids {
+		interned[name] = id
+	}
+	id := uint32(len(ids))
+	interned[tag] = id
+	tagIDs.Store(interned)
+
+	return id, true
+
+}
+
+// tagBit returns the bit the tag provided is kept as in Tags sets, interning it if there's still a free bit for it.
+// It returns false if the tag isn't kept as a bit.
+func tagBit(tag string) (uint64, bool) {
+	if id, ok := internTag(tag, false); ok && id < bitTags {
+		return 1 << id, true
+	}
+	return 0, false
+}
+
+// tagMask returns the bits of all of the tags provided, or false if any of them aren't kept as bits (in which case the
+// tags have to be compared as strings).
+func tagMask(tags []string) (uint64, bool) {
+	ids, _ := tagIDs.Load().(map[string]uint32)
+	mask := uint64(0)
+	for _, tag := range tags {
+		id, ok := ids[tag]
+		if !ok || id >= bitTags {
+			return 0, false
+		}
+		mask |= 1 << id
+	}
+	return mask, true
+}
+
+// filterByTagBits returns a Space comprised of the Shapes in the Space that have all of the bits in the mask, which holds
+// the bits of the tags provided. Shapes whose tags aren't indexable are tested with HasTags().
+func (sp *Space) filterByTagBits(tags []string, mask uint64) *Space {
+	subSpace := newResultSpace()
+	for _, shape := range sp.shapes {
+		if set, ok := indexableTagSet(shape); ok {
+			if set.bits&mask == mask {
+				subSpace.Add(shape)
+			}
+		} else if shape.HasTags(tags...) {
+			subSpace.Add(shape)
+		}
+	}
+	return subSpace
+}
@@ -0,0 +1,376 @@
+package resolv
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// tagGeneration counts changes to the tags of any BasicShape. Each BasicShape remembers the generation its own tags last
+// changed at, and each tag index the generation it was last known to be up to date at.
+var tagGeneration uint64
+
+// tagsChanged records that the BasicShape's tags have changed, which puts any index it's in out of date.
+func (b *BasicShape) tagsChanged() {
+	atomic.StoreUint64(&b.tagGeneration, atomic.AddUint64(&tagGeneration, 1))
+}
+
+// tagIndex maps tags to the (sorted) indices of the Shapes in a Space that have them. Only the built-in Shapes are
+// indexed, as their tags can only change through BasicShape; any other Shapes (including nested Spaces) are kept in a
+// separate list and checked with HasTags() when filtering. The index is built lazily, kept up to date when adding Shapes,
+// and thrown away when Shapes are removed, or once the tags of any of its Shapes have changed since it was built (see
+// tagGeneration). The index holds no references from the Shapes back to it, so Spaces that are dropped (like the results
+// of queries) are simply garbage collected along with their indexes.
+type tagIndex struct {
+	valid      bool
+	generation uint64
+	byTag      map[string][]int
+	unindexed  []int
+
+	// indexed holds the BasicShape of each Shape in the Space, by index, or nil for the Shapes that aren't indexed.
+	indexed []*BasicShape
+}
+
+// indexableBasicShape returns the BasicShape of the Shape, if it's one of the Shapes that can be indexed.
+func indexableBasicShape(shape Shape) (*BasicShape, bool) {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return &s.BasicShape, true
+	case *Circle:
+		return &s.BasicShape, true
+	case *Line:
+		return &s.BasicShape, true
+	}
+	return nil, false
+}
+
+// indexableTagSet returns the set of tags on the Shape, if it's one of the Shapes that can be indexed.
+func indexableTagSet(shape Shape) (*Tags, bool) {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return &s.tags, true
+	case *Circle:
+		return &s.tags, true
+	case *Line:
+		return &s.tags, true
+	}
+	return nil, false
+}
+
+func (ti *tagIndex) invalidate() {
+	ti.valid = false
+	ti.byTag = nil
+	ti.indexed = nil
+	ti.unindexed = nil
+}
+
+func (ti *tagIndex) build(shapes []Shape) {
+	ti.invalidate()
+	// The generation is read before the tags are, so that tags changing while the index is built leave it out of date.
+	ti.generation = atomic.LoadUint64(&tagGeneration)
+	ti.byTag = map[string][]int{}
+	ti.valid = true
+	for i, shape := range shapes {
+		ti.add(shape, i)
+	}
+}
+
+func (ti *tagIndex) add(shape Shape, index int) {
+
+	if !ti.valid {
+		return
+	}
+
+	b, ok := indexableBasicShape(shape)
+	ti.indexed = append(ti.indexed, b)
+	if !ok {
+		ti.unindexed = append(ti.unindexed, index)
+		return
+	}
+
+	tags := b.tags.sorted
+
+	for _, tag := range tags {
+		list := ti.byTag[tag]
+		// A Shape can have the same tag multiple times, but should only be indexed once.
+		if len(list) == 0 || list[len(list)-1] != index {
+			ti.byTag[tag] = append(list, index)
+		}
+	}
+
+}
+
+// current returns whether the index is built and up to date. Only when tags have changed somewhere since the index was
+// last checked are its Shapes looked at, to see whether it was any of theirs.
+func (ti *tagIndex) current() bool {
+	if !ti.valid {
+		return false
+	}
+	latest := atomic.LoadUint64(&tagGeneration)
+	if ti.generation == latest {
+		return true
+	}
+	for _, b := range ti.indexed {
+		if b != nil && atomic.LoadUint64(&b.tagGeneration) > ti.generation {
+			return false
+		}
+	}
+	ti.generation = latest
+	return true
+}
+
+// swapRemove updates the index for the Shape at the index provided being removed, and the last Shape (at the index
+// provided as last) being moved into its place, so that only the lists of those two Shapes' tags change.
+func (ti *tagIndex) swapRemove(index, last int) {
+
+	if !ti.current() {
+		ti.invalidate()
+		return
+	}
+
+	if removed := ti.indexed[index]; removed == nil {
+		ti.unindexed = removeSortedInt(ti.unindexed, index)
+	} else {
+		for _, tag := range removed.tags.sorted {
+			ti.setList(tag, removeSortedInt(ti.byTag[tag], index))
+		}
+	}
+
+	if index < last {
+		if moved := ti.indexed[last]; moved == nil {
+			ti.unindexed = insertSortedInt(removeSortedInt(ti.unindexed, last), index)
+		} else {
+			for _, tag := range moved.tags.sorted {
+				ti.setList(tag, insertSortedInt(removeSortedInt(ti.byTag[tag], last), index))
+			}
+		}
+		ti.indexed[index] = ti.indexed[last]
+	}
+
+	ti.indexed[last] = nil
+	ti.indexed = ti.indexed[:last]
+
+}
+
+// setList sets the list of indices of the Shapes with the tag provided, dropping the tag if no Shapes have it.
+func (ti *tagIndex) setList(tag string, list []int) {
+	if len(list) == 0 {
+		delete(ti.byTag, tag)
+	} else {
+		ti.byTag[tag] = list
+	}
+}
+
+// removeSortedInt removes the value provided from the sorted list, if it's in it.
+func removeSortedInt(list []int, value int) []int {
+	if i := sort.SearchInts(list, value); i < len(list) && list[i] == value {
+		return append(list[:i], list[i+1:]...)
+	}
+	return list
+}
+
+// insertSortedInt inserts the value provided into the sorted list, if it isn't already in it.
+func insertSortedInt(list []int, value int) []int {
+	i := sort.SearchInts(list, value)
+	if i < len(list) && list[i] == value {
+		return list
+	}
+	list = append(list, 0)
+	copy(list[i+1:], list[i:])
+	list[i] = value
+	return list
+}
+
+func (ti *tagIndex) update(shapes []Shape) {
+	if !ti.current() {
+		ti.build(shapes)
+	}
+}
+
+// filterAny returns the sorted indices of the Shapes that have at least one of the tags provided.
+func (ti *tagIndex) filterAny(shapes []Shape, tags []string) []int {
+
+	ti.update(shapes)
+
+	found := map[int]bool{}
+	matches := []int{}
+
+	for _, tag := range tags {
+		for _, index := range ti.byTag[tag] {
+			if !found[index] {
+				found[index] = true
+				matches = append(matches, index)
+			}
+		}
+	}
+
+	for _, index := range ti.unindexed {
+		if hasAnyTags(shapes[index], tags...) {
+			matches = append(matches, index)
+		}
+	}
+
+	sort.Ints(matches)
+
+	return matches
+
+}
+
+// filterPattern returns the sorted indices of the Shapes that have a tag matching the pattern provided. Each distinct
+// indexed tag is matched against the pattern only once.
+func (ti *tagIndex) filterPattern(shapes []Shape, p tagPattern) []int {
+
+	ti.update(shapes)
+
+	found := map[int]bool{}
+	matches := []int{}
+
+	for tag, list := range ti.byTag {
+		if !p.match(tag) {
+			continue
+		}
+		for _, index := range list {
+			if !found[index] {
+				found[index] = true
+				matches = append(matches, index)
+			}
+		}
+	}
+
+	for _, index := range ti.unindexed {
+		if hasTagMatching(shapes[index], p) {
+			matches = append(matches, index)
+		}
+	}
+
+	sort.Ints(matches)
+
+	return matches
+
+}
+
+// filter returns the sorted indices of the Shapes that have all of the tags provided.
+func (ti *tagIndex) filter(shapes []Shape, tags []string) []int {
+
+	ti.update(shapes)
+
+	lists := make([][]int, 0, len(tags))
+	for _, tag := range tags {
+		lists = append(lists, ti.byTag[tag])
+	}
+
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	matches := append([]int{}, lists[0]...)
+
+	for _, list := range lists[1:] {
+		kept := matches[:0]
+		j := 0
+		for _, index := range matches {
+			for j < len(list) && list[j] < index {
+				j++
+			}
+			if j < len(list) && list[j] == index {
+				kept = append(kept, index)
+			}
+		}
+		matches = kept
+	}
+
+	if len(ti.unindexed) == 0 {
+		return matches
+	}
+
+	merged := make([]int, 0, len(matches))
+	j := 0
+	for _, index := range ti.unindexed {
+		if !shapes[index].HasTags(tags...) {
+			continue
+		}
+		for j < len(matches) && matches[j] < index {
+			merged = append(merged, matches[j])
+			j++
+		}
+		merged = append(merged, index)
+	}
+
+	return append(merged, matches[j:]...)
+
+}
+
+// count returns how many Shapes have the tag provided, without allocating.
+func (ti *tagIndex) count(shapes []Shape, tag string) int {
+	ti.update(shapes)
+	count := len(ti.byTag[tag])
+	for _, index := range ti.unindexed {
+		if shapes[index].HasTags(tag) {
+			count++
+		}
+	}
+	return count
+}
+
+// countAll returns how many Shapes have all of the tags provided (of which there has to be at least one), without
+// allocating unless there are Shapes the index doesn't cover.
+func (ti *tagIndex) countAll(shapes []Shape, tags []string) int {
+
+	ti.update(shapes)
+
+	shortest := ti.byTag[tags[0]]
+	for _, tag := range tags[1:] {
+		if list := ti.byTag[tag]; len(list) < len(shortest) {
+			shortest = list
+		}
+	}
+
+	count := 0
+	for _, index := range shortest {
+		all := true
+		for _, tag := range tags {
+			list := ti.byTag[tag]
+			if i := sort.SearchInts(list, index); i == len(list) || list[i] != index {
+				all = false
+				break
+			}
+		}
+		if all {
+			count++
+		}
+	}
+
+	if len(ti.unindexed) > 0 {
+		// The tags are copied for the Shapes that have to be asked, so that the caller's slice doesn't escape.
+		copied := append([]string(nil), tags...)
+		for _, index := range ti.unindexed {
+			if shapes[index].HasTags(copied...) {
+				count++
+			}
+		}
+	}
+
+	return count
+
+}
+
+// census returns how many Shapes have each tag.
+func (ti *tagIndex) census(shapes []Shape) map[string]int {
+
+	ti.update(shapes)
+
+	census := make(map[string]int, len(ti.byTag))
+	for tag, list := range ti.byTag {
+		census[tag] = len(list)
+	}
+
+	for _, index := range ti.unindexed {
+		counted := map[string]bool{}
+		for _, tag := range shapes[index].GetTags() {
+			if !counted[tag] {
+				counted[tag] = true
+				census[tag]++
+			}
+		}
+	}
+
+	return census
+
+}
@@ -0,0 +1,373 @@
+package resolv
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTagIndexFilter(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		change func(sp *Space, a, b, c *Rectangle)
+		want   []int
+	}{
+		{"unchanged", func(sp *Space, a, b, c *Rectangle) {}, []int{0, 2}},
+		{"tag added", func(sp *Space, a, b, c *Rectangle) { b.AddTags("enemy") }, []int{0, 1, 2}},
+		{"tag removed", func(sp *Space, a, b, c *Rectangle) { a.RemoveTags("enemy") }, []int{2}},
+		{"tags cleared", func(sp *Space, a, b, c *Rectangle) { c.ClearTags() }, []int{0}},
+		{"shape removed", func(sp *Space, a, b, c *Rectangle) { sp.Remove(a) }, []int{2}},
+		{"removed shape retagged", func(sp *Space, a, b, c *Rectangle) {
+			sp.Remove(b)
+			b.AddTags("enemy")
+		}, []int{0, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			a, b, c := NewRectangle(0, 0, 8, 8), NewRectangle(8, 0, 8, 8), NewRectangle(16, 0, 8, 8)
+			a.AddTags("enemy")
+			c.AddTags("enemy", "flying")
+			all := []*Rectangle{a, b, c}
+			sp := NewSpace()
+			sp.Add(a, b, c)
+			sp.tags.update(sp.shapes)
+
+			tt.change(sp, a, b, c)
+
+			got := sp.FilterByTags("enemy")
+			if got.Length() != len(tt.want) {
+				t.Fatalf("FilterByTags() found %d shapes, want %d", got.Length(), len(tt.want))
+			}
+			for i, index := range tt.want {
+				if got.Get(i) != all[index] {
+					t.Errorf("FilterByTags() shape %d isn't shape %d", i, index)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestTagIndexIsPerSpace(t *testing.T) {
+
+	tests := []struct {
+		name                 string
+		shared               bool
+		wantFirst, wantOther bool
+	}{
+		{"shape in the other space only", false, true, false},
+		{"shape in both spaces", true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			first, other := NewSpace(), NewSpace()
+			first.Add(NewRectangle(0, 0, 8, 8))
+			changed := NewRectangle(0, 0, 8, 8)
+			other.Add(changed)
+			if tt.shared {
+				first.Add(changed)
+			}
+			first.tags.update(first.shapes)
+			other.tags.update(other.shapes)
+
+			changed.AddTags("enemy")
+
+			if first.tags.current() != tt.wantFirst || other.tags.current() != tt.wantOther {
+				t.Errorf("indexes current = %v, %v, want %v, %v", first.tags.current(), other.tags.current(), tt.wantFirst,
+					tt.wantOther)
+			}
+
+		})
+	}
+
+}
+
+func TestTagIndexGoesStale(t *testing.T) {
+
+	shape := NewRectangle(0, 0, 8, 8, WithTags("a"))
+	level := NewSpace()
+	level.Add(shape, NewRectangle(4, 4, 8, 8, WithTags("b")))
+	probe := NewRectangle(0, 0, 16, 16)
+
+	// The level's index and a query result's index both see the Shape's tags change.
+	result := level.GetCollidingShapes(probe)
+	if level.FilterByTags("a").Length() != 1 || result.FilterByTags("a").Length() != 1 {
+		t.Fatal("FilterByTags() didn't find the tagged Shape")
+	}
+	shape.RemoveTags("a")
+	shape.AddTags("b")
+	if level.FilterByTags("a").Length() != 0 || result.FilterByTags("a").Length() != 0 ||
+		level.FilterByTags("b").Length() != 2 || result.FilterByAnyTags("b").Length() != 2 {
+		t.Error("FilterByTags() used an index from before the Shape's tags changed")
+	}
+
+	// Query results that were filtered by tag don't stay reachable through their Shapes once they're dropped.
+	var collected int32
+	for i := 0; i < 100; i++ {
+		result := level.GetCollidingShapes(probe)
+		result.FilterByAnyTags("a", "b")
+		runtime.SetFinalizer(result, func(*Space) { atomic.AddInt32(&collected, 1) })
+	}
+	for i := 0; i < 20 && atomic.LoadInt32(&collected) == 0; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&collected) == 0 {
+		t.Error("none of the query results filtered by tag were garbage collected")
+	}
+
+}
+
+func TestTagIndexMatchesScan(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(5))
+	tags := []string{"solid", "platform", "slope", "enemy", "hazard", "water"}
+	randomTags := func() []string {
+		var picked []string
+		for _, tag := range tags {
+			if rng.Intn(3) == 0 {
+				picked = append(picked, tag)
+			}
+		}
+		return picked
+	}
+
+	var shapes []*Rectangle
+	sp := NewSpace()
+	for i := 0; i < 200; i++ {
+		shape := NewRectangle(int32(i)*8, 0, 8, 8)
+		shape.AddTags(randomTags()...)
+		shapes = append(shapes, shape)
+		sp.Add(shape)
+	}
+
+	for round := 0; round < 100; round++ {
+
+		shape := shapes[rng.Intn(len(shapes))]
+		switch rng.Intn(4) {
+		case 0:
+			shape.AddTags(randomTags()...)
+		case 1:
+			shape.RemoveTags(randomTags()...)
+		case 2:
+			sp.Remove(shape)
+		default:
+			if !sp.Contains(shape) {
+				sp.Add(shape)
+			}
+		}
+
+		filter := randomTags()
+		want := []Shape{}
+		for _, s := range sp.shapes {
+			if s.HasTags(filter...) {
+				want = append(want, s)
+			}
+		}
+
+		if got := sp.CountByTags(filter...); got != len(want) {
+			t.Fatalf("round %d: CountByTags(%v) = %d, want %d", round, filter, got, len(want))
+		}
+
+		got := sp.FilterByTags(filter...)
+		if got.Length() != len(want) {
+			t.Fatalf("round %d: FilterByTags(%v) found %d Shapes, want %d", round, filter, got.Length(), len(want))
+		}
+		for i, shape := range want {
+			if got.Get(i) != shape {
+				t.Fatalf("round %d: FilterByTags(%v) Shape %d is out of order", round, filter, i)
+			}
+		}
+
+	}
+
+}
+
+func BenchmarkFilterByTags(b *testing.B) {
+
+	sp := NewSpace()
+	for i := 0; i < 10000; i++ {
+		shape := NewRectangle(int32(i)*8, 0, 8, 8)
+		shape.AddTags(fmt.Sprintf("kind%d", i%100), "solid")
+		sp.Add(shape)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sp.FilterByTags("kind7", "solid")
+	}
+
+}
+
+func TestSpaceShapes(t *testing.T) {
+
+	a, b := NewRectangle(0, 0, 8, 8), NewCircle(0, 0, 4)
+	sp := NewSpace()
+	sp.Add(a, b)
+
+	shapes := sp.Shapes()
+	if len(shapes) != 2 || shapes[0] != a || shapes[1] != b {
+		t.Fatalf("Shapes() = %v, want the Shapes in order", shapes)
+	}
+	shapes[0] = b
+	if sp.Get(0) != a {
+		t.Error("changing the slice returned by Shapes() changed the Space")
+	}
+
+}
+
+func BenchmarkCountByTagsOtherSpaceRetagged(b *testing.B) {
+
+	level := NewSpace()
+	for i := 0; i < 10000; i++ {
+		shape := NewRectangle(int32(i)*8, 0, 8, 8)
+		// More distinct tags than fit in bits, so the tag index is used.
+		shape.AddTags(fmt.Sprintf("kind%d", i%100))
+		level.Add(shape)
+	}
+	particle := NewRectangle(0, 0, 1, 1)
+	effects := NewSpace()
+	effects.Add(particle)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		particle.ClearTags()
+		particle.AddTags("spark")
+		level.CountByTags("kind7")
+	}
+
+}
+
+func TestFilterByAnyTags(t *testing.T) {
+
+	solid, platform := NewRectangle(0, 0, 8, 8), NewRectangle(8, 0, 8, 8)
+	slope, decor := NewRectangle(16, 0, 8, 8), NewRectangle(24, 0, 8, 8)
+	solid.AddTags("solid")
+	platform.AddTags("platform", "solid")
+	slope.AddTags("slope")
+	decor.AddTags("decor")
+	sp := NewSpace()
+	sp.Add(solid, platform, slope, decor)
+
+	tests := []struct {
+		name string
+		tags []string
+		want []Shape
+	}{
+		{"no tags", nil, nil},
+		{"one tag", []string{"slope"}, []Shape{slope}},
+		{"any of several", []string{"solid", "platform", "slope"}, []Shape{solid, platform, slope}},
+		{"unknown tag", []string{"water"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got := sp.FilterByAnyTags(tt.tags...)
+			if got.Length() != len(tt.want) {
+				t.Fatalf("FilterByAnyTags() found %d Shapes, want %d", got.Length(), len(tt.want))
+			}
+			for i, shape := range tt.want {
+				if got.Get(i) != shape {
+					t.Errorf("FilterByAnyTags() Shape %d = %v, want %v", i, got.Get(i), shape)
+				}
+			}
+
+			wantAny := len(tt.want) > 0
+			if got := sp.HasAnyTags(tt.tags...); got != wantAny {
+				t.Errorf("Space.HasAnyTags() = %v, want %v", got, wantAny)
+			}
+
+		})
+	}
+
+	if decor.HasAnyTags() {
+		t.Error("BasicShape.HasAnyTags() with no tags = true, want false")
+	}
+	if NewSpace().HasAnyTags("solid") {
+		t.Error("HasAnyTags() on an empty Space = true, want false")
+	}
+
+}
+
+func TestTagCounts(t *testing.T) {
+
+	tagged := func(tags ...string) Shape {
+		shape := NewRectangle(0, 0, 8, 8)
+		shape.AddTags(tags...)
+		return shape
+	}
+
+	tests := []struct {
+		name       string
+		shapes     []Shape
+		all, any   int
+		wantCensus map[string]int
+	}{
+		{"empty Space", nil, 0, 0, map[string]int{}},
+		{"untagged Shapes", []Shape{tagged(), tagged()}, 0, 0, map[string]int{}},
+		{"mixed", []Shape{tagged("enemy", "flying"), tagged("enemy"), tagged("coin"), tagged()}, 1, 3,
+			map[string]int{"enemy": 2, "flying": 1, "coin": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			sp := NewSpace()
+			sp.Add(tt.shapes...)
+
+			if got := sp.CountByTags("enemy", "flying"); got != tt.all {
+				t.Errorf("CountByTags() = %d, want %d", got, tt.all)
+			}
+			if got := sp.CountByAnyTags("flying", "coin", "enemy"); got != tt.any {
+				t.Errorf("CountByAnyTags() = %d, want %d", got, tt.any)
+			}
+			if got := sp.CountByTags(); got != len(tt.shapes) {
+				t.Errorf("CountByTags() with no tags = %d, want every Shape (%d)", got, len(tt.shapes))
+			}
+			if got := sp.CountByAnyTags(); got != 0 {
+				t.Errorf("CountByAnyTags() with no tags = %d, want 0", got)
+			}
+
+			census := sp.TagCensus()
+			if len(census) != len(tt.wantCensus) {
+				t.Errorf("TagCensus() = %v, want %v", census, tt.wantCensus)
+			}
+			for tag, want := range tt.wantCensus {
+				if census[tag] != want {
+					t.Errorf("TagCensus()[%q] = %d, want %d", tag, census[tag], want)
+				}
+			}
+
+		})
+	}
+
+}
+
+func BenchmarkCountByTags(b *testing.B) {
+
+	sp := NewSpace()
+	for i := 0; i < 10000; i++ {
+		shape := NewRectangle(int32(i)*8, 0, 8, 8)
+		shape.AddTags(fmt.Sprintf("kind%d", i%100))
+		sp.Add(shape)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sp.CountByTags("kind7")
+	}
+
+}
@@ -0,0 +1,156 @@
+package resolv
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	tagPatternExact = iota
+	tagPatternPrefix
+	tagPatternGlob
+	tagPatternInvalid
+)
+
+// tagPattern is a tag pattern compiled once, so it can be matched against many tags quickly. Patterns use the syntax of
+// path.Match(); patterns with no wildcards match just the tag itself, and patterns whose only wildcard is a trailing '*'
+// (like "enemy.*") are matched as prefixes, without going through path.Match().
+type tagPattern struct {
+	pattern string
+	prefix  string
+	kind    int
+}
+
+func compileTagPattern(pattern string) tagPattern {
+
+	p := tagPattern{pattern: pattern}
+	meta := strings.IndexAny(pattern, `*?[\`)
+
+	switch {
+	case meta < 0:
+		p.kind = tagPatternExact
+	case meta == len(pattern)-1 && pattern[meta] == '*':
+		p.kind = tagPatternPrefix
+		p.prefix = pattern[:meta]
+	default:
+		p.kind = tagPatternGlob
+		if _, err := path.Match(pattern, ""); err != nil {
+			p.kind = tagPatternInvalid
+		}
+	}
+
+	return p
+
+}
+
+// match returns whether the tag provided matches the pattern.
+func (p tagPattern) match(tag string) bool {
+	switch p.kind {
+	case tagPatternExact:
+		return tag == p.pattern
+	case tagPatternPrefix:
+		// Like with path.Match(), '*' doesn't match '/'.
+		return strings.HasPrefix(tag, p.prefix) && !strings.Contains(tag[len(p.prefix):], "/")
+	case tagPatternGlob:
+		matched, _ := path.Match(p.pattern, tag)
+		return matched
+	}
+	return false
+}
+
+// matchTags returns whether any of the tags in the set matches the pattern.
+func (p tagPattern) matchTags(tags Tags) bool {
+
+	switch p.kind {
+
+	case tagPatternExact:
+		return tags.Has(p.pattern)
+
+	case tagPatternPrefix:
+		// The tags are sorted, so the tags with the prefix are all together, starting where the prefix would be.
+		for i := sort.SearchStrings(tags.sorted, p.prefix); i < len(tags.sorted); i++ {
+			if !strings.HasPrefix(tags.sorted[i], p.prefix) {
+				break
+			}
+			if p.match(tags.sorted[i]) {
+				return true
+			}
+		}
+		return false
+
+	}
+
+	for _, tag := range tags.sorted {
+		if p.match(tag) {
+			return true
+		}
+	}
+	return false
+
+}
+
+// HasTagMatching returns true if the Shape has a tag matching the pattern provided. Patterns use the syntax of
+// path.Match(), so "enemy.*" matches every tag starting with "enemy.", and "*.solid.*" matches "terrain.solid.ice". A
+// pattern with no wildcards is the same as HasTags() with that single tag. Malformed patterns don't match anything.
+func (b *BasicShape) HasTagMatching(pattern string) bool {
+	return compileTagPattern(pattern).matchTags(b.tags)
+}
+
+// hasTagMatching returns whether the Shape has a tag matching the compiled pattern.
+func hasTagMatching(shape Shape, p tagPattern) bool {
+	switch s := shape.(type) {
+	case *Rectangle:
+		return p.matchTags(s.tags)
+	case *Circle:
+		return p.matchTags(s.tags)
+	case *Line:
+		return p.matchTags(s.tags)
+	case *Space:
+		return s.hasTagMatching(p)
+	case interface{ HasTagMatching(string) bool }:
+		return s.HasTagMatching(p.pattern)
+	}
+	for _, tag := range shape.GetTags() {
+		if p.match(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTagMatching returns true if all of the Shapes contained within the Space have a tag matching the pattern provided
+// (see BasicShape.HasTagMatching()). Like HasTags(), it returns false for an empty Space.
+func (sp *Space) HasTagMatching(pattern string) bool {
+	return sp.hasTagMatching(compileTagPattern(pattern))
+}
+
+func (sp *Space) hasTagMatching(p tagPattern) bool {
+	if len(sp.shapes) == 0 {
+		return false
+	}
+	for _, shape := range sp.shapes {
+		if !hasTagMatching(shape, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterByTagPattern returns a new Space that has just the Shapes that have a tag matching the pattern provided (see
+// BasicShape.HasTagMatching()). The pattern is only compiled once, and checked against each distinct tag in the Space
+// rather than against every Shape, using the Space's tag index.
+func (sp *Space) FilterByTagPattern(pattern string) *Space {
+
+	p := compileTagPattern(pattern)
+	if p.kind == tagPatternExact {
+		return sp.FilterByTags(pattern)
+	}
+
+	subSpace := newResultSpace()
+	for _, index := range sp.tags.filterPattern(sp.shapes, p) {
+		subSpace.Add(sp.shapes[index])
+	}
+	return subSpace
+
+}
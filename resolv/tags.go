@@ -0,0 +1,150 @@
+package resolv
+
+import "sort"
+
+// Tags is a set of tags, kept sorted and without duplicates. The zero value is an empty set, ready to use. BasicShape
+// stores its tags in a Tags set; see BasicShape.TagSet().
+type Tags struct {
+	sorted []string
+
+	// bits holds the bits of the tags in the set that are kept as bits (see TagID()).
+	bits uint64
+}
+
+// NewTags returns a new set holding the tags provided.
+func NewTags(tags ...string) Tags {
+	t := Tags{}
+	t.Add(tags...)
+	return t
+}
+
+// search returns the index of the tag in the set, or the index it would be inserted at, and whether it's in the set.
+func (t Tags) search(tag string) (int, bool) {
+	i := sort.SearchStrings(t.sorted, tag)
+	return i, i < len(t.sorted) && t.sorted[i] == tag
+}
+
+// Add adds the tags provided to the set. Adding a tag that's already in the set does nothing.
+func (t *Tags) Add(tags ...string) {
+	for _, tag := range tags {
+		if i, found := t.search(tag); !found {
+			t.sorted = append(t.sorted, "")
+			copy(t.sorted[i+1:], t.sorted[i:])
+			t.sorted[i] = tag
+			if bit, ok := tagBit(tag); ok {
+				t.bits |= bit
+			}
+		}
+	}
+}
+
+// Remove removes the tags provided from the set. Removing a tag that isn't in the set does nothing.
+func (t *Tags) Remove(tags ...string) {
+	for _, tag := range tags {
+		if i, found := t.search(tag); found {
+			t.sorted = append(t.sorted[:i], t.sorted[i+1:]...)
+			if bit, ok := tagBit(tag); ok {
+				t.bits &^= bit
+			}
+		}
+	}
+}
+
+// Has returns whether the tag provided is in the set.
+func (t Tags) Has(tag string) bool {
+	_, found := t.search(tag)
+	return found
+}
+
+// HasAll returns whether all of the tags provided are in the set. It returns true if no tags are provided.
+func (t Tags) HasAll(tags ...string) bool {
+	if mask, ok := tagMask(tags); ok {
+		return t.bits&mask == mask
+	}
+	for _, tag := range tags {
+		if !t.Has(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns whether at least one of the tags provided is in the set. It returns false if no tags are provided.
+func (t Tags) HasAny(tags ...string) bool {
+	for _, tag := range tags {
+		if t.Has(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of tags in the set.
+func (t Tags) Len() int {
+	return len(t.sorted)
+}
+
+// Strings returns the tags in the set as a new slice, in sorted order.
+func (t Tags) Strings() []string {
+	return append([]string{}, t.sorted...)
+}
+
+// Union returns a new set holding the tags that are in either set.
+func (t Tags) Union(other Tags) Tags {
+	union := make([]string, 0, len(t.sorted)+len(other.sorted))
+	i, j := 0, 0
+	for i < len(t.sorted) && j < len(other.sorted) {
+		switch a, b := t.sorted[i], other.sorted[j]; {
+		case a < b:
+			union = append(union, a)
+			i++
+		case b < a:
+			union = append(union, b)
+			j++
+		default:
+			union = append(union, a)
+			i++
+			j++
+		}
+	}
+	union = append(union, t.sorted[i:]...)
+	union = append(union, other.sorted[j:]...)
+	return Tags{union, t.bits | other.bits}
+}
+
+// Intersect returns a new set holding the tags that are in both sets.
+func (t Tags) Intersect(other Tags) Tags {
+	intersection := []string{}
+	i, j := 0, 0
+	for i < len(t.sorted) && j < len(other.sorted) {
+		switch a, b := t.sorted[i], other.sorted[j]; {
+		case a < b:
+			i++
+		case b < a:
+			j++
+		default:
+			intersection = append(intersection, a)
+			i++
+			j++
+		}
+	}
+	return Tags{intersection, t.bits & other.bits}
+}
+
+// Equal returns whether both sets hold exactly the same tags.
+func (t Tags) Equal(other Tags) bool {
+	if len(t.sorted) != len(other.sorted) {
+		return false
+	}
+	for i := range t.sorted {
+		if t.sorted[i] != other.sorted[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a copy of the set that doesn't share its storage with the original.
+func (t Tags) clone() Tags {
+	return Tags{append([]string(nil), t.sorted...), t.bits}
+}
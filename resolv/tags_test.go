@@ -0,0 +1,158 @@
+package resolv
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+
+	tags := NewTags("solid", "enemy", "solid")
+	tags.Add("enemy", "flying")
+	if got := tags.Strings(); len(got) != 3 || got[0] != "enemy" || got[1] != "flying" || got[2] != "solid" {
+		t.Fatalf("Strings() = %v, want the tags sorted, without duplicates", got)
+	}
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"Has", tags.Has("solid"), true},
+		{"Has missing", tags.Has("water"), false},
+		{"HasAll", tags.HasAll("solid", "enemy"), true},
+		{"HasAll, one missing", tags.HasAll("solid", "water"), false},
+		{"HasAll, none", tags.HasAll(), true},
+		{"HasAny", tags.HasAny("water", "flying"), true},
+		{"HasAny, all missing", tags.HasAny("water", "lava"), false},
+		{"HasAny, none", tags.HasAny(), false},
+		{"Equal", tags.Equal(NewTags("flying", "solid", "enemy")), true},
+		{"Equal, subset", tags.Equal(NewTags("flying", "solid")), false},
+		{"Union", NewTags("a", "c").Union(NewTags("b", "c")).Equal(NewTags("a", "b", "c")), true},
+		{"Intersect", NewTags("a", "b", "c").Intersect(NewTags("b", "c", "d")).Equal(NewTags("b", "c")), true},
+		{"Intersect, disjoint", NewTags("a").Intersect(NewTags("b")).Len() == 0, true},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	tags.Remove("flying", "water")
+	tags.Remove("flying")
+	if !tags.Equal(NewTags("enemy", "solid")) {
+		t.Errorf("Remove() left %v", tags.Strings())
+	}
+
+}
+
+func TestShapeTagsRoundTrip(t *testing.T) {
+
+	shape := NewRectangle(0, 0, 8, 8)
+	shape.AddTags("solid", "enemy")
+	shape.AddTags("solid")
+
+	got := shape.GetTags()
+	if len(got) != 2 || got[0] != "enemy" || got[1] != "solid" {
+		t.Fatalf("GetTags() = %v, want [enemy solid]", got)
+	}
+
+	// GetTags() and TagSet() return copies, so changing them doesn't change the Shape.
+	got[0] = "changed"
+	set := shape.TagSet()
+	set.Add("flying")
+	set.Remove("solid")
+	if !shape.HasTags("enemy", "solid") || shape.HasTags("flying") {
+		t.Errorf("changing the copies changed the Shape's tags to %v", shape.GetTags())
+	}
+
+	// The tags survive being passed back through the string methods.
+	other := NewRectangle(0, 0, 8, 8)
+	other.AddTags(shape.GetTags()...)
+	if !other.TagSet().Equal(shape.TagSet()) {
+		t.Errorf("tags copied through GetTags() = %v, want %v", other.GetTags(), shape.GetTags())
+	}
+	other.RemoveTags(shape.GetTags()...)
+	if len(other.GetTags()) != 0 {
+		t.Errorf("RemoveTags() left %v", other.GetTags())
+	}
+
+}
+
+func TestGetTagsAliasing(t *testing.T) {
+
+	rect := NewRectangle(0, 0, 8, 8, WithTags("a", "b", "c"))
+	space := NewSpace()
+	space.Add(NewRectangle(0, 0, 8, 8, WithTags("a", "b", "c")), NewCircle(4, 4, 4, WithTags("a", "b", "c")))
+
+	tests := []struct {
+		name  string
+		shape Shape
+	}{
+		{"Rectangle", rect},
+		{"Space", space},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Sorting the tags in reverse, overwriting them, and appending to them (which, with spare capacity, would
+			// write past the end of a shared slice) must leave the Shape's own tags alone.
+			tags := tt.shape.GetTags()
+			sort.Sort(sort.Reverse(sort.StringSlice(tags)))
+			tags[1] = "changed"
+			tags = append(tags[:1], "appended")
+			_ = tags
+
+			unsafeTags := tt.shape.(interface{ GetTagsUnsafe() []string }).GetTagsUnsafe()
+			if got := tt.shape.GetTags(); len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+				t.Errorf("GetTags() after changing an earlier result = %v, want [a b c]", got)
+			}
+			if len(unsafeTags) != 3 || unsafeTags[0] != "a" || unsafeTags[1] != "b" || unsafeTags[2] != "c" {
+				t.Errorf("GetTagsUnsafe() = %v, want [a b c]", unsafeTags)
+			}
+			if !tt.shape.HasTags("a", "b", "c") || tt.shape.HasTags("changed") || tt.shape.HasTags("appended") {
+				t.Errorf("changing the result of GetTags() changed the Shape's tags")
+			}
+
+		})
+	}
+
+	// Other getters return copies too.
+	shapes := space.Shapes()
+	shapes[0] = NewLine(0, 0, 1, 1)
+	if _, isRect := space.Get(0).(*Rectangle); !isRect {
+		t.Error("changing the result of Shapes() changed the Space")
+	}
+	bounds := space.GetBoundingRect()
+	bounds.X, bounds.W = 100, 1
+	if again := space.GetBoundingRect(); again.X != 0 || again.W != 8 {
+		t.Errorf("changing the result of GetBoundingRect() changed the Space's bounds to %v", again)
+	}
+
+	if tags := NewSpace().GetTags(); tags == nil || len(tags) != 0 {
+		t.Errorf("GetTags() of an empty Space = %#v, want an empty slice", tags)
+	}
+
+}
+
+func BenchmarkGetTags(b *testing.B) {
+
+	rect := NewRectangle(0, 0, 8, 8, WithTags("solid", "enemy", "flying"))
+
+	b.Run("GetTags", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = rect.GetTags()
+		}
+	})
+
+	b.Run("GetTagsUnsafe", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = rect.GetTagsUnsafe()
+		}
+	})
+
+}
@@ -0,0 +1,487 @@
+package resolv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type tmxMap struct {
+	TileWidth    int32         `xml:"tilewidth,attr"`
+	TileHeight   int32         `xml:"tileheight,attr"`
+	Infinite     bool          `xml:"infinite,attr"`
+	Layers       []tmxLayer    `xml:"layer"`
+	ObjectGroups []tmxObjGroup `xml:"objectgroup"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type tmxLayer struct {
+	Name       string        `xml:"name,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       struct {
+		Encoding    string `xml:"encoding,attr"`
+		Compression string `xml:"compression,attr"`
+		Content     string `xml:",chardata"`
+	} `xml:"data"`
+}
+
+type tmxObjGroup struct {
+	Name    string      `xml:"name,attr"`
+	Objects []tmxObject `xml:"object"`
+}
+
+type tmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+type tmxObject struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Text       *struct{}     `xml:"text"`
+	Polyline   *tmxPoints    `xml:"polyline"`
+	Polygon    *tmxPoints    `xml:"polygon"`
+}
+
+type tmxOptions struct {
+	mergeTiles      bool
+	collisionLayers map[string]bool
+	newData         func() interface{}
+}
+
+// TMXOption is an option that changes how LoadTMX() reads a TMX file.
+type TMXOption func(*tmxOptions)
+
+// TMXMergeTiles sets whether the solid tiles of tile layers are merged into as few Rectangles as possible, rather than
+// each tile becoming its own Rectangle. This is on by default.
+func TMXMergeTiles(merge bool) TMXOption {
+	return func(o *tmxOptions) {
+		o.mergeTiles = merge
+	}
+}
+
+// TMXCollisionLayers sets the names of the tile layers that LoadTMX() creates Rectangles for, in addition to any tile
+// layers that have a "collision" property set to true.
+func TMXCollisionLayers(names ...string) TMXOption {
+	return func(o *tmxOptions) {
+		for _, name := range names {
+			o.collisionLayers[name] = true
+		}
+	}
+}
+
+// TMXProperties sets a function that LoadTMX() calls to create the Data for each object with custom properties. The
+// function should return a pointer to a struct; the properties are set on the struct's fields that have a matching
+// `tmx:"name"` tag, or that have the same name as a property if they have no tag. Fields can be strings, bools, ints,
+// uints, or floats. By default, the Data of an object with properties is a map[string]string of them.
+func TMXProperties(newData func() interface{}) TMXOption {
+	return func(o *tmxOptions) {
+		o.newData = newData
+	}
+}
+
+// LoadTMX reads a map made with the Tiled editor in the TMX format, and returns a Space containing Shapes for the map's
+// objects and collision tile layers. Rectangle objects (and tile objects) become Rectangles, ellipse objects become
+// Circles (using half of the larger of the ellipse's width and height as the radius), and polyline and polygon objects
+// become Spaces of Lines. The name and type (or class) of each object are added to its Shape as tags, and any custom
+// properties are set as its Data (see TMXProperties()). Objects are also named in the Space by their names (see
+// Space.AddNamed()), with the first object taking each name. Tile layers are only loaded if they have a "collision" property
+// set to true, or are named in TMXCollisionLayers(); every non-empty tile in them is solid.
+// LoadTMX returns an error for malformed files, and for objects and layers it can't turn into Shapes, like point and
+// text objects, rotated objects, infinite maps, and collision tile layers with a negative size (or one too large for
+// their tiles' positions to fit in an int32). Objects whose Shapes fail validation (like ones with a negative size)
+// return an error wrapping ErrInvalidShape.
+func LoadTMX(r io.Reader, opts ...TMXOption) (*Space, error) {
+
+	options := &tmxOptions{mergeTiles: true, collisionLayers: map[string]bool{}}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	m := tmxMap{}
+	if err := xml.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("resolv: can't read TMX map: %w", err)
+	}
+
+	if m.Infinite {
+		return nil, fmt.Errorf("resolv: infinite TMX maps aren't supported")
+	}
+
+	space := NewSpace()
+
+	for _, layer := range m.Layers {
+
+		if !options.collisionLayers[layer.Name] && tmxPropertyValue(layer.Properties, "collision") != "true" {
+			continue
+		}
+
+		if err := layer.validate(m.TileWidth, m.TileHeight); err != nil {
+			return nil, err
+		}
+
+		gids, err := layer.tiles()
+		if err != nil {
+			return nil, err
+		}
+
+		solid := make([][]bool, layer.Height)
+		for y := range solid {
+			solid[y] = make([]bool, layer.Width)
+			for x := range solid[y] {
+				solid[y][x] = gids[y*layer.Width+x] != 0
+			}
+		}
+
+		for _, rect := range gridRectangles(solid, m.TileWidth, m.TileHeight, options.mergeTiles) {
+			rect.AddTags(layer.Name)
+			space.Add(rect)
+		}
+
+	}
+
+	for _, group := range m.ObjectGroups {
+		for _, obj := range group.Objects {
+			shape, err := obj.shape()
+			if err != nil {
+				return nil, err
+			}
+			if err := validateShape(shape); err != nil {
+				return nil, fmt.Errorf("resolv: TMX object %q: %w", obj.Name, err)
+			}
+			if obj.Name != "" {
+				shape.AddTags(obj.Name)
+			}
+			if obj.Type != "" {
+				shape.AddTags(obj.Type)
+			}
+			if obj.Class != "" {
+				shape.AddTags(obj.Class)
+			}
+			if len(obj.Properties) > 0 {
+				data, err := options.data(obj.Properties)
+				if err != nil {
+					return nil, fmt.Errorf("resolv: can't read properties of TMX object %q: %w", obj.Name, err)
+				}
+				shape.SetData(data)
+			}
+			// Objects are named in the Space by their object names, though as names in a Space are unique, only the
+			// first object with each name gets it.
+			if obj.Name == "" || space.AddNamed(obj.Name, shape) != nil {
+				space.Add(shape)
+			}
+		}
+	}
+
+	return space, nil
+
+}
+
+func tmxPropertyValue(properties []tmxProperty, name string) string {
+	for _, p := range properties {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+func tmxRound(v float64) int32 {
+	return int32(math.Round(v))
+}
+
+// validate returns an error if the layer's size is negative, or too large for the positions of its tiles (with the tile
+// size provided) to fit in an int32.
+func (layer *tmxLayer) validate(tileW, tileH int32) error {
+	if layer.Width < 0 || layer.Height < 0 {
+		return fmt.Errorf("resolv: TMX layer %q has a negative size (%d x %d)", layer.Name, layer.Width, layer.Height)
+	}
+	if tileW <= 0 || tileH <= 0 {
+		return fmt.Errorf("resolv: TMX map has a tile size of %d x %d, which should be positive", tileW, tileH)
+	}
+	if int64(layer.Width)*int64(tileW) > math.MaxInt32 || int64(layer.Height)*int64(tileH) > math.MaxInt32 {
+		return fmt.Errorf("resolv: TMX layer %q is too large (%d x %d tiles)", layer.Name, layer.Width, layer.Height)
+	}
+	return nil
+}
+
+// tiles returns the global tile IDs of the layer, decoding them according to the layer's encoding and compression. The
+// layer's size should be validated first (see validate()). The IDs are counted as they're decoded rather than space being
+// made for them up front, so that a layer claiming to be huge can't make it allocate more than its data holds.
+func (layer *tmxLayer) tiles() ([]uint32, error) {
+
+	count := int64(layer.Width) * int64(layer.Height)
+	var gids []uint32
+
+	switch layer.Data.Encoding {
+
+	case "csv":
+		fields := strings.Split(strings.TrimSpace(layer.Data.Content), ",")
+		gids = make([]uint32, 0, len(fields))
+		for _, field := range fields {
+			gid, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("resolv: can't read tile data of TMX layer %q: %w", layer.Name, err)
+			}
+			gids = append(gids, uint32(gid))
+		}
+
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(layer.Data.Content))
+		if err != nil {
+			return nil, fmt.Errorf("resolv: can't read tile data of TMX layer %q: %w", layer.Name, err)
+		}
+
+		var decompressor io.Reader
+		switch layer.Data.Compression {
+		case "":
+		case "zlib":
+			decompressor, err = zlib.NewReader(bytes.NewReader(raw))
+		case "gzip":
+			decompressor, err = gzip.NewReader(bytes.NewReader(raw))
+		default:
+			return nil, fmt.Errorf("resolv: TMX layer %q uses unsupported compression %q", layer.Name, layer.Data.Compression)
+		}
+		if err == nil && decompressor != nil {
+			raw, err = ioutil.ReadAll(decompressor)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resolv: can't read tile data of TMX layer %q: %w", layer.Name, err)
+		}
+
+		gids = make([]uint32, 0, len(raw)/4)
+		for i := 0; i+4 <= len(raw); i += 4 {
+			gids = append(gids, binary.LittleEndian.Uint32(raw[i:]))
+		}
+
+	default:
+		return nil, fmt.Errorf("resolv: TMX layer %q uses unsupported encoding %q", layer.Name, layer.Data.Encoding)
+
+	}
+
+	if int64(len(gids)) != count {
+		return nil, fmt.Errorf("resolv: TMX layer %q has %d tiles, but should have %d", layer.Name, len(gids), count)
+	}
+
+	return gids, nil
+
+}
+
+// shape returns the Shape for the object.
+func (obj *tmxObject) shape() (Shape, error) {
+
+	if obj.Rotation != 0 {
+		return nil, fmt.Errorf("resolv: TMX object %q is rotated, which isn't supported", obj.Name)
+	}
+
+	x, y := tmxRound(obj.X), tmxRound(obj.Y)
+
+	switch {
+
+	case obj.Point != nil:
+		return nil, fmt.Errorf("resolv: TMX object %q is a point, which isn't supported", obj.Name)
+
+	case obj.Text != nil:
+		return nil, fmt.Errorf("resolv: TMX object %q is text, which isn't supported", obj.Name)
+
+	case obj.Ellipse != nil:
+		radius := math.Max(obj.Width, obj.Height) / 2
+		return NewCircle(tmxRound(obj.X+obj.Width/2), tmxRound(obj.Y+obj.Height/2), tmxRound(radius)), nil
+
+	case obj.Polyline != nil, obj.Polygon != nil:
+		points := obj.Polyline
+		if points == nil {
+			points = obj.Polygon
+		}
+
+		coords := [][2]int32{}
+		for _, pair := range strings.Fields(points.Points) {
+			xy := strings.Split(pair, ",")
+			if len(xy) != 2 {
+				return nil, fmt.Errorf("resolv: TMX object %q has malformed point %q", obj.Name, pair)
+			}
+			px, err := strconv.ParseFloat(xy[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("resolv: TMX object %q has malformed point %q: %w", obj.Name, pair, err)
+			}
+			py, err := strconv.ParseFloat(xy[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("resolv: TMX object %q has malformed point %q: %w", obj.Name, pair, err)
+			}
+			coords = append(coords, [2]int32{tmxRound(obj.X + px), tmxRound(obj.Y + py)})
+		}
+
+		if len(coords) < 2 {
+			return nil, fmt.Errorf("resolv: TMX object %q has fewer than two points", obj.Name)
+		}
+
+		if obj.Polygon != nil {
+			coords = append(coords, coords[0])
+		}
+
+		lines := NewSpace()
+		for i := 1; i < len(coords); i++ {
+			lines.Add(NewLine(coords[i-1][0], coords[i-1][1], coords[i][0], coords[i][1]))
+		}
+		return lines, nil
+
+	case obj.GID != 0:
+		// Tile objects are positioned by their bottom-left corner.
+		return NewRectangle(x, tmxRound(obj.Y-obj.Height), tmxRound(obj.Width), tmxRound(obj.Height)), nil
+
+	}
+
+	return NewRectangle(x, y, tmxRound(obj.Width), tmxRound(obj.Height)), nil
+
+}
+
+// data returns the Data for an object with the custom properties provided.
+func (o *tmxOptions) data(properties []tmxProperty) (interface{}, error) {
+
+	if o.newData == nil {
+		data := map[string]string{}
+		for _, p := range properties {
+			data[p.Name] = p.Value
+		}
+		return data, nil
+	}
+
+	data := o.newData()
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("properties must be read into a pointer to a struct, not %T", data)
+	}
+	v = v.Elem()
+
+	for i := 0; i < v.NumField(); i++ {
+
+		field := v.Type().Field(i)
+		name := field.Tag.Get("tmx")
+		if name == "" {
+			name = field.Name
+		}
+
+		found := false
+		value := ""
+		for _, p := range properties {
+			if p.Name == name {
+				found = true
+				value = p.Value
+			}
+		}
+		if !found || field.PkgPath != "" {
+			continue
+		}
+
+		f := v.Field(i)
+		var err error
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(value)
+		case reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(value)
+			f.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var n int64
+			n, err = strconv.ParseInt(value, 10, f.Type().Bits())
+			f.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var n uint64
+			n, err = strconv.ParseUint(value, 10, f.Type().Bits())
+			f.SetUint(n)
+		case reflect.Float32, reflect.Float64:
+			var n float64
+			n, err = strconv.ParseFloat(value, f.Type().Bits())
+			f.SetFloat(n)
+		default:
+			err = fmt.Errorf("field %s has unsupported type %s", field.Name, f.Type())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", name, err)
+		}
+
+	}
+
+	return data, nil
+
+}
+
+// gridRectangles returns Rectangles covering the solid cells of the grid provided, where each cell is tileW by tileH in
+// size. If merge is true, neighboring solid cells are greedily merged into larger Rectangles (first extending each
+// Rectangle to the right, then downwards), so that a wall of tiles becomes a single Rectangle.
+func gridRectangles(solid [][]bool, tileW, tileH int32, merge bool) []*Rectangle {
+
+	rects := []*Rectangle{}
+
+	used := make([][]bool, len(solid))
+	for y := range solid {
+		used[y] = make([]bool, len(solid[y]))
+	}
+
+	free := func(x, y int) bool {
+		return y < len(solid) && x < len(solid[y]) && solid[y][x] && !used[y][x]
+	}
+
+	for y := range solid {
+		for x := range solid[y] {
+
+			if !free(x, y) {
+				continue
+			}
+
+			w, h := 1, 1
+
+			if merge {
+				for free(x+w, y) {
+					w++
+				}
+			grow:
+				for {
+					for i := x; i < x+w; i++ {
+						if !free(i, y+h) {
+							break grow
+						}
+					}
+					h++
+				}
+			}
+
+			for j := y; j < y+h; j++ {
+				for i := x; i < x+w; i++ {
+					used[j][i] = true
+				}
+			}
+
+			rects = append(rects, NewRectangle(int32(x)*tileW, int32(y)*tileH, int32(w)*tileW, int32(h)*tileH))
+
+		}
+	}
+
+	return rects
+
+}
@@ -0,0 +1,72 @@
+package resolv
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func tmxWithLayer(width, height, tileW int, data string) string {
+	return fmt.Sprintf(`<map tilewidth="%d" tileheight="16">
+ <layer name="walls" width="%d" height="%d">
+  <properties><property name="collision" value="true"/></properties>
+  <data encoding="csv">%s</data>
+ </layer>
+</map>`, tileW, width, height, data)
+}
+
+func TestLoadTMXLayerSize(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		xml        string
+		wantShapes int
+		wantErr    string
+	}{
+		{"valid", tmxWithLayer(3, 2, 16, "1,0,1,1,1,0"), 3, ""},
+		{"negative width", tmxWithLayer(-3, 2, 16, "1,0,1,1,1,0"), 0, "negative size"},
+		{"negative height", tmxWithLayer(3, -2, 16, "1,0,1,1,1,0"), 0, "negative size"},
+		{"both negative", tmxWithLayer(-3, -2, 16, "1,0,1,1,1,0"), 0, "negative size"},
+		{"huge", tmxWithLayer(1<<30, 1<<30, 16, "1"), 0, "too large"},
+		{"wider than int32 in pixels", tmxWithLayer(1<<28, 1, 16, "1"), 0, "too large"},
+		{"zero tile size", tmxWithLayer(3, 2, 0, "1,0,1,1,1,0"), 0, "tile size"},
+		{"too few tiles", tmxWithLayer(3, 2, 16, "1,0,1"), 0, "has 3 tiles, but should have 6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp, err := LoadTMX(strings.NewReader(tt.xml))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadTMX() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTMX() = %v", err)
+			}
+			if sp.Length() != tt.wantShapes {
+				t.Errorf("LoadTMX() made %d shapes, want %d", sp.Length(), tt.wantShapes)
+			}
+		})
+	}
+
+}
+
+func BenchmarkLoadTMX(b *testing.B) {
+
+	tiles := make([]string, 100*100)
+	for i := range tiles {
+		tiles[i] = fmt.Sprint(i % 3 / 2)
+	}
+	xml := tmxWithLayer(100, 100, 16, strings.Join(tiles, ","))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadTMX(strings.NewReader(xml)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+}
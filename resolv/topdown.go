@@ -0,0 +1,62 @@
+package resolv
+
+import "math"
+
+// TopDownBody moves a Shape around a Space like the player of a top-down game: it moves in the direction of the input at
+// a set speed, diagonals included, with movement resolved against the other Shapes in the Space one axis at a time, so
+// that moving diagonally into a wall slides along it. Which Shapes it collides with follows their collision layers and
+// masks, and elevation (see LayersCollide()), like Space.Resolve().
+// Speeds are in pixels per frame, and can be fractions of a pixel; like with PlatformerBody, the fractions are carried
+// over between frames, so slow movement isn't lost to the Shape's whole-pixel position.
+type TopDownBody struct {
+	Shape Shape
+	Space *Space
+
+	remX, remY float64
+}
+
+// NewTopDownBody returns a new TopDownBody moving the Shape provided around the Space provided. The Shape should be in the
+// Space, so that the body doesn't collide with its own Shape.
+func NewTopDownBody(space *Space, shape Shape) *TopDownBody {
+	return &TopDownBody{Shape: shape, Space: space}
+}
+
+// Update moves the body for a frame, in the direction of the input provided (each from -1 to 1) at the speed provided.
+// Input longer than 1 (like both axes at 1 for a diagonal) is normalized, so that moving diagonally is no faster than
+// moving along an axis; shorter input (like from an analog stick pushed part of the way) moves the body more slowly.
+func (t *TopDownBody) Update(inputX, inputY float64, speed float64) {
+
+	if length := math.Hypot(inputX, inputY); length > 1 {
+		inputX /= length
+		inputY /= length
+	}
+
+	if dx := carry(&t.remX, inputX*speed); dx != 0 {
+		col := resolveDelta(t.Space, t.Shape, dx, 0)
+		t.Shape.Move(col.ResolveX, 0)
+		if col.Colliding() {
+			t.remX = 0
+		}
+	}
+
+	if dy := carry(&t.remY, inputY*speed); dy != 0 {
+		col := resolveDelta(t.Space, t.Shape, 0, dy)
+		t.Shape.Move(0, col.ResolveY)
+		if col.Colliding() {
+			t.remY = 0
+		}
+	}
+
+}
+
+// Position returns the position of the body's Shape, including the fractions of a pixel it's moved by that haven't been
+// applied to the Shape yet, for drawing the Shape smoothly.
+func (t *TopDownBody) Position() (float64, float64) {
+	x, y := t.Shape.GetXY()
+	return float64(x) + t.remX, float64(y) + t.remY
+}
+
+// Remainder returns the fractions of a pixel the body has moved by that haven't been applied to its Shape yet.
+func (t *TopDownBody) Remainder() (float64, float64) {
+	return t.remX, t.remY
+}
@@ -0,0 +1,131 @@
+package resolv
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestTopDownSpeed(t *testing.T) {
+
+	const frames, speed = 100, 1.5
+
+	tests := []struct {
+		inputX, inputY float64
+		wantDistance   float64
+	}{
+		{1, 0, frames * speed},
+		{0, -1, frames * speed},
+		{1, 1, frames * speed},
+		{-1, 1, frames * speed},
+		{-1, -1, frames * speed},
+		{0.6, 0.8, frames * speed},
+		{0.3, 0.4, frames * speed / 2},
+		{0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.inputX, ",", tt.inputY), func(t *testing.T) {
+
+			space := NewSpace()
+			player := NewRectangle(0, 0, 8, 8)
+			space.Add(player)
+			body := NewTopDownBody(space, player)
+
+			for frame := 0; frame < frames; frame++ {
+				body.Update(tt.inputX, tt.inputY, speed)
+			}
+
+			x, y := body.Position()
+			if distance := math.Hypot(x, y); math.Abs(distance-tt.wantDistance) > 1e-6 {
+				t.Errorf("moved %v pixels in %d frames, want %v", distance, frames, tt.wantDistance)
+			}
+			if angle, want := math.Atan2(y, x), math.Atan2(tt.inputY, tt.inputX); tt.wantDistance > 0 &&
+				math.Abs(angle-want) > 1e-6 {
+				t.Errorf("moved at an angle of %v, want %v", angle, want)
+			}
+			if px, py := player.GetXY(); float64(px) != math.Trunc(x) || float64(py) != math.Trunc(y) {
+				t.Errorf("the Shape is at %d, %d, want the whole pixels of Position() = %v, %v", px, py, x, y)
+			}
+
+		})
+	}
+
+}
+
+func TestTopDownSubPixel(t *testing.T) {
+
+	space := NewSpace()
+	player := NewRectangle(0, 0, 8, 8)
+	space.Add(player)
+	body := NewTopDownBody(space, player)
+
+	// A quarter of a pixel per frame moves the Shape a pixel every fourth frame, rather than never.
+	for frame := 1; frame <= 12; frame++ {
+		body.Update(1, 0, 0.25)
+		if want := int32(frame / 4); player.X != want {
+			t.Fatalf("frame %d: the Shape is at x = %d, want %d", frame, player.X, want)
+		}
+		if remX, _ := body.Remainder(); math.Abs(remX-float64(frame%4)*0.25) > 1e-9 {
+			t.Fatalf("frame %d: Remainder() = %v, want %v", frame, remX, float64(frame%4)*0.25)
+		}
+	}
+
+}
+
+func TestTopDownSlide(t *testing.T) {
+
+	// A wall running down along x = 64 from y = 0 to 64, with another wall across its bottom, making an inner corner.
+	space := NewSpace()
+	space.Add(NewRectangle(64, 0, 16, 80), NewRectangle(0, 64, 80, 16))
+	player := NewRectangle(40, 0, 8, 8)
+	space.Add(player)
+	body := NewTopDownBody(space, player)
+
+	// Moving diagonally into the wall slides steadily down along it, never bouncing off it, until it reaches the corner.
+	touched := false
+	lastY := player.Y
+	for frame := 0; frame < 60; frame++ {
+		body.Update(1, 1, 2)
+		if player.X+player.W > 64 {
+			t.Fatalf("frame %d: the body moved into the wall, to %v", frame, player)
+		}
+		if touched && player.X+player.W != 64 {
+			t.Fatalf("frame %d: the body moved away from the wall it's sliding along, to %v", frame, player)
+		}
+		touched = touched || player.X+player.W == 64
+		if player.Y < lastY || player.Y-lastY > 2 {
+			t.Fatalf("frame %d: the body moved from y = %d to %d while sliding", frame, lastY, player.Y)
+		}
+		lastY = player.Y
+	}
+	if !touched {
+		t.Fatal("the body never reached the wall")
+	}
+
+	// Once in the corner, the body stays put rather than jittering between the walls.
+	for frame := 0; frame < 20; frame++ {
+		body.Update(1, 1, 2)
+		if player.X != 56 || player.Y != 56 {
+			t.Fatalf("frame %d: the body moved to %v in the corner, want it to stay at 56, 56", frame, player)
+		}
+	}
+
+}
+
+func BenchmarkTopDownUpdate(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 256; i++ {
+		space.Add(NewRectangle(i%16*32, i/16*32, 16, 16))
+	}
+	player := NewRectangle(16, 16, 8, 8)
+	space.Add(player)
+	body := NewTopDownBody(space, player)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		body.Update(math.Cos(float64(i)/50), math.Sin(float64(i)/50), 1.5)
+	}
+
+}
@@ -0,0 +1,137 @@
+package resolv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceHook is the function set with SetTraceHook(), or nil if tracing is off.
+var traceHook func(region string, d time.Duration)
+
+// SetTraceHook sets a function to be called with how long each traced region of the collision functions took, to see
+// where the time goes without attaching a profiler; pass nil to turn tracing off (the default). With tracing off, each
+// region costs just a nil check. See TraceSummary for a hook that totals the regions up. The regions are:
+//
+//	Space.Resolve            a whole call to Space.Resolve()
+//	Space.Resolve/broad      picking the Shapes that Space.Resolve() tests (see KeepSortedX())
+//	Resolve/narrow           testing whether a Shape would be colliding at its destination, or where it started, before
+//	                         resolving a movement, in Space.Resolve() and Resolve()
+//	Resolve/step             stepping along a movement to find where the Shape stops, in Space.Resolve() and Resolve()
+//	Space.IsColliding        a whole call to IsColliding(), WouldBeColliding(), or GetCollidingShapes() (and their
+//	                         variants) on a Space
+//	Space.IsColliding/broad  picking the Shapes that those functions test (see KeepSortedX())
+//	Space.GetCollidingPairs/broad  finding the pairs of Shapes that GetCollidingPairs() tests (see WithSpatialHash())
+//
+// The hook is called from whichever goroutine runs the collision functions, so it has to be safe to call concurrently if
+// they're run from multiple goroutines. Like RegisterLayer(), SetTraceHook() mustn't be called while collision functions
+// are running.
+func SetTraceHook(hook func(region string, d time.Duration)) {
+	traceHook = hook
+}
+
+// traceStart returns the time a traced region started at, or the zero time if tracing is off.
+func traceStart() time.Time {
+	if traceHook != nil {
+		return time.Now()
+	}
+	return time.Time{}
+}
+
+// traceEnd reports how long the region that started at the time provided took, if tracing is on (and was when it
+// started).
+func traceEnd(region string, start time.Time) {
+	if traceHook != nil && !start.IsZero() {
+		traceHook(region, time.Since(start))
+	}
+}
+
+// TraceRegion holds the totals for a traced region (see SetTraceHook()): how many times it ran, and how long it took in
+// total and at most.
+type TraceRegion struct {
+	Count int64
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Average returns how long the region took on average, or 0 if it never ran.
+func (r TraceRegion) Average() time.Duration {
+	if r.Count == 0 {
+		return 0
+	}
+	return r.Total / time.Duration(r.Count)
+}
+
+// TraceSummary totals up the traced regions of the collision functions, for seeing where the time goes: pass its Hook()
+// function to SetTraceHook(), and read the totals back with Regions() or String(). It's safe to use from multiple
+// goroutines.
+type TraceSummary struct {
+	mutex   sync.Mutex
+	regions map[string]*TraceRegion
+}
+
+// NewTraceSummary returns a new, empty TraceSummary.
+func NewTraceSummary() *TraceSummary {
+	return &TraceSummary{regions: map[string]*TraceRegion{}}
+}
+
+// Hook adds the time the region took to the TraceSummary's totals; it's the function to pass to SetTraceHook().
+func (t *TraceSummary) Hook(region string, d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	r := t.regions[region]
+	if r == nil {
+		r = &TraceRegion{}
+		t.regions[region] = r
+	}
+	r.Count++
+	r.Total += d
+	if d > r.Max {
+		r.Max = d
+	}
+}
+
+// Regions returns a copy of the totals for each region traced so far, by name.
+func (t *TraceSummary) Regions() map[string]TraceRegion {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	regions := make(map[string]TraceRegion, len(t.regions))
+	for name, r := range t.regions {
+		regions[name] = *r
+	}
+	return regions
+}
+
+// Reset clears the TraceSummary's totals, like for starting a new frame.
+func (t *TraceSummary) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.regions = map[string]*TraceRegion{}
+}
+
+// String returns a table of the totals for each region traced so far, sorted by name.
+func (t *TraceSummary) String() string {
+
+	regions := t.Regions()
+
+	names := make([]string, 0, len(regions))
+	width := len("region")
+	for name := range regions {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "%-*s %10s %12s %12s %12s\n", width, "region", "count", "total", "average", "max")
+	for _, name := range names {
+		r := regions[name]
+		fmt.Fprintf(&sb, "%-*s %10d %12v %12v %12v\n", width, name, r.Count, r.Total, r.Average(), r.Max)
+	}
+	return sb.String()
+
+}
@@ -0,0 +1,73 @@
+package resolv
+
+// CollisionTracker keeps track of which Shapes within a Space are colliding from one frame to the next, allowing you to
+// respond to Shapes starting to overlap, continuing to overlap, and separating (like you would with trigger volumes).
+// Call Update() once per frame; it compares the colliding pairs within the Space against the ones found on the previous
+// Update() call and calls OnEnter, OnStay, and OnExit accordingly. Any of the callbacks can be left nil.
+// A pair is identified by its two Shapes regardless of their order, so removing and re-adding a Shape to the Space
+// doesn't count as a new collision. Shapes removed from the Space while colliding have OnExit called for them on the
+// next Update().
+type CollisionTracker struct {
+	Space   *Space
+	OnEnter func(a, b Shape)
+	OnStay  func(a, b Shape)
+	OnExit  func(a, b Shape)
+
+	previous []CollisionPair
+}
+
+// NewCollisionTracker returns a pointer to a new CollisionTracker watching the Space provided.
+func NewCollisionTracker(space *Space) *CollisionTracker {
+	return &CollisionTracker{Space: space}
+}
+
+type pairKey struct {
+	a, b Shape
+}
+
+func (ct *CollisionTracker) has(pairs map[pairKey]bool, pair CollisionPair) bool {
+	return pairs[pairKey{pair.ShapeA, pair.ShapeB}] || pairs[pairKey{pair.ShapeB, pair.ShapeA}]
+}
+
+// Update checks the Space for colliding pairs, calling OnEnter for pairs that weren't colliding on the previous Update,
+// OnStay for pairs that were and still are, and OnExit for pairs that were colliding but no longer are. Exits are reported
+// first, followed by enters and stays in the order the pairs are found within the Space.
+func (ct *CollisionTracker) Update() {
+
+	current := ct.Space.GetCollidingPairs()
+
+	currentSet := make(map[pairKey]bool, len(current))
+	for _, pair := range current {
+		currentSet[pairKey{pair.ShapeA, pair.ShapeB}] = true
+	}
+
+	previousSet := make(map[pairKey]bool, len(ct.previous))
+	for _, pair := range ct.previous {
+		previousSet[pairKey{pair.ShapeA, pair.ShapeB}] = true
+	}
+
+	for _, pair := range ct.previous {
+		if !ct.has(currentSet, pair) && ct.OnExit != nil {
+			ct.OnExit(pair.ShapeA, pair.ShapeB)
+		}
+	}
+
+	for _, pair := range current {
+		if ct.has(previousSet, pair) {
+			if ct.OnStay != nil {
+				ct.OnStay(pair.ShapeA, pair.ShapeB)
+			}
+		} else if ct.OnEnter != nil {
+			ct.OnEnter(pair.ShapeA, pair.ShapeB)
+		}
+	}
+
+	ct.previous = current
+
+}
+
+// Reset forgets the colliding pairs found on the previous Update() call without calling OnExit for them, so the next
+// Update() reports every colliding pair as newly entered.
+func (ct *CollisionTracker) Reset() {
+	ct.previous = nil
+}
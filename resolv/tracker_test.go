@@ -0,0 +1,138 @@
+package resolv
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// trackerLog records the calls a CollisionTracker makes, as "enter", "stay", or "exit" followed by the names of the two
+// Shapes, in the order the tracker gave them.
+type trackerLog struct {
+	names map[Shape]string
+	calls []string
+}
+
+func (l *trackerLog) track(space *Space) *CollisionTracker {
+	record := func(kind string) func(a, b Shape) {
+		return func(a, b Shape) {
+			l.calls = append(l.calls, fmt.Sprintf("%s %s %s", kind, l.names[a], l.names[b]))
+		}
+	}
+	ct := NewCollisionTracker(space)
+	ct.OnEnter, ct.OnStay, ct.OnExit = record("enter"), record("stay"), record("exit")
+	return ct
+}
+
+// next returns the calls recorded since it was last called.
+func (l *trackerLog) next() []string {
+	calls := l.calls
+	l.calls = nil
+	return calls
+}
+
+func TestCollisionTrackerUpdate(t *testing.T) {
+
+	player, trigger := NewRectangle(0, 0, 8, 8), NewRectangle(20, 0, 8, 8)
+	space := NewSpace()
+	space.Add(player, trigger)
+	log := &trackerLog{names: map[Shape]string{player: "player", trigger: "trigger"}}
+	ct := log.track(space)
+
+	steps := []struct {
+		name  string
+		step  func()
+		calls []string
+	}{
+		{"apart", func() {}, nil},
+		{"enter", func() { player.SetXY(16, 0) }, []string{"enter player trigger"}},
+		{"stay", func() {}, []string{"stay player trigger"}},
+		{"stay while moving", func() { player.Move(2, 0) }, []string{"stay player trigger"}},
+
+		// Re-adding the player puts it after the trigger, so the pair is found the other way around, but it's still the
+		// same pair.
+		{"pair order swapped", func() {
+			space.Remove(player)
+			space.Add(player)
+		}, []string{"stay trigger player"}},
+
+		{"exit", func() { player.SetXY(-40, 0) }, []string{"exit trigger player"}},
+		{"apart again", func() {}, nil},
+		{"enter again", func() { player.SetXY(22, 0) }, []string{"enter trigger player"}},
+	}
+
+	for _, step := range steps {
+		step.step()
+		ct.Update()
+		if calls := log.next(); !reflect.DeepEqual(calls, step.calls) {
+			t.Fatalf("%s: Update() made calls %q, want %q", step.name, calls, step.calls)
+		}
+	}
+
+}
+
+func TestCollisionTrackerRemoved(t *testing.T) {
+
+	player, coin, wall := NewRectangle(0, 0, 8, 8), NewRectangle(4, 0, 8, 8), NewRectangle(4, 4, 8, 8)
+	space := NewSpace()
+	space.Add(player, coin, wall)
+	log := &trackerLog{names: map[Shape]string{player: "player", coin: "coin", wall: "wall"}}
+	ct := log.track(space)
+
+	ct.Update()
+	if calls := log.next(); len(calls) != 3 {
+		t.Fatalf("Update() made calls %q, want 3 enters", calls)
+	}
+
+	// Removing a Shape while it's overlapping others exits each of its pairs on the next Update(), even though it's no
+	// longer in the Space, and the pairs without it stay.
+	space.Remove(coin)
+	ct.Update()
+	want := []string{"exit player coin", "exit coin wall", "stay player wall"}
+	if calls := log.next(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("after removing the coin, Update() made calls %q, want %q", calls, want)
+	}
+
+	ct.Update()
+	if calls := log.next(); !reflect.DeepEqual(calls, []string{"stay player wall"}) {
+		t.Errorf("Update() after that made calls %q, want the coin forgotten", calls)
+	}
+
+	// Reset() forgets the pairs without exiting them.
+	ct.Reset()
+	ct.Update()
+	if calls := log.next(); !reflect.DeepEqual(calls, []string{"enter player wall"}) {
+		t.Errorf("Update() after Reset() made calls %q", calls)
+	}
+
+}
+
+func TestCollisionTrackerNilCallbacks(t *testing.T) {
+
+	// Any of the callbacks can be left nil, which Update() skips rather than panicking.
+	space := NewSpace()
+	space.Add(NewRectangle(0, 0, 8, 8), NewRectangle(4, 4, 8, 8))
+	ct := NewCollisionTracker(space)
+	ct.Update()
+	space.Clear()
+	ct.Update()
+
+}
+
+func BenchmarkCollisionTrackerUpdate(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 200; i++ {
+		space.Add(NewRectangle(i%20*12, i/20*12, 16, 16))
+	}
+	ct := NewCollisionTracker(space)
+	ct.OnEnter = func(a, b Shape) {}
+	ct.OnStay = func(a, b Shape) {}
+	ct.OnExit = func(a, b Shape) {}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ct.Update()
+	}
+
+}
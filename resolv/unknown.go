@@ -0,0 +1,71 @@
+package resolv
+
+import "fmt"
+
+// UnsupportedShapePairError is what's panicked with in strict mode (see SetStrictShapes()) when two Shapes that resolv
+// doesn't know how to test for collision against each other are tested. It wraps ErrUnsupportedShapePair, so a recovered
+// value can be checked with errors.Is() or errors.As() once asserted to be an error.
+type UnsupportedShapePairError struct {
+	A, B Shape
+}
+
+func (e UnsupportedShapePairError) Error() string {
+	return fmt.Sprintf("resolv: collision between %T and %T isn't supported", e.A, e.B)
+}
+
+func (e UnsupportedShapePairError) Unwrap() error {
+	return ErrUnsupportedShapePair
+}
+
+var (
+	unknownShapeHandler  = func(a, b Shape) {}
+	strictShapes         bool
+	boundingRectFallback bool
+)
+
+// SetUnknownShapeHandler sets the function that's called when one of the built-in Shapes (a Circle or Line, or any of
+// them through ShapesColliding()) is tested for collision against a Shape it doesn't know how to handle, like a custom
+// Shape implementation without a function registered for the pair (see RegisterCollisionFuncFor()). Rectangles ask such
+// Shapes to test themselves against them instead (see Rectangle.IsColliding()). The function is called with the
+// built-in Shape first. By default, nothing is done, and the Shapes aren't colliding (but see SetBoundingRectFallback()).
+// Passing nil restores the default. Like RegisterLayer(), this is meant to be set up once, before collision testing
+// starts, rather than changed while Shapes are being tested from other goroutines.
+func SetUnknownShapeHandler(handler func(a, b Shape)) {
+	if handler == nil {
+		handler = func(a, b Shape) {}
+	}
+	unknownShapeHandler = handler
+}
+
+// SetStrictShapes sets whether testing a built-in Shape against a Shape it doesn't know how to handle panics with an
+// UnsupportedShapePairError (after calling the handler set with SetUnknownShapeHandler()). This is useful for catching
+// unsupported Shape pairs during development. Strict mode is off by default.
+func SetStrictShapes(strict bool) {
+	strictShapes = strict
+}
+
+// SetBoundingRectFallback sets whether a built-in Shape tested against a Shape it doesn't know how to handle falls back to
+// testing their bounding rectangles (see Shape) against each other, so that custom Shapes still collide
+// roughly as expected. It's off by default, in which case such Shapes never collide.
+func SetBoundingRectFallback(fallback bool) {
+	boundingRectFallback = fallback
+}
+
+// unknownShapePair handles the built-in Shape a being tested for collision against the other Shape b, which it doesn't
+// know how to handle as there's no function registered for the pair (see RegisterCollisionFuncFor()), returning whether
+// they should be considered to be colliding.
+func unknownShapePair(a, b Shape) bool {
+
+	unknownShapeHandler(a, b)
+
+	if strictShapes {
+		panic(UnsupportedShapePairError{a, b})
+	}
+
+	if boundingRectFallback {
+		return shapeBoundingRect(a).IsColliding(shapeBoundingRect(b))
+	}
+
+	return false
+
+}
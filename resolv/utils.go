@@ -1,38 +1,109 @@
 package resolv
 
-import "math"
+import (
+	"math"
+	"reflect"
+)
 
 // Resolve attempts to move the checking Shape with the specified X and Y values, returning a Collision object
 // if it collides with the specified other Shape. The deltaX and deltaY arguments are the movement displacement
 // in pixels. For platformers in particular, you would probably want to resolve on the X and Y axes separately.
+// As Rectangles are half-open (see Rectangle), a Rectangle moved by the resolved values ends up directly abutting the
+// Rectangle it collided with, rather than overlapping it by a pixel. The whole path of the movement is checked, so the
+// checking Shape stops at the other Shape even if it's thin enough for the destination to be past it (see
+// Collision.Teleporting).
 func Resolve(firstShape Shape, other Shape, deltaX, deltaY int32) Collision {
+	out, _ := resolve(firstShape, other, deltaX, deltaY)
+	return out
+}
+
+// resolve performs Resolve(), also returning how many steps it took to resolve the movement.
+func resolve(firstShape Shape, other Shape, deltaX, deltaY int32) (Collision, int) {
 
 	out := Collision{}
+	steps := 0
 	out.ResolveX = deltaX
 	out.ResolveY = deltaY
 	out.ShapeA = firstShape
 
 	if deltaX == 0 && deltaY == 0 {
-		return out
+		return out, steps
 	}
 
-	x := float32(deltaX)
-	y := float32(deltaY)
+	x := float64(deltaX)
+	y := float64(deltaY)
 
 	primeX := true
-	slope := float32(0)
+	slope := 0.0
 
-	if math.Abs(float64(deltaY)) > math.Abs(float64(deltaX)) {
+	if absInt64(int64(deltaY)) > absInt64(int64(deltaX)) {
 		primeX = false
-		if deltaY != 0 && deltaX != 0 {
-			slope = float32(deltaX) / float32(deltaY)
+		if deltaX != 0 {
+			slope = float64(deltaX) / float64(deltaY)
 		}
 	} else if deltaY != 0 && deltaX != 0 {
-		slope = float32(deltaY) / float32(deltaX)
+		slope = float64(deltaY) / float64(deltaX)
+	}
+
+	narrow := traceStart()
+
+	steps++
+	destinationColliding := firstShape.WouldBeColliding(other, deltaX, deltaY)
+
+	if !destinationColliding && !sweptBoundsOverlap(firstShape, other, deltaX, deltaY) {
+		traceEnd("Resolve/narrow", narrow)
+		return out, steps
 	}
 
+	steps++
+	startFree := !firstShape.WouldBeColliding(other, 0, 0)
+
+	traceEnd("Resolve/narrow", narrow)
+	defer traceEnd("Resolve/step", traceStart())
+
+	if startFree {
+
+		// Step along the path from the Shape's current position, one pixel at a time along the longer axis, so that the
+		// Shape stops at the first Shape in its way, even one thinner than the movement that the destination is past.
+		n := absInt64(int64(deltaX))
+		if d := absInt64(int64(deltaY)); d > n {
+			n = d
+		}
+
+		for k := int64(1); k <= n; k++ {
+
+			if k < n {
+				steps++
+				if !firstShape.WouldBeColliding(other, stepAlong(deltaX, k, n), stepAlong(deltaY, k, n)) {
+					continue
+				}
+			} else if !destinationColliding {
+				break
+			}
+
+			out.ResolveX = stepAlong(deltaX, k-1, n)
+			out.ResolveY = stepAlong(deltaY, k-1, n)
+			out.ShapeB = other
+			out.Teleporting = !destinationColliding
+			return out, steps
+
+		}
+
+		return out, steps
+
+	}
+
+	if !destinationColliding {
+		// The Shape is already colliding, but moving frees it.
+		return out, steps
+	}
+
+	// The Shape is already colliding, so step back from the destination until it's free, even if that's behind where it
+	// started.
 	for true {
 
+		steps++
+
 		if firstShape.WouldBeColliding(other, out.ResolveX, out.ResolveY) {
 
 			if primeX {
@@ -75,20 +146,158 @@ func Resolve(firstShape Shape, other Shape, deltaX, deltaY int32) Collision {
 
 	}
 
-	if math.Abs(float64(deltaX-out.ResolveX)) > math.Abs(float64(deltaX)*1.5) || math.Abs(float64(deltaY-out.ResolveY)) > math.Abs(float64(deltaY)*1.5) {
+	// Pushed back by more than 1.5 times the delta, compared as twice the distance against three times the delta.
+	pushedX := absInt64(int64(deltaX) - int64(out.ResolveX))
+	pushedY := absInt64(int64(deltaY) - int64(out.ResolveY))
+	if pushedX*2 > absInt64(int64(deltaX))*3 || pushedY*2 > absInt64(int64(deltaY))*3 {
 		out.Teleporting = true
 	}
 
-	return out
+	return out, steps
+
+}
+
+// stepAlong returns how far along the delta provided the Shape is after k of n steps, truncated toward zero.
+func stepAlong(delta int32, k, n int64) int32 {
+	return int32(int64(delta) * k / n)
+}
 
+// sweptBoundsOverlap returns whether the bounding rectangle of the area the Shape sweeps through when moving by the delta
+// provided overlaps the bounding rectangle of the other Shape; if it doesn't, the Shape can't collide with it on the way.
+func sweptBoundsOverlap(shape, other Shape, dx, dy int32) bool {
+	var swept, bounds Rectangle
+	boundingRectInto(shape, &swept)
+	boundingRectInto(other, &bounds)
+	if dx < 0 {
+		swept.X += dx
+		swept.W -= dx
+	} else {
+		swept.W += dx
+	}
+	if dy < 0 {
+		swept.Y += dy
+		swept.H -= dy
+	} else {
+		swept.H += dy
+	}
+	return boundsOverlap(&swept, &bounds)
 }
 
-// Distance returns the distance from one pair of X and Y values to another.
+// Distance returns the distance from one pair of X and Y values to another, truncated to a whole number. The distance is
+// computed without overflowing, even for coordinates at the extremes of int32; distances too large for an int32 return
+// math.MaxInt32. See Distance64() for the exact distance.
 func Distance(x, y, x2, y2 int32) int32 {
 
-	dx := x - x2
-	dy := y - y2
-	ds := (dx * dx) + (dy * dy)
-	return int32(math.Sqrt(float64(ds)))
+	d := Distance64(x, y, x2, y2)
+	if d > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(d)
+
+}
 
+// Distance64 returns the distance from one pair of X and Y values to another as a float64, without truncating it.
+func Distance64(x, y, x2, y2 int32) float64 {
+	return math.Hypot(float64(x)-float64(x2), float64(y)-float64(y2))
+}
+
+// maxExactRadius is the largest radius for which withinRadius() can compare squared distances exactly in int64.
+const maxExactRadius = 3037000499 // floor(sqrt(math.MaxInt64 / 2))
+
+// withinRadius returns whether the two points are no further apart than the radius provided. The squared distance is
+// compared in int64, so there's no rounding from a square root, nor overflow with coordinates far from the origin.
+func withinRadius(x, y, x2, y2 int32, radius int64) bool {
+
+	dx := int64(x) - int64(x2)
+	dy := int64(y) - int64(y2)
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+
+	if dx > radius || dy > radius {
+		return false
+	}
+
+	if radius > maxExactRadius {
+		// The squares could overflow, but radii this large are far beyond anything where precision matters.
+		return math.Hypot(float64(dx), float64(dy)) <= float64(radius)
+	}
+
+	return dx*dx+dy*dy <= radius*radius
+
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// abs32 returns the absolute value of v; like the int32 subtraction it's usually used on, it wraps around for
+// math.MinInt32.
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// shapeCenter returns the center point of the provided Shape if it has one, or its position otherwise.
+func shapeCenter(shape Shape) (int32, int32) {
+	if c, ok := shape.(interface{ Center() (int32, int32) }); ok {
+		return c.Center()
+	}
+	return shape.GetXY()
+}
+
+// closestPoint returns the point on (or within) the Shape provided that's closest to the point x, y, which is x, y itself
+// if the Shape contains it. Shapes other than Rectangles, Circles, and Lines (like nested Spaces) are treated as their
+// bounding rectangles.
+func closestPoint(shape Shape, x, y int32) (float64, float64) {
+
+	px, py := float64(x), float64(y)
+
+	switch s := shape.(type) {
+
+	case *Circle:
+		dx, dy := px-float64(s.X), py-float64(s.Y)
+		d := math.Hypot(dx, dy)
+		if radius := float64(s.Radius); d > radius {
+			return float64(s.X) + dx/d*radius, float64(s.Y) + dy/d*radius
+		}
+		return px, py
+
+	case *Line:
+		ax, ay := float64(s.X), float64(s.Y)
+		dx, dy := float64(s.X2)-ax, float64(s.Y2)-ay
+		t := 0.0
+		if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+			t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lengthSquared))
+		}
+		return ax + t*dx, ay + t*dy
+
+	}
+
+	var r Rectangle
+	boundingRectInto(shape, &r)
+	// The Rectangle is half-open (see Rectangle), so the closest point is clamped to its last column and row.
+	right, bottom := float64(r.X)+float64(r.W)-1, float64(r.Y)+float64(r.H)-1
+	return math.Max(float64(r.X), math.Min(math.Max(right, float64(r.X)), px)),
+		math.Max(float64(r.Y), math.Min(math.Max(bottom, float64(r.Y)), py))
+
+}
+
+// dataEqual returns whether the two Data values provided are equal, without panicking for values that can't be compared.
+func dataEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+	return a == b
 }
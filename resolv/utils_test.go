@@ -0,0 +1,115 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceLargeCoordinates(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		x, y, x2, y2 int32
+		want         int32
+		want64       float64
+	}{
+		// dx*dx alone is 9e10 here, which overflowed int32 when it was worked out in it.
+		{"big scrolling world", 0, 0, 300000, 400000, 500000, 500000},
+		{"far from the origin", 2000000000, 2000000000, 2000000003, 2000000004, 5, 5},
+		{"near the negative extreme", math.MinInt32, math.MinInt32, math.MinInt32 + 30, math.MinInt32 + 40, 50, 50},
+		{"across the whole range", math.MinInt32, 0, math.MaxInt32, 0, math.MaxInt32, math.MaxUint32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distance(tt.x, tt.y, tt.x2, tt.y2); got != tt.want {
+				t.Errorf("Distance() = %d, want %d", got, tt.want)
+			}
+			if got := Distance64(tt.x, tt.y, tt.x2, tt.y2); got != tt.want64 {
+				t.Errorf("Distance64() = %f, want %f", got, tt.want64)
+			}
+		})
+	}
+
+}
+
+func TestCirclesNearInt32Extremes(t *testing.T) {
+
+	tests := []struct {
+		name string
+		a, b *Circle
+		want bool
+	}{
+		{"touching near the maximum", NewCircle(math.MaxInt32-10, math.MaxInt32-10, 5),
+			NewCircle(math.MaxInt32-2, math.MaxInt32-10, 3), true},
+		{"apart near the maximum", NewCircle(math.MaxInt32-10, math.MaxInt32-10, 5),
+			NewCircle(math.MaxInt32-1, math.MaxInt32-10, 3), false},
+		{"touching near the minimum", NewCircle(math.MinInt32+10, math.MinInt32, 6),
+			NewCircle(math.MinInt32+10, math.MinInt32+10, 4), true},
+		{"at opposite extremes", NewCircle(math.MinInt32, math.MinInt32, 1000),
+			NewCircle(math.MaxInt32, math.MaxInt32, 1000), false},
+		{"radii spanning the range", NewCircle(math.MinInt32+1, 0, math.MaxInt32),
+			NewCircle(math.MaxInt32, 0, math.MaxInt32), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.IsColliding(tt.b); got != tt.want {
+				t.Errorf("IsColliding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+}
+
+func TestResolveTeleporting(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		wall            *Rectangle
+		dx, dy          int32
+		wantX, wantY    int32
+		wantTeleporting bool
+	}{
+		// The wall is 2 pixels wide and the Shape moves 50, so its destination is clear past the wall.
+		{"through a thin wall", NewRectangle(30, 0, 2, 8), 50, 0, 22, 0, true},
+		{"back through a thin wall", NewRectangle(-24, 0, 2, 8), -50, 0, -22, 0, true},
+		{"down through a thin floor", NewRectangle(0, 30, 8, 2), 0, 50, 0, 22, true},
+		{"diagonally through a thin wall", NewRectangle(30, -100, 2, 200), 50, 50, 22, 22, true},
+		{"into a thick wall", NewRectangle(30, 0, 40, 8), 50, 0, 22, 0, false},
+		{"stopping short of a thin wall", NewRectangle(60, 0, 2, 8), 50, 0, 50, 0, false},
+	}
+
+	// step returns a single pixel in the direction of the delta provided.
+	step := func(delta int32) int32 {
+		if delta > 0 {
+			return 1
+		} else if delta < 0 {
+			return -1
+		}
+		return 0
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			player := NewRectangle(0, 0, 8, 8)
+			col := Resolve(player, tt.wall, tt.dx, tt.dy)
+			if col.ResolveX != tt.wantX || col.ResolveY != tt.wantY || col.Teleporting != tt.wantTeleporting {
+				t.Errorf("Resolve() = %d, %d, Teleporting = %v, want %d, %d, %v", col.ResolveX, col.ResolveY,
+					col.Teleporting, tt.wantX, tt.wantY, tt.wantTeleporting)
+			}
+
+			// Moved by the resolved delta, the Shape ends up against the wall, not in it or past it.
+			player.Move(col.ResolveX, col.ResolveY)
+			if player.IsColliding(tt.wall) {
+				t.Errorf("moved by the resolved delta, the Shape at %v is in the wall %v", player, tt.wall)
+			}
+			if col.Colliding() && !player.WouldBeColliding(tt.wall, step(tt.dx), step(tt.dy)) {
+				t.Errorf("moved by the resolved delta, the Shape at %v isn't against the wall %v", player, tt.wall)
+			}
+
+		})
+	}
+
+}
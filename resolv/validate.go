@@ -0,0 +1,101 @@
+package resolv
+
+import (
+	"fmt"
+	"math"
+)
+
+// InvalidShapeError describes why a Shape is invalid, as returned by the Validate() methods of the built-in Shapes.
+// It wraps ErrInvalidShape, so it can be checked for with errors.Is().
+type InvalidShapeError struct {
+	Shape  Shape
+	Reason string
+}
+
+func (e *InvalidShapeError) Error() string {
+	return fmt.Sprintf("resolv: invalid %T: %s", e.Shape, e.Reason)
+}
+
+func (e *InvalidShapeError) Unwrap() error {
+	return ErrInvalidShape
+}
+
+// fitsInt32 returns whether the value provided can be held by an int32.
+func fitsInt32(v int64) bool {
+	return v >= math.MinInt32 && v <= math.MaxInt32
+}
+
+// normalizeSpan returns the start and length provided, flipped so that the length isn't negative, if possible. A length of
+// math.MinInt32 can't be flipped, so it's returned as it is.
+func normalizeSpan(start, length int32) (int32, int32) {
+	if length < 0 && length != math.MinInt32 && fitsInt32(int64(start)+int64(length)) {
+		return start + length, -length
+	}
+	return start, length
+}
+
+// Validate returns an error describing the problem if the Rectangle's size is negative, or if it extends beyond the
+// range of int32 coordinates, and nil otherwise.
+func (r *Rectangle) Validate() error {
+	switch {
+	case r.W < 0 || r.H < 0:
+		return &InvalidShapeError{r, fmt.Sprintf("negative size %dx%d", r.W, r.H)}
+	case !fitsInt32(int64(r.X)+int64(r.W)) || !fitsInt32(int64(r.Y)+int64(r.H)):
+		return &InvalidShapeError{r, "extends beyond the range of int32 coordinates"}
+	}
+	return nil
+}
+
+// Validate returns an error describing the problem if the Circle's radius is negative, or if the Circle extends beyond
+// the range of int32 coordinates, and nil otherwise.
+func (c *Circle) Validate() error {
+	switch {
+	case c.Radius < 0:
+		return &InvalidShapeError{c, fmt.Sprintf("negative radius %d", c.Radius)}
+	case !fitsInt32(int64(c.X)-int64(c.Radius)) || !fitsInt32(int64(c.X)+int64(c.Radius)) ||
+		!fitsInt32(int64(c.Y)-int64(c.Radius)) || !fitsInt32(int64(c.Y)+int64(c.Radius)) ||
+		!fitsInt32(int64(c.Radius)*2):
+		return &InvalidShapeError{c, "extends beyond the range of int32 coordinates"}
+	}
+	return nil
+}
+
+// Validate returns an error if the Line is too long for its delta to be held by int32 values, and nil otherwise. Lines
+// of zero length are valid.
+func (l *Line) Validate() error {
+	if !fitsInt32(int64(l.X2)-int64(l.X)) || !fitsInt32(int64(l.Y2)-int64(l.Y)) {
+		return &InvalidShapeError{l, "too long for its delta to fit in int32 values"}
+	}
+	return nil
+}
+
+// validateShape returns the first error found validating the Shape provided, if it can be validated.
+func validateShape(shape Shape) error {
+	switch s := shape.(type) {
+	case *Space:
+		if errs := s.Validate(); len(errs) > 0 {
+			return errs[0]
+		}
+	case interface{ Validate() error }:
+		return s.Validate()
+	}
+	return nil
+}
+
+// Validate validates every Shape in the Space (including the Shapes within nested Spaces) that has a Validate() error
+// method, like the built-in Shapes do, returning the errors found in the order of the Shapes. It returns nil if all of
+// the Shapes are valid.
+func (sp *Space) Validate() []error {
+	var errs []error
+	for _, shape := range sp.shapes {
+		switch s := shape.(type) {
+		case *Space:
+			errs = append(errs, s.Validate()...)
+		case interface{ Validate() error }:
+			if err := s.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+package resolv
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNormalizingConstructors(t *testing.T) {
+
+	r := NewRectangle(10, 10, -4, -6)
+	if r.X != 6 || r.Y != 4 || r.W != 4 || r.H != 6 {
+		t.Errorf("NewRectangle() with a negative size = %v, want it flipped to 6, 4 4x6", r)
+	}
+	if err := r.Validate(); err != nil {
+		t.Errorf("Validate() of a normalized Rectangle = %v", err)
+	}
+
+	c := NewCircle(0, 0, -5)
+	if c.Radius != 5 {
+		t.Errorf("NewCircle() with a negative radius has radius %d, want 5", c.Radius)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() of a normalized Circle = %v", err)
+	}
+
+}
+
+func TestValidate(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		shape interface{ Validate() error }
+		valid bool
+	}{
+		{"Rectangle", NewRectangle(0, 0, 8, 8), true},
+		{"empty Rectangle", NewRectangle(0, 0, 0, 0), true},
+		{"Rectangle too wide to flip", NewRectangle(0, 0, math.MinInt32, 8), false},
+		{"Rectangle with a negative size set directly", &Rectangle{W: -1, H: 8}, false},
+		{"Rectangle past the maximum", NewRectangle(math.MaxInt32-1, 0, 4, 4), false},
+		{"Circle", NewCircle(0, 0, 8), true},
+		{"Circle radius too large to flip", NewCircle(0, 0, math.MinInt32), false},
+		{"Circle past the maximum", NewCircle(math.MaxInt32, 0, 1), false},
+		{"Circle past the minimum", NewCircle(0, math.MinInt32, 1), false},
+		{"Line", NewLine(0, 0, 10, 10), true},
+		{"zero-length Line", NewLine(5, 5, 5, 5), true},
+		{"Line too long", NewLine(math.MinInt32, 0, math.MaxInt32, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			err := tt.shape.Validate()
+			if tt.valid {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidShape) {
+				t.Fatalf("Validate() = %v, want ErrInvalidShape", err)
+			}
+			var invalid *InvalidShapeError
+			if !errors.As(err, &invalid) || invalid.Shape != tt.shape.(Shape) || invalid.Reason == "" {
+				t.Errorf("Validate() = %#v, want an InvalidShapeError describing the Shape", err)
+			}
+
+		})
+	}
+
+}
+
+func TestSpaceValidate(t *testing.T) {
+
+	bad, worse, nestedBad := &Rectangle{W: -1}, &Circle{Radius: -1}, &Rectangle{H: -1}
+	nested := NewSpace()
+	nested.Add(NewCircle(0, 0, 4), nestedBad)
+	space := NewSpace()
+	space.Add(NewRectangle(0, 0, 8, 8), bad, nested, worse)
+
+	errs := space.Validate()
+	want := []Shape{bad, nestedBad, worse}
+	if len(errs) != len(want) {
+		t.Fatalf("Validate() = %v, want %d errors", errs, len(want))
+	}
+	for i, err := range errs {
+		var invalid *InvalidShapeError
+		if !errors.As(err, &invalid) || invalid.Shape != want[i] {
+			t.Errorf("Validate() error %d = %v, want one for %v", i, err, want[i])
+		}
+	}
+
+	if errs := NewSpace().Validate(); errs != nil {
+		t.Errorf("Validate() of an empty Space = %v, want nil", errs)
+	}
+
+	if err := NewSpace().AddChecked(NewRectangle(0, 0, 8, 8), bad); !errors.Is(err, ErrInvalidShape) {
+		t.Errorf("AddChecked() with an invalid Shape = %v, want ErrInvalidShape", err)
+	}
+
+}
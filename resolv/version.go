@@ -0,0 +1,129 @@
+package resolv
+
+// Version returns the version of the Shape, a number that goes up whenever the Shape is changed through its functions
+// (moved, or its tags, Data, layer, mask, or elevation set), so data derived from it only has to be rebuilt when it
+// changes. Setting the Shape's fields directly (like a Rectangle's W and H) doesn't change the version.
+func (b *BasicShape) Version() uint64 {
+	return b.version
+}
+
+// changed bumps the version of the BasicShape, and lets the Spaces watching it know.
+func (b *BasicShape) changed() {
+	b.version++
+	if b.listeners != nil && b.listeners.watch != nil {
+		b.listeners.watch.notify(nil, 0, 0)
+	}
+}
+
+// watch registers a function to be called whenever the BasicShape changes, returning a function that removes it again.
+// This is how Spaces keep their versions up to date with their Shapes; unlike OnMove(), the functions are called for
+// every change, not just moves.
+func (b *BasicShape) watch(listener func(Shape, int32, int32)) (remove func()) {
+	if b.listeners == nil {
+		b.listeners = &shapeListeners{}
+	}
+	return addMoveListener(&b.listeners.watch, listener)
+}
+
+// Version returns the version of the Space, which goes up when Shapes are added, removed, or reordered, when its root or
+// names change, and when any of its Shapes change (see BasicShape.Version()); queries never change it. The Space only
+// starts watching its Shapes the first time Version() is called (see Unwatch()), and doesn't see custom Shapes that
+// don't embed BasicShape change. Spaces returned by queries (like Filter()) never watch their Shapes; their versions are
+// worked out by adding up their Shapes' versions each time instead, as are those of any such Spaces nested in a Space.
+func (sp *Space) Version() uint64 {
+	sp.watchShapes()
+	if sp.result || sp.nestsResults {
+		// The sum of the versions only goes down when Shapes are removed, which changes the version anyway, so any change
+		// to it means a Shape has changed.
+		if sum := sp.pulledVersions(); sum != sp.pulled {
+			sp.pulled = sum
+			sp.version++
+		}
+	}
+	return sp.version
+}
+
+// pulls returns whether the Space works out the version of the Shape each time its own version is asked for, rather
+// than watching it.
+func (sp *Space) pulls(shape Shape) bool {
+	if sp.result {
+		return true
+	}
+	nested, ok := shape.(*Space)
+	return ok && nested.result
+}
+
+// pulledVersions returns the sum of the versions of the Shapes the Space doesn't watch.
+func (sp *Space) pulledVersions() uint64 {
+	var sum uint64
+	for _, shape := range sp.shapes {
+		if sp.pulls(shape) {
+			if version, ok := shapeVersion(shape); ok {
+				sum += version
+			}
+		}
+	}
+	return sum
+}
+
+// changed bumps the version of the Space, and lets the Spaces watching it know.
+func (sp *Space) changed() {
+	sp.version++
+	if sp.watchers != nil {
+		sp.watchers.notify(nil, 0, 0)
+	}
+}
+
+func (sp *Space) watch(listener func(Shape, int32, int32)) (remove func()) {
+	sp.watchShapes()
+	return addMoveListener(&sp.watchers, listener)
+}
+
+// watchShapes starts watching the Shapes in the Space for changes, if it isn't already, and the Space isn't the results
+// of a query.
+func (sp *Space) watchShapes() {
+	if sp.watched == nil && !sp.result {
+		sp.trackMembers()
+		sp.watched = make(map[Shape]func(), len(sp.shapes))
+		for _, shape := range sp.shapes {
+			sp.watchShape(shape)
+		}
+	}
+}
+
+// watchShape starts watching the Shape for changes, if the Space is watching its Shapes and isn't already watching it.
+func (sp *Space) watchShape(shape Shape) {
+	if nested, ok := shape.(*Space); ok && nested.result {
+		sp.nestsResults = true
+		return
+	}
+	if sp.watched == nil || sp.watched[shape] != nil {
+		return
+	}
+	if s, ok := shape.(interface {
+		watch(func(Shape, int32, int32)) func()
+	}); ok {
+		sp.watched[shape] = s.watch(func(Shape, int32, int32) {
+			sp.changed()
+		})
+	}
+}
+
+// unwatchShape stops watching the Shape for changes once it's no longer in the Space.
+func (sp *Space) unwatchShape(shape Shape) {
+	if remove := sp.watched[shape]; remove != nil && !sp.Contains(shape) {
+		remove()
+		delete(sp.watched, shape)
+	}
+}
+
+// Unwatch stops the Space watching its Shapes for changes, until Version() is next called. Each Shape holds on to the
+// Spaces watching it, so a Space that Version() has been called on should be unwatched when it's dropped while its Shapes
+// live on (like a Space built to cache part of a level), so that it can be garbage collected.
+func (sp *Space) Unwatch() {
+	for shape, remove := range sp.watched {
+		remove()
+		delete(sp.watched, shape)
+	}
+	sp.watched = nil
+}
@@ -0,0 +1,185 @@
+package resolv
+
+import "testing"
+
+func TestSpaceVersion(t *testing.T) {
+
+	// newSpace returns a Space holding a Rectangle, a Circle, and a nested Space with a Line in it, along with a Shape
+	// that isn't in it.
+	newSpace := func() (space *Space, rect *Rectangle, line *Line, outside *Rectangle) {
+		rect, line = NewRectangle(0, 0, 16, 16, WithTags("solid")), NewLine(40, 0, 48, 8)
+		nested := NewSpace()
+		nested.Add(line)
+		space = NewSpace()
+		space.Add(NewCircle(80, 8, 4), rect, nested)
+		return space, rect, line, NewRectangle(4, 4, 8, 8)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(space *Space, rect *Rectangle, line *Line, outside *Rectangle)
+		changed bool
+	}{
+		{"Add", func(space *Space, _ *Rectangle, _ *Line, outside *Rectangle) { space.Add(outside) }, true},
+		{"Remove", func(space *Space, rect *Rectangle, _ *Line, _ *Rectangle) { space.Remove(rect) }, true},
+		{"Remove missing", func(space *Space, _ *Rectangle, _ *Line, outside *Rectangle) { space.Remove(outside) }, false},
+		{"Clear", func(space *Space, _ *Rectangle, _ *Line, _ *Rectangle) { space.Clear() }, true},
+		{"Sort", func(space *Space, _ *Rectangle, _ *Line, _ *Rectangle) { space.Sort(ByPosition) }, true},
+		{"member moved", func(_ *Space, rect *Rectangle, _ *Line, _ *Rectangle) { rect.Move(1, 0) }, true},
+		{"nested member moved", func(_ *Space, _ *Rectangle, line *Line, _ *Rectangle) { line.Move(0, 1) }, true},
+		{"member tagged", func(_ *Space, rect *Rectangle, _ *Line, _ *Rectangle) { rect.AddTags("wall") }, true},
+		{"member's Data set", func(_ *Space, rect *Rectangle, _ *Line, _ *Rectangle) { rect.SetData(1) }, true},
+		{"outside Shape moved", func(_ *Space, _ *Rectangle, _ *Line, outside *Rectangle) { outside.Move(1, 1) }, false},
+		{"removed member moved", func(space *Space, rect *Rectangle, _ *Line, _ *Rectangle) {
+			space.Remove(rect)
+			before := space.Version()
+			rect.Move(1, 0)
+			if space.Version() != before {
+				t.Error("moving a Shape removed from the Space changed its version")
+			}
+		}, true},
+		{"queries", func(space *Space, rect *Rectangle, _ *Line, outside *Rectangle) {
+			space.IsColliding(outside)
+			space.WouldBeColliding(outside, 4, 0)
+			space.GetCollidingShapes(outside)
+			space.Resolve(outside, 8, 0)
+			space.Filter(func(shape Shape) bool { return shape != rect })
+			space.FilterByTags("solid")
+			space.CountByTags("solid")
+			space.GetBoundingRect()
+			space.Contains(rect)
+			space.Shapes()
+			space.ForEach(func(Shape) bool { return true })
+			_ = space.String()
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			space, rect, line, outside := newSpace()
+			before := space.Version()
+			if again := space.Version(); again != before {
+				t.Fatalf("Version() = %d, then %d, without any change", before, again)
+			}
+
+			tt.change(space, rect, line, outside)
+
+			after := space.Version()
+			if changed := after != before; changed != tt.changed {
+				t.Errorf("Version() went from %d to %d, want changed = %v", before, after, tt.changed)
+			}
+			if after < before {
+				t.Errorf("Version() went down from %d to %d", before, after)
+			}
+
+		})
+	}
+
+}
+
+func TestShapeVersion(t *testing.T) {
+
+	other := NewRectangle(4, 4, 8, 8)
+
+	tests := []struct {
+		name    string
+		change  func(r *Rectangle)
+		changed bool
+	}{
+		{"Move", func(r *Rectangle) { r.Move(1, 0) }, true},
+		{"SetXY", func(r *Rectangle) { r.SetXY(2, 3) }, true},
+		{"AddTags", func(r *Rectangle) { r.AddTags("solid") }, true},
+		{"SetData", func(r *Rectangle) { r.SetData("data") }, true},
+		{"SetElevation", func(r *Rectangle) { r.SetElevation(1) }, true},
+		{"IsColliding", func(r *Rectangle) { r.IsColliding(other) }, false},
+		{"WouldBeColliding", func(r *Rectangle) { r.WouldBeColliding(other, 4, 4) }, false},
+		{"String", func(r *Rectangle) { _ = r.String() }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRectangle(0, 0, 8, 8)
+			before := r.Version()
+			tt.change(r)
+			if changed := r.Version() != before; changed != tt.changed {
+				t.Errorf("Version() went from %d to %d, want changed = %v", before, r.Version(), tt.changed)
+			}
+		})
+	}
+
+}
+
+// watching returns how many Spaces are watching the Rectangle.
+func watching(r *Rectangle) int {
+	if r.listeners == nil || r.listeners.watch == nil {
+		return 0
+	}
+	return len(r.listeners.watch.list)
+}
+
+func TestResultSpaceVersion(t *testing.T) {
+
+	rect, other := NewRectangle(0, 0, 16, 16), NewRectangle(8, 8, 16, 16)
+	space := NewSpace()
+	space.Add(rect, other)
+
+	// The results of a query see their Shapes change, without watching them.
+	result := space.GetCollidingShapes(NewRectangle(10, 10, 4, 4))
+	before := result.Version()
+	rect.Move(1, 0)
+	after := result.Version()
+	if after <= before || result.Version() != after {
+		t.Errorf("the result's Version() went from %d to %d, then %d, after a Shape moved", before, after, result.Version())
+	}
+	result.Remove(other)
+	if removed := result.Version(); removed <= after {
+		t.Errorf("the result's Version() went from %d to %d after a Shape was removed", after, removed)
+	}
+	if watching(rect) != 0 {
+		t.Errorf("%d Spaces are watching the Shape, want 0", watching(rect))
+	}
+
+	// A Space with a query result nested in it sees the result's Shapes change too, without watching them.
+	outer := NewSpace()
+	outer.Add(result)
+	before = outer.Version()
+	rect.Move(1, 0)
+	if after := outer.Version(); after <= before {
+		t.Errorf("Version() of the Space holding the result went from %d to %d after a Shape moved", before, after)
+	}
+	if watching(rect) != 0 {
+		t.Errorf("%d Spaces are watching the Shape, want 0", watching(rect))
+	}
+
+	// Spaces that do watch their Shapes stop with Unwatch(), and start again with Version().
+	space.Version()
+	if watching(rect) != 1 {
+		t.Fatalf("%d Spaces are watching the Shape, want 1", watching(rect))
+	}
+	space.Unwatch()
+	if watching(rect) != 0 {
+		t.Errorf("after Unwatch(), %d Spaces are watching the Shape, want 0", watching(rect))
+	}
+	before = space.Version()
+	rect.Move(1, 0)
+	if after := space.Version(); after <= before || watching(rect) != 1 {
+		t.Errorf("after Unwatch(), Version() went from %d to %d, with %d Spaces watching the Shape", before, after,
+			watching(rect))
+	}
+
+}
+
+func BenchmarkWatchedMove(b *testing.B) {
+
+	rect := NewRectangle(0, 0, 8, 8)
+	space := NewSpace()
+	space.Add(rect)
+	space.Version()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rect.Move(1, 0)
+	}
+
+}
@@ -0,0 +1,103 @@
+package resolv
+
+// SpaceView is a live, filtered view of a Space, created with Space.View(). Unlike the Spaces returned by Filter() and
+// FilterByTags(), which are copies made when they're called, a SpaceView checks its predicate against the Space's Shapes
+// each time it's queried, so it always reflects the Shapes currently in the Space (and their current tags, Data, etc).
+type SpaceView struct {
+	space     *Space
+	predicate func(Shape) bool
+}
+
+// View returns a live view of the Shapes in the Space for which the function provided returns true. See SpaceView.
+func (sp *Space) View(predicate func(Shape) bool) *SpaceView {
+	return &SpaceView{space: sp, predicate: predicate}
+}
+
+// ViewByTags returns a live view of the Shapes in the Space that have all of the specified tags. See SpaceView.
+func (sp *Space) ViewByTags(tags ...string) *SpaceView {
+	return sp.View(func(s Shape) bool {
+		return s.HasTags(tags...)
+	})
+}
+
+// View returns a live view of the Shapes in this view for which the function provided also returns true.
+func (sv *SpaceView) View(predicate func(Shape) bool) *SpaceView {
+	parent := sv.predicate
+	return &SpaceView{
+		space: sv.space,
+		predicate: func(s Shape) bool {
+			return parent(s) && predicate(s)
+		},
+	}
+}
+
+// ForEach calls the function provided for each Shape in the view, in the order they are in the Space, stopping early if
+// the function returns false. See Space.ForEach().
+func (sv *SpaceView) ForEach(forEach func(Shape) bool) {
+	sv.space.ForEach(func(s Shape) bool {
+		if sv.predicate(s) {
+			return forEach(s)
+		}
+		return true
+	})
+}
+
+// Length returns the number of Shapes currently in the view.
+func (sv *SpaceView) Length() int {
+	count := 0
+	for _, shape := range sv.space.shapes {
+		if sv.predicate(shape) {
+			count++
+		}
+	}
+	return count
+}
+
+// Space returns a new Space containing the Shapes currently in the view.
+func (sv *SpaceView) Space() *Space {
+	return sv.space.Filter(sv.predicate)
+}
+
+// IsColliding returns whether the provided Shape is colliding with a Shape in the view. See Space.IsColliding().
+func (sv *SpaceView) IsColliding(shape Shape) bool {
+	self := unwrapShape(shape)
+	for _, other := range sv.space.shapes {
+		if unwrapShape(other) != self && sv.space.canCollide(shape, other) && sv.predicate(other) && shape.IsColliding(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCollidingShapes returns a Space comprised of the Shapes in the view that collide with the checking Shape. See
+// Space.GetCollidingShapes().
+func (sv *SpaceView) GetCollidingShapes(shape Shape) *Space {
+	self := unwrapShape(shape)
+	newSpace := newResultSpace()
+	for _, other := range sv.space.shapes {
+		if unwrapShape(other) != self && sv.space.canCollide(shape, other) && sv.predicate(other) && shape.IsColliding(other) {
+			newSpace.Add(other)
+		}
+	}
+	return newSpace
+}
+
+// Resolve runs Resolve() using the checking Shape against the Shapes in the view. Like with Space.Resolve(), the
+// Collision returned is the one with the Shape nearest along the movement.
+func (sv *SpaceView) Resolve(checkingShape Shape, deltaX, deltaY int32) Collision {
+
+	self := unwrapShape(checkingShape)
+	res := Collision{}
+
+	for _, other := range sv.space.shapes {
+		if unwrapShape(other) != self && sv.space.canCollide(checkingShape, other) && sv.predicate(other) {
+			collision := Resolve(checkingShape, other, deltaX, deltaY)
+			if collision.Colliding() && (!res.Colliding() || nearer(collision, res, deltaX, deltaY)) {
+				res = collision
+			}
+		}
+	}
+
+	return res
+
+}
@@ -0,0 +1,114 @@
+package resolv
+
+import "math"
+
+// VisionCone returns a new Space containing the Shapes in the Space that can be seen from the point x, y (like an enemy's
+// eyes): those whose centers are within the cone facing the angle provided, spreading halfAngle to either side of it, and
+// no further than rangeDist away, with a clear line of sight to them past the Shapes that have all of the occluder tags
+// provided (like walls). If no occluder tags are provided, any Shape can block sight, like with LineOfSight().
+// Angles are in radians, measured like math.Atan2(dy, dx): 0 faces right, and as Y grows downwards, math.Pi/2 faces
+// down. The facing angle can be any angle (like one that's wrapped past math.Pi), and a halfAngle of math.Pi or more sees
+// all the way around. Shapes exactly on the edge of the cone or at rangeDist are seen.
+// Shapes that contain x, y (like the looker's own Shape) aren't seen, and don't block sight either. A seen Shape never
+// blocks sight of itself. See VisionConeClosest() to detect large Shapes whose centers are outside of the cone.
+func (sp *Space) VisionCone(x, y int32, facingAngle, halfAngle float64, rangeDist int32, occluderTags ...string) *Space {
+	return sp.visionCone(x, y, facingAngle, halfAngle, rangeDist, false, occluderTags)
+}
+
+// VisionConeClosest is like VisionCone(), but tests each Shape's point closest to x, y, rather than its center, against
+// the cone, the range, and the line of sight. If that point is outside of the cone, the nearest point where the Shape
+// crosses either edge of the cone (or the line straight down its middle) is tested instead, and failing that, the Shape's
+// center. This means that a large Shape (like a long wall) that pokes into the cone is seen, even though its center's
+// outside of it.
+func (sp *Space) VisionConeClosest(x, y int32, facingAngle, halfAngle float64, rangeDist int32, occluderTags ...string) *Space {
+	return sp.visionCone(x, y, facingAngle, halfAngle, rangeDist, true, occluderTags)
+}
+
+func (sp *Space) visionCone(x, y int32, facingAngle, halfAngle float64, rangeDist int32, closest bool, occluderTags []string) *Space {
+
+	seen := newResultSpace()
+	eye := NewRectangle(x, y, 1, 1)
+
+	// The Shapes containing the eye are found first, so that they can be left out of the lines of sight.
+	var looker []Shape
+	for _, shape := range sp.shapes {
+		if eye.IsColliding(shape) {
+			looker = append(looker, shape)
+		}
+	}
+
+	sight := NewLine(x, y, x, y)
+	ignore := make([]Shape, len(looker)+1)
+	copy(ignore[1:], looker)
+
+	// inView returns whether the point px, py is within the cone and range, and can be seen.
+	inView := func(shape Shape, px, py float64) bool {
+
+		dx, dy := px-float64(x), py-float64(y)
+		if math.Hypot(dx, dy) > float64(rangeDist) {
+			return false
+		}
+
+		// The difference between the angles is wrapped to [-Pi, Pi], so that facing angles near ±Pi (or past them)
+		// compare correctly with points on the other side of the wrap.
+		if (dx != 0 || dy != 0) && math.Abs(math.Remainder(math.Atan2(dy, dx)-facingAngle, 2*math.Pi)) > halfAngle {
+			return false
+		}
+
+		sight.SetEndpoints(x, y, int32(math.Round(px)), int32(math.Round(py)))
+		ignore[0] = shape
+		return sp.lineOfSight(sight, occluderTags, ignore...)
+
+	}
+
+	for _, shape := range sp.shapes {
+
+		if containsShape(looker, shape) {
+			continue
+		}
+
+		if closest {
+			if px, py := closestPoint(shape, x, y); inView(shape, px, py) {
+				seen.Add(shape)
+				continue
+			}
+			if sp.visionEdges(shape, x, y, facingAngle, halfAngle, rangeDist, inView) {
+				seen.Add(shape)
+				continue
+			}
+		}
+
+		if cx, cy := shapeCenter(shape); inView(shape, float64(cx), float64(cy)) {
+			seen.Add(shape)
+		}
+
+	}
+
+	return seen
+
+}
+
+// visionEdges returns whether the Shape is seen where it crosses the edges of the cone, or the line down its middle.
+func (sp *Space) visionEdges(shape Shape, x, y int32, facingAngle, halfAngle float64, rangeDist int32, inView func(Shape, float64, float64) bool) bool {
+
+	if halfAngle >= math.Pi || halfAngle < 0 {
+		// A cone that sees all the way around (or nothing at all) has no edges; the closest point is all there is.
+		return false
+	}
+
+	for _, angle := range [3]float64{facingAngle - halfAngle, facingAngle, facingAngle + halfAngle} {
+		endX, endY := rayAt(x, y, angle, rangeDist)
+		if px, py, ok := firstContact(shape, x, y, endX, endY); ok && inView(shape, float64(px), float64(py)) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// rayAt returns the end of a ray of the length provided, cast from x, y at the angle provided (in radians, measured like
+// math.Atan2(dy, dx)).
+func rayAt(x, y int32, angle float64, length int32) (int32, int32) {
+	return x + int32(math.Round(math.Cos(angle)*float64(length))), y + int32(math.Round(math.Sin(angle)*float64(length)))
+}
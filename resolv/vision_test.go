@@ -0,0 +1,136 @@
+package resolv
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// targetAt returns a small Rectangle centered 60 pixels from the origin, at the angle provided.
+func targetAt(angle float64) *Rectangle {
+	x, y := rayAt(0, 0, angle, 60)
+	return NewRectangle(x-1, y-1, 2, 2)
+}
+
+func TestVisionConeAngles(t *testing.T) {
+
+	tests := []struct {
+		facing, target float64
+		want           bool
+	}{
+		{0, 0, true},
+		{0, 0.3, true},
+		{0, -0.3, true},
+		{0, 0.6, false},
+		{0, math.Pi, false},
+		{math.Pi / 2, math.Pi / 2, true},
+		{math.Pi / 2, 0, false},
+
+		// Facing near (or past) ±Pi, the cone wraps around to the other side.
+		{math.Pi, math.Pi, true},
+		{math.Pi, -math.Pi + 0.3, true},
+		{math.Pi, math.Pi - 0.3, true},
+		{-math.Pi, math.Pi - 0.3, true},
+		{math.Pi - 0.1, -math.Pi + 0.1, true},
+		{-math.Pi + 0.1, math.Pi - 0.1, true},
+		{math.Pi - 0.2, -math.Pi + 0.5, false},
+		{math.Pi, 0, false},
+		{3 * math.Pi, math.Pi - 0.3, true},
+		{-3 * math.Pi, -math.Pi + 0.3, true},
+		{2 * math.Pi, 0.3, true},
+		{2 * math.Pi, math.Pi, false},
+		{-5 * math.Pi / 2, -math.Pi / 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("facing %.2f, target at %.2f", tt.facing, tt.target), func(t *testing.T) {
+
+			target := targetAt(tt.target)
+			space := NewSpace()
+			space.Add(target)
+
+			if got := space.VisionCone(0, 0, tt.facing, 0.4, 100).Contains(target); got != tt.want {
+				t.Errorf("VisionCone() sees the target = %v, want %v", got, tt.want)
+			}
+			if got := space.VisionConeClosest(0, 0, tt.facing, 0.4, 100).Contains(target); got != tt.want {
+				t.Errorf("VisionConeClosest() sees the target = %v, want %v", got, tt.want)
+			}
+
+		})
+	}
+
+}
+
+func TestVisionCone(t *testing.T) {
+
+	looker := NewRectangle(-4, -4, 8, 8)
+	near := NewRectangle(30, -2, 4, 4)
+	far := NewRectangle(120, -2, 4, 4)
+	hidden := NewRectangle(80, -2, 4, 4)
+	wall := NewRectangle(60, -10, 4, 20, WithTags("wall"))
+	crate := NewRectangle(40, 20, 4, 8)
+	behindCrate := NewRectangle(60, 40, 4, 4)
+	behind := NewRectangle(-40, -2, 4, 4)
+
+	space := NewSpace()
+	space.Add(looker, near, far, hidden, wall, crate, behindCrate, behind)
+
+	seen := space.VisionCone(0, 0, 0, math.Pi/3, 100, "wall")
+	want := map[Shape]bool{near: true, wall: true, crate: true, behindCrate: true}
+	for _, shape := range space.Shapes() {
+		if seen.Contains(shape) != want[shape] {
+			t.Errorf("VisionCone() sees %v = %v, want %v", shape, seen.Contains(shape), want[shape])
+		}
+	}
+
+	// Without occluder tags, anything blocks sight, including the crate.
+	if space.VisionCone(0, 0, 0, math.Pi/3, 100).Contains(behindCrate) {
+		t.Error("VisionCone() without occluder tags sees past the crate")
+	}
+
+	// A Shape exactly at the range is seen; one a pixel past it isn't.
+	edge := NewSpace()
+	atRange, pastRange := NewRectangle(49, 49, 2, 2), NewRectangle(-1, -52, 2, 2)
+	edge.Add(atRange, pastRange)
+	if seen := edge.VisionCone(0, 50, -math.Pi/2, math.Pi, 50); !seen.Contains(atRange) || seen.Contains(pastRange) {
+		t.Errorf("VisionCone() at the edge of its range sees %v", seen.Shapes())
+	}
+
+}
+
+func TestVisionConeClosest(t *testing.T) {
+
+	// A long wall running across the top of the cone, with its center far outside of it.
+	wall := NewRectangle(40, -200, 8, 210)
+	space := NewSpace()
+	space.Add(wall)
+
+	if space.VisionCone(0, 0, 0, 0.3, 100).Contains(wall) {
+		t.Fatal("VisionCone() sees the wall by its center")
+	}
+	if !space.VisionConeClosest(0, 0, 0, 0.3, 100).Contains(wall) {
+		t.Error("VisionConeClosest() doesn't see the wall poking into the cone")
+	}
+	if space.VisionConeClosest(0, 0, math.Pi, 0.3, 100).Contains(wall) {
+		t.Error("VisionConeClosest() sees the wall while facing away from it")
+	}
+
+}
+
+func BenchmarkVisionCone(b *testing.B) {
+
+	space := NewSpace()
+	for i := int32(0); i < 256; i++ {
+		var opts []ShapeOption
+		if i%4 == 0 {
+			opts = append(opts, WithTags("wall"))
+		}
+		space.Add(NewRectangle(i%16*24-192, i/16*24-192, 8, 8, opts...))
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		space.VisionCone(0, 0, float64(i%64)/10, math.Pi/4, 160, "wall")
+	}
+
+}
@@ -0,0 +1,195 @@
+package resolv
+
+// Body gives a Shape in a World a velocity and acceleration, which World.Update() moves it by each step. Speeds are in
+// pixels per unit of time (whatever dt is measured in for World.Update()), and can be fractions of a pixel; like with
+// PlatformerBody, the fractions are carried over between steps, so slow movement isn't lost to the Shape's whole-pixel
+// position.
+type Body struct {
+	Shape Shape
+
+	// Vx and Vy are the Body's velocity, and Ax and Ay its acceleration.
+	Vx, Vy float64
+	Ax, Ay float64
+
+	// Static Bodies aren't moved by World.Update(), but other Bodies still collide with them.
+	Static bool
+
+	remX, remY float64
+}
+
+// Contact describes a Body running into another Shape while being moved by World.Update().
+// NormalX and NormalY point from the other Shape back towards the Body, along the axis it was blocked on (so a Body
+// landing on a floor has a normal of 0, -1). OtherBody is the other Shape's Body, if it has one.
+type Contact struct {
+	Body      *Body
+	Other     Shape
+	OtherBody *Body
+
+	NormalX, NormalY int32
+
+	Collision Collision
+}
+
+// World moves Bodies around a Space with simple kinematics: each step, their velocities are changed by their
+// accelerations, and they're moved by their velocities, with the movement resolved against the other Shapes in the Space
+// one axis at a time. A Body that's blocked on an axis stops moving along it. This is "physics-lite": there's no rotation,
+// mass, or bouncing; Bodies simply stop where they're blocked.
+// Shapes in the Space that aren't Bodies (like a level's walls) block Bodies, but aren't moved. Which Shapes a Body
+// collides with follows their collision layers and masks, and elevation (see LayersCollide()), like Space.Resolve().
+// For a fixed dt, Update() is deterministic: the Bodies are moved in the order they were added, and the same steps always
+// lead to the same positions.
+type World struct {
+	Space *Space
+
+	bodies    []*Body
+	bodyOf    map[Shape]*Body
+	onContact []func(Contact)
+}
+
+// NewWorld returns a new World moving Bodies around the Space provided, or a new Space if it's nil.
+func NewWorld(space *Space) *World {
+	if space == nil {
+		space = NewSpace()
+	}
+	return &World{Space: space, bodyOf: map[Shape]*Body{}}
+}
+
+// AddBody adds the Shape provided to the World's Space (unless it's already in it) and returns a new Body for it. If the
+// Shape already has a Body in the World, that Body is returned instead.
+func (w *World) AddBody(shape Shape) *Body {
+	if b := w.bodyOf[shape]; b != nil {
+		return b
+	}
+	if !w.Space.Contains(shape) {
+		w.Space.Add(shape)
+	}
+	b := &Body{Shape: shape}
+	w.bodies = append(w.bodies, b)
+	w.bodyOf[shape] = b
+	return b
+}
+
+// RemoveBody removes the Body and its Shape from the World, returning true if the Body was in it.
+func (w *World) RemoveBody(b *Body) bool {
+	if w.bodyOf[b.Shape] != b {
+		return false
+	}
+	delete(w.bodyOf, b.Shape)
+	for i, other := range w.bodies {
+		if other == b {
+			w.bodies = append(w.bodies[:i], w.bodies[i+1:]...)
+			break
+		}
+	}
+	w.Space.Remove(b.Shape)
+	return true
+}
+
+// Bodies returns the Bodies in the World, in the order they were added. The slice returned mustn't be changed.
+func (w *World) Bodies() []*Body {
+	return w.bodies
+}
+
+// BodyOf returns the Body of the Shape provided, or nil if it doesn't have one in the World.
+func (w *World) BodyOf(shape Shape) *Body {
+	return w.bodyOf[shape]
+}
+
+// OnContact registers a function to be called whenever a Body runs into another Shape during Update(). Multiple functions
+// can be registered, and are called in the order they were registered.
+func (w *World) OnContact(listener func(Contact)) {
+	w.onContact = append(w.onContact, listener)
+}
+
+// Update steps the World forward by dt: each Body that isn't Static has its velocity changed by its acceleration, and is
+// then moved by its velocity, first horizontally and then vertically, stopping where it's blocked (and zeroing its
+// velocity along that axis). Bodies are moved one at a time, in the order they were added, against the others' current
+// positions.
+func (w *World) Update(dt float64) {
+
+	for _, b := range w.bodies {
+
+		if b.Static {
+			continue
+		}
+
+		// The explicit conversions round each product, so that the results don't depend on whether the platform fuses
+		// multiplications and additions, keeping Update() deterministic everywhere.
+		b.Vx += float64(b.Ax * dt)
+		b.Vy += float64(b.Ay * dt)
+
+		dx := carry(&b.remX, float64(b.Vx*dt))
+		if w.move(b, dx, 0, b.Vx, 0) {
+			b.Vx, b.remX = 0, 0
+		}
+
+		dy := carry(&b.remY, float64(b.Vy*dt))
+		if w.move(b, 0, dy, 0, b.Vy) {
+			b.Vy, b.remY = 0, 0
+		}
+
+	}
+
+}
+
+// move moves the Body by the delta provided, stopping where it's blocked, and returns whether it was blocked, reporting
+// the contact if so. If the delta is 0 as the Body is moving by less than a pixel, it's still blocked if it's pressed
+// against something in the direction of its velocity, like a Body resting on a floor under gravity.
+func (w *World) move(b *Body, dx, dy int32, vx, vy float64) bool {
+
+	probeX, probeY := dx, dy
+	if dx == 0 && dy == 0 {
+		probeX, probeY = signFloat(vx), signFloat(vy)
+		if probeX == 0 && probeY == 0 {
+			return false
+		}
+	}
+
+	col := resolveDelta(w.Space, b.Shape, probeX, probeY)
+	if !col.Colliding() {
+		b.Shape.Move(dx, dy)
+		return false
+	}
+
+	b.Shape.Move(col.ResolveX, col.ResolveY)
+	w.contact(b, col, -sign32(probeX), -sign32(probeY))
+	return true
+
+}
+
+func (w *World) contact(b *Body, col Collision, normalX, normalY int32) {
+	if len(w.onContact) == 0 {
+		return
+	}
+	c := Contact{
+		Body:      b,
+		Other:     col.ShapeB,
+		OtherBody: w.bodyOf[col.ShapeB],
+		NormalX:   normalX,
+		NormalY:   normalY,
+		Collision: col,
+	}
+	for _, listener := range w.onContact {
+		listener(c)
+	}
+}
+
+func sign32(v int32) int32 {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+func signFloat(v float64) int32 {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
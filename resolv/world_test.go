@@ -0,0 +1,143 @@
+package resolv
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// newTestWorld returns a walled-in World with a floor, a Static Body in the middle, and Bodies thrown around in it under
+// gravity.
+func newTestWorld(bodies int) *World {
+
+	world := NewWorld(nil)
+	world.Space.Add(
+		NewRectangle(0, 240, 320, 16),
+		NewRectangle(-16, 0, 16, 240),
+		NewRectangle(320, 0, 16, 240),
+		NewRectangle(0, -16, 320, 16),
+	)
+	world.AddBody(NewRectangle(144, 160, 32, 32)).Static = true
+
+	rng := rand.New(rand.NewSource(34))
+	for i := 0; i < bodies; i++ {
+		b := world.AddBody(NewRectangle(int32(i%10)*30+4, int32(i/10)*30+4, 8, 8))
+		b.Vx, b.Vy = rng.Float64()*240-120, rng.Float64()*240-120
+		b.Ay = 300
+	}
+	return world
+
+}
+
+// hashWorld hashes the positions, velocities, and remainders of the Bodies in the World.
+func hashWorld(world *World) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, b := range world.Bodies() {
+		x, y := b.Shape.GetXY()
+		for _, v := range []uint64{uint64(uint32(x)), uint64(uint32(y)), math.Float64bits(b.Vx), math.Float64bits(b.Vy),
+			math.Float64bits(b.remX), math.Float64bits(b.remY)} {
+			binary.LittleEndian.PutUint64(buf[:], v)
+			h.Write(buf[:])
+		}
+	}
+	return h.Sum64()
+}
+
+func TestWorldDeterministic(t *testing.T) {
+
+	const steps, dt = 600, 1.0 / 60
+
+	run := func() uint64 {
+		world := newTestWorld(30)
+		for i := 0; i < steps; i++ {
+			world.Update(dt)
+		}
+		return hashWorld(world)
+	}
+
+	first := run()
+	for i := 0; i < 3; i++ {
+		if again := run(); again != first {
+			t.Fatalf("the World's hash after %d steps was %#x, then %#x", steps, first, again)
+		}
+	}
+
+	// The hash is the same on every platform, too.
+	const want = 0x8a71cd227cf7eacf
+	if first != want {
+		t.Errorf("the World's hash after %d steps = %#x, want %#x", steps, first, uint64(want))
+	}
+
+}
+
+func TestWorldUpdate(t *testing.T) {
+
+	world := NewWorld(nil)
+	floor := NewRectangle(0, 100, 200, 16)
+	world.Space.Add(floor)
+
+	falling := world.AddBody(NewRectangle(10, 80, 8, 8))
+	falling.Vx, falling.Vy = 30, 60
+
+	static := world.AddBody(NewRectangle(100, 84, 16, 16))
+	static.Static = true
+	static.Vx, static.Ax = 100, 100
+
+	runner := world.AddBody(NewRectangle(60, 92, 8, 8))
+	runner.Vx = 120
+
+	var contacts []Contact
+	world.OnContact(func(c Contact) { contacts = append(contacts, c) })
+
+	for i := 0; i < 60; i++ {
+		world.Update(1.0 / 60)
+	}
+
+	// The falling Body lands on the floor, stopping vertically but carrying on horizontally.
+	if x, y := falling.Shape.GetXY(); y != 92 || x != 40 || falling.Vy != 0 || falling.Vx != 30 {
+		t.Errorf("the falling Body ended at %d, %d with a velocity of %v, %v, want 40, 92 and 30, 0", x, y,
+			falling.Vx, falling.Vy)
+	}
+
+	// The Static Body doesn't move, and the running Body stops against it.
+	if x, y := static.Shape.GetXY(); x != 100 || y != 84 || static.Vx != 100 {
+		t.Errorf("the Static Body moved to %d, %d with a velocity of %v", x, y, static.Vx)
+	}
+	if x, _ := runner.Shape.GetXY(); x != 92 || runner.Vx != 0 {
+		t.Errorf("the running Body ended at x = %d with a speed of %v, want 92 and 0", x, runner.Vx)
+	}
+
+	var landed, hitStatic bool
+	for _, c := range contacts {
+		switch {
+		case c.Body == falling && c.Other == floor:
+			landed = c.NormalX == 0 && c.NormalY == -1 && c.OtherBody == nil
+		case c.Body == runner && c.Other == static.Shape:
+			hitStatic = c.NormalX == -1 && c.NormalY == 0 && c.OtherBody == static
+		}
+	}
+	if !landed || !hitStatic {
+		t.Errorf("OnContact() got %d contacts, with the landing reported = %v and the hit reported = %v",
+			len(contacts), landed, hitStatic)
+	}
+
+	if !world.RemoveBody(runner) || world.RemoveBody(runner) || world.Space.Contains(runner.Shape) ||
+		world.BodyOf(runner.Shape) != nil {
+		t.Error("RemoveBody() didn't remove the Body and its Shape from the World exactly once")
+	}
+
+}
+
+func BenchmarkWorldUpdate(b *testing.B) {
+
+	world := newTestWorld(50)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		world.Update(1.0 / 60)
+	}
+
+}
@@ -0,0 +1,125 @@
+package resolv
+
+import "math"
+
+// ZoneEffect is the Data to set on a Shape to make it a zone that changes the movement of the Shapes inside it (like
+// water that slows them down, or wind that pushes them); see GetZoneEffects() and ApplyZones(). A *ZoneEffect works too.
+// Zones shouldn't block the Shapes that move through them, so they should be given a collision mask of 0 (or a collision
+// layer that those Shapes' masks don't include).
+type ZoneEffect struct {
+	// Multiplier scales the movement of Shapes in the zone (like 0.5 for water that halves their speed). A Multiplier of 0
+	// leaves their movement as it is, so that zones that just push don't have to set it.
+	Multiplier float64
+
+	// PushX and PushY are added to the movement of Shapes in the zone each frame, in pixels (like wind).
+	PushX, PushY float64
+}
+
+// multiplier returns the zone's Multiplier, with 0 meaning 1.
+func (z ZoneEffect) multiplier() float64 {
+	if z.Multiplier == 0 {
+		return 1
+	}
+	return z.Multiplier
+}
+
+// ZoneOption is an option for ApplyZones().
+type ZoneOption func(*zoneOptions)
+
+type zoneOptions struct {
+	overlapFraction bool
+}
+
+// ZoneOverlapFraction makes ApplyZones() scale each zone's effect by how much of the Shape is in it (by the overlap of
+// their bounding rectangles), so that a Shape half in water is slowed half as much as one that's wholly in it.
+func ZoneOverlapFraction() ZoneOption {
+	return func(o *zoneOptions) {
+		o.overlapFraction = true
+	}
+}
+
+// GetZoneEffects returns the effects of the zones in the Space (Shapes with a ZoneEffect as their Data) that the Shape
+// provided is in, in the order the zones are in the Space. Like OverlappingTagged(), it ignores collision layers and
+// elevation, so that zones the Shape passes through are found.
+func (sp *Space) GetZoneEffects(shape Shape) []ZoneEffect {
+	var effects []ZoneEffect
+	sp.eachZone(shape, func(zone Shape, effect ZoneEffect) {
+		effects = append(effects, effect)
+	})
+	return effects
+}
+
+// eachZone calls the function provided with each zone in the Space that the Shape provided is in, along with its effect.
+func (sp *Space) eachZone(shape Shape, fn func(zone Shape, effect ZoneEffect)) {
+	for _, other := range sp.shapes {
+		if other == shape {
+			continue
+		}
+		var effect ZoneEffect
+		switch e := other.GetData().(type) {
+		case ZoneEffect:
+			effect = e
+		case *ZoneEffect:
+			if e == nil {
+				continue
+			}
+			effect = *e
+		default:
+			continue
+		}
+		if shape.IsColliding(other) {
+			fn(other, effect)
+		}
+	}
+}
+
+// ApplyZones returns the movement dx, dy changed by the zones in the Space that the Shape is in (see ZoneEffect), for
+// resolving against the Space as usual. The zones' multipliers are multiplied together, and their pushes are added
+// together; the movement is scaled by the multipliers, and then the pushes are added to it, with the result rounded to the
+// nearest pixel. See ZoneOverlapFraction() for scaling the zones' effects by how much of the Shape is in them.
+func ApplyZones(space *Space, shape Shape, dx, dy int32, opts ...ZoneOption) (int32, int32) {
+
+	o := zoneOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var bounds Rectangle
+	if o.overlapFraction {
+		boundingRectInto(shape, &bounds)
+	}
+
+	multiplier, pushX, pushY := 1.0, 0.0, 0.0
+	space.eachZone(shape, func(zone Shape, effect ZoneEffect) {
+		fraction := 1.0
+		if o.overlapFraction {
+			fraction = overlapFraction(&bounds, zone)
+		}
+		multiplier *= 1 + (effect.multiplier()-1)*fraction
+		pushX += effect.PushX * fraction
+		pushY += effect.PushY * fraction
+	})
+
+	return int32(math.Round(float64(dx)*multiplier + pushX)), int32(math.Round(float64(dy)*multiplier + pushY))
+
+}
+
+// overlapFraction returns how much of the bounding rectangle provided the Shape's bounding rectangle covers, from 0 to 1.
+// An empty bounding rectangle is wholly covered.
+func overlapFraction(bounds *Rectangle, shape Shape) float64 {
+
+	area := float64(bounds.W) * float64(bounds.H)
+	if area <= 0 {
+		return 1
+	}
+
+	var other Rectangle
+	boundingRectInto(shape, &other)
+	w := math.Min(float64(bounds.X)+float64(bounds.W), float64(other.X)+float64(other.W)) - math.Max(float64(bounds.X), float64(other.X))
+	h := math.Min(float64(bounds.Y)+float64(bounds.H), float64(other.Y)+float64(other.H)) - math.Max(float64(bounds.Y), float64(other.Y))
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h / area
+
+}
@@ -0,0 +1,10 @@
+// Package resolvebiten has helpers for using resolv with Ebitengine (github.com/hajimehoshi/ebiten/v2), like drawing a
+// Space onto the screen for debugging. It's a module of its own, so that resolv itself doesn't depend on Ebitengine, and
+// it's only built with the "ebiten" build tag:
+//
+//	go get github.com/ClessLi/resolvForGame/resolvebiten
+//	go build -tags ebiten
+//
+// Within this repository, run "go mod tidy" in the resolvebiten directory once (which needs network access) to fetch
+// Ebitengine and write the module's go.sum before building or vetting it.
+package resolvebiten
@@ -0,0 +1,49 @@
+//go:build ebiten
+// +build ebiten
+
+package resolvebiten
+
+import (
+	"image/color"
+
+	"github.com/ClessLi/resolvForGame/resolv"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// StrokeWidth is the width of the lines DebugDraw() draws Shapes with.
+var StrokeWidth float32 = 1
+
+// screenDrawer is a resolv.Drawer drawing onto an Ebitengine image with vector graphics, offset by the camera position.
+type screenDrawer struct {
+	screen     *ebiten.Image
+	camX, camY float64
+}
+
+func (d screenDrawer) DrawRect(x, y, w, h int32, c color.Color) {
+	vector.StrokeRect(d.screen, float32(float64(x)-d.camX), float32(float64(y)-d.camY), float32(w), float32(h), StrokeWidth, c, false)
+}
+
+func (d screenDrawer) DrawCircle(x, y, radius int32, c color.Color) {
+	vector.StrokeCircle(d.screen, float32(float64(x)-d.camX), float32(float64(y)-d.camY), float32(radius), StrokeWidth, c, true)
+}
+
+func (d screenDrawer) DrawLine(x, y, x2, y2 int32, c color.Color) {
+	vector.StrokeLine(d.screen, float32(float64(x)-d.camX), float32(float64(y)-d.camY),
+		float32(float64(x2)-d.camX), float32(float64(y2)-d.camY), StrokeWidth, c, true)
+}
+
+// DebugDraw draws the outlines of the Shapes in the Space onto the screen, with the camera at camX and camY (so that a
+// Shape at the camera's position is drawn at the screen's top-left corner). The options are the ones Space.DebugDraw()
+// takes, so resolv.DrawTagColor() sets the colors of Shapes by their tags, and resolv.DrawColliding() or
+// resolv.DrawTracked() highlights colliding Shapes.
+func DebugDraw(screen *ebiten.Image, sp *resolv.Space, camX, camY float64, opts ...resolv.DebugDrawOption) {
+	sp.DebugDraw(screenDrawer{screen, camX, camY}, opts...)
+}
+
+// ShapeFromImage returns a new Rectangle at the position provided, the size of the image's bounds, for using as the
+// hitbox of a sprite.
+func ShapeFromImage(img *ebiten.Image, x, y int32) *resolv.Rectangle {
+	bounds := img.Bounds()
+	return resolv.NewRectangle(x, y, int32(bounds.Dx()), int32(bounds.Dy()))
+}
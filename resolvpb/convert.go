@@ -0,0 +1,91 @@
+package resolvpb
+
+import (
+	"errors"
+
+	"github.com/ClessLi/resolvForGame/resolv"
+)
+
+// ErrUnknownShape is returned by FromProto() for Shape messages that don't hold any kind of Shape it knows, like ones
+// sent by a newer version of a program with more kinds of Shapes.
+var ErrUnknownShape = errors.New("resolvpb: unknown shape kind")
+
+// ToProto returns a Shape message for the resolv.Shape provided, with its position, size, tags, and ID (for nested
+// Spaces, the Shapes within them). The Shapes' Data, layers, masks, and elevations aren't included. It returns nil for
+// Shapes other than the built-in ones, which can't be sent as messages.
+func ToProto(shape resolv.Shape) *Shape {
+
+	switch s := shape.(type) {
+
+	case *resolv.Rectangle:
+		return &Shape{Rectangle: &Rectangle{ID: s.ID(), X: s.X, Y: s.Y, Tags: s.GetTags(), W: s.W, H: s.H}}
+
+	case *resolv.Circle:
+		return &Shape{Circle: &Circle{ID: s.ID(), X: s.X, Y: s.Y, Tags: s.GetTags(), Radius: s.Radius}}
+
+	case *resolv.Line:
+		return &Shape{Line: &Line{ID: s.ID(), X: s.X, Y: s.Y, Tags: s.GetTags(), X2: s.X2, Y2: s.Y2}}
+
+	case *resolv.Space:
+		space := &Space{}
+		for i := 0; i < s.Length(); i++ {
+			if inner := ToProto(s.Get(i)); inner != nil {
+				space.Shapes = append(space.Shapes, inner)
+			}
+		}
+		return &Shape{Space: space}
+
+	}
+
+	return nil
+
+}
+
+// FromProto returns a new resolv.Shape for the Shape message provided, with the ID from the message (see
+// resolv.BasicShape.SetID()). It returns an error wrapping ErrUnknownShape if the message (or a Shape message within a
+// Space) doesn't hold a kind of Shape it knows.
+func FromProto(msg *Shape) (resolv.Shape, error) {
+
+	if msg == nil {
+		return nil, ErrUnknownShape
+	}
+
+	switch {
+
+	case msg.Rectangle != nil:
+		m := msg.Rectangle
+		r := &resolv.Rectangle{BasicShape: resolv.BasicShape{X: m.X, Y: m.Y}, W: m.W, H: m.H}
+		r.SetID(m.ID)
+		r.AddTags(m.Tags...)
+		return r, nil
+
+	case msg.Circle != nil:
+		m := msg.Circle
+		c := &resolv.Circle{BasicShape: resolv.BasicShape{X: m.X, Y: m.Y}, Radius: m.Radius}
+		c.SetID(m.ID)
+		c.AddTags(m.Tags...)
+		return c, nil
+
+	case msg.Line != nil:
+		m := msg.Line
+		l := &resolv.Line{BasicShape: resolv.BasicShape{X: m.X, Y: m.Y}, X2: m.X2, Y2: m.Y2}
+		l.SetID(m.ID)
+		l.AddTags(m.Tags...)
+		return l, nil
+
+	case msg.Space != nil:
+		space := resolv.NewSpace(resolv.WithCapacity(len(msg.Space.Shapes)))
+		for _, inner := range msg.Space.Shapes {
+			shape, err := FromProto(inner)
+			if err != nil {
+				return nil, err
+			}
+			space.Add(shape)
+		}
+		return space, nil
+
+	}
+
+	return nil, ErrUnknownShape
+
+}
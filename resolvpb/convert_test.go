@@ -0,0 +1,222 @@
+package resolvpb
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ClessLi/resolvForGame/resolv"
+)
+
+var testTags = []string{"solid", "coin", "player", "hazard"}
+
+func randomCoord(rng *rand.Rand) int32 {
+	switch rng.Intn(8) {
+	case 0:
+		return math.MinInt32
+	case 1:
+		return math.MaxInt32
+	}
+	return rng.Int31n(1<<16) - 1<<15
+}
+
+// randomShape returns a random built-in Shape with random tags and ID, nesting Spaces up to depth levels deep.
+func randomShape(rng *rand.Rand, depth int) resolv.Shape {
+
+	var shape resolv.Shape
+	switch kind := rng.Intn(4); {
+	case kind == 3 && depth > 0:
+		space := resolv.NewSpace()
+		for i := rng.Intn(4); i > 0; i-- {
+			space.Add(randomShape(rng, depth-1))
+		}
+		return space
+	case kind == 0:
+		shape = resolv.NewRectangle(randomCoord(rng), randomCoord(rng), rng.Int31(), rng.Int31())
+	case kind == 1:
+		shape = resolv.NewCircle(randomCoord(rng), randomCoord(rng), rng.Int31())
+	default:
+		shape = resolv.NewLine(randomCoord(rng), randomCoord(rng), randomCoord(rng), randomCoord(rng))
+	}
+
+	for _, tag := range testTags {
+		if rng.Intn(3) == 0 {
+			shape.AddTags(tag)
+		}
+	}
+	shape.(interface{ SetID(uint64) }).SetID(rng.Uint64())
+	return shape
+
+}
+
+func equalTags(a, b resolv.Shape) bool {
+	tagsA, tagsB := a.GetTags(), b.GetTags()
+	if len(tagsA) != len(tagsB) {
+		return false
+	}
+	for i := range tagsA {
+		if tagsA[i] != tagsB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalShapes returns whether the Shapes are the same kind of Shape, with the same position, size, tags, and ID (or, for
+// Spaces, equal Shapes in the same order).
+func equalShapes(a, b resolv.Shape) bool {
+
+	switch a := a.(type) {
+
+	case *resolv.Rectangle:
+		b, ok := b.(*resolv.Rectangle)
+		return ok && a.ID() == b.ID() && a.X == b.X && a.Y == b.Y && a.W == b.W && a.H == b.H && equalTags(a, b)
+
+	case *resolv.Circle:
+		b, ok := b.(*resolv.Circle)
+		return ok && a.ID() == b.ID() && a.X == b.X && a.Y == b.Y && a.Radius == b.Radius && equalTags(a, b)
+
+	case *resolv.Line:
+		b, ok := b.(*resolv.Line)
+		return ok && a.ID() == b.ID() && a.X == b.X && a.Y == b.Y && a.X2 == b.X2 && a.Y2 == b.Y2 && equalTags(a, b)
+
+	case *resolv.Space:
+		b, ok := b.(*resolv.Space)
+		if !ok || a.Length() != b.Length() {
+			return false
+		}
+		for i := 0; i < a.Length(); i++ {
+			if !equalShapes(a.Get(i), b.Get(i)) {
+				return false
+			}
+		}
+		return true
+
+	}
+
+	return false
+
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(11))
+
+	for i := 0; i < 2000; i++ {
+
+		shape := randomShape(rng, 3)
+
+		msg := ToProto(shape)
+		if msg == nil {
+			t.Fatalf("ToProto(%v) = nil", shape)
+		}
+
+		back, err := FromProto(msg)
+		if err != nil {
+			t.Fatalf("FromProto(ToProto(%v)) error = %v", shape, err)
+		}
+		if !equalShapes(shape, back) {
+			t.Fatalf("FromProto(ToProto(%v)) = %v, want an equal Shape", shape, back)
+		}
+
+		decoded := &Shape{}
+		if err := decoded.Unmarshal(msg.Marshal()); err != nil {
+			t.Fatalf("Unmarshal(Marshal()) of %v error = %v", shape, err)
+		}
+		back, err = FromProto(decoded)
+		if err != nil {
+			t.Fatalf("FromProto() of the unmarshaled %v error = %v", shape, err)
+		}
+		if !equalShapes(shape, back) {
+			t.Fatalf("round trip through the wire format of %v = %v, want an equal Shape", shape, back)
+		}
+
+	}
+
+}
+
+func TestFromProtoUnknownShape(t *testing.T) {
+
+	// A Shape message with only field 9, which this version of resolv.proto doesn't define.
+	newer := &Shape{}
+	if err := newer.Unmarshal([]byte{9<<3 | wireBytes, 2, 8, 1}); err != nil {
+		t.Fatalf("Unmarshal() of an unknown kind of Shape error = %v, want it skipped", err)
+	}
+
+	tests := []struct {
+		name string
+		msg  *Shape
+	}{
+		{"nil", nil},
+		{"empty", &Shape{}},
+		{"newer kind", newer},
+		{"within a Space", &Shape{Space: &Space{Shapes: []*Shape{ToProto(resolv.NewRectangle(0, 0, 4, 4)), {}}}}},
+		{"nested", &Shape{Space: &Space{Shapes: []*Shape{{Space: &Space{Shapes: []*Shape{nil}}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shape, err := FromProto(tt.msg)
+			if !errors.Is(err, ErrUnknownShape) {
+				t.Errorf("FromProto() error = %v, want ErrUnknownShape", err)
+			}
+			if shape != nil {
+				t.Errorf("FromProto() = %v, want nil", shape)
+			}
+		})
+	}
+
+	if msg := ToProto(struct{ *resolv.Rectangle }{resolv.NewRectangle(0, 0, 4, 4)}); msg != nil {
+		t.Errorf("ToProto() of a custom Shape = %v, want nil", msg)
+	}
+
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(12))
+
+	var valid []byte
+	for len(valid) < 64 {
+		valid = ToProto(randomShape(rng, 3)).Marshal()
+	}
+
+	// Truncated, corrupted, and random messages must only ever fail with an error, never panic; anything that does
+	// decode must convert without panicking too.
+	try := func(data []byte) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("Unmarshal(%x) panicked: %v", data, r)
+			}
+		}()
+		msg := &Shape{}
+		if err := msg.Unmarshal(data); err == nil {
+			FromProto(msg)
+		}
+	}
+
+	for i := range valid {
+		try(valid[:i])
+	}
+	for i := 0; i < 5000; i++ {
+		corrupted := append([]byte(nil), valid...)
+		for j := rng.Intn(4); j >= 0; j-- {
+			corrupted[rng.Intn(len(corrupted))] = byte(rng.Intn(256))
+		}
+		try(corrupted)
+		random := make([]byte, rng.Intn(64))
+		rng.Read(random)
+		try(random)
+	}
+
+	// Spaces nested more deeply than maxDepth are rejected rather than recursed into.
+	deep := ToProto(resolv.NewRectangle(0, 0, 4, 4))
+	for i := 0; i <= maxDepth; i++ {
+		deep = &Shape{Space: &Space{Shapes: []*Shape{deep}}}
+	}
+	if err := (&Shape{}).Unmarshal(deep.Marshal()); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Unmarshal() of Spaces nested %d deep error = %v, want ErrMalformed", maxDepth+1, err)
+	}
+
+}
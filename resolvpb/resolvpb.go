@@ -0,0 +1,410 @@
+// Package resolvpb holds protocol buffer messages for resolv's Shapes, for sending them over the network (like to
+// replicate hitboxes from a server to its clients), along with functions converting between the messages and Shapes
+// (see ToProto() and FromProto()). The messages are defined in resolv.proto, so that programs in other languages can
+// generate code for them; in Go, they're encoded and decoded by hand, so that resolvpb doesn't depend on the protobuf
+// runtime. Their Marshal() and Unmarshal() functions read and write the standard protobuf wire format.
+package resolvpb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Shape is one of the built-in resolv Shapes. Exactly one of its fields should be set.
+type Shape struct {
+	Rectangle *Rectangle
+	Circle    *Circle
+	Line      *Line
+	Space     *Space
+}
+
+// Rectangle is a resolv.Rectangle.
+type Rectangle struct {
+	ID   uint64
+	X, Y int32
+	Tags []string
+	W, H int32
+}
+
+// Circle is a resolv.Circle.
+type Circle struct {
+	ID     uint64
+	X, Y   int32
+	Tags   []string
+	Radius int32
+}
+
+// Line is a resolv.Line.
+type Line struct {
+	ID     uint64
+	X, Y   int32
+	Tags   []string
+	X2, Y2 int32
+}
+
+// Space is a resolv.Space, holding its Shapes.
+type Space struct {
+	Shapes []*Shape
+}
+
+// maxDepth is how deeply Spaces can be nested within a message being unmarshaled, so that a malicious message can't
+// exhaust the stack.
+const maxDepth = 64
+
+// ErrMalformed is wrapped by the errors returned for messages that aren't valid protobuf messages.
+var ErrMalformed = errors.New("resolvpb: malformed message")
+
+const (
+	wireVarint = 0
+	wire64     = 1
+	wireBytes  = 2
+	wire32     = 5
+)
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendKey(b []byte, field, wireType int) []byte {
+	return appendVarint(b, uint64(field<<3|wireType))
+}
+
+// appendUint appends the field as a varint, unless it's zero (like proto3 does).
+func appendUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return appendVarint(appendKey(b, field, wireVarint), v)
+}
+
+// appendSint appends the field as a zigzag-encoded sint32, unless it's zero.
+func appendSint(b []byte, field int, v int32) []byte {
+	return appendUint(b, field, uint64(uint32(v<<1)^uint32(v>>31)))
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendVarint(appendKey(b, field, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+// decoder reads the fields of a message.
+type decoder struct {
+	b []byte
+}
+
+func (d *decoder) varint() (uint64, error) {
+	var v uint64
+	for i := uint(0); i < 10; i++ {
+		if len(d.b) == 0 {
+			return 0, fmt.Errorf("%w: truncated varint", ErrMalformed)
+		}
+		c := d.b[0]
+		d.b = d.b[1:]
+		v |= uint64(c&0x7f) << (7 * i)
+		if c < 0x80 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: varint is too long", ErrMalformed)
+}
+
+func (d *decoder) sint() (int32, error) {
+	v, err := d.varint()
+	u := uint32(v)
+	return int32(u>>1) ^ -int32(u&1), err
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.b)) {
+		return nil, fmt.Errorf("%w: truncated field", ErrMalformed)
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v, nil
+}
+
+// next returns the number and wire type of the next field, or 0 if there are no more fields.
+func (d *decoder) next() (int, int, error) {
+	if len(d.b) == 0 {
+		return 0, 0, nil
+	}
+	key, err := d.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	field, wireType := int(key>>3), int(key&7)
+	if field <= 0 || uint64(field) != key>>3 {
+		return 0, 0, fmt.Errorf("%w: invalid field number", ErrMalformed)
+	}
+	return field, wireType, nil
+}
+
+// skip skips the value of a field that isn't known, as protobuf requires for compatibility with newer messages.
+func (d *decoder) skip(wireType int) error {
+	var n int
+	switch wireType {
+	case wireVarint:
+		_, err := d.varint()
+		return err
+	case wireBytes:
+		_, err := d.bytes()
+		return err
+	case wire64:
+		n = 8
+	case wire32:
+		n = 4
+	default:
+		return fmt.Errorf("%w: unsupported wire type %d", ErrMalformed, wireType)
+	}
+	if len(d.b) < n {
+		return fmt.Errorf("%w: truncated field", ErrMalformed)
+	}
+	d.b = d.b[n:]
+	return nil
+}
+
+// expect returns an error if the wire type of a known field isn't the one expected.
+func expect(field, wireType, expected int) error {
+	if wireType != expected {
+		return fmt.Errorf("%w: field %d has wire type %d rather than %d", ErrMalformed, field, wireType, expected)
+	}
+	return nil
+}
+
+// basic holds pointers to the fields Rectangles, Circles, and Lines share, which are numbered the same in each.
+type basic struct {
+	id   *uint64
+	x, y *int32
+	tags *[]string
+}
+
+func (f basic) append(b []byte) []byte {
+	b = appendUint(b, 1, *f.id)
+	b = appendSint(b, 2, *f.x)
+	b = appendSint(b, 3, *f.y)
+	for _, tag := range *f.tags {
+		b = appendBytes(b, 4, []byte(tag))
+	}
+	return b
+}
+
+// unmarshal reads the fields of the message into the shared fields and the extra sint32 fields provided (numbered from
+// 5 on), skipping unknown fields.
+func (f basic) unmarshal(data []byte, extra ...*int32) error {
+
+	d := &decoder{data}
+
+	for {
+
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		switch {
+		case field == 1:
+			if err = expect(field, wireType, wireVarint); err == nil {
+				*f.id, err = d.varint()
+			}
+		case field == 2 || field == 3:
+			if err = expect(field, wireType, wireVarint); err == nil {
+				coord := f.x
+				if field == 3 {
+					coord = f.y
+				}
+				*coord, err = d.sint()
+			}
+		case field == 4:
+			if err = expect(field, wireType, wireBytes); err == nil {
+				var tag []byte
+				if tag, err = d.bytes(); err == nil {
+					*f.tags = append(*f.tags, string(tag))
+				}
+			}
+		case field-5 < len(extra):
+			if err = expect(field, wireType, wireVarint); err == nil {
+				*extra[field-5], err = d.sint()
+			}
+		default:
+			err = d.skip(wireType)
+		}
+
+		if err != nil {
+			return err
+		}
+
+	}
+
+}
+
+// Marshal encodes the Rectangle in the protobuf wire format.
+func (m *Rectangle) Marshal() []byte {
+	b := basic{&m.ID, &m.X, &m.Y, &m.Tags}.append(nil)
+	b = appendSint(b, 5, m.W)
+	return appendSint(b, 6, m.H)
+}
+
+// Unmarshal decodes the Rectangle from the protobuf wire format, overwriting its fields.
+func (m *Rectangle) Unmarshal(data []byte) error {
+	*m = Rectangle{}
+	return basic{&m.ID, &m.X, &m.Y, &m.Tags}.unmarshal(data, &m.W, &m.H)
+}
+
+// Marshal encodes the Circle in the protobuf wire format.
+func (m *Circle) Marshal() []byte {
+	b := basic{&m.ID, &m.X, &m.Y, &m.Tags}.append(nil)
+	return appendSint(b, 5, m.Radius)
+}
+
+// Unmarshal decodes the Circle from the protobuf wire format, overwriting its fields.
+func (m *Circle) Unmarshal(data []byte) error {
+	*m = Circle{}
+	return basic{&m.ID, &m.X, &m.Y, &m.Tags}.unmarshal(data, &m.Radius)
+}
+
+// Marshal encodes the Line in the protobuf wire format.
+func (m *Line) Marshal() []byte {
+	b := basic{&m.ID, &m.X, &m.Y, &m.Tags}.append(nil)
+	b = appendSint(b, 5, m.X2)
+	return appendSint(b, 6, m.Y2)
+}
+
+// Unmarshal decodes the Line from the protobuf wire format, overwriting its fields.
+func (m *Line) Unmarshal(data []byte) error {
+	*m = Line{}
+	return basic{&m.ID, &m.X, &m.Y, &m.Tags}.unmarshal(data, &m.X2, &m.Y2)
+}
+
+// Marshal encodes the Space in the protobuf wire format.
+func (m *Space) Marshal() []byte {
+	var b []byte
+	for _, shape := range m.Shapes {
+		b = appendBytes(b, 1, shape.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes the Space from the protobuf wire format, overwriting its fields.
+func (m *Space) Unmarshal(data []byte) error {
+	return m.unmarshal(data, 0)
+}
+
+func (m *Space) unmarshal(data []byte, depth int) error {
+
+	*m = Space{}
+	d := &decoder{data}
+
+	for {
+
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		if field != 1 {
+			if err := d.skip(wireType); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expect(field, wireType, wireBytes); err != nil {
+			return err
+		}
+		value, err := d.bytes()
+		if err != nil {
+			return err
+		}
+		shape := &Shape{}
+		if err := shape.unmarshal(value, depth); err != nil {
+			return err
+		}
+		m.Shapes = append(m.Shapes, shape)
+
+	}
+
+}
+
+// Marshal encodes the Shape in the protobuf wire format.
+func (m *Shape) Marshal() []byte {
+	switch {
+	case m.Rectangle != nil:
+		return appendBytes(nil, 1, m.Rectangle.Marshal())
+	case m.Circle != nil:
+		return appendBytes(nil, 2, m.Circle.Marshal())
+	case m.Line != nil:
+		return appendBytes(nil, 3, m.Line.Marshal())
+	case m.Space != nil:
+		return appendBytes(nil, 4, m.Space.Marshal())
+	}
+	return nil
+}
+
+// Unmarshal decodes the Shape from the protobuf wire format, overwriting its fields. Like with generated protobuf code,
+// kinds of Shapes it doesn't know (like ones added to resolv.proto later) are skipped, leaving the Shape empty; see
+// FromProto().
+func (m *Shape) Unmarshal(data []byte) error {
+	return m.unmarshal(data, 0)
+}
+
+func (m *Shape) unmarshal(data []byte, depth int) error {
+
+	*m = Shape{}
+	d := &decoder{data}
+
+	for {
+
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		if field < 1 || field > 4 {
+			if err := d.skip(wireType); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expect(field, wireType, wireBytes); err != nil {
+			return err
+		}
+		value, err := d.bytes()
+		if err != nil {
+			return err
+		}
+
+		// As with any oneof, the last kind in the message wins.
+		*m = Shape{}
+		switch field {
+		case 1:
+			m.Rectangle = &Rectangle{}
+			err = m.Rectangle.Unmarshal(value)
+		case 2:
+			m.Circle = &Circle{}
+			err = m.Circle.Unmarshal(value)
+		case 3:
+			m.Line = &Line{}
+			err = m.Line.Unmarshal(value)
+		case 4:
+			if depth >= maxDepth {
+				return fmt.Errorf("%w: spaces are nested too deeply", ErrMalformed)
+			}
+			m.Space = &Space{}
+			err = m.Space.unmarshal(value, depth+1)
+		}
+		if err != nil {
+			return err
+		}
+
+	}
+
+}